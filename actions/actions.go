@@ -0,0 +1,12 @@
+package actions
+
+import "mr-conflict-checker/provider"
+
+// ignoreReadOnly drops the error of a write refused by a read-only client, which has
+// already recorded it as a skipped write
+func ignoreReadOnly(err error) error {
+	if provider.IsReadOnly(err) {
+		return nil
+	}
+	return err
+}
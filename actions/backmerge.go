@@ -0,0 +1,134 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+// syncBranchPrefix starts the name of the branches created in branch mode
+const syncBranchPrefix = "sync/"
+
+// BackMerger opens merge requests that bring the target branch back into the source branch,
+// which resolves the drift behind most release->master conflicts
+type BackMerger struct {
+	client *gitlab.Client
+	mode   string
+	now    func() time.Time
+}
+
+// NewBackMerger creates a back-merger from its configuration
+func NewBackMerger(client *gitlab.Client, cfg config.BackMergeConfig) *BackMerger {
+	mode := cfg.Mode
+	if mode == "" {
+		mode = config.BackMergeModeDirect
+	}
+	return &BackMerger{client: client, mode: mode, now: time.Now}
+}
+
+// Run makes sure every repository with conflicting MRs has an open back-merge MR and links it in the report
+func (b *BackMerger) Run(ctx context.Context, report *models.Report) error {
+	var errs []string
+
+	for i := range report.Repositories {
+		repoReport := &report.Repositories[i]
+		if len(repoReport.ConflictingMRs) == 0 {
+			continue
+		}
+
+		mr, err := b.ensureBackMerge(ctx, repoReport.Repository, repoReport.ConflictingMRs)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", repoReport.Repository.Name, err))
+			continue
+		}
+		repoReport.BackMergeMR = mr
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to create back-merge MRs: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ensureBackMerge returns the open back-merge MR of a repository, creating it if there is none
+func (b *BackMerger) ensureBackMerge(ctx context.Context, repo models.Repository, conflicting []models.MergeRequest) (*models.MergeRequest, error) {
+	// The conflicting MRs go source -> target, so the back-merge goes target -> source
+	source := conflicting[0].SourceBranch
+	target := conflicting[0].TargetBranch
+
+	existing, err := b.findBackMerge(ctx, repo.ID, source, target)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		slog.Debug("Back-merge MR already open", "repository", repo.Name, "mr", existing.ID)
+		return existing, nil
+	}
+
+	branch := target
+	if b.mode == config.BackMergeModeBranch {
+		branch = fmt.Sprintf("%s%s-%s", syncBranchPrefix, source, b.now().UTC().Format("2006-01-02"))
+	}
+
+	if branch != target {
+		existingBranch, err := b.client.GetBranch(ctx, repo.ID, branch)
+		if err != nil {
+			return nil, err
+		}
+		if existingBranch == nil {
+			// A read-only client refuses the branch but should still show the MR it would open
+			if _, err := b.client.CreateBranch(ctx, repo.ID, branch, target); err == nil {
+				slog.Info("Created sync branch", "repository", repo.Name, "branch", branch, "ref", target)
+			} else if !provider.IsReadOnly(err) {
+				return nil, err
+			}
+		}
+	}
+
+	mr, err := b.client.CreateMergeRequest(ctx, repo.ID, gitlab.CreateMergeRequestOptions{
+		SourceBranch:       branch,
+		TargetBranch:       source,
+		Title:              fmt.Sprintf("Back-merge %s into %s", target, source),
+		Description:        backMergeDescription(source, target, conflicting),
+		RemoveSourceBranch: branch != target,
+	})
+	if err != nil {
+		return nil, ignoreReadOnly(err)
+	}
+
+	slog.Info("Created back-merge MR", "repository", repo.Name, "mr", mr.ID, "url", mr.WebURL)
+	return mr, nil
+}
+
+// findBackMerge returns an open MR from target (or one of its sync branches) into source, or nil
+func (b *BackMerger) findBackMerge(ctx context.Context, projectID int, source, target string) (*models.MergeRequest, error) {
+	mrs, err := b.client.ListMergeRequestsWithOptions(ctx, projectID, gitlab.MergeRequestListOptions{TargetBranch: source})
+	if err != nil {
+		return nil, err
+	}
+	for i := range mrs {
+		if mrs[i].SourceBranch == target || strings.HasPrefix(mrs[i].SourceBranch, syncBranchPrefix+source+"-") {
+			return &mrs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// backMergeDescription renders the description of a back-merge MR
+func backMergeDescription(source, target string, conflicting []models.MergeRequest) string {
+	var refs []string
+	for _, mr := range conflicting {
+		refs = append(refs, fmt.Sprintf("!%d", mr.ID))
+	}
+
+	return fmt.Sprintf("Brings the changes on `%s` that are missing from `%s` back into it, "+
+		"to resolve the conflicts of %s.\n\n_Opened by MR Conflict Checker._\n",
+		target, source, strings.Join(refs, ", "))
+}
@@ -0,0 +1,155 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+)
+
+// fakeBackMergeServer serves merge request, branch listing and creation for project 1 from memory
+type fakeBackMergeServer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	mrs      []models.MergeRequest
+	branches map[string]bool
+	created  []map[string]interface{}
+}
+
+// newFakeBackMergeServer starts a server holding the given merge requests of project 1
+func newFakeBackMergeServer(t *testing.T, mrs ...models.MergeRequest) *fakeBackMergeServer {
+	fake := &fakeBackMergeServer{mrs: mrs, branches: map[string]bool{"master": true, "release": true}}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeBackMergeServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	const branchesPath = "/api/v4/projects/1/repository/branches"
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/1/merge_requests":
+		result := []models.MergeRequest{}
+		for _, mr := range f.mrs {
+			if target := r.URL.Query().Get("target_branch"); target == "" || mr.TargetBranch == target {
+				result = append(result, mr)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/1/merge_requests":
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.created = append(f.created, body)
+		mr := models.MergeRequest{
+			ID:           100 + len(f.created),
+			Title:        body["title"].(string),
+			SourceBranch: body["source_branch"].(string),
+			TargetBranch: body["target_branch"].(string),
+			WebURL:       "https://gitlab.example.com/backend/api/-/merge_requests/100",
+		}
+		f.mrs = append(f.mrs, mr)
+		json.NewEncoder(w).Encode(mr)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, branchesPath+"/"):
+		name := strings.TrimPrefix(r.URL.Path, branchesPath+"/")
+		if !f.branches[name] {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Branch Not Found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(models.Branch{Name: name})
+	case r.Method == http.MethodPost && r.URL.Path == branchesPath:
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.branches[body["branch"]] = true
+		json.NewEncoder(w).Encode(models.Branch{Name: body["branch"]})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestBackMerger_CreatesDirectBackMerge(t *testing.T) {
+	fake := newFakeBackMergeServer(t, models.MergeRequest{ID: 5, SourceBranch: "release", TargetBranch: "master"})
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	report := buildActionsTestReport()
+	backMerger := NewBackMerger(client, config.BackMergeConfig{Enabled: true})
+	require.NoError(t, backMerger.Run(context.Background(), report))
+
+	require.Len(t, fake.created, 1)
+	assert.Equal(t, "master", fake.created[0]["source_branch"])
+	assert.Equal(t, "release", fake.created[0]["target_branch"])
+	assert.Equal(t, "Back-merge master into release", fake.created[0]["title"])
+	assert.Contains(t, fake.created[0]["description"], "!5")
+
+	require.NotNil(t, report.Repositories[0].BackMergeMR)
+	assert.Equal(t, 101, report.Repositories[0].BackMergeMR.ID)
+
+	// The next run links the existing MR instead of opening another
+	report = buildActionsTestReport()
+	require.NoError(t, backMerger.Run(context.Background(), report))
+	assert.Len(t, fake.created, 1)
+	require.NotNil(t, report.Repositories[0].BackMergeMR)
+	assert.Equal(t, 101, report.Repositories[0].BackMergeMR.ID)
+}
+
+func TestBackMerger_BranchMode(t *testing.T) {
+	fake := newFakeBackMergeServer(t)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	backMerger := NewBackMerger(client, config.BackMergeConfig{Enabled: true, Mode: config.BackMergeModeBranch})
+	backMerger.now = func() time.Time { return time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC) }
+
+	report := buildActionsTestReport()
+	require.NoError(t, backMerger.Run(context.Background(), report))
+
+	assert.True(t, fake.branches["sync/release-2024-01-10"])
+	require.Len(t, fake.created, 1)
+	assert.Equal(t, "sync/release-2024-01-10", fake.created[0]["source_branch"])
+	assert.Equal(t, "release", fake.created[0]["target_branch"])
+	assert.Equal(t, true, fake.created[0]["remove_source_branch"])
+
+	// A sync MR from an earlier day counts as an existing back-merge
+	backMerger.now = func() time.Time { return time.Date(2024, 1, 11, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, backMerger.Run(context.Background(), buildActionsTestReport()))
+	assert.Len(t, fake.created, 1)
+	assert.False(t, fake.branches["sync/release-2024-01-11"])
+}
+
+func TestBackMerger_ReadOnlyAndCleanRepositories(t *testing.T) {
+	fake := newFakeBackMergeServer(t)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token", gitlab.WithReadOnly())
+	defer client.Close()
+
+	report := buildActionsTestReport()
+	report.AddRepository(models.Repository{ID: 2, Name: "web"}, nil, models.StatusAccessible, "")
+
+	backMerger := NewBackMerger(client, config.BackMergeConfig{Enabled: true, Mode: config.BackMergeModeBranch})
+	require.NoError(t, backMerger.Run(context.Background(), report))
+
+	assert.Empty(t, fake.created)
+	assert.Len(t, fake.branches, 2)
+	assert.Nil(t, report.Repositories[0].BackMergeMR)
+	assert.Nil(t, report.Repositories[1].BackMergeMR)
+	assert.Len(t, client.SkippedWrites(), 2)
+}
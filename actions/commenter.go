@@ -0,0 +1,234 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+// CommentMarker is the hidden HTML comment used to find the note posted by this tool on later runs
+const CommentMarker = "<!-- mr-conflict-checker:conflict -->"
+
+// Commenter keeps a single bot comment up to date on each conflicting merge request
+type Commenter struct {
+	client provider.Provider
+	cfg    config.CommentConfig
+}
+
+// conflictingFilesFinder is implemented by the providers that can tell which files conflict
+type conflictingFilesFinder interface {
+	ConflictingFiles(ctx context.Context, repoID int, mr models.MergeRequest) ([]string, error)
+}
+
+// NewCommenter creates a commenter from its configuration
+func NewCommenter(client provider.Provider, cfg config.CommentConfig) *Commenter {
+	if cfg.OnResolved == "" {
+		cfg.OnResolved = config.CommentOnResolvedUpdate
+	}
+	return &Commenter{client: client, cfg: cfg}
+}
+
+// Run comments on every conflicting merge request in the report and resolves the comments of
+// merge requests that stopped conflicting since the previous report or, only when there is no
+// usable previous report, according to their current conflict status
+func (c *Commenter) Run(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	var errs []string
+	handled := make(map[string]bool)
+
+	for _, entry := range report.ConflictingEntries() {
+		handled[entry.Key()] = true
+		if err := c.commentConflict(ctx, entry, reportDiff); err != nil {
+			errs = append(errs, fmt.Sprintf("%s!%d: %v", entry.RepositoryName, entry.MergeRequest.ID, err))
+		}
+	}
+
+	if reportDiff != nil {
+		for _, change := range reportDiff.Resolved {
+			if err := c.resolveConflict(ctx, change); err != nil {
+				errs = append(errs, fmt.Sprintf("%s!%d: %v", change.RepositoryName, change.MergeRequest.ID, err))
+			}
+		}
+	} else {
+		// Without a previous report to name the resolved conflicts, the open merge requests are
+		// checked for comments left on those that stopped conflicting
+		for _, repoReport := range report.Repositories {
+			// Repositories that failed to scan are left alone, their conflict state is unknown
+			if repoReport.Status == models.StatusError || repoReport.Status == models.StatusNoMRs {
+				continue
+			}
+			if err := c.resolveClean(ctx, repoReport.Repository, handled); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", repoReport.Repository.Name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to update comments: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// commentConflict creates or updates the conflict comment on a merge request
+func (c *Commenter) commentConflict(ctx context.Context, entry models.ConflictChange, reportDiff *models.ReportDiff) error {
+	var files []string
+	if finder, ok := c.client.(conflictingFilesFinder); ok {
+		var err error
+		files, err = finder.ConflictingFiles(ctx, entry.RepositoryID, entry.MergeRequest)
+		if err != nil {
+			// The comment is still useful without the file list
+			slog.Debug("Could not determine conflicting files", "repository", entry.RepositoryName, "mr", entry.MergeRequest.ID, "error", err)
+			files = nil
+		}
+	}
+
+	if firstSeen, ok := reportDiff.FirstSeen(entry.Key()); ok {
+		entry.FirstSeen = firstSeen
+	}
+	body := conflictCommentBody(entry, files)
+
+	note, err := c.findComment(ctx, entry.RepositoryID, entry.MergeRequest.ID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case note == nil:
+		if _, err := c.client.CreateComment(ctx, entry.RepositoryID, entry.MergeRequest.ID, body); err != nil {
+			return ignoreReadOnly(err)
+		}
+		slog.Info("Commented on conflicting merge request", "repository", entry.RepositoryName, "mr", entry.MergeRequest.ID)
+	case note.Body != body:
+		if _, err := c.client.UpdateComment(ctx, entry.RepositoryID, entry.MergeRequest.ID, note.ID, body); err != nil {
+			return ignoreReadOnly(err)
+		}
+		slog.Info("Updated conflict comment", "repository", entry.RepositoryName, "mr", entry.MergeRequest.ID, "note", note.ID)
+	default:
+		slog.Debug("Conflict comment is up to date", "repository", entry.RepositoryName, "mr", entry.MergeRequest.ID)
+	}
+
+	return nil
+}
+
+// resolveClean resolves the stale comments left on the open release->master merge requests of a
+// repository that are confirmed to no longer conflict
+func (c *Commenter) resolveClean(ctx context.Context, repo models.Repository, handled map[string]bool) error {
+	mrs, err := c.client.ListMergeRequests(ctx, repo.ID, models.CheckedSourceBranch, models.CheckedTargetBranch)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, mr := range mrs {
+		key := models.ConflictKey(repo.ID, mr.ID)
+		// Merge requests still being checked may conflict, filtered ones are not in the report and
+		// those without comments have no comment to resolve
+		if handled[key] || mr.HasConflicts || !mr.ConflictChecked() || mr.UserNotesCount == 0 {
+			continue
+		}
+		handled[key] = true
+		change := models.ConflictChange{RepositoryID: repo.ID, RepositoryName: repo.Name, MergeRequest: mr}
+		if err := c.resolveConflict(ctx, change); err != nil {
+			errs = append(errs, fmt.Sprintf("!%d: %v", mr.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// resolveConflict deletes or marks resolved the comment on a merge request that no longer conflicts
+func (c *Commenter) resolveConflict(ctx context.Context, change models.ConflictChange) error {
+	note, err := c.findComment(ctx, change.RepositoryID, change.MergeRequest.ID)
+	if err != nil {
+		return err
+	}
+	if note == nil {
+		return nil
+	}
+
+	if c.cfg.OnResolved == config.CommentOnResolvedDelete {
+		if err := c.client.DeleteComment(ctx, change.RepositoryID, change.MergeRequest.ID, note.ID); err != nil {
+			return ignoreReadOnly(err)
+		}
+		slog.Info("Deleted conflict comment", "repository", change.RepositoryName, "mr", change.MergeRequest.ID, "note", note.ID)
+		return nil
+	}
+
+	body := resolvedCommentBody()
+	if note.Body == body {
+		return nil
+	}
+	if _, err := c.client.UpdateComment(ctx, change.RepositoryID, change.MergeRequest.ID, note.ID, body); err != nil {
+		return ignoreReadOnly(err)
+	}
+	slog.Info("Marked conflict comment resolved", "repository", change.RepositoryName, "mr", change.MergeRequest.ID, "note", note.ID)
+	return nil
+}
+
+// findComment returns the note carrying CommentMarker, or nil if the merge request has none
+func (c *Commenter) findComment(ctx context.Context, projectID, mrID int) (*models.Note, error) {
+	notes, err := c.client.ListComments(ctx, projectID, mrID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range notes {
+		if !notes[i].System && strings.Contains(notes[i].Body, CommentMarker) {
+			return &notes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// conflictCommentBody renders the comment posted on a conflicting merge request
+func conflictCommentBody(entry models.ConflictChange, files []string) string {
+	var body strings.Builder
+	mr := entry.MergeRequest
+
+	body.WriteString(CommentMarker + "\n")
+	body.WriteString(":warning: **Merge conflict detected**\n\n")
+
+	if mr.Author.Username != "" {
+		body.WriteString(fmt.Sprintf("@%s, this ", mr.Author.Username))
+	} else {
+		body.WriteString("This ")
+	}
+	body.WriteString(fmt.Sprintf("merge request from `%s` into `%s` has conflicts that must be resolved before it can be merged.\n\n",
+		mr.SourceBranch, mr.TargetBranch))
+
+	if len(files) > 0 {
+		body.WriteString("**Conflicting files:**\n\n")
+		for _, file := range files {
+			body.WriteString(fmt.Sprintf("- `%s`\n", file))
+		}
+		body.WriteString("\n")
+	} else {
+		body.WriteString("The conflicting files could not be determined")
+		if mr.WebURL != "" {
+			body.WriteString(fmt.Sprintf("; see the [conflict resolution page](%s/conflicts)", strings.TrimSuffix(mr.WebURL, "/")))
+		}
+		body.WriteString(".\n\n")
+	}
+
+	if !entry.FirstSeen.IsZero() {
+		body.WriteString(fmt.Sprintf("_Conflicting since %s. ", entry.FirstSeen.UTC().Format("2006-01-02")))
+	} else {
+		body.WriteString("_")
+	}
+	body.WriteString("This comment is maintained by MR Conflict Checker and will be updated when the conflict is resolved._\n")
+
+	return body.String()
+}
+
+// resolvedCommentBody renders the comment left once a merge request no longer conflicts
+func resolvedCommentBody() string {
+	return CommentMarker + "\n" +
+		":white_check_mark: **Merge conflict resolved**\n\n" +
+		"This merge request no longer has conflicts.\n"
+}
@@ -0,0 +1,360 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/github"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/githubfake"
+	"mr-conflict-checker/provider"
+)
+
+var (
+	notesPathPattern = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)/notes(?:/(\d+))?$`)
+	mrsPathPattern   = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests$`)
+)
+
+// fakeNotesServer serves the GitLab notes and merge request list APIs from memory; any other endpoint fails
+type fakeNotesServer struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	notes  map[string][]models.Note
+	mrs    map[int][]models.MergeRequest
+	nextID int
+	calls  []string
+	reads  []string
+}
+
+// newFakeNotesServer starts a notes API server with no notes and no open merge requests
+func newFakeNotesServer(t *testing.T) *fakeNotesServer {
+	fake := &fakeNotesServer{notes: make(map[string][]models.Note), mrs: make(map[int][]models.MergeRequest), nextID: 1}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+// mrNotes returns the notes of a merge request
+func (f *fakeNotesServer) mrNotes(projectID, mrID int) []models.Note {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.Note(nil), f.notes[models.ConflictKey(projectID, mrID)]...)
+}
+
+// addNote seeds a note on a merge request
+func (f *fakeNotesServer) addNote(projectID, mrID int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := models.ConflictKey(projectID, mrID)
+	f.notes[key] = append(f.notes[key], models.Note{ID: f.nextID, Body: body})
+	f.nextID++
+}
+
+// setMergeRequests sets the open merge requests of a project
+func (f *fakeNotesServer) setMergeRequests(projectID int, mrs []models.MergeRequest) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mrs[projectID] = mrs
+}
+
+// noteReads returns the paths of the note lists requested
+func (f *fakeNotesServer) noteReads() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.reads...)
+}
+
+// writeCalls returns the non-GET requests received
+func (f *fakeNotesServer) writeCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeNotesServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if match := mrsPathPattern.FindStringSubmatch(r.URL.Path); match != nil && r.Method == http.MethodGet {
+		projectID, _ := strconv.Atoi(match[1])
+		// Like GitLab, every merge request tells how many notes it has
+		mrs := []models.MergeRequest{}
+		for _, mr := range f.mrs[projectID] {
+			mr.UserNotesCount = len(f.notes[models.ConflictKey(projectID, mr.ID)])
+			mrs = append(mrs, mr)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mrs)
+		return
+	}
+
+	match := notesPathPattern.FindStringSubmatch(r.URL.Path)
+	if match == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	projectID, _ := strconv.Atoi(match[1])
+	mrID, _ := strconv.Atoi(match[2])
+	key := models.ConflictKey(projectID, mrID)
+
+	if r.Method != http.MethodGet {
+		f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	} else {
+		f.reads = append(f.reads, r.URL.Path)
+	}
+
+	var request struct {
+		Body string `json:"body"`
+	}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&request)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && match[3] == "":
+		notes := f.notes[key]
+		if notes == nil {
+			notes = []models.Note{}
+		}
+		json.NewEncoder(w).Encode(notes)
+	case r.Method == http.MethodPost && match[3] == "":
+		note := models.Note{ID: f.nextID, Body: request.Body}
+		f.nextID++
+		f.notes[key] = append(f.notes[key], note)
+		json.NewEncoder(w).Encode(note)
+	case r.Method == http.MethodPut && match[3] != "":
+		noteID, _ := strconv.Atoi(match[3])
+		for i := range f.notes[key] {
+			if f.notes[key][i].ID == noteID {
+				f.notes[key][i].Body = request.Body
+				json.NewEncoder(w).Encode(f.notes[key][i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodDelete && match[3] != "":
+		noteID, _ := strconv.Atoi(match[3])
+		for i := range f.notes[key] {
+			if f.notes[key][i].ID == noteID {
+				f.notes[key] = append(f.notes[key][:i], f.notes[key][i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// buildActionsTestReport creates a report with one conflicting merge request in project 1
+func buildActionsTestReport() *models.Report {
+	report := &models.Report{Timestamp: "2024-01-10T00-00-00"}
+	report.AddRepository(models.Repository{ID: 1, Name: "api", PathWithNamespace: "backend/api"}, []models.MergeRequest{{
+		ID:           5,
+		Title:        "Release 1.2",
+		Author:       models.Author{Name: "Alice", Username: "alice"},
+		WebURL:       "https://gitlab.example.com/backend/api/-/merge_requests/5",
+		SourceBranch: "release",
+		TargetBranch: "master",
+		HasConflicts: true,
+	}}, models.StatusConflicts, "")
+	return report
+}
+
+func TestCommenter_CreatesThenUpdatesSingleComment(t *testing.T) {
+	fake := newFakeNotesServer(t)
+	fake.addNote(1, 5, "LGTM")
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	commenter := NewCommenter(provider.NewGitLab(client, client), config.CommentConfig{Enabled: true})
+	ctx := context.Background()
+
+	// First run creates the comment
+	require.NoError(t, commenter.Run(ctx, buildActionsTestReport(), nil))
+	notes := fake.mrNotes(1, 5)
+	require.Len(t, notes, 2)
+	assert.Contains(t, notes[1].Body, CommentMarker)
+	assert.Contains(t, notes[1].Body, "@alice")
+	assert.Contains(t, notes[1].Body, "from `release` into `master`")
+	// The fake server has no changes endpoint, so the files fall back to the conflict page link
+	assert.Contains(t, notes[1].Body, "merge_requests/5/conflicts")
+
+	// An identical second run leaves the comment alone
+	require.NoError(t, commenter.Run(ctx, buildActionsTestReport(), nil))
+	assert.Equal(t, []string{"POST /api/v4/projects/1/merge_requests/5/notes"}, fake.writeCalls())
+
+	// A changed body edits the existing comment instead of adding another
+	firstSeen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reportDiff := &models.ReportDiff{Persisting: []models.ConflictChange{{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 5}, FirstSeen: firstSeen}}}
+	require.NoError(t, commenter.Run(ctx, buildActionsTestReport(), reportDiff))
+
+	notes = fake.mrNotes(1, 5)
+	require.Len(t, notes, 2)
+	assert.Contains(t, notes[1].Body, "Conflicting since 2024-01-01")
+	assert.Equal(t, fmt.Sprintf("PUT /api/v4/projects/1/merge_requests/5/notes/%d", notes[1].ID), fake.writeCalls()[1])
+}
+
+func TestCommenter_ResolvesComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		onResolved string
+		wantNotes  int
+	}{
+		{name: "update", onResolved: "", wantNotes: 1},
+		{name: "delete", onResolved: config.CommentOnResolvedDelete, wantNotes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeNotesServer(t)
+			fake.addNote(1, 5, CommentMarker+"\nconflict")
+
+			client := gitlab.NewClient(fake.server.URL, "test-token")
+			defer client.Close()
+
+			commenter := NewCommenter(provider.NewGitLab(client, client), config.CommentConfig{Enabled: true, OnResolved: tt.onResolved})
+			reportDiff := &models.ReportDiff{Resolved: []models.ConflictChange{{RepositoryID: 1, RepositoryName: "api", MergeRequest: models.MergeRequest{ID: 5}}}}
+
+			require.NoError(t, commenter.Run(context.Background(), &models.Report{}, reportDiff))
+
+			notes := fake.mrNotes(1, 5)
+			require.Len(t, notes, tt.wantNotes)
+			if tt.wantNotes > 0 {
+				assert.Contains(t, notes[0].Body, "Merge conflict resolved")
+			}
+		})
+	}
+}
+
+func TestCommenter_ResolvedWithoutCommentIsNoop(t *testing.T) {
+	fake := newFakeNotesServer(t)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	commenter := NewCommenter(provider.NewGitLab(client, client), config.CommentConfig{Enabled: true})
+	reportDiff := &models.ReportDiff{Resolved: []models.ConflictChange{{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 5}}}}
+
+	require.NoError(t, commenter.Run(context.Background(), &models.Report{}, reportDiff))
+	assert.Empty(t, fake.writeCalls())
+}
+
+func TestCommenter_ResolvesCleanMergeRequestsWithoutPreviousReport(t *testing.T) {
+	fake := newFakeNotesServer(t)
+	fake.addNote(1, 6, CommentMarker+"\nconflict")
+	fake.addNote(1, 7, CommentMarker+"\nconflict")
+	fake.addNote(1, 8, CommentMarker+"\nconflict")
+	fake.setMergeRequests(1, []models.MergeRequest{
+		{ID: 5, SourceBranch: "release", TargetBranch: "master", HasConflicts: true, MergeStatus: "cannot_be_merged"},
+		{ID: 6, SourceBranch: "release", TargetBranch: "master", MergeStatus: "can_be_merged"},
+		// Still being checked, or conflicting but filtered out of the report
+		{ID: 7, SourceBranch: "release", TargetBranch: "master", MergeStatus: "checking"},
+		{ID: 8, SourceBranch: "release", TargetBranch: "master", HasConflicts: true, MergeStatus: "cannot_be_merged"},
+		// Clean without any comment
+		{ID: 9, SourceBranch: "release", TargetBranch: "master", MergeStatus: "can_be_merged"},
+	})
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	commenter := NewCommenter(provider.NewGitLab(client, client), config.CommentConfig{Enabled: true})
+	require.NoError(t, commenter.Run(context.Background(), buildActionsTestReport(), nil))
+
+	notes := fake.mrNotes(1, 6)
+	require.Len(t, notes, 1)
+	assert.Contains(t, notes[0].Body, "Merge conflict resolved")
+	assert.Contains(t, fake.mrNotes(1, 7)[0].Body, "\nconflict")
+	assert.Contains(t, fake.mrNotes(1, 8)[0].Body, "\nconflict")
+	assert.Len(t, fake.writeCalls(), 2, "one comment created on !5 and one resolved on !6")
+	assert.NotContains(t, fake.noteReads(), "/api/v4/projects/1/merge_requests/9/notes")
+}
+
+func TestCommenter_OnlyResolvesChangesWithPreviousReport(t *testing.T) {
+	fake := newFakeNotesServer(t)
+	fake.addNote(1, 6, CommentMarker+"\nconflict")
+	fake.addNote(1, 7, CommentMarker+"\nconflict")
+	fake.setMergeRequests(1, []models.MergeRequest{
+		{ID: 6, SourceBranch: "release", TargetBranch: "master", MergeStatus: "can_be_merged"},
+		{ID: 7, SourceBranch: "release", TargetBranch: "master", MergeStatus: "can_be_merged"},
+	})
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	// The previous report names !6 as resolved; !7 was resolved in an earlier run
+	reportDiff := &models.ReportDiff{Resolved: []models.ConflictChange{{RepositoryID: 1, RepositoryName: "api", MergeRequest: models.MergeRequest{ID: 6}}}}
+	commenter := NewCommenter(provider.NewGitLab(client, client), config.CommentConfig{Enabled: true})
+	require.NoError(t, commenter.Run(context.Background(), buildActionsTestReport(), reportDiff))
+
+	assert.Contains(t, fake.mrNotes(1, 6)[0].Body, "Merge conflict resolved")
+	assert.Contains(t, fake.mrNotes(1, 7)[0].Body, "\nconflict")
+	assert.Equal(t, []string{"/api/v4/projects/1/merge_requests/5/notes", "/api/v4/projects/1/merge_requests/6/notes"}, fake.noteReads(),
+		"no merge request is listed and only the changed ones are read")
+}
+
+func TestCommenter_GitHubPullRequest(t *testing.T) {
+	server := githubfake.NewServer()
+	defer server.Close()
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "api", Namespace: models.Namespace{Path: "backend"}}})
+	server.SetPullRequests(1, []githubfake.PullRequest{{Number: 5, Head: "release", Base: "master"}})
+
+	client := github.NewClient(server.URL(), githubfake.DefaultToken)
+	commenter := NewCommenter(provider.NewGitHub(client, nil), config.CommentConfig{Enabled: true})
+	ctx := context.Background()
+
+	require.NoError(t, commenter.Run(ctx, buildActionsTestReport(), nil))
+	comments := server.Comments(1, 5)
+	require.Len(t, comments, 1)
+	assert.Contains(t, comments[0].Body, CommentMarker)
+	assert.Contains(t, comments[0].Body, "@alice")
+	// GitHub cannot list the conflicting files
+	assert.NotContains(t, comments[0].Body, "**Conflicting files:**")
+
+	reportDiff := &models.ReportDiff{Resolved: []models.ConflictChange{{RepositoryID: 1, RepositoryName: "api", MergeRequest: models.MergeRequest{ID: 5}}}}
+	require.NoError(t, commenter.Run(ctx, &models.Report{}, reportDiff))
+	comments = server.Comments(1, 5)
+	require.Len(t, comments, 1)
+	assert.Contains(t, comments[0].Body, "Merge conflict resolved")
+}
+
+func TestConflictCommentBody_ListsFiles(t *testing.T) {
+	entry := buildActionsTestReport().ConflictingEntries()[0]
+	body := conflictCommentBody(entry, []string{"go.mod", "main.go"})
+
+	assert.Contains(t, body, "**Conflicting files:**")
+	assert.Contains(t, body, "- `go.mod`\n- `main.go`\n")
+	assert.NotContains(t, body, "could not be determined")
+}
+
+func TestCommenter_ReadOnlyMakesNoChanges(t *testing.T) {
+	fake := newFakeNotesServer(t)
+	fake.addNote(1, 7, CommentMarker+"\nconflict")
+
+	client := gitlab.NewClient(fake.server.URL, "test-token", gitlab.WithReadOnly())
+	defer client.Close()
+
+	commenter := NewCommenter(provider.NewGitLab(client, client), config.CommentConfig{Enabled: true})
+	reportDiff := &models.ReportDiff{Resolved: []models.ConflictChange{{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 7}}}}
+
+	require.NoError(t, commenter.Run(context.Background(), buildActionsTestReport(), reportDiff))
+	assert.Empty(t, fake.writeCalls())
+	assert.Len(t, client.SkippedWrites(), 2)
+}
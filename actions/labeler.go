@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+)
+
+// Labeler keeps the conflict label on conflicting merge requests and removes it once they are clean
+type Labeler struct {
+	client *gitlab.Client
+	label  string
+}
+
+// NewLabeler creates a labeler from its configuration
+func NewLabeler(client *gitlab.Client, cfg config.LabelConfig) *Labeler {
+	label := cfg.Name
+	if label == "" {
+		label = config.DefaultConflictLabel
+	}
+	return &Labeler{client: client, label: label}
+}
+
+// Run labels every conflicting merge request in the report and unlabels the release->master
+// merge requests of the scanned repositories that no longer conflict
+func (l *Labeler) Run(ctx context.Context, report *models.Report) error {
+	var errs []string
+
+	for _, repoReport := range report.Repositories {
+		// Repositories that failed to scan are left alone, their conflict state is unknown
+		if repoReport.Status == models.StatusError {
+			continue
+		}
+		if err := l.labelRepository(ctx, repoReport); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", repoReport.Repository.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to update labels: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// labelRepository brings the labels of a single repository's merge requests up to date
+func (l *Labeler) labelRepository(ctx context.Context, repoReport models.RepositoryReport) error {
+	repo := repoReport.Repository
+	conflicting := make(map[int]bool)
+	var errs []string
+
+	for _, mr := range repoReport.ConflictingMRs {
+		conflicting[mr.ID] = true
+		if hasLabel(mr.Labels, l.label) {
+			continue
+		}
+		if err := l.apply(ctx, "add", repo, mr.ID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// Merge requests the report filter left out or an ignore rule suppressed still conflict and
+	// keep their label
+	for _, mr := range repoReport.FilteredOutMRs {
+		conflicting[mr.ID] = true
+	}
+	for _, suppressed := range repoReport.SuppressedMRs {
+		conflicting[suppressed.MergeRequest.ID] = true
+	}
+
+	// Merge requests still carrying the label from an earlier run
+	labeled, err := l.client.ListMergeRequestsWithOptions(ctx, repo.ID, gitlab.MergeRequestListOptions{
+		SourceBranch: models.CheckedSourceBranch,
+		TargetBranch: models.CheckedTargetBranch,
+		Labels:       []string{l.label},
+	})
+	if err != nil {
+		return err
+	}
+	for _, mr := range labeled {
+		if conflicting[mr.ID] {
+			continue
+		}
+		if err := l.apply(ctx, "remove", repo, mr.ID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// apply adds or removes the label on a merge request
+func (l *Labeler) apply(ctx context.Context, operation string, repo models.Repository, mrID int) error {
+	var err error
+	if operation == "add" {
+		_, err = l.client.AddMergeRequestLabels(ctx, repo.ID, mrID, l.label)
+	} else {
+		_, err = l.client.RemoveMergeRequestLabels(ctx, repo.ID, mrID, l.label)
+	}
+	if err != nil {
+		return ignoreReadOnly(err)
+	}
+
+	slog.Info("Updated merge request label", "operation", operation, "label", l.label, "repository", repo.Name, "mr", mrID)
+	return nil
+}
+
+// hasLabel reports whether a label list contains label, ignoring case as GitLab does
+func hasLabel(labels []string, label string) bool {
+	for _, existing := range labels {
+		if strings.EqualFold(existing, label) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+)
+
+// fakeLabelServer serves merge request listing and label updates for project 1 from memory
+type fakeLabelServer struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	mrs    map[int]*models.MergeRequest
+	writes []string
+}
+
+// newFakeLabelServer starts a server holding the given merge requests of project 1
+func newFakeLabelServer(t *testing.T, mrs ...models.MergeRequest) *fakeLabelServer {
+	fake := &fakeLabelServer{mrs: make(map[int]*models.MergeRequest)}
+	for i := range mrs {
+		fake.mrs[mrs[i].ID] = &mrs[i]
+	}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+// labels returns the current labels of a merge request
+func (f *fakeLabelServer) labels(mrID int) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.mrs[mrID].Labels
+}
+
+func (f *fakeLabelServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/1/merge_requests" {
+		wanted := r.URL.Query().Get("labels")
+		result := []models.MergeRequest{}
+		for _, mr := range f.mrs {
+			if wanted == "" || hasLabel(mr.Labels, wanted) {
+				result = append(result, *mr)
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	if r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/api/v4/projects/1/merge_requests/") {
+		mrID, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/v4/projects/1/merge_requests/"))
+		mr, exists := f.mrs[mrID]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.writes = append(f.writes, r.URL.Path+" "+strings.TrimSpace(body["add_labels"]+" -"+body["remove_labels"]))
+
+		if label := body["add_labels"]; label != "" && !hasLabel(mr.Labels, label) {
+			mr.Labels = append(mr.Labels, label)
+		}
+		if label := body["remove_labels"]; label != "" {
+			var kept []string
+			for _, existing := range mr.Labels {
+				if existing != label {
+					kept = append(kept, existing)
+				}
+			}
+			mr.Labels = kept
+		}
+		json.NewEncoder(w).Encode(mr)
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func TestLabeler_AddsAndRemovesLabel(t *testing.T) {
+	fake := newFakeLabelServer(t,
+		models.MergeRequest{ID: 5, Labels: []string{"release"}},
+		models.MergeRequest{ID: 6, Labels: []string{"merge-conflict", "release"}},
+	)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	// MR 5 conflicts, MR 6 was labeled by an earlier run but is clean now
+	report := buildActionsTestReport()
+	report.Repositories[0].ConflictingMRs[0].Labels = []string{"release"}
+
+	labeler := NewLabeler(client, config.LabelConfig{Enabled: true})
+	require.NoError(t, labeler.Run(context.Background(), report))
+
+	assert.Equal(t, []string{"release", "merge-conflict"}, fake.labels(5))
+	assert.Equal(t, []string{"release"}, fake.labels(6))
+	assert.Len(t, fake.writes, 2)
+
+	// A second run with the label already in place changes nothing
+	report.Repositories[0].ConflictingMRs[0].Labels = fake.labels(5)
+	require.NoError(t, labeler.Run(context.Background(), report))
+	assert.Len(t, fake.writes, 2)
+}
+
+func TestLabeler_KeepsLabelOfFilteredMergeRequests(t *testing.T) {
+	fake := newFakeLabelServer(t,
+		models.MergeRequest{ID: 5},
+		models.MergeRequest{ID: 6, Labels: []string{"merge-conflict"}, Draft: true},
+	)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	// MR 6 still conflicts but the report filter leaves drafts out
+	report := buildActionsTestReport()
+	report.Repositories[0].FilteredOutMRs = []models.MergeRequest{{ID: 6, Labels: []string{"merge-conflict"}, Draft: true}}
+
+	require.NoError(t, NewLabeler(client, config.LabelConfig{Enabled: true}).Run(context.Background(), report))
+
+	assert.Equal(t, []string{"merge-conflict"}, fake.labels(6))
+	assert.Equal(t, []string{"/api/v4/projects/1/merge_requests/5 merge-conflict -"}, fake.writes)
+}
+
+func TestLabeler_KeepsLabelOfSuppressedMergeRequests(t *testing.T) {
+	fake := newFakeLabelServer(t,
+		models.MergeRequest{ID: 5},
+		models.MergeRequest{ID: 7, Labels: []string{"merge-conflict"}},
+	)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	// MR 7 still conflicts but matches an ignore rule
+	report := buildActionsTestReport()
+	report.Repositories[0].SuppressedMRs = []models.SuppressedMR{{MergeRequest: models.MergeRequest{ID: 7, Labels: []string{"merge-conflict"}}, Reason: "label wip"}}
+
+	require.NoError(t, NewLabeler(client, config.LabelConfig{Enabled: true}).Run(context.Background(), report))
+
+	assert.Equal(t, []string{"merge-conflict"}, fake.labels(7))
+	assert.Equal(t, []string{"/api/v4/projects/1/merge_requests/5 merge-conflict -"}, fake.writes)
+}
+
+func TestLabeler_CustomLabelAndReadOnly(t *testing.T) {
+	fake := newFakeLabelServer(t,
+		models.MergeRequest{ID: 5},
+		models.MergeRequest{ID: 6, Labels: []string{"needs-rebase"}},
+	)
+
+	client := gitlab.NewClient(fake.server.URL, "test-token", gitlab.WithReadOnly())
+	defer client.Close()
+
+	labeler := NewLabeler(client, config.LabelConfig{Enabled: true, Name: "needs-rebase"})
+	require.NoError(t, labeler.Run(context.Background(), buildActionsTestReport()))
+
+	assert.Empty(t, fake.writes)
+	assert.Empty(t, fake.labels(5))
+	assert.Equal(t, []string{"needs-rebase"}, fake.labels(6))
+	assert.Len(t, client.SkippedWrites(), 2)
+}
+
+func TestLabeler_SkipsErroredRepositories(t *testing.T) {
+	fake := newFakeLabelServer(t, models.MergeRequest{ID: 6, Labels: []string{"merge-conflict"}})
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1, Name: "api"}, nil, models.StatusError, "boom")
+
+	labeler := NewLabeler(client, config.LabelConfig{Enabled: true})
+	require.NoError(t, labeler.Run(context.Background(), report))
+	assert.Equal(t, []string{"merge-conflict"}, fake.labels(6))
+}
@@ -0,0 +1,133 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+const (
+	// defaultRebasePollInterval is the delay between rebase status checks when none is configured
+	defaultRebasePollInterval = 2 * time.Second
+	// defaultRebaseTimeout is how long to wait for a rebase when no timeout is configured
+	defaultRebaseTimeout = time.Minute
+)
+
+// Rebaser triggers GitLab's server-side rebase for release->master MRs that only need a rebase
+type Rebaser struct {
+	client       *gitlab.Client
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+// NewRebaser creates a rebaser from its configuration
+func NewRebaser(client *gitlab.Client, cfg config.RebaseConfig) *Rebaser {
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultRebasePollInterval
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultRebaseTimeout
+	}
+	return &Rebaser{client: client, pollInterval: pollInterval, timeout: timeout}
+}
+
+// Run rebases the MRs of the scanned repositories that need a rebase but have no conflicts,
+// and records each outcome in the report
+func (r *Rebaser) Run(ctx context.Context, report *models.Report) error {
+	var errs []string
+
+	for i := range report.Repositories {
+		repoReport := &report.Repositories[i]
+		if repoReport.Status == models.StatusError || repoReport.Status == models.StatusNoMRs {
+			continue
+		}
+
+		mrs, err := r.client.ListMergeRequests(ctx, repoReport.Repository.ID, models.CheckedSourceBranch, models.CheckedTargetBranch)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", repoReport.Repository.Name, err))
+			continue
+		}
+
+		for _, mr := range mrs {
+			if mr.HasConflicts || mr.DetailedMergeStatus != models.DetailedMergeStatusNeedRebase {
+				continue
+			}
+
+			result, triggered := r.rebase(ctx, repoReport.Repository, mr)
+			if !triggered {
+				continue
+			}
+			repoReport.Rebases = append(repoReport.Rebases, result)
+			if result.Status != models.RebaseSucceeded {
+				errs = append(errs, fmt.Sprintf("%s!%d: %s", repoReport.Repository.Name, mr.ID, result.Error))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to rebase merge requests: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// rebase triggers the rebase of a merge request and waits for it to finish; it reports
+// false when a read-only client refused to trigger the rebase
+func (r *Rebaser) rebase(ctx context.Context, repo models.Repository, mr models.MergeRequest) (models.RebaseResult, bool) {
+	result := models.RebaseResult{MergeRequest: mr}
+
+	if err := r.client.RebaseMergeRequest(ctx, repo.ID, mr.ID); err != nil {
+		if provider.IsReadOnly(err) {
+			return result, false
+		}
+		result.Status = models.RebaseFailed
+		result.Error = err.Error()
+		return result, true
+	}
+	slog.Info("Triggered rebase", "repository", repo.Name, "mr", mr.ID)
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Poll until GitLab reports the rebase is no longer in progress
+	for {
+		select {
+		case <-time.After(r.pollInterval):
+		case <-ctx.Done():
+			result.Status = models.RebaseTimedOut
+			result.Error = fmt.Sprintf("rebase still in progress after %s", r.timeout)
+			return result, true
+		}
+
+		current, err := r.client.GetMergeRequest(ctx, repo.ID, mr.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			result.Status = models.RebaseFailed
+			result.Error = err.Error()
+			return result, true
+		}
+		if current.RebaseInProgress {
+			continue
+		}
+
+		result.MergeRequest = *current
+		if current.MergeError != "" {
+			result.Status = models.RebaseFailed
+			result.Error = current.MergeError
+		} else {
+			result.Status = models.RebaseSucceeded
+			slog.Info("Rebase finished", "repository", repo.Name, "mr", mr.ID)
+		}
+		return result, true
+	}
+}
@@ -0,0 +1,167 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+)
+
+// fakeRebaseServer serves merge request listing, lookup and rebase for project 1 from memory
+type fakeRebaseServer struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+	// pollsUntilDone is how many status checks report the rebase still in progress
+	pollsUntilDone int
+	// mergeError is reported once a rebase finishes
+	mergeError string
+	mrs        []models.MergeRequest
+	rebased    []int
+}
+
+// newFakeRebaseServer starts a server holding the given merge requests of project 1
+func newFakeRebaseServer(t *testing.T, mrs ...models.MergeRequest) *fakeRebaseServer {
+	fake := &fakeRebaseServer{mrs: mrs}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeRebaseServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	const mrsPath = "/api/v4/projects/1/merge_requests"
+
+	if r.Method == http.MethodGet && r.URL.Path == mrsPath {
+		json.NewEncoder(w).Encode(f.mrs)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, mrsPath+"/")
+	if r.Method == http.MethodPut && strings.HasSuffix(rest, "/rebase") {
+		mrID, _ := strconv.Atoi(strings.TrimSuffix(rest, "/rebase"))
+		f.rebased = append(f.rebased, mrID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]bool{"rebase_in_progress": true})
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		mrID, _ := strconv.Atoi(rest)
+		if r.URL.Query().Get("include_rebase_in_progress") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, mr := range f.mrs {
+			if mr.ID != mrID {
+				continue
+			}
+			if f.pollsUntilDone > 0 {
+				f.pollsUntilDone--
+				mr.RebaseInProgress = true
+			} else {
+				mr.MergeError = f.mergeError
+				mr.DetailedMergeStatus = "mergeable"
+			}
+			json.NewEncoder(w).Encode(mr)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// buildRebaseTestReport creates a report where project 1 has release->master MRs but no conflicts
+func buildRebaseTestReport() *models.Report {
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1, Name: "api"}, nil, models.StatusAccessible, "")
+	return report
+}
+
+func TestRebaser_RebasesOnlyMRsThatNeedIt(t *testing.T) {
+	fake := newFakeRebaseServer(t,
+		models.MergeRequest{ID: 5, Title: "Release 1.2", DetailedMergeStatus: models.DetailedMergeStatusNeedRebase},
+		models.MergeRequest{ID: 6, Title: "Release 1.3", DetailedMergeStatus: "conflict", HasConflicts: true},
+		models.MergeRequest{ID: 7, Title: "Release 1.4", DetailedMergeStatus: "mergeable"},
+	)
+	fake.pollsUntilDone = 2
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	report := buildRebaseTestReport()
+	rebaser := NewRebaser(client, config.RebaseConfig{Enabled: true, PollInterval: time.Millisecond})
+	require.NoError(t, rebaser.Run(context.Background(), report))
+
+	assert.Equal(t, []int{5}, fake.rebased)
+	require.Len(t, report.Repositories[0].Rebases, 1)
+	result := report.Repositories[0].Rebases[0]
+	assert.Equal(t, models.RebaseSucceeded, result.Status)
+	assert.Equal(t, 5, result.MergeRequest.ID)
+	assert.Equal(t, "mergeable", result.MergeRequest.DetailedMergeStatus)
+	assert.Equal(t, 0, fake.pollsUntilDone)
+}
+
+func TestRebaser_RecordsFailure(t *testing.T) {
+	fake := newFakeRebaseServer(t, models.MergeRequest{ID: 5, DetailedMergeStatus: models.DetailedMergeStatusNeedRebase})
+	fake.mergeError = "Rebase failed: Rebase locally, resolve all conflicts, then push the branch."
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	report := buildRebaseTestReport()
+	rebaser := NewRebaser(client, config.RebaseConfig{Enabled: true, PollInterval: time.Millisecond})
+	err := rebaser.Run(context.Background(), report)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "api!5")
+	require.Len(t, report.Repositories[0].Rebases, 1)
+	assert.Equal(t, models.RebaseFailed, report.Repositories[0].Rebases[0].Status)
+	assert.Equal(t, fake.mergeError, report.Repositories[0].Rebases[0].Error)
+}
+
+func TestRebaser_TimesOut(t *testing.T) {
+	fake := newFakeRebaseServer(t, models.MergeRequest{ID: 5, DetailedMergeStatus: models.DetailedMergeStatusNeedRebase})
+	fake.pollsUntilDone = 1 << 30
+
+	client := gitlab.NewClient(fake.server.URL, "test-token")
+	defer client.Close()
+
+	report := buildRebaseTestReport()
+	rebaser := NewRebaser(client, config.RebaseConfig{Enabled: true, PollInterval: 10 * time.Millisecond, Timeout: 300 * time.Millisecond})
+	err := rebaser.Run(context.Background(), report)
+
+	require.Error(t, err)
+	require.Len(t, report.Repositories[0].Rebases, 1)
+	assert.Equal(t, models.RebaseTimedOut, report.Repositories[0].Rebases[0].Status)
+}
+
+func TestRebaser_ReadOnly(t *testing.T) {
+	fake := newFakeRebaseServer(t, models.MergeRequest{ID: 5, DetailedMergeStatus: models.DetailedMergeStatusNeedRebase})
+
+	client := gitlab.NewClient(fake.server.URL, "test-token", gitlab.WithReadOnly())
+	defer client.Close()
+
+	report := buildRebaseTestReport()
+	rebaser := NewRebaser(client, config.RebaseConfig{Enabled: true})
+	require.NoError(t, rebaser.Run(context.Background(), report))
+
+	assert.Empty(t, fake.rebased)
+	assert.Empty(t, report.Repositories[0].Rebases)
+	assert.Len(t, client.SkippedWrites(), 1)
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// IgnoreRules decides which conflicting merge requests are suppressed or downgraded; a nil
+// *IgnoreRules matches none
+type IgnoreRules struct {
+	rules []ignoreRule
+	now   func() time.Time
+}
+
+// ignoreRule is a configured ignore rule with its title pattern compiled
+type ignoreRule struct {
+	config.IgnoreRule
+	title *regexp.Regexp
+}
+
+// NewIgnoreRules compiles the configured ignore rules
+func NewIgnoreRules(rules []config.IgnoreRule) (*IgnoreRules, error) {
+	compiled := make([]ignoreRule, 0, len(rules))
+	for i, rule := range rules {
+		r := ignoreRule{IgnoreRule: rule}
+		if rule.Title != "" {
+			title, err := regexp.Compile(rule.Title)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile title of ignore rule %d: %w", i, err)
+			}
+			r.title = title
+		}
+		compiled = append(compiled, r)
+	}
+	return &IgnoreRules{rules: compiled, now: time.Now}, nil
+}
+
+// Match returns the reason of the first rule matching the merge request, and false when none does
+func (r *IgnoreRules) Match(mr models.MergeRequest) (string, bool) {
+	_, reason, ok := r.first(mr)
+	return reason, ok
+}
+
+// first returns the first rule matching the merge request with its reason
+func (r *IgnoreRules) first(mr models.MergeRequest) (ignoreRule, string, bool) {
+	if r == nil {
+		return ignoreRule{}, "", false
+	}
+	for _, rule := range r.rules {
+		if reason, ok := rule.match(mr, r.now()); ok {
+			return rule, reason, true
+		}
+	}
+	return ignoreRule{}, "", false
+}
+
+// split separates the merge requests a rule skips from the others, keeping their order; the kept
+// merge requests a rule downgrades are returned as downgrades too
+func (r *IgnoreRules) split(mrs []models.MergeRequest) ([]models.MergeRequest, []models.SuppressedMR, []models.Downgrade) {
+	var kept []models.MergeRequest
+	var suppressed []models.SuppressedMR
+	var downgrades []models.Downgrade
+	for _, mr := range mrs {
+		rule, reason, ok := r.first(mr)
+		switch {
+		case !ok:
+			kept = append(kept, mr)
+		case rule.Action == config.IgnoreActionDowngrade:
+			kept = append(kept, mr)
+			downgrades = append(downgrades, models.Downgrade{MergeRequestID: mr.ID, Reason: reason})
+		default:
+			suppressed = append(suppressed, models.SuppressedMR{MergeRequest: mr, Reason: reason})
+		}
+	}
+	return kept, suppressed, downgrades
+}
+
+// match checks every condition the rule sets, and describes the ones that matched when the rule has no name
+func (r ignoreRule) match(mr models.MergeRequest, now time.Time) (string, bool) {
+	var conditions []string
+
+	if len(r.Labels) > 0 {
+		label, ok := firstLabel(mr, r.Labels)
+		if !ok {
+			return "", false
+		}
+		conditions = append(conditions, fmt.Sprintf("label %q", label))
+	}
+	if r.Draft {
+		if !mr.IsDraft() {
+			return "", false
+		}
+		conditions = append(conditions, "draft")
+	}
+	if r.title != nil {
+		if !r.title.MatchString(mr.Title) {
+			return "", false
+		}
+		conditions = append(conditions, fmt.Sprintf("title matches %q", r.Title))
+	}
+	if len(r.Authors) > 0 {
+		if !hasAuthor(mr, r.Authors) {
+			return "", false
+		}
+		conditions = append(conditions, fmt.Sprintf("author %q", mr.Author.Username))
+	}
+	if r.OlderThan > 0 {
+		if mr.CreatedAt.IsZero() || now.Sub(mr.CreatedAt) <= r.OlderThan {
+			return "", false
+		}
+		conditions = append(conditions, "older than "+formatAge(r.OlderThan))
+	}
+
+	if r.Name != "" {
+		return r.Name, true
+	}
+	return strings.Join(conditions, ", "), true
+}
+
+// firstLabel returns the first of labels the merge request carries
+func firstLabel(mr models.MergeRequest, labels []string) (string, bool) {
+	for _, label := range labels {
+		if mr.HasLabel(label) {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// hasAuthor reports whether the merge request was opened by one of the usernames
+func hasAuthor(mr models.MergeRequest, usernames []string) bool {
+	for _, username := range usernames {
+		if strings.EqualFold(mr.Author.Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAge renders whole days as such, and other durations as Go does
+func formatAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
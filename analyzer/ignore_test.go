@@ -0,0 +1,186 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+	"mr-conflict-checker/provider"
+)
+
+// newIgnoreRules compiles rules evaluated at a fixed time
+func newIgnoreRules(t *testing.T, now time.Time, rules ...config.IgnoreRule) *IgnoreRules {
+	ignore, err := NewIgnoreRules(rules)
+	require.NoError(t, err)
+	ignore.now = func() time.Time { return now }
+	return ignore
+}
+
+func TestIgnoreRules_Match(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mr := models.MergeRequest{
+		Title:     "Release 1.2 (parked)",
+		Author:    models.Author{Username: "release-bot"},
+		Labels:    []string{"On-Hold"},
+		Draft:     true,
+		CreatedAt: now.Add(-40 * 24 * time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		rule   config.IgnoreRule
+		reason string
+	}{
+		{name: "label", rule: config.IgnoreRule{Labels: []string{"blocked", "on-hold"}}, reason: `label "on-hold"`},
+		{name: "draft", rule: config.IgnoreRule{Draft: true}, reason: "draft"},
+		{name: "title", rule: config.IgnoreRule{Title: `\(parked\)$`}, reason: `title matches "\\(parked\\)$"`},
+		{name: "author", rule: config.IgnoreRule{Authors: []string{"Release-Bot"}}, reason: `author "release-bot"`},
+		{name: "age", rule: config.IgnoreRule{OlderThan: 30 * 24 * time.Hour}, reason: "older than 30d"},
+		{name: "every condition", rule: config.IgnoreRule{Labels: []string{"on-hold"}, Draft: true, OlderThan: 36 * time.Hour}, reason: `label "on-hold", draft, older than 36h0m0s`},
+		{name: "named", rule: config.IgnoreRule{Name: "parked until the freeze", Labels: []string{"on-hold"}}, reason: "parked until the freeze"},
+		{name: "other label", rule: config.IgnoreRule{Labels: []string{"blocked"}}},
+		{name: "other title", rule: config.IgnoreRule{Title: "^WIP"}},
+		{name: "other author", rule: config.IgnoreRule{Authors: []string{"alice"}}},
+		{name: "too young", rule: config.IgnoreRule{OlderThan: 60 * 24 * time.Hour}},
+		{name: "one condition fails", rule: config.IgnoreRule{Labels: []string{"on-hold"}, Authors: []string{"alice"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := newIgnoreRules(t, now, tt.rule).Match(mr)
+			assert.Equal(t, tt.reason != "", ok)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestIgnoreRules_FirstMatchingRuleWins(t *testing.T) {
+	ignore := newIgnoreRules(t, time.Now(),
+		config.IgnoreRule{Name: "bots", Authors: []string{"bot"}},
+		config.IgnoreRule{Name: "drafts", Draft: true},
+	)
+
+	reason, ok := ignore.Match(models.MergeRequest{Draft: true, Author: models.Author{Username: "bot"}})
+	assert.True(t, ok)
+	assert.Equal(t, "bots", reason)
+
+	reason, ok = ignore.Match(models.MergeRequest{Draft: true})
+	assert.True(t, ok)
+	assert.Equal(t, "drafts", reason)
+}
+
+func TestIgnoreRules_Nil(t *testing.T) {
+	var ignore *IgnoreRules
+	_, ok := ignore.Match(models.MergeRequest{Draft: true})
+	assert.False(t, ok)
+}
+
+func TestIgnoreRules_SplitDowngrades(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ignore := newIgnoreRules(t, now,
+		config.IgnoreRule{Name: "parked", Labels: []string{"on-hold"}},
+		config.IgnoreRule{Name: "bot", Authors: []string{"bot"}, Action: config.IgnoreActionDowngrade},
+	)
+	mrs := []models.MergeRequest{
+		{ID: 1, Author: models.Author{Username: "bot"}},
+		{ID: 2, Labels: []string{"on-hold"}, Author: models.Author{Username: "bot"}},
+		{ID: 3},
+	}
+
+	kept, suppressed, downgrades := ignore.split(mrs)
+
+	// Downgraded merge requests stay conflicts; the first matching rule decides
+	require.Len(t, kept, 2)
+	assert.Equal(t, 1, kept[0].ID)
+	assert.Equal(t, 3, kept[1].ID)
+	assert.Equal(t, []models.SuppressedMR{{MergeRequest: mrs[1], Reason: "parked"}}, suppressed)
+	assert.Equal(t, []models.Downgrade{{MergeRequestID: 1, Reason: "bot"}}, downgrades)
+}
+
+func TestNewIgnoreRules_InvalidTitle(t *testing.T) {
+	_, err := NewIgnoreRules([]config.IgnoreRule{{Title: "("}})
+	assert.ErrorContains(t, err, "failed to compile title of ignore rule 0")
+}
+
+func TestAnalyzeMRs_SuppressesIgnoredConflicts(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	server.SetMergeRequests(1, []models.MergeRequest{
+		{ID: 1, Title: "Release", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: created},
+		{ID: 2, Title: "Parked release", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, Labels: []string{"on-hold"}, CreatedAt: created},
+	})
+	server.SetMergeRequests(2, []models.MergeRequest{
+		{ID: 1, Title: "Parked release", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, Labels: []string{"on-hold"}, CreatedAt: created},
+	})
+	for _, id := range []int{1, 2} {
+		server.SetChanges(id, 1, "main.go")
+		server.SetChanges(id, 2, "main.go")
+	}
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+	host := provider.NewGitLab(client, client)
+	ignore := newIgnoreRules(t, created, config.IgnoreRule{Name: "parked", Labels: []string{"on-hold"}})
+	repos := []models.Repository{{ID: 1, Name: "api"}, {ID: 2, Name: "web"}}
+
+	result, err := AnalyzeMRs(context.Background(), host, repos, ignore)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, models.StatusConflicts, result[0].Status)
+	require.Len(t, result[0].SuppressedMRs, 1)
+	assert.Equal(t, 2, result[0].SuppressedMRs[0].MergeRequest.ID)
+	assert.Equal(t, "parked", result[0].SuppressedMRs[0].Reason)
+
+	// Suppressed conflicts alone do not make a repository conflicting
+	assert.Equal(t, models.StatusAccessible, result[1].Status)
+	require.Len(t, result[1].SuppressedMRs, 1)
+
+	conflicts, err := GetConflictingMRs(context.Background(), host, result, ignore)
+	require.NoError(t, err)
+	require.Len(t, conflicts[1], 1)
+	assert.Equal(t, 1, conflicts[1][0].ID)
+	assert.NotContains(t, conflicts, 2)
+}
+
+func TestAnalyzeIncremental_AppliesIgnoreRulesAgain(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	parked := models.MergeRequest{ID: 1, Title: "Parked", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, Labels: []string{"on-hold"}, CreatedAt: since.Add(-time.Hour)}
+	released := models.MergeRequest{ID: 2, Title: "Unparked", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: since.Add(-2 * time.Hour)}
+
+	previous := &models.Report{
+		Repositories: []models.RepositoryReport{
+			// Conflicting before the rule was added
+			{Repository: models.Repository{ID: 1, Name: "quiet"}, ConflictingMRs: []models.MergeRequest{parked}, Status: models.StatusConflicts},
+			// Suppressed by a rule that was removed since
+			{Repository: models.Repository{ID: 2, Name: "calm"}, SuppressedMRs: []models.SuppressedMR{{MergeRequest: released, Reason: "old rule"}}, Status: models.StatusAccessible},
+		},
+	}
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+	ignore := newIgnoreRules(t, since, config.IgnoreRule{Labels: []string{"on-hold"}})
+
+	repos, conflicts, err := AnalyzeIncremental(context.Background(), provider.NewGitLab(client, client), previous, nil, since, ignore)
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+
+	assert.Equal(t, models.StatusAccessible, repos[0].Status)
+	assert.NotContains(t, conflicts, 1)
+	require.Len(t, repos[0].SuppressedMRs, 1)
+	assert.Equal(t, `label "on-hold"`, repos[0].SuppressedMRs[0].Reason)
+
+	assert.Equal(t, models.StatusConflicts, repos[1].Status)
+	assert.Equal(t, []models.MergeRequest{released}, conflicts[2])
+	assert.Empty(t, repos[1].SuppressedMRs)
+}
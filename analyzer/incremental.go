@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+// AnalyzeIncremental updates a previous report with the merge requests updated since the given time
+// in the active repositories; the other repositories keep their previous results, with the ignore rules
+// applied again. Active repositories the previous report has no results for are analyzed in full. It
+// returns the same repositories and conflicting merge requests as AnalyzeMRs and GetConflictingMRs would.
+func AnalyzeIncremental(ctx context.Context, client provider.Provider, previous *models.Report, active []models.Repository, since time.Time, ignore *IgnoreRules) ([]models.Repository, map[int][]models.MergeRequest, error) {
+	if client == nil {
+		return nil, nil, fmt.Errorf("provider cannot be nil")
+	}
+
+	activeIDs := make(map[int]bool)
+	for _, repo := range active {
+		activeIDs[repo.ID] = true
+	}
+
+	var repositories []models.Repository
+	conflictingMRs := make(map[int][]models.MergeRequest)
+	previousReports := make(map[int]models.RepositoryReport)
+
+	// Repositories without activity keep their previous results
+	for _, repoReport := range previous.Repositories {
+		previousReports[repoReport.Repository.ID] = repoReport
+		if activeIDs[repoReport.Repository.ID] {
+			continue
+		}
+
+		repo := repoReport.Repository
+		repo.Status = repoReport.Status
+		if repoReport.ErrorMessage != "" {
+			repo.Error = errors.New(repoReport.ErrorMessage)
+		}
+		conflicts, suppressed, downgrades := ignore.split(append(previousConflicts(repoReport, nil), suppressedMRs(repoReport, nil)...))
+		repo.SuppressedMRs = suppressed
+		repo.Downgrades = downgrades
+		sortNewestFirst(conflicts)
+		switch {
+		case len(conflicts) > 0:
+			repo.Status = models.StatusConflicts
+			conflictingMRs[repo.ID] = conflicts
+		case repo.Status == models.StatusConflicts:
+			// Every conflict is suppressed now
+			repo.Status = models.StatusAccessible
+		}
+		repositories = append(repositories, repo)
+	}
+
+	// Active repositories only look at the merge requests updated since
+	for _, repo := range active {
+		var analyzedRepo models.Repository
+		var conflicts []models.MergeRequest
+		if previousReport, ok := previousReports[repo.ID]; ok && previousReport.Status != models.StatusError {
+			analyzedRepo, conflicts = analyzeUpdatedMRs(ctx, client, repo, previousReport, since, ignore)
+		} else {
+			// New or previously failing repositories have nothing to build on
+			analyzedRepo, conflicts = analyzeAllMRs(ctx, client, repo, ignore)
+		}
+		repositories = append(repositories, analyzedRepo)
+		if len(conflicts) > 0 {
+			conflictingMRs[repo.ID] = conflicts
+		}
+	}
+
+	// Keep the order of a full scan
+	sort.Slice(repositories, func(i, j int) bool {
+		return repositories[i].ID < repositories[j].ID
+	})
+
+	return repositories, conflictingMRs, nil
+}
+
+// analyzeUpdatedMRs merges the merge requests updated since the previous scan into the conflicts it found
+func analyzeUpdatedMRs(ctx context.Context, client provider.Provider, repo models.Repository, previous models.RepositoryReport, since time.Time, ignore *IgnoreRules) (models.Repository, []models.MergeRequest) {
+	// Closed and merged merge requests are listed too, so that they can be dropped
+	updated, err := client.ListMergeRequestsWithOptions(ctx, repo.ID, provider.MergeRequestListOptions{
+		State:        "all",
+		SourceBranch: models.CheckedSourceBranch,
+		TargetBranch: models.CheckedTargetBranch,
+		UpdatedAfter: since,
+	})
+	if err != nil {
+		repo.Status = models.StatusError
+		repo.Error = fmt.Errorf("failed to fetch merge requests for repository %s: %w", repo.Name, err)
+		return repo, nil
+	}
+
+	updatedIDs := make(map[int]bool)
+	var opened []models.MergeRequest
+	for _, mr := range updated {
+		updatedIDs[mr.ID] = true
+		if mr.State == "opened" {
+			opened = append(opened, mr)
+		}
+	}
+
+	// Updated merge requests replace their previous versions, suppressed or not
+	conflicts, suppressed, downgrades := ignore.split(append(previousConflicts(previous, updatedIDs), suppressedMRs(previous, updatedIDs)...))
+	updatedConflicts, updatedSuppressed, updatedDowngrades := filterAndSortRealConflictingMRs(ctx, client, repo.ID, opened, ignore)
+	conflicts = append(conflicts, updatedConflicts...)
+	repo.SuppressedMRs = append(suppressed, updatedSuppressed...)
+	repo.Downgrades = append(downgrades, updatedDowngrades...)
+	sortNewestFirst(conflicts)
+
+	repo.Error = nil
+	switch {
+	case len(conflicts) > 0:
+		repo.Status = models.StatusConflicts
+	case len(opened) > 0 || len(repo.SuppressedMRs) > 0:
+		// Has MRs but no conflicts, or only suppressed ones
+		repo.Status = models.StatusAccessible
+	case previous.Status == models.StatusAccessible || previous.Status == models.StatusConflicts:
+		// The previous report does not list the merge requests without conflicts, which may all be closed by now
+		open, err := client.ListMergeRequests(ctx, repo.ID, models.CheckedSourceBranch, models.CheckedTargetBranch)
+		if err != nil {
+			repo.Status = models.StatusError
+			repo.Error = fmt.Errorf("failed to fetch merge requests for repository %s: %w", repo.Name, err)
+			return repo, nil
+		}
+		repo.Status = models.StatusNoMRs
+		if len(open) > 0 {
+			repo.Status = models.StatusAccessible
+		}
+	default:
+		repo.Status = models.StatusNoMRs
+	}
+
+	return repo, conflicts
+}
+
+// analyzeAllMRs analyzes every open merge request of a repository, as a full scan does
+func analyzeAllMRs(ctx context.Context, client provider.Provider, repo models.Repository, ignore *IgnoreRules) (models.Repository, []models.MergeRequest) {
+	analyzedRepo, conflicts, err := analyzeRepository(ctx, client, repo, ignore)
+	if err != nil {
+		analyzedRepo = repo
+		analyzedRepo.Status = models.StatusError
+		analyzedRepo.Error = err
+		return analyzedRepo, nil
+	}
+	analyzedRepo.Error = nil
+	return analyzedRepo, conflicts
+}
+
+// previousConflicts returns the conflicting merge requests of a previous report that were not updated since,
+// including those the report filter left out, which the filter gets to exclude again
+func previousConflicts(previous models.RepositoryReport, updatedIDs map[int]bool) []models.MergeRequest {
+	var mrs []models.MergeRequest
+	for _, mr := range previous.ConflictingMRs {
+		if !updatedIDs[mr.ID] {
+			mrs = append(mrs, mr)
+		}
+	}
+	for _, mr := range previous.FilteredOutMRs {
+		if !updatedIDs[mr.ID] {
+			mrs = append(mrs, mr)
+		}
+	}
+	return mrs
+}
+
+// suppressedMRs returns the suppressed merge requests of a previous report that were not updated since
+func suppressedMRs(previous models.RepositoryReport, updatedIDs map[int]bool) []models.MergeRequest {
+	var mrs []models.MergeRequest
+	for _, suppressed := range previous.SuppressedMRs {
+		if !updatedIDs[suppressed.MergeRequest.ID] {
+			mrs = append(mrs, suppressed.MergeRequest)
+		}
+	}
+	return mrs
+}
+
+// sortNewestFirst sorts merge requests by creation date, newest first
+func sortNewestFirst(mrs []models.MergeRequest) {
+	sort.Slice(mrs, func(i, j int) bool {
+		return mrs[i].CreatedAt.After(mrs[j].CreatedAt)
+	})
+}
@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+	"mr-conflict-checker/provider"
+)
+
+func TestAnalyzeIncremental(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := since.Add(-24 * time.Hour)
+	after := since.Add(time.Hour)
+
+	carried := models.MergeRequest{ID: 1, Title: "untouched", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: before}
+	merged := models.MergeRequest{ID: 2, Title: "merged since", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: before}
+	kept := models.MergeRequest{ID: 3, Title: "still conflicting", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: before}
+
+	previous := &models.Report{
+		Repositories: []models.RepositoryReport{
+			{Repository: models.Repository{ID: 1, Name: "quiet"}, ConflictingMRs: []models.MergeRequest{carried}, Status: models.StatusConflicts},
+			{Repository: models.Repository{ID: 2, Name: "busy"}, ConflictingMRs: []models.MergeRequest{merged, kept}, Status: models.StatusConflicts},
+			{Repository: models.Repository{ID: 4, Name: "broken"}, ConflictingMRs: []models.MergeRequest{}, Status: models.StatusError, ErrorMessage: "403 Forbidden"},
+		},
+	}
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+
+	// The busy repository: one conflict was merged, one is untouched, one is new
+	server.SetMergeRequests(2, []models.MergeRequest{kept, {ID: 4, Title: "new conflict", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: after, UpdatedAt: after}})
+	server.AddMergeRequest(2, merged)
+	server.SetMergeRequestState(2, 2, "merged")
+	server.SetChanges(2, 4, "main.go")
+
+	// A repository created since the last scan, with an MR that has no conflicts
+	server.SetMergeRequests(3, []models.MergeRequest{{ID: 1, Title: "clean", SourceBranch: "release", TargetBranch: "master", CreatedAt: after, UpdatedAt: after}})
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	active := []models.Repository{
+		{ID: 3, Name: "fresh", Status: models.StatusAccessible},
+		{ID: 2, Name: "busy", Status: models.StatusAccessible},
+	}
+
+	repos, conflicts, err := AnalyzeIncremental(context.Background(), provider.NewGitLab(client, client), previous, active, since, nil)
+	require.NoError(t, err)
+	require.Len(t, repos, 4)
+
+	// Repositories keep the order of a full scan
+	assert.Equal(t, []int{1, 2, 3, 4}, []int{repos[0].ID, repos[1].ID, repos[2].ID, repos[3].ID})
+
+	// Inactive repositories keep their previous results without any request
+	assert.Equal(t, models.StatusConflicts, repos[0].Status)
+	assert.Equal(t, []models.MergeRequest{carried}, conflicts[1])
+	assert.Equal(t, models.StatusError, repos[3].Status)
+	assert.EqualError(t, repos[3].Error, "403 Forbidden")
+	assert.Zero(t, countRequests(server, "/api/v4/projects/1/merge_requests"))
+
+	// Updated merge requests replace their previous versions, newest first
+	assert.Equal(t, models.StatusConflicts, repos[1].Status)
+	require.Len(t, conflicts[2], 2)
+	assert.Equal(t, 4, conflicts[2][0].ID)
+	assert.Equal(t, 3, conflicts[2][1].ID)
+
+	assert.Equal(t, models.StatusAccessible, repos[2].Status)
+	assert.Empty(t, conflicts[3])
+
+	// Only merge requests updated since the last scan are listed
+	for _, request := range server.Requests() {
+		if request.Path == "/api/v4/projects/2/merge_requests" {
+			assert.Equal(t, "2024-03-01T12:00:00Z", request.Query.Get("updated_after"))
+			assert.Equal(t, "all", request.Query.Get("state"))
+		}
+	}
+}
+
+func TestAnalyzeIncremental_StatusAndFullListing(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := since.Add(-24 * time.Hour)
+
+	previous := &models.Report{
+		Repositories: []models.RepositoryReport{
+			{Repository: models.Repository{ID: 5, Name: "emptied"}, Status: models.StatusAccessible},
+			{Repository: models.Repository{ID: 6, Name: "still open"}, Status: models.StatusAccessible},
+			{Repository: models.Repository{ID: 7, Name: "recovered"}, Status: models.StatusError, ErrorMessage: "500 Internal Server Error"},
+		},
+	}
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+
+	// The only open merge request was closed since
+	server.AddMergeRequest(5, models.MergeRequest{ID: 1, SourceBranch: "release", TargetBranch: "master", CreatedAt: before, UpdatedAt: before})
+	server.SetMergeRequestState(5, 1, "closed")
+
+	// One merge request was closed since, another stays open untouched
+	server.AddMergeRequest(6, models.MergeRequest{ID: 1, SourceBranch: "release", TargetBranch: "master", CreatedAt: before, UpdatedAt: before})
+	server.AddMergeRequest(6, models.MergeRequest{ID: 2, SourceBranch: "release", TargetBranch: "master", CreatedAt: before, UpdatedAt: before})
+	server.SetMergeRequestState(6, 2, "closed")
+
+	// Conflicts older than the last scan in repositories the previous report has no results for
+	stale := models.MergeRequest{ID: 3, SourceBranch: "release", TargetBranch: "master", HasConflicts: true, CreatedAt: before, UpdatedAt: before}
+	server.AddMergeRequest(7, stale)
+	server.SetChanges(7, 3, "main.go")
+	server.AddMergeRequest(8, stale)
+	server.SetChanges(8, 3, "main.go")
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	active := []models.Repository{{ID: 5, Name: "emptied"}, {ID: 6, Name: "still open"}, {ID: 7, Name: "recovered"}, {ID: 8, Name: "unseen"}}
+
+	repos, conflicts, err := AnalyzeIncremental(context.Background(), provider.NewGitLab(client, client), previous, active, since, nil)
+	require.NoError(t, err)
+	require.Len(t, repos, 4)
+
+	assert.Equal(t, models.StatusNoMRs, repos[0].Status)
+	assert.Equal(t, models.StatusAccessible, repos[1].Status)
+
+	for i, id := range []int{7, 8} {
+		repo := repos[2+i]
+		assert.Equal(t, models.StatusConflicts, repo.Status, "repository %d", id)
+		assert.NoError(t, repo.Error)
+		require.Len(t, conflicts[id], 1)
+		assert.Equal(t, 3, conflicts[id][0].ID)
+	}
+}
+
+func TestAnalyzeIncremental_FilteredOutMRs(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := since.Add(-24 * time.Hour)
+
+	draft := models.MergeRequest{ID: 1, Title: "Draft: release", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, Draft: true, CreatedAt: before, UpdatedAt: before}
+	merged := models.MergeRequest{ID: 2, Title: "Draft: merged since", SourceBranch: "release", TargetBranch: "master", HasConflicts: true, Draft: true, CreatedAt: before, UpdatedAt: before}
+
+	// The report filter left every conflict out, so the repositories were reported as accessible
+	previous := &models.Report{
+		Repositories: []models.RepositoryReport{
+			{Repository: models.Repository{ID: 1, Name: "quiet"}, ConflictingMRs: []models.MergeRequest{}, FilteredOutMRs: []models.MergeRequest{draft}, Status: models.StatusAccessible},
+			{Repository: models.Repository{ID: 2, Name: "busy"}, ConflictingMRs: []models.MergeRequest{}, FilteredOutMRs: []models.MergeRequest{draft, merged}, Status: models.StatusAccessible},
+		},
+	}
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+
+	server.AddMergeRequest(2, draft)
+	server.AddMergeRequest(2, merged)
+	server.SetMergeRequestState(2, 2, "merged")
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	active := []models.Repository{{ID: 2, Name: "busy", Status: models.StatusAccessible}}
+
+	repos, conflicts, err := AnalyzeIncremental(context.Background(), provider.NewGitLab(client, client), previous, active, since, nil)
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+
+	// Filtered out conflicts are carried forward for the filter to leave out again
+	assert.Equal(t, models.StatusConflicts, repos[0].Status)
+	assert.Equal(t, []models.MergeRequest{draft}, conflicts[1])
+
+	// Unless they were updated since
+	assert.Equal(t, models.StatusConflicts, repos[1].Status)
+	assert.Equal(t, []models.MergeRequest{draft}, conflicts[2])
+}
+
+func TestAnalyzeIncremental_NilClient(t *testing.T) {
+	_, _, err := AnalyzeIncremental(context.Background(), nil, &models.Report{}, nil, time.Now(), nil)
+	assert.ErrorContains(t, err, "provider cannot be nil")
+}
+
+// countRequests counts the requests the fake server received for a path
+func countRequests(server *gitlabfake.Server, path string) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if request.Path == path {
+			count++
+		}
+	}
+	return count
+}
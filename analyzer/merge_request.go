@@ -5,14 +5,16 @@ import (
 	"fmt"
 	"sort"
 
-	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
 )
 
-// AnalyzeMRs analyzes repositories for conflicting merge requests from release to master branch
-func AnalyzeMRs(ctx context.Context, client *gitlab.Client, repositories []models.Repository) ([]models.Repository, error) {
+// AnalyzeMRs analyzes repositories for conflicting merge requests from release to master branch;
+// conflicts skipped by the ignore rules are kept in SuppressedMRs of their repository, and those
+// downgraded in Downgrades
+func AnalyzeMRs(ctx context.Context, client provider.Provider, repositories []models.Repository, ignore *IgnoreRules) ([]models.Repository, error) {
 	if client == nil {
-		return nil, fmt.Errorf("gitlab client cannot be nil")
+		return nil, fmt.Errorf("provider cannot be nil")
 	}
 
 	var analyzedRepos []models.Repository
@@ -25,7 +27,7 @@ func AnalyzeMRs(ctx context.Context, client *gitlab.Client, repositories []model
 		}
 
 		// Analyze this repository for conflicting MRs
-		analyzedRepo, err := analyzeRepository(ctx, client, repo)
+		analyzedRepo, _, err := analyzeRepository(ctx, client, repo, ignore)
 		if err != nil {
 			// Set error status and continue with other repositories
 			analyzedRepo = repo
@@ -39,19 +41,21 @@ func AnalyzeMRs(ctx context.Context, client *gitlab.Client, repositories []model
 	return analyzedRepos, nil
 }
 
-// analyzeRepository analyzes a single repository for conflicting merge requests
-func analyzeRepository(ctx context.Context, client *gitlab.Client, repo models.Repository) (models.Repository, error) {
+// analyzeRepository analyzes a single repository for conflicting merge requests and returns them
+func analyzeRepository(ctx context.Context, client provider.Provider, repo models.Repository, ignore *IgnoreRules) (models.Repository, []models.MergeRequest, error) {
 	// Get merge requests from release to master branch
-	mrs, err := client.ListMergeRequests(ctx, repo.ID, "release", "master")
+	mrs, err := client.ListMergeRequests(ctx, repo.ID, models.CheckedSourceBranch, models.CheckedTargetBranch)
 	if err != nil {
-		return repo, fmt.Errorf("failed to fetch merge requests for repository %s: %w", repo.Name, err)
+		return repo, nil, fmt.Errorf("failed to fetch merge requests for repository %s: %w", repo.Name, err)
 	}
 
 	// Filter for conflicting MRs and sort by creation date (newest first)
-	conflictingMRs := filterAndSortRealConflictingMRs(ctx, client, repo.ID, mrs)
+	conflictingMRs, suppressedMRs, downgrades := filterAndSortRealConflictingMRs(ctx, client, repo.ID, mrs, ignore)
 
 	// Update repository status based on findings
 	updatedRepo := repo
+	updatedRepo.SuppressedMRs = suppressedMRs
+	updatedRepo.Downgrades = downgrades
 	if len(conflictingMRs) > 0 {
 		updatedRepo.Status = models.StatusConflicts
 	} else if len(mrs) > 0 {
@@ -62,7 +66,7 @@ func analyzeRepository(ctx context.Context, client *gitlab.Client, repo models.R
 		updatedRepo.Status = models.StatusNoMRs
 	}
 
-	return updatedRepo, nil
+	return updatedRepo, conflictingMRs, nil
 }
 
 // filterAndSortConflictingMRs filters merge requests for basic conflicts and sorts by creation date (newest first)
@@ -72,7 +76,7 @@ func filterAndSortConflictingMRs(mrs []models.MergeRequest) []models.MergeReques
 
 	// Filter for conflicting MRs with exact branch matching
 	for _, mr := range mrs {
-		if mr.SourceBranch == "release" && mr.TargetBranch == "master" && mr.HasConflicts {
+		if mr.SourceBranch == models.CheckedSourceBranch && mr.TargetBranch == models.CheckedTargetBranch && mr.HasConflicts {
 			conflictingMRs = append(conflictingMRs, mr)
 		}
 	}
@@ -85,13 +89,14 @@ func filterAndSortConflictingMRs(mrs []models.MergeRequest) []models.MergeReques
 	return conflictingMRs
 }
 
-// filterAndSortRealConflictingMRs filters merge requests for real conflicts (with actual changes) and sorts by creation date (newest first)
-func filterAndSortRealConflictingMRs(ctx context.Context, client *gitlab.Client, projectID int, mrs []models.MergeRequest) []models.MergeRequest {
+// filterAndSortRealConflictingMRs filters merge requests for real conflicts (with actual changes) and sorts by creation date (newest first);
+// the conflicts skipped by an ignore rule are returned separately with the reason, as are the downgrades
+func filterAndSortRealConflictingMRs(ctx context.Context, client provider.Provider, projectID int, mrs []models.MergeRequest, ignore *IgnoreRules) ([]models.MergeRequest, []models.SuppressedMR, []models.Downgrade) {
 	var conflictingMRs []models.MergeRequest
 
 	// Filter for conflicting MRs with exact branch matching
 	for _, mr := range mrs {
-		if mr.SourceBranch == "release" && mr.TargetBranch == "master" && mr.HasConflicts {
+		if mr.SourceBranch == models.CheckedSourceBranch && mr.TargetBranch == models.CheckedTargetBranch && mr.HasConflicts {
 			// Check if this MR has actual changes (not just an empty merge)
 			if hasActualChanges(ctx, client, projectID, mr.ID) {
 				conflictingMRs = append(conflictingMRs, mr)
@@ -104,11 +109,11 @@ func filterAndSortRealConflictingMRs(ctx context.Context, client *gitlab.Client,
 		return conflictingMRs[i].CreatedAt.After(conflictingMRs[j].CreatedAt)
 	})
 
-	return conflictingMRs
+	return ignore.split(conflictingMRs)
 }
 
 // hasActualChanges checks if a merge request has actual file changes
-func hasActualChanges(ctx context.Context, client *gitlab.Client, projectID, mrID int) bool {
+func hasActualChanges(ctx context.Context, client provider.Provider, projectID, mrID int) bool {
 	changesCount, err := client.GetMergeRequestChanges(ctx, projectID, mrID)
 	if err != nil {
 		// If we can't get changes info, assume it has conflicts to be safe
@@ -119,21 +124,22 @@ func hasActualChanges(ctx context.Context, client *gitlab.Client, projectID, mrI
 	return changesCount > 0
 }
 
-// GetConflictingMRs retrieves all conflicting merge requests from analyzed repositories
-func GetConflictingMRs(ctx context.Context, client *gitlab.Client, repositories []models.Repository) (map[int][]models.MergeRequest, error) {
+// GetConflictingMRs retrieves all conflicting merge requests from analyzed repositories, leaving out
+// those skipped by the ignore rules
+func GetConflictingMRs(ctx context.Context, client provider.Provider, repositories []models.Repository, ignore *IgnoreRules) (map[int][]models.MergeRequest, error) {
 	conflictingMRs := make(map[int][]models.MergeRequest)
 
 	for _, repo := range repositories {
 		if repo.Status == models.StatusConflicts {
 			// Get merge requests for this repository
-			mrs, err := client.ListMergeRequests(ctx, repo.ID, "release", "master")
+			mrs, err := client.ListMergeRequests(ctx, repo.ID, models.CheckedSourceBranch, models.CheckedTargetBranch)
 			if err != nil {
 				// Log error but continue processing other repositories
 				continue
 			}
 
 			// Filter and sort conflicting MRs
-			conflicts := filterAndSortRealConflictingMRs(ctx, client, repo.ID, mrs)
+			conflicts, _, _ := filterAndSortRealConflictingMRs(ctx, client, repo.ID, mrs, ignore)
 			if len(conflicts) > 0 {
 				conflictingMRs[repo.ID] = conflicts
 			}
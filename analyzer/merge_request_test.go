@@ -14,8 +14,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"mr-conflict-checker/github"
 	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/githubfake"
+	"mr-conflict-checker/provider"
 )
 
 // **Feature: mr-conflict-checker, Property 3: Branch Filtering Accuracy**
@@ -160,9 +163,9 @@ func TestAnalyzeMRs_NilClient(t *testing.T) {
 		{ID: 1, Name: "test-repo", Status: models.StatusAccessible},
 	}
 
-	_, err := AnalyzeMRs(context.Background(), nil, repos)
+	_, err := AnalyzeMRs(context.Background(), nil, repos, nil)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "gitlab client cannot be nil")
+	assert.Contains(t, err.Error(), "provider cannot be nil")
 }
 
 func TestAnalyzeMRs_WithErrorRepository(t *testing.T) {
@@ -180,7 +183,7 @@ func TestAnalyzeMRs_WithErrorRepository(t *testing.T) {
 		{ID: 2, Name: "test-repo-2", Status: models.StatusAccessible},
 	}
 
-	result, err := AnalyzeMRs(context.Background(), client, repos)
+	result, err := AnalyzeMRs(context.Background(), provider.NewGitLab(client, client), repos, nil)
 
 	require.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -193,6 +196,30 @@ func TestAnalyzeMRs_WithErrorRepository(t *testing.T) {
 	assert.Equal(t, models.StatusError, result[1].Status)
 }
 
+func TestAnalyzeMRs_GitHub(t *testing.T) {
+	server := githubfake.NewServer()
+	defer server.Close()
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "api", Namespace: models.Namespace{Path: "acme"}},
+		{ID: 2, Name: "web", Namespace: models.Namespace{Path: "acme"}},
+	})
+	server.SetPullRequests(1, []githubfake.PullRequest{
+		{Number: 3, Head: "release", Base: "master", MergeableState: github.MergeableStateDirty, ChangedFiles: 2, UnknownFor: 1},
+		{Number: 4, Head: "feature", Base: "master", MergeableState: github.MergeableStateDirty},
+	})
+	server.SetPullRequests(2, []githubfake.PullRequest{{Number: 8, Head: "release", Base: "master"}})
+
+	client := github.NewClient(server.URL(), githubfake.DefaultToken, github.WithMergeabilityRetry(3, 0))
+	repos := []models.Repository{{ID: 1, Name: "api"}, {ID: 2, Name: "web"}}
+
+	result, err := AnalyzeMRs(context.Background(), provider.NewGitHub(client, nil), repos, nil)
+
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, models.StatusConflicts, result[0].Status)
+	assert.Equal(t, models.StatusAccessible, result[1].Status)
+}
+
 // Generator for merge request slices with various branch combinations
 func genMergeRequestSlice() gopter.Gen {
 	return gen.SliceOf(genMergeRequest())
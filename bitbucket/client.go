@@ -0,0 +1,178 @@
+// Package bitbucket is a client for the parts of the Bitbucket Server and Data Center REST API the
+// checker uses
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// DefaultTimeout is the per-request timeout used when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultPageSize is the page size requested from list endpoints
+const DefaultPageSize = 100
+
+// apiPath is the path of the core REST API below the instance URL
+const apiPath = "/rest/api/1.0"
+
+// Client represents a Bitbucket Server API client
+type Client struct {
+	api        *httpapi.Client
+	httpClient *http.Client
+	readOnly   bool
+
+	mu    sync.Mutex
+	repos map[int]repositoryRef
+}
+
+// repositoryRef locates a repository; the REST API addresses repositories by project key and slug
+type repositoryRef struct {
+	ProjectKey string
+	Slug       string
+}
+
+// path returns the API path of the repository
+func (r repositoryRef) path() string {
+	return fmt.Sprintf("/projects/%s/repos/%s", url.PathEscape(r.ProjectKey), url.PathEscape(r.Slug))
+}
+
+// String returns the repository as PROJECT/slug
+func (r repositoryRef) String() string {
+	return r.ProjectKey + "/" + r.Slug
+}
+
+// Option configures optional Client behaviour
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests through httpClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithReadOnly makes the client refuse every request other than GET and HEAD at the transport
+// layer; the refused writes are logged and available from SkippedWrites
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// NewClient creates a new Bitbucket Server API client; baseURL is the instance URL and token an
+// HTTP access token
+func NewClient(baseURL, token string, opts ...Option) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		repos:      make(map[int]repositoryRef),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.api = httpapi.NewClient(httpapi.Config{
+		Service:    "Bitbucket",
+		BaseURL:    strings.TrimSuffix(baseURL, "/") + apiPath,
+		HTTPClient: client.httpClient,
+		ReadOnly:   client.readOnly,
+		SetHeaders: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Accept", "application/json")
+			return nil
+		},
+		ErrorMessage: errorMessage,
+		UserEndpoint: "/application-properties",
+		User:         authenticatedUser,
+	})
+
+	return client
+}
+
+// ReadOnly reports whether the client refuses write requests
+func (c *Client) ReadOnly() bool {
+	return c.api.ReadOnly()
+}
+
+// SkippedWrites returns the write requests refused so far in read-only mode
+func (c *Client) SkippedWrites() []models.SkippedWrite {
+	return c.api.SkippedWrites()
+}
+
+// TestConnection verifies that the token is accepted by Bitbucket
+func (c *Client) TestConnection(ctx context.Context) error {
+	return c.api.TestConnection(ctx)
+}
+
+// authenticatedUser returns the user Bitbucket names in a response. Anonymous access may be
+// allowed, so a request only counts as authenticated when Bitbucket names the user.
+func authenticatedUser(resp *http.Response) (string, error) {
+	user := resp.Header.Get("X-AUSERNAME")
+	if user == "" {
+		return "", fmt.Errorf("authentication failed: the token was not accepted")
+	}
+	return user, nil
+}
+
+// errorMessage extracts the messages of a Bitbucket error response, or returns the body as is
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil || len(apiErr.Errors) == 0 {
+		return string(body)
+	}
+	messages := make([]string, len(apiErr.Errors))
+	for i, e := range apiErr.Errors {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// page is one page of a Bitbucket list endpoint
+type page[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// paginate fetches every page of a Bitbucket list endpoint by following nextPageStart
+func paginate[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	endpoint = httpapi.SetQueryValue(endpoint, "limit", strconv.Itoa(DefaultPageSize))
+
+	start := 0
+	return httpapi.Paginate(ctx, httpapi.SetQueryValue(endpoint, "start", "0"), func(ctx context.Context, endpoint string) (httpapi.Page[T], error) {
+		var p page[T]
+		if err := c.api.Get(ctx, endpoint, &p); err != nil {
+			return httpapi.Page[T]{}, err
+		}
+
+		result := httpapi.Page[T]{Items: p.Values}
+		if !p.IsLastPage && p.NextPageStart > start {
+			start = p.NextPageStart
+			result.Next = httpapi.SetQueryValue(endpoint, "start", strconv.Itoa(start))
+		}
+		return result, nil
+	})
+}
+
+// millis converts a Bitbucket timestamp in milliseconds since the epoch to a time
+func millis(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
@@ -0,0 +1,119 @@
+package bitbucket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/bitbucketfake"
+)
+
+// newFakeClient starts a fake Bitbucket server and returns a client for it
+func newFakeClient(t *testing.T, opts ...Option) (*bitbucketfake.Server, *Client) {
+	t.Helper()
+	server := bitbucketfake.NewServer()
+	t.Cleanup(server.Close)
+	return server, NewClient(server.URL(), bitbucketfake.DefaultToken, opts...)
+}
+
+func TestClient_TestConnection(t *testing.T) {
+	server, client := newFakeClient(t)
+
+	require.NoError(t, client.TestConnection(context.Background()))
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/rest/api/1.0/application-properties", requests[0].Path)
+	assert.Equal(t, "Bearer "+bitbucketfake.DefaultToken, requests[0].Header.Get("Authorization"))
+}
+
+func TestClient_TestConnection_InvalidToken(t *testing.T) {
+	server := bitbucketfake.NewServer()
+	defer server.Close()
+
+	err := NewClient(server.URL(), "wrong-token").TestConnection(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to Bitbucket: authentication failed: invalid token")
+}
+
+func TestClient_TestConnection_Anonymous(t *testing.T) {
+	// An instance allowing public access answers without naming a user instead of refusing the request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"8.19.0"}`))
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL, "token").TestConnection(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the token was not accepted")
+}
+
+func TestClient_APIErrorMessage(t *testing.T) {
+	_, client := newFakeClient(t)
+
+	_, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{Projects: []string{"OPS"}})
+
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+	assert.Contains(t, err.Error(), "API error 404: Project OPS does not exist.")
+}
+
+func TestErrorMessage(t *testing.T) {
+	assert.Equal(t, "first; second", errorMessage([]byte(`{"errors":[{"message":"first"},{"message":"second"}]}`)))
+	assert.Equal(t, "plain text", errorMessage([]byte("plain text")))
+	assert.Equal(t, `{"errors":[]}`, errorMessage([]byte(`{"errors":[]}`)))
+}
+
+func TestClient_PaginatesWithNextPageStart(t *testing.T) {
+	server, client := newFakeClient(t)
+	repos := make([]models.Repository, 250)
+	for i := range repos {
+		repos[i] = models.Repository{ID: i + 1, Name: "repo", Namespace: models.Namespace{Path: "PAY"}}
+	}
+	server.SetRepositories(repos)
+
+	result, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{})
+
+	require.NoError(t, err)
+	assert.Len(t, result, 250)
+	requests := server.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "200", requests[2].Query.Get("start"))
+	assert.Equal(t, "100", requests[2].Query.Get("limit"))
+}
+
+func TestClient_ReadOnlySkipsWrites(t *testing.T) {
+	server, client := newFakeClient(t, WithReadOnly())
+	assert.True(t, client.ReadOnly())
+
+	resp, err := client.api.Do(context.Background(), http.MethodDelete, "/projects/PAY/repos/app/pull-requests/1/comments/2?version=0", nil)
+
+	assert.Nil(t, resp)
+	assert.True(t, httpapi.IsReadOnly(err))
+	assert.Empty(t, server.Requests())
+	assert.Equal(t, []models.SkippedWrite{{
+		Method:      http.MethodDelete,
+		Path:        "/rest/api/1.0/projects/PAY/repos/app/pull-requests/1/comments/2",
+		Description: "DELETE /rest/api/1.0/projects/PAY/repos/app/pull-requests/1/comments/2",
+	}}, client.SkippedWrites())
+}
+
+func TestMillis(t *testing.T) {
+	assert.True(t, millis(0).IsZero())
+	assert.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), millis(1705314600000))
+}
+
+func TestRepositoryRef(t *testing.T) {
+	ref := repositoryRef{ProjectKey: "~ALICE", Slug: "my repo"}
+	assert.Equal(t, "/projects/~ALICE/repos/my%20repo", ref.path())
+	assert.Equal(t, "~ALICE/my repo", ref.String())
+}
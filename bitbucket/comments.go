@@ -0,0 +1,173 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// comment is a pull request comment as returned by the Bitbucket API
+type comment struct {
+	ID      int    `json:"id"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+	Author  struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"author"`
+	CreatedDate int64 `json:"createdDate"`
+	UpdatedDate int64 `json:"updatedDate"`
+}
+
+// model converts the comment to the shared note model
+func (c comment) model() models.Note {
+	name := c.Author.DisplayName
+	if name == "" {
+		name = c.Author.Name
+	}
+	return models.Note{
+		ID:        c.ID,
+		Body:      c.Text,
+		Author:    models.Author{Name: name, Username: c.Author.Name},
+		CreatedAt: millis(c.CreatedDate),
+		UpdatedAt: millis(c.UpdatedDate),
+	}
+}
+
+// activity is an entry of the activity stream of a pull request
+type activity struct {
+	Action        string   `json:"action"`
+	CommentAction string   `json:"commentAction"`
+	Comment       *comment `json:"comment"`
+}
+
+// commentRequest is the body of comment create and update requests; updates must name the
+// version they replace
+type commentRequest struct {
+	Text    string `json:"text"`
+	Version *int   `json:"version,omitempty"`
+}
+
+// ListPullRequestComments retrieves the general comments on a pull request, oldest first. The API
+// has no comment list, so the comments are read from the activity stream of the pull request.
+func (c *Client) ListPullRequestComments(ctx context.Context, repoID, id int) ([]models.Note, error) {
+	ref, err := c.repository(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := paginate[activity](ctx, c, fmt.Sprintf("%s/pull-requests/%d/activities", ref.path(), id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments of pull request %d for repository %s: %w", id, ref, err)
+	}
+
+	// The stream is newest first and holds every comment in its current state, once per change
+	deleted := make(map[int]bool)
+	seen := make(map[int]bool)
+	var notes []models.Note
+	for _, a := range activities {
+		if a.Action != "COMMENTED" || a.Comment == nil {
+			continue
+		}
+		if a.CommentAction == "DELETED" {
+			deleted[a.Comment.ID] = true
+		}
+		if deleted[a.Comment.ID] || seen[a.Comment.ID] {
+			continue
+		}
+		seen[a.Comment.ID] = true
+		notes = append(notes, a.Comment.model())
+	}
+	slices.SortStableFunc(notes, func(a, b models.Note) int {
+		if order := a.CreatedAt.Compare(b.CreatedAt); order != 0 {
+			return order
+		}
+		return a.ID - b.ID
+	})
+	return notes, nil
+}
+
+// CreatePullRequestComment adds a general comment to a pull request
+func (c *Client) CreatePullRequestComment(ctx context.Context, repoID, id int, body string) (*models.Note, error) {
+	ref, err := c.repository(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/pull-requests/%d/comments", ref.path(), id)
+	description := fmt.Sprintf("Comment on PR #%d in %s", id, ref)
+	resp, err := c.api.Write(ctx, http.MethodPost, endpoint, commentRequest{Text: body}, description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment on pull request %d for repository %s: %w", id, ref, err)
+	}
+	return decodeComment(resp)
+}
+
+// UpdatePullRequestComment replaces the text of an existing pull request comment
+func (c *Client) UpdatePullRequestComment(ctx context.Context, repoID, id, commentID int, body string) (*models.Note, error) {
+	ref, err := c.repository(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/pull-requests/%d/comments/%d", ref.path(), id, commentID)
+	current, err := c.getComment(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment %d on pull request %d for repository %s: %w", commentID, id, ref, err)
+	}
+
+	description := fmt.Sprintf("Update comment on PR #%d in %s", id, ref)
+	resp, err := c.api.Write(ctx, http.MethodPut, endpoint, commentRequest{Text: body, Version: &current.Version}, description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment %d on pull request %d for repository %s: %w", commentID, id, ref, err)
+	}
+	return decodeComment(resp)
+}
+
+// DeletePullRequestComment removes a comment from a pull request
+func (c *Client) DeletePullRequestComment(ctx context.Context, repoID, id, commentID int) error {
+	ref, err := c.repository(ctx, repoID)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/pull-requests/%d/comments/%d", ref.path(), id, commentID)
+	current, err := c.getComment(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment %d on pull request %d for repository %s: %w", commentID, id, ref, err)
+	}
+
+	description := fmt.Sprintf("Delete comment on PR #%d in %s", id, ref)
+	resp, err := c.api.Write(ctx, http.MethodDelete, fmt.Sprintf("%s?version=%d", endpoint, current.Version), nil, description)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment %d on pull request %d for repository %s: %w", commentID, id, ref, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// getComment fetches a comment, for the version that updates and deletes must name
+func (c *Client) getComment(ctx context.Context, endpoint string) (comment, error) {
+	var result comment
+	if err := c.api.Get(ctx, endpoint, &result); err != nil {
+		return comment{}, err
+	}
+	return result, nil
+}
+
+// decodeComment decodes a comment response into a note
+func decodeComment(resp *http.Response) (*models.Note, error) {
+	defer resp.Body.Close()
+
+	var result comment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode comment response: %w", err)
+	}
+	note := result.model()
+	return &note, nil
+}
@@ -0,0 +1,80 @@
+package bitbucket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/bitbucketfake"
+)
+
+func TestClient_PullRequestComments(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []bitbucketfake.PullRequest{{ID: 7, Source: "release", Target: "master"}})
+	ctx := context.Background()
+
+	first, err := client.CreatePullRequestComment(ctx, 1, 7, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "first", first.Body)
+	assert.Equal(t, "bitbucket-bot", first.Author.Username)
+	second, err := client.CreatePullRequestComment(ctx, 1, 7, "second")
+	require.NoError(t, err)
+
+	// Updates and deletes name the current version of the comment
+	updated, err := client.UpdatePullRequestComment(ctx, 1, 7, first.ID, "edited")
+	require.NoError(t, err)
+	assert.Equal(t, "edited", updated.Body)
+	_, err = client.UpdatePullRequestComment(ctx, 1, 7, first.ID, "edited again")
+	require.NoError(t, err)
+
+	notes, err := client.ListPullRequestComments(ctx, 1, 7)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, first.ID, notes[0].ID)
+	assert.Equal(t, "edited again", notes[0].Body)
+	assert.Equal(t, second.ID, notes[1].ID)
+
+	require.NoError(t, client.DeletePullRequestComment(ctx, 1, 7, first.ID))
+	notes, err = client.ListPullRequestComments(ctx, 1, 7)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, second.ID, notes[0].ID)
+	assert.Len(t, server.Comments(1, 7), 1)
+}
+
+func TestClient_PullRequestComments_Errors(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []bitbucketfake.PullRequest{{ID: 7, Source: "release", Target: "master"}})
+	ctx := context.Background()
+
+	_, err := client.CreatePullRequestComment(ctx, 1, 8, "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create comment on pull request 8 for repository PAY/app")
+	assert.True(t, httpapi.IsNotFound(err))
+
+	_, err = client.UpdatePullRequestComment(ctx, 1, 7, 99, "edited")
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+
+	err = client.DeletePullRequestComment(ctx, 1, 7, 99)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestClient_ReadOnlySkipsCommentChanges(t *testing.T) {
+	server, client := newFakeClient(t, WithReadOnly())
+	setUpPulls(server, []bitbucketfake.PullRequest{{ID: 7, Source: "release", Target: "master"}})
+	ctx := context.Background()
+
+	_, err := client.CreatePullRequestComment(ctx, 1, 7, "hello")
+	assert.True(t, httpapi.IsReadOnly(err))
+
+	assert.Empty(t, server.Comments(1, 7))
+	assert.Equal(t, []models.SkippedWrite{
+		{Method: "POST", Path: "/rest/api/1.0/projects/PAY/repos/app/pull-requests/7/comments", Description: "Comment on PR #7 in PAY/app"},
+	}, client.SkippedWrites())
+}
@@ -0,0 +1,215 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// Merge outcomes of a pull request
+const (
+	OutcomeClean      = "CLEAN"
+	OutcomeConflicted = "CONFLICTED"
+	OutcomeUnknown    = "UNKNOWN"
+)
+
+// pullRequest is a pull request as returned by the Bitbucket API
+type pullRequest struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	State       string `json:"state"` // OPEN, MERGED or DECLINED
+	Draft       bool   `json:"draft"`
+	CreatedDate int64  `json:"createdDate"`
+	UpdatedDate int64  `json:"updatedDate"`
+	FromRef     struct {
+		DisplayID    string `json:"displayId"`
+		LatestCommit string `json:"latestCommit"`
+	} `json:"fromRef"`
+	ToRef struct {
+		DisplayID string `json:"displayId"`
+	} `json:"toRef"`
+	Author     participant   `json:"author"`
+	Reviewers  []participant `json:"reviewers"`
+	Properties struct {
+		CommentCount int `json:"commentCount"`
+	} `json:"properties"`
+	Links struct {
+		Self []link `json:"self"`
+	} `json:"links"`
+}
+
+// participant is the author or a reviewer of a pull request
+type participant struct {
+	User struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+}
+
+// author converts the participant to the shared model, named by the user name when the display name is empty
+func (p participant) author() models.Author {
+	name := p.User.DisplayName
+	if name == "" {
+		name = p.User.Name
+	}
+	return models.Author{Name: name, Username: p.User.Name}
+}
+
+// mergeStatus is the answer of the merge endpoint of a pull request
+type mergeStatus struct {
+	CanMerge   bool   `json:"canMerge"`
+	Conflicted bool   `json:"conflicted"`
+	Outcome    string `json:"outcome"`
+	Vetoes     []struct {
+		SummaryMessage string `json:"summaryMessage"`
+	} `json:"vetoes"`
+}
+
+// model converts the pull request to the shared model, with GitLab's state names
+func (pr pullRequest) model() models.MergeRequest {
+	mr := models.MergeRequest{
+		ID:             pr.ID,
+		Title:          pr.Title,
+		Author:         pr.Author.author(),
+		SourceBranch:   pr.FromRef.DisplayID,
+		TargetBranch:   pr.ToRef.DisplayID,
+		CreatedAt:      millis(pr.CreatedDate),
+		UpdatedAt:      millis(pr.UpdatedDate),
+		Draft:          pr.Draft,
+		SHA:            pr.FromRef.LatestCommit,
+		UserNotesCount: pr.Properties.CommentCount,
+	}
+	for _, reviewer := range pr.Reviewers {
+		mr.Reviewers = append(mr.Reviewers, reviewer.author())
+	}
+	if len(pr.Links.Self) > 0 {
+		mr.WebURL = pr.Links.Self[0].Href
+	}
+
+	switch pr.State {
+	case "MERGED":
+		mr.State = "merged"
+	case "DECLINED":
+		mr.State = "closed"
+	default:
+		mr.State = "opened"
+	}
+
+	return mr
+}
+
+// apply maps the merge status of the pull request onto GitLab's merge status names
+func (s mergeStatus) apply(mr *models.MergeRequest, draft bool) {
+	mr.HasConflicts = s.Conflicted
+	mr.MergeStatus = "cannot_be_merged"
+	if s.CanMerge {
+		mr.MergeStatus = "can_be_merged"
+	}
+
+	switch {
+	case s.Conflicted:
+		mr.DetailedMergeStatus = "conflict"
+	case draft:
+		mr.DetailedMergeStatus = "draft_status"
+	case s.Outcome == OutcomeUnknown:
+		mr.MergeStatus = "checking"
+		mr.DetailedMergeStatus = "checking"
+	case len(s.Vetoes) > 0:
+		// Merge checks such as required approvals or builds block the merge
+		mr.DetailedMergeStatus = "blocked_status"
+	default:
+		mr.DetailedMergeStatus = "mergeable"
+	}
+}
+
+// PullRequestListOptions filters the pull requests returned by ListPullRequests
+type PullRequestListOptions struct {
+	State        string // "opened" (default), "closed", "merged" or "all", as for GitLab merge requests
+	SourceBranch string
+	TargetBranch string
+	Labels       []string  // Bitbucket has no labels, so no pull request matches when set
+	UpdatedAfter time.Time // Only pull requests updated after this time, when set
+}
+
+// ListPullRequests retrieves the pull requests of a repository. The merge status of open pull
+// requests is fetched one by one, since the list does not include it.
+func (c *Client) ListPullRequests(ctx context.Context, repoID int, opts PullRequestListOptions) ([]models.MergeRequest, error) {
+	ref, err := c.repository(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Labels) > 0 {
+		return nil, nil
+	}
+
+	params := url.Values{}
+	switch opts.State {
+	case "", "opened":
+		params.Set("state", "OPEN")
+	case "closed":
+		params.Set("state", "DECLINED")
+	case "merged":
+		params.Set("state", "MERGED")
+	default:
+		params.Set("state", "ALL")
+	}
+	params.Set("order", "NEWEST")
+	if opts.TargetBranch != "" {
+		// Only one branch can be matched, the target one
+		params.Set("at", "refs/heads/"+opts.TargetBranch)
+		params.Set("direction", "INCOMING")
+	}
+
+	pulls, err := paginate[pullRequest](ctx, c, fmt.Sprintf("%s/pull-requests?%s", ref.path(), params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for repository %s: %w", ref, err)
+	}
+
+	var result []models.MergeRequest
+	for _, pr := range pulls {
+		mr := pr.model()
+		if opts.SourceBranch != "" && mr.SourceBranch != opts.SourceBranch {
+			continue
+		}
+		if !opts.UpdatedAfter.IsZero() && !mr.UpdatedAt.After(opts.UpdatedAfter) {
+			continue
+		}
+
+		if mr.State == "opened" {
+			status, err := c.mergeStatus(ctx, ref, pr.ID)
+			if err != nil {
+				return nil, err
+			}
+			status.apply(&mr, pr.Draft)
+		}
+		result = append(result, mr)
+	}
+
+	return result, nil
+}
+
+// GetPullRequestChanges retrieves the number of files changed by a pull request
+func (c *Client) GetPullRequestChanges(ctx context.Context, repoID, id int) (int, error) {
+	ref, err := c.repository(ctx, repoID)
+	if err != nil {
+		return 0, err
+	}
+
+	changes, err := paginate[struct{}](ctx, c, fmt.Sprintf("%s/pull-requests/%d/changes", ref.path(), id))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get changes of pull request %d for repository %s: %w", id, ref, err)
+	}
+	return len(changes), nil
+}
+
+// mergeStatus asks Bitbucket whether a pull request can be merged
+func (c *Client) mergeStatus(ctx context.Context, ref repositoryRef, id int) (mergeStatus, error) {
+	var status mergeStatus
+	if err := c.api.Get(ctx, fmt.Sprintf("%s/pull-requests/%d/merge", ref.path(), id), &status); err != nil {
+		return mergeStatus{}, fmt.Errorf("failed to get merge status of pull request %d for repository %s: %w", id, ref, err)
+	}
+	return status, nil
+}
@@ -0,0 +1,170 @@
+package bitbucket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/bitbucketfake"
+)
+
+// setUpPulls stores PAY/app with ID 1 and its pull requests on the server
+func setUpPulls(server *bitbucketfake.Server, pulls []bitbucketfake.PullRequest) {
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "PAY"}}})
+	server.SetPullRequests(1, pulls)
+}
+
+func TestClient_ListPullRequests(t *testing.T) {
+	server, client := newFakeClient(t)
+	created := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	setUpPulls(server, []bitbucketfake.PullRequest{
+		{ID: 4, Title: "Release 1.2", Author: "alice", Source: "release", Target: "master", Conflicted: true, CreatedAt: created, UpdatedAt: created.Add(time.Hour),
+			Reviewers: []string{"dave"}, SHA: "abc123"},
+	})
+
+	mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{SourceBranch: "release", TargetBranch: "master"})
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	mr := mrs[0]
+	assert.Equal(t, 4, mr.ID)
+	assert.Equal(t, "Release 1.2", mr.Title)
+	assert.Equal(t, models.Author{Name: "alice", Username: "alice"}, mr.Author)
+	assert.Equal(t, server.URL()+"/projects/PAY/repos/app/pull-requests/4", mr.WebURL)
+	assert.Equal(t, "release", mr.SourceBranch)
+	assert.Equal(t, "master", mr.TargetBranch)
+	assert.Equal(t, "opened", mr.State)
+	assert.Equal(t, "cannot_be_merged", mr.MergeStatus)
+	assert.Equal(t, "conflict", mr.DetailedMergeStatus)
+	assert.True(t, mr.HasConflicts)
+	assert.Equal(t, created, mr.CreatedAt)
+	assert.Equal(t, created.Add(time.Hour), mr.UpdatedAt)
+	assert.Equal(t, []models.Author{{Name: "dave", Username: "dave"}}, mr.Reviewers)
+	assert.Equal(t, "abc123", mr.SHA)
+
+	var list bitbucketfake.Request
+	for _, r := range server.Requests() {
+		if r.Path == "/rest/api/1.0/projects/PAY/repos/app/pull-requests" {
+			list = r
+		}
+	}
+	assert.Equal(t, "OPEN", list.Query.Get("state"))
+	assert.Equal(t, "refs/heads/master", list.Query.Get("at"))
+	assert.Equal(t, "INCOMING", list.Query.Get("direction"))
+}
+
+func TestClient_ListPullRequests_MergeStatus(t *testing.T) {
+	tests := []struct {
+		name                string
+		pull                bitbucketfake.PullRequest
+		state               string
+		mergeStatus         string
+		detailedMergeStatus string
+		hasConflicts        bool
+	}{
+		{name: "clean", pull: bitbucketfake.PullRequest{}, state: "opened", mergeStatus: "can_be_merged", detailedMergeStatus: "mergeable"},
+		{name: "conflicted", pull: bitbucketfake.PullRequest{Conflicted: true}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "conflict", hasConflicts: true},
+		{name: "conflicted draft", pull: bitbucketfake.PullRequest{Conflicted: true, Draft: true}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "conflict", hasConflicts: true},
+		{name: "draft", pull: bitbucketfake.PullRequest{Draft: true}, state: "opened", mergeStatus: "can_be_merged", detailedMergeStatus: "draft_status"},
+		{name: "unknown", pull: bitbucketfake.PullRequest{Unknown: true}, state: "opened", mergeStatus: "checking", detailedMergeStatus: "checking"},
+		{name: "vetoed", pull: bitbucketfake.PullRequest{Vetoes: []string{"Requires 2 approvals"}}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "blocked_status"},
+		{name: "merged", pull: bitbucketfake.PullRequest{State: "MERGED"}, state: "merged"},
+		{name: "declined", pull: bitbucketfake.PullRequest{State: "DECLINED"}, state: "closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newFakeClient(t)
+			pull := tt.pull
+			pull.ID = 1
+			pull.Source, pull.Target = "release", "master"
+			setUpPulls(server, []bitbucketfake.PullRequest{pull})
+
+			mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{State: "all"})
+
+			require.NoError(t, err)
+			require.Len(t, mrs, 1)
+			assert.Equal(t, tt.state, mrs[0].State)
+			assert.Equal(t, tt.mergeStatus, mrs[0].MergeStatus)
+			assert.Equal(t, tt.detailedMergeStatus, mrs[0].DetailedMergeStatus)
+			assert.Equal(t, tt.hasConflicts, mrs[0].HasConflicts)
+		})
+	}
+}
+
+func TestClient_ListPullRequests_Filters(t *testing.T) {
+	server, client := newFakeClient(t)
+	updated := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	setUpPulls(server, []bitbucketfake.PullRequest{
+		{ID: 1, Source: "release", Target: "master", UpdatedAt: updated},
+		{ID: 2, Source: "feature", Target: "master", UpdatedAt: updated},
+		{ID: 3, Source: "release", Target: "develop", UpdatedAt: updated},
+		{ID: 4, Source: "release", Target: "master", UpdatedAt: updated.Add(-48 * time.Hour)},
+		{ID: 5, Source: "release", Target: "master", State: "MERGED", UpdatedAt: updated},
+		{ID: 6, Source: "release", Target: "master", State: "DECLINED", UpdatedAt: updated},
+	})
+	ctx := context.Background()
+
+	ids := func(opts PullRequestListOptions) []int {
+		mrs, err := client.ListPullRequests(ctx, 1, opts)
+		require.NoError(t, err)
+		var result []int
+		for _, mr := range mrs {
+			result = append(result, mr.ID)
+		}
+		return result
+	}
+
+	assert.ElementsMatch(t, []int{1, 4}, ids(PullRequestListOptions{SourceBranch: "release", TargetBranch: "master"}))
+	assert.ElementsMatch(t, []int{1}, ids(PullRequestListOptions{SourceBranch: "release", TargetBranch: "master", UpdatedAfter: updated.Add(-time.Hour)}))
+	assert.ElementsMatch(t, []int{5}, ids(PullRequestListOptions{State: "merged"}))
+	assert.ElementsMatch(t, []int{6}, ids(PullRequestListOptions{State: "closed"}))
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6}, ids(PullRequestListOptions{State: "all"}))
+
+	// Bitbucket has no labels, so no pull request carries the required ones
+	server.ResetRequests()
+	assert.Empty(t, ids(PullRequestListOptions{Labels: []string{"urgent"}}))
+	assert.Empty(t, server.Requests())
+}
+
+func TestClient_ListPullRequests_MergeStatusOnlyForOpen(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []bitbucketfake.PullRequest{
+		{ID: 1, Source: "release", Target: "master"},
+		{ID: 2, Source: "release", Target: "master", State: "MERGED"},
+	})
+	_, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{})
+	require.NoError(t, err)
+	server.ResetRequests()
+
+	_, err = client.ListPullRequests(context.Background(), 1, PullRequestListOptions{State: "all"})
+	require.NoError(t, err)
+
+	var paths []string
+	for _, r := range server.Requests() {
+		paths = append(paths, r.Path)
+	}
+	assert.Equal(t, []string{
+		"/rest/api/1.0/projects/PAY/repos/app/pull-requests",
+		"/rest/api/1.0/projects/PAY/repos/app/pull-requests/1/merge",
+	}, paths)
+}
+
+func TestClient_GetPullRequestChanges(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []bitbucketfake.PullRequest{{ID: 1, Source: "release", Target: "master", ChangedFiles: 130}})
+	ctx := context.Background()
+
+	changes, err := client.GetPullRequestChanges(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 130, changes)
+
+	_, err = client.GetPullRequestChanges(ctx, 1, 2)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
@@ -0,0 +1,122 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// link is an entry of the links of a Bitbucket resource
+type link struct {
+	Href string `json:"href"`
+}
+
+// repository is a repository as returned by the Bitbucket API
+type repository struct {
+	ID      int    `json:"id"`
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Project struct {
+		ID   int    `json:"id"`
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Links struct {
+		Self []link `json:"self"`
+	} `json:"links"`
+}
+
+// ref returns the project key and slug locating the repository
+func (r repository) ref() repositoryRef {
+	return repositoryRef{ProjectKey: r.Project.Key, Slug: r.Slug}
+}
+
+// model converts the repository to the shared model; the project stands in for the namespace.
+// Bitbucket does not report repository activity, so LastActivityAt is left unset.
+func (r repository) model() models.Repository {
+	repo := models.Repository{
+		ID:                r.ID,
+		Name:              r.Name,
+		PathWithNamespace: r.ref().String(),
+		Namespace: models.Namespace{
+			ID:       r.Project.ID,
+			Name:     r.Project.Name,
+			Path:     r.Project.Key,
+			FullPath: r.Project.Key,
+		},
+	}
+	if len(r.Links.Self) > 0 {
+		repo.WebURL = r.Links.Self[0].Href
+	}
+	return repo
+}
+
+// ListRepositoriesOptions filters the repositories returned by ListRepositories
+type ListRepositoriesOptions struct {
+	Projects          []string  // Only repositories of these project keys; every repository the token can read when empty
+	LastActivityAfter time.Time // Ignored: Bitbucket does not report repository activity
+}
+
+// ListRepositories retrieves the repositories the token can read. Bitbucket does not report
+// repository activity, so every repository is returned whatever LastActivityAfter is.
+func (c *Client) ListRepositories(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	var repos []repository
+	if len(opts.Projects) == 0 {
+		all, err := paginate[repository](ctx, c, "/repos")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		repos = all
+	}
+	for _, project := range opts.Projects {
+		projectRepos, err := paginate[repository](ctx, c, fmt.Sprintf("/projects/%s/repos", url.PathEscape(project)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of project %s: %w", project, err)
+		}
+		repos = append(repos, projectRepos...)
+	}
+
+	result := make([]models.Repository, 0, len(repos))
+	for _, repo := range repos {
+		c.rememberRepository(repo)
+		result = append(result, repo.model())
+	}
+
+	return result, nil
+}
+
+// repository returns where a repository is. The API cannot look repositories up by ID, so the
+// repositories are listed again when the ID was not seen before.
+func (c *Client) repository(ctx context.Context, repoID int) (repositoryRef, error) {
+	if ref, ok := c.knownRepository(repoID); ok {
+		return ref, nil
+	}
+
+	if _, err := c.ListRepositories(ctx, ListRepositoriesOptions{}); err != nil {
+		return repositoryRef{}, fmt.Errorf("failed to look up repository %d: %w", repoID, err)
+	}
+	if ref, ok := c.knownRepository(repoID); ok {
+		return ref, nil
+	}
+	return repositoryRef{}, &httpapi.APIError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("repository %d not found", repoID)}
+}
+
+// knownRepository returns where a listed repository is
+func (c *Client) knownRepository(repoID int) (repositoryRef, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ref, ok := c.repos[repoID]
+	return ref, ok
+}
+
+// rememberRepository keeps where a repository is for the requests made by ID
+func (c *Client) rememberRepository(repo repository) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repos[repo.ID] = repo.ref()
+}
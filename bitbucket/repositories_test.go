@@ -0,0 +1,80 @@
+package bitbucket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_ListRepositories(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{ID: 10, Name: "Payments", Path: "PAY"}},
+		{ID: 2, Name: "infra", Namespace: models.Namespace{ID: 20, Name: "Operations", Path: "OPS"}},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, 1, repos[0].ID)
+	assert.Equal(t, "app", repos[0].Name)
+	assert.Equal(t, "PAY/app", repos[0].PathWithNamespace)
+	assert.Equal(t, server.URL()+"/projects/PAY/repos/app/browse", repos[0].WebURL)
+	assert.Equal(t, models.Namespace{ID: 10, Name: "Payments", Path: "PAY", FullPath: "PAY"}, repos[0].Namespace)
+	assert.True(t, repos[0].LastActivityAt.IsZero())
+	assert.Equal(t, "/rest/api/1.0/repos", server.Requests()[0].Path)
+}
+
+func TestClient_ListRepositories_Projects(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{Path: "PAY"}},
+		{ID: 2, Name: "infra", Namespace: models.Namespace{Path: "OPS"}},
+		{ID: 3, Name: "web", Namespace: models.Namespace{Path: "WEB"}},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{Projects: []string{"PAY", "OPS"}})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "PAY/app", repos[0].PathWithNamespace)
+	assert.Equal(t, "OPS/infra", repos[1].PathWithNamespace)
+}
+
+func TestClient_ListRepositories_IgnoresLastActivityAfter(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "PAY"}}})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{LastActivityAfter: time.Now()})
+
+	require.NoError(t, err)
+	assert.Len(t, repos, 1)
+}
+
+func TestClient_RepositoryLookup(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 7, Name: "app", Namespace: models.Namespace{Path: "PAY"}}})
+	ctx := context.Background()
+
+	// An unknown ID is found by listing the repositories again
+	ref, err := client.repository(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, repositoryRef{ProjectKey: "PAY", Slug: "app"}, ref)
+	require.Len(t, server.Requests(), 1)
+
+	_, err = client.repository(ctx, 7)
+	require.NoError(t, err)
+	assert.Len(t, server.Requests(), 1)
+
+	_, err = client.repository(ctx, 8)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+	assert.Contains(t, err.Error(), "repository 8 not found")
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/diff"
+	"mr-conflict-checker/internal/ack"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/reporter"
+)
+
+// runDiffCommand implements the "diff" subcommand comparing two JSON reports
+func runDiffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var reportDir string
+	var outputFile string
+	fs.StringVar(&reportDir, "dir", ".", "Directory containing JSON reports (used when report paths are omitted)")
+	fs.StringVar(&outputFile, "output", "", "Write the diff to this file instead of stdout")
+	fs.StringVar(&outputFile, "o", "", "Write the diff to this file (shorthand)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s diff [OPTIONS] [PREVIOUS.json [CURRENT.json]]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Compares two JSON reports and lists new, resolved and persisting conflicts.\n")
+		fmt.Fprintf(fs.Output(), "Without arguments the two most recent reports in --dir are compared.\n\n")
+		fmt.Fprintf(fs.Output(), "OPTIONS:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	previousPath, currentPath, err := resolveDiffPaths(reportDir, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	previous, err := reporter.LoadJSONReport(previousPath)
+	if err != nil {
+		return err
+	}
+	current, err := reporter.LoadJSONReport(currentPath)
+	if err != nil {
+		return err
+	}
+
+	content := reporter.GenerateChangesMarkdown(diff.Compare(previous, current))
+
+	var out io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if _, err := io.WriteString(out, content); err != nil {
+		return fmt.Errorf("failed to write diff: %w", err)
+	}
+	return nil
+}
+
+// resolveDiffPaths works out which reports to compare from the positional arguments
+func resolveDiffPaths(reportDir string, args []string) (string, string, error) {
+	switch len(args) {
+	case 2:
+		return args[0], args[1], nil
+	case 1:
+		latest, err := reporter.FindLatestJSONReport(reportDir)
+		if err != nil {
+			return "", "", err
+		}
+		if latest == "" {
+			return "", "", fmt.Errorf("no JSON reports found in %s", reportDir)
+		}
+		return args[0], latest, nil
+	case 0:
+		paths, err := reporter.ListJSONReports(reportDir)
+		if err != nil {
+			return "", "", err
+		}
+		if len(paths) < 2 {
+			return "", "", fmt.Errorf("need at least two JSON reports in %s to compare, found %d", reportDir, len(paths))
+		}
+		return paths[len(paths)-2], paths[len(paths)-1], nil
+	default:
+		return "", "", fmt.Errorf("expected at most two report paths, got %d", len(args))
+	}
+}
+
+// runAckCommand implements the "ack" subcommand acknowledging a conflicting merge request until a date
+func runAckCommand(args []string) error {
+	fs := flag.NewFlagSet("ack", flag.ContinueOnError)
+	var configPath, file, dir, project, reason, until, by string
+	var mrID int
+	var list bool
+	addAckFileFlags(fs, &configPath, &file, &dir)
+	fs.StringVar(&project, "project", "", "Project path with namespace, such as group/app, or numeric ID")
+	fs.IntVar(&mrID, "mr", 0, "IID of the merge request within the project")
+	fs.StringVar(&reason, "reason", "", "Why the conflict is acknowledged, shown in the reports")
+	fs.StringVar(&until, "until", "", "Last day of the acknowledgement (2024-04-01), or an RFC 3339 time")
+	fs.StringVar(&by, "by", os.Getenv("USER"), "Who acknowledges the conflict")
+	fs.BoolVar(&list, "list", false, "List the acknowledgements instead of adding one")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s ack [OPTIONS] --project PATH --mr IID --reason TEXT --until DATE\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Acknowledges a conflicting merge request until a date. Acknowledged merge requests are\n")
+		fmt.Fprintf(fs.Output(), "marked in the reports but left out of notifications and --fail-on.\n\n")
+		fmt.Fprintf(fs.Output(), "OPTIONS:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, outputDir, err := ackFilePath(configPath, file, dir)
+	if err != nil {
+		return err
+	}
+	store, err := ack.Load(path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if list {
+		for _, a := range store.Acknowledgements {
+			state := "until"
+			if !a.Active(now) {
+				state = "expired"
+			}
+			fmt.Printf("%s!%d\t%s %s\tby %s\t%s\n", a.Project, a.MergeRequestID, state, a.ExpiresAt.Format(time.RFC3339), a.By, a.Reason)
+		}
+		return nil
+	}
+
+	switch {
+	case project == "" || mrID <= 0:
+		return fmt.Errorf("--project and --mr are required")
+	case reason == "":
+		return fmt.Errorf("--reason is required")
+	case until == "":
+		return fmt.Errorf("--until is required")
+	case by == "":
+		return fmt.Errorf("--by is required when $USER is not set")
+	}
+	expiresAt, err := ack.ParseExpiry(until)
+	if err != nil {
+		return err
+	}
+	if !expiresAt.After(now) {
+		return fmt.Errorf("--until %s is already in the past", until)
+	}
+
+	// Expired acknowledgements no longer mark anything, so they are dropped on the next change, and
+	// an earlier acknowledgement naming the project the other way is replaced too
+	store.Prune(now)
+	store.RemoveRepository(lookupRepository(outputDir, project), mrID)
+	store.Add(ack.Acknowledgement{
+		Project:        project,
+		MergeRequestID: mrID,
+		By:             by,
+		Reason:         reason,
+		AcknowledgedAt: now,
+		ExpiresAt:      expiresAt,
+	})
+	if err := store.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Acknowledged %s!%d until %s\n", project, mrID, expiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// runUnackCommand implements the "unack" subcommand removing the acknowledgement of a merge request
+func runUnackCommand(args []string) error {
+	fs := flag.NewFlagSet("unack", flag.ContinueOnError)
+	var configPath, file, dir, project string
+	var mrID int
+	addAckFileFlags(fs, &configPath, &file, &dir)
+	fs.StringVar(&project, "project", "", "Project path with namespace, such as group/app, or numeric ID")
+	fs.IntVar(&mrID, "mr", 0, "IID of the merge request within the project")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s unack [OPTIONS] --project PATH --mr IID\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Removes the acknowledgement of a conflicting merge request before it expires.\n\n")
+		fmt.Fprintf(fs.Output(), "OPTIONS:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if project == "" || mrID <= 0 {
+		return fmt.Errorf("--project and --mr are required")
+	}
+
+	path, outputDir, err := ackFilePath(configPath, file, dir)
+	if err != nil {
+		return err
+	}
+	store, err := ack.Load(path)
+	if err != nil {
+		return err
+	}
+	if !store.RemoveRepository(lookupRepository(outputDir, project), mrID) {
+		return fmt.Errorf("%s!%d is not acknowledged in %s", project, mrID, path)
+	}
+	if err := store.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed the acknowledgement of %s!%d\n", project, mrID)
+	return nil
+}
+
+// addAckFileFlags defines the flags locating the acknowledgements file of the ack and unack subcommands
+func addAckFileFlags(fs *flag.FlagSet, configPath, file, dir *string) {
+	fs.StringVar(configPath, "config", "", "Configuration file whose acknowledgements.file and output.directory are used")
+	fs.StringVar(configPath, "c", "", "Configuration file (shorthand)")
+	fs.StringVar(file, "file", "", "Acknowledgements file (defaults to acknowledgements.file, or "+ack.DefaultFileName+" in --dir)")
+	fs.StringVar(dir, "dir", ".", "Output directory of the scans, holding the reports and acknowledgements file (defaults to output.directory)")
+}
+
+// ackFilePath returns the acknowledgements file the ack and unack subcommands work on, the same one
+// a scan with the configuration uses unless --file is given, and the output directory of the scans
+func ackFilePath(configPath, file, dir string) (string, string, error) {
+	cfg := &config.Config{}
+	if configPath != "" {
+		loaded, err := config.LoadConfig(configPath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to load configuration: %w", err)
+		}
+		cfg = loaded
+	}
+	if dir == "." && cfg.Output.Directory != "" {
+		dir = cfg.Output.Directory
+	}
+
+	if file != "" {
+		return file, dir, nil
+	}
+	return acknowledgementsFilePath(cfg, dir), dir, nil
+}
+
+// lookupRepository returns the repository a --project flag names, with both its path and ID when
+// the latest report in outputDir lists it, so that an acknowledgement made either way is found
+func lookupRepository(outputDir, project string) models.Repository {
+	repo := models.Repository{PathWithNamespace: project}
+	if id, err := strconv.Atoi(project); err == nil {
+		repo = models.Repository{ID: id}
+	}
+
+	latest, err := reporter.FindLatestJSONReport(outputDir)
+	if err != nil || latest == "" {
+		return repo
+	}
+	report, err := reporter.LoadJSONReport(latest)
+	if err != nil {
+		slog.Debug("Failed to load latest report, matching the project as given", "path", latest, "error", err)
+		return repo
+	}
+	for _, repoReport := range report.Repositories {
+		r := repoReport.Repository
+		if (repo.ID != 0 && r.ID == repo.ID) || (repo.PathWithNamespace != "" && strings.EqualFold(r.PathWithNamespace, repo.PathWithNamespace)) {
+			return r
+		}
+	}
+	return repo
+}
+
+// compareWithPreviousReport diffs the report against the latest JSON report in outputDir
+func compareWithPreviousReport(report *models.Report, outputDir string) *models.ReportDiff {
+	previousPath, err := reporter.FindLatestJSONReport(outputDir)
+	if err != nil {
+		slog.Warn("Failed to look up previous report", "error", err)
+		return diff.Compare(nil, report)
+	}
+	if previousPath == "" {
+		slog.Info("No previous JSON report found, all conflicts will be reported as new")
+		return diff.Compare(nil, report)
+	}
+
+	previous, err := reporter.LoadJSONReport(previousPath)
+	if err != nil {
+		slog.Warn("Failed to load previous report", "path", previousPath, "error", err)
+		return diff.Compare(nil, report)
+	}
+
+	slog.Debug("Comparing with previous report", "path", previousPath)
+	return diff.Compare(previous, report)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActionsConfig holds the opt-in actions performed on conflicting merge requests
+type ActionsConfig struct {
+	DryRun    bool            `yaml:"dry_run,omitempty"` // Deprecated: alias of read_only, which LoadConfig sets when this is
+	Comment   CommentConfig   `yaml:"comment,omitempty"`
+	Label     LabelConfig     `yaml:"label,omitempty"`
+	BackMerge BackMergeConfig `yaml:"back_merge,omitempty"`
+	Rebase    RebaseConfig    `yaml:"rebase,omitempty"`
+}
+
+// CommentConfig configures the bot comment posted on conflicting merge requests
+type CommentConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	OnResolved string `yaml:"on_resolved,omitempty"` // "update" (default) marks the comment resolved, "delete" removes it
+}
+
+// LabelConfig configures the label kept on conflicting merge requests
+type LabelConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name,omitempty"` // Defaults to DefaultConflictLabel
+}
+
+// BackMergeConfig configures the back-merge MRs opened to bring target branch changes into the source branch
+type BackMergeConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Mode    string `yaml:"mode,omitempty"` // "direct" (default) merges master into release, "branch" goes through a sync/release-<date> branch
+}
+
+// RebaseConfig configures the server-side rebase of MRs that only need a rebase
+type RebaseConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"` // Delay between rebase status checks (default 2s)
+	Timeout      time.Duration `yaml:"timeout,omitempty"`       // How long to wait for each rebase (default 1m)
+}
+
+// Back-merge modes
+const (
+	BackMergeModeDirect = "direct"
+	BackMergeModeBranch = "branch"
+)
+
+// DefaultConflictLabel is the label used when none is configured
+const DefaultConflictLabel = "merge-conflict"
+
+// Comment resolution modes
+const (
+	CommentOnResolvedUpdate = "update"
+	CommentOnResolvedDelete = "delete"
+)
+
+// Validate checks all action settings
+func (a ActionsConfig) Validate() error {
+	switch a.Comment.OnResolved {
+	case "", CommentOnResolvedUpdate, CommentOnResolvedDelete:
+	default:
+		return fmt.Errorf("actions.comment.on_resolved must be update or delete")
+	}
+	switch a.BackMerge.Mode {
+	case "", BackMergeModeDirect, BackMergeModeBranch:
+	default:
+		return fmt.Errorf("actions.back_merge.mode must be direct or branch")
+	}
+	if a.Rebase.PollInterval < 0 || a.Rebase.Timeout < 0 {
+		return fmt.Errorf("actions.rebase.poll_interval and actions.rebase.timeout must not be negative")
+	}
+	if strings.Contains(a.Label.Name, ",") {
+		return fmt.Errorf("actions.label.name must not contain commas")
+	}
+	return nil
+}
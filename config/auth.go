@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// Authentication modes
+const (
+	AuthModeToken    = "token"
+	AuthModeJobToken = "job_token"
+	AuthModeOAuth    = "oauth"
+)
+
+// JobTokenEnv is the environment variable GitLab CI sets to the job token
+const JobTokenEnv = "CI_JOB_TOKEN"
+
+// AuthConfig selects how the checker authenticates to GitLab
+type AuthConfig struct {
+	Mode  string      `yaml:"mode,omitempty"` // "token" (default) for personal, group and project access tokens, "job_token" or "oauth"
+	OAuth OAuthConfig `yaml:"oauth,omitempty"`
+}
+
+// OAuthConfig configures the OAuth2 device flow used on workstations
+type OAuthConfig struct {
+	ClientID  string   `yaml:"client_id"`            // Application ID of a GitLab OAuth application with the device flow enabled
+	Scopes    []string `yaml:"scopes,omitempty"`     // Defaults to read_api, or api when write actions are enabled
+	TokenFile string   `yaml:"token_file,omitempty"` // Where the token is kept between runs (default: mr-conflict-checker/oauth-token.json in the user config directory)
+}
+
+// JobToken returns the configured job token, falling back to CI_JOB_TOKEN
+func (g GitLabConfig) JobToken() string {
+	if g.Token != "" {
+		return g.Token
+	}
+	return os.Getenv(JobTokenEnv)
+}
+
+// validateAuth checks the credentials required by the authentication mode
+func (g GitLabConfig) validateAuth() error {
+	switch g.Auth.Mode {
+	case "", AuthModeToken:
+		if g.Token == "" {
+			return fmt.Errorf("gitlab.token is required")
+		}
+	case AuthModeJobToken:
+		if g.JobToken() == "" {
+			return fmt.Errorf("gitlab.token or the %s environment variable is required for job_token authentication", JobTokenEnv)
+		}
+	case AuthModeOAuth:
+		if g.Auth.OAuth.ClientID == "" {
+			return fmt.Errorf("gitlab.auth.oauth.client_id is required for oauth authentication")
+		}
+	default:
+		return fmt.Errorf("gitlab.auth.mode must be token, job_token or oauth")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitLabConfig_ValidateAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		gitlab   GitLabConfig
+		jobToken string
+		errMsg   string
+	}{
+		{name: "token", gitlab: GitLabConfig{Token: "valid-token"}},
+		{name: "token missing", gitlab: GitLabConfig{Auth: AuthConfig{Mode: AuthModeToken}}, errMsg: "gitlab.token is required"},
+		{name: "job token from environment", gitlab: GitLabConfig{Auth: AuthConfig{Mode: AuthModeJobToken}}, jobToken: "ci-job-token"},
+		{name: "job token missing", gitlab: GitLabConfig{Auth: AuthConfig{Mode: AuthModeJobToken}}, errMsg: "CI_JOB_TOKEN"},
+		{name: "oauth", gitlab: GitLabConfig{Auth: AuthConfig{Mode: AuthModeOAuth, OAuth: OAuthConfig{ClientID: "client-id"}}}},
+		{name: "oauth without client id", gitlab: GitLabConfig{Auth: AuthConfig{Mode: AuthModeOAuth}}, errMsg: "gitlab.auth.oauth.client_id is required"},
+		{name: "unknown mode", gitlab: GitLabConfig{Token: "valid-token", Auth: AuthConfig{Mode: "basic"}}, errMsg: "gitlab.auth.mode must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(JobTokenEnv, tt.jobToken)
+
+			err := tt.gitlab.validateAuth()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestGitLabConfig_JobToken(t *testing.T) {
+	t.Setenv(JobTokenEnv, "from-environment")
+
+	assert.Equal(t, "from-environment", GitLabConfig{}.JobToken())
+	assert.Equal(t, "from-config", GitLabConfig{Token: "from-config"}.JobToken())
+}
+
+func TestLoadConfig_OAuth(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+
+	yamlContent := `gitlab:
+  url: https://gitlab.example.com
+  auth:
+    mode: oauth
+    oauth:
+      client_id: 0123456789abcdef
+      scopes: [read_api]
+      token_file: /tmp/oauth-token.json
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, AuthModeOAuth, config.GitLab.Auth.Mode)
+	assert.Equal(t, "0123456789abcdef", config.GitLab.Auth.OAuth.ClientID)
+	assert.Equal(t, []string{"read_api"}, config.GitLab.Auth.OAuth.Scopes)
+	assert.Equal(t, "/tmp/oauth-token.json", config.GitLab.Auth.OAuth.TokenFile)
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// BitbucketTokenEnv is the environment variable the Bitbucket token is read from when not configured
+const BitbucketTokenEnv = "BITBUCKET_TOKEN"
+
+// BitbucketConfig holds the Bitbucket Server and Data Center connection settings
+type BitbucketConfig struct {
+	Token    string        `yaml:"token"`              // HTTP access token; defaults to $BITBUCKET_TOKEN
+	URL      string        `yaml:"url"`                // Instance URL, e.g. https://bitbucket.example.com
+	Projects []string      `yaml:"projects,omitempty"` // Only scan these project keys (empty = every repository the token can read)
+	Timeout  time.Duration `yaml:"timeout,omitempty"`  // Per-request timeout (default 30s)
+}
+
+// AccessToken returns the configured token, falling back to BITBUCKET_TOKEN
+func (b BitbucketConfig) AccessToken() string {
+	if b.Token != "" {
+		return b.Token
+	}
+	return os.Getenv(BitbucketTokenEnv)
+}
+
+// validate checks the Bitbucket settings
+func (b BitbucketConfig) validate() error {
+	if b.AccessToken() == "" {
+		return fmt.Errorf("bitbucket.token or the %s environment variable is required", BitbucketTokenEnv)
+	}
+	if instanceURL, err := url.Parse(b.URL); err != nil || instanceURL.Scheme == "" || instanceURL.Host == "" {
+		return fmt.Errorf("bitbucket.url must be an absolute URL such as https://bitbucket.example.com")
+	}
+	if b.Timeout < 0 {
+		return fmt.Errorf("bitbucket.timeout must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// CacheConfig configures the on-disk cache of GitLab responses
+type CacheConfig struct {
+	Enabled bool           `yaml:"enabled"`
+	Dir     string         `yaml:"dir,omitempty"` // Defaults to mr-conflict-checker in the user cache directory
+	TTL     CacheTTLConfig `yaml:"ttl,omitempty"`
+}
+
+// CacheTTLConfig sets how long each kind of response is reused before it is revalidated with
+// its ETag; unset values keep the defaults and 0 revalidates on every request
+type CacheTTLConfig struct {
+	Projects      *time.Duration `yaml:"projects,omitempty"`       // Project lists (default 1h)
+	MergeRequests *time.Duration `yaml:"merge_requests,omitempty"` // Merge request lists (default 0)
+	Changes       *time.Duration `yaml:"changes,omitempty"`        // Merge request changes (default 15m)
+	Other         *time.Duration `yaml:"other,omitempty"`          // Every other request (default 0)
+}
+
+// validate checks that no time-to-live is negative
+func (c CacheConfig) validate() error {
+	for _, ttl := range []*time.Duration{c.TTL.Projects, c.TTL.MergeRequests, c.TTL.Changes, c.TTL.Other} {
+		if ttl != nil && *ttl < 0 {
+			return fmt.Errorf("gitlab.cache.ttl values must not be negative")
+		}
+	}
+	return nil
+}
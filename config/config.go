@@ -2,23 +2,69 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration structure
 type Config struct {
-	GitLab struct {
-		Token         string `yaml:"token"`
-		URL           string `yaml:"url"`
-		IncludeGroups []int  `yaml:"include_groups,omitempty"`
-	} `yaml:"gitlab"`
-	Output struct {
-		Directory string `yaml:"directory,omitempty"`
-	} `yaml:"output,omitempty"`
+	Provider         string                 `yaml:"provider,omitempty"`  // "gitlab" (default), "github", "gitea" or "bitbucket"
+	Providers        []string               `yaml:"providers,omitempty"` // Several providers scanned into one report, instead of provider
+	GitLab           GitLabConfig           `yaml:"gitlab"`
+	GitHub           GitHubConfig           `yaml:"github,omitempty"`
+	Gitea            GiteaConfig            `yaml:"gitea,omitempty"`
+	Bitbucket        BitbucketConfig        `yaml:"bitbucket,omitempty"`
+	Output           OutputConfig           `yaml:"output,omitempty"`
+	Ignore           []IgnoreRule           `yaml:"ignore,omitempty"` // Conflicting MRs listed as suppressed instead of as conflicts
+	Notify           NotifyConfig           `yaml:"notify,omitempty"`
+	Actions          ActionsConfig          `yaml:"actions,omitempty"`
+	ReadOnly         bool                   `yaml:"read_only,omitempty"` // Refuse every write request to GitLab
+	Incremental      IncrementalConfig      `yaml:"incremental,omitempty"`
+	Acknowledgements AcknowledgementsConfig `yaml:"acknowledgements,omitempty"`
+	Severity         SeverityConfig         `yaml:"severity,omitempty"`
 }
 
+// IncrementalConfig enables scans that only look at what changed since the last successful scan
+type IncrementalConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	StateFile string `yaml:"state_file,omitempty"` // Defaults to .mr-conflict-checker-state.json in the output directory
+}
+
+// AcknowledgementsConfig locates the conflicting merge requests acknowledged with the ack subcommand
+type AcknowledgementsConfig struct {
+	File string `yaml:"file,omitempty"` // Defaults to .mr-conflict-checker-acks.json in the output directory
+}
+
+// GitLabConfig holds the GitLab connection settings
+type GitLabConfig struct {
+	Token              string        `yaml:"token"`
+	URL                string        `yaml:"url"`
+	IncludeGroups      []int         `yaml:"include_groups,omitempty"`
+	TokenExpiryWarning time.Duration `yaml:"token_expiry_warning,omitempty"` // Warn when the token expires within this window (default 7 days)
+	CAFile             string        `yaml:"ca_file,omitempty"`              // PEM bundle trusted in addition to the system roots
+	CertFile           string        `yaml:"cert_file,omitempty"`            // Client certificate for mutual TLS
+	KeyFile            string        `yaml:"key_file,omitempty"`             // Key of the client certificate
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify,omitempty"` // Disable certificate verification; never use in production
+	ProxyURL           string        `yaml:"proxy_url,omitempty"`            // Proxy for GitLab requests; defaults to $HTTPS_PROXY/$HTTP_PROXY
+	NoProxy            string        `yaml:"no_proxy,omitempty"`             // Hosts reached without proxy_url; defaults to $NO_PROXY
+	Timeout            time.Duration `yaml:"timeout,omitempty"`              // Per-request timeout (default 30s)
+	Auth               AuthConfig    `yaml:"auth,omitempty"`
+	Cache              CacheConfig   `yaml:"cache,omitempty"`
+	API                string        `yaml:"api,omitempty"` // "rest" (default) or "graphql" for scanning
+}
+
+// APIs the scanner can read GitLab with
+const (
+	APIREST    = "rest"
+	APIGraphQL = "graphql"
+)
+
+// DefaultTokenExpiryWarning is the token expiry warning window used when none is configured
+const DefaultTokenExpiryWarning = 7 * 24 * time.Hour
+
 // LoadConfig reads and parses the YAML configuration file
 func LoadConfig(filePath string) (*Config, error) {
 	// Check if file exists
@@ -38,6 +84,11 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse YAML configuration: %w", err)
 	}
 
+	// actions.dry_run came before read_only; it must keep every write from reaching the server
+	if config.Actions.DryRun {
+		config.ReadOnly = true
+	}
+
 	// Validate required fields
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -48,11 +99,54 @@ func LoadConfig(filePath string) (*Config, error) {
 
 // Validate checks that all required configuration fields are present
 func (c *Config) Validate() error {
-	if c.GitLab.Token == "" {
-		return fmt.Errorf("gitlab.token is required")
+	if err := c.validateProviders(); err != nil {
+		return err
+	}
+	if err := c.Output.Filter.validate(); err != nil {
+		return err
+	}
+	if err := c.validateIgnoreRules(); err != nil {
+		return err
 	}
-	if c.GitLab.URL == "" {
+	if err := c.Notify.Validate(); err != nil {
+		return err
+	}
+	if err := c.validateSeverity(); err != nil {
+		return err
+	}
+	if err := c.Actions.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validate checks the GitLab settings
+func (g GitLabConfig) validate() error {
+	if err := g.validateAuth(); err != nil {
+		return err
+	}
+	if g.URL == "" {
 		return fmt.Errorf("gitlab.url is required")
 	}
+	if g.TokenExpiryWarning < 0 {
+		return fmt.Errorf("gitlab.token_expiry_warning must not be negative")
+	}
+	if g.Timeout < 0 {
+		return fmt.Errorf("gitlab.timeout must not be negative")
+	}
+	if (g.CertFile == "") != (g.KeyFile == "") {
+		return fmt.Errorf("gitlab.cert_file and gitlab.key_file must be set together")
+	}
+	if g.ProxyURL != "" {
+		if proxyURL, err := url.Parse(g.ProxyURL); err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return fmt.Errorf("gitlab.proxy_url must be an absolute URL such as http://proxy.example.com:3128")
+		}
+	}
+	if api := g.API; api != "" && api != APIREST && api != APIGraphQL {
+		return fmt.Errorf("gitlab.api must be %q or %q, got %q", APIREST, APIGraphQL, api)
+	}
+	if err := g.Cache.validate(); err != nil {
+		return err
+	}
 	return nil
 }
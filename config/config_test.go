@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -207,11 +208,7 @@ func TestConfig_Validate(t *testing.T) {
 		{
 			name: "valid config",
 			config: Config{
-				GitLab: struct {
-					Token         string `yaml:"token"`
-					URL           string `yaml:"url"`
-					IncludeGroups []int  `yaml:"include_groups,omitempty"`
-				}{
+				GitLab: GitLabConfig{
 					Token: "valid-token",
 					URL:   "https://gitlab.com",
 				},
@@ -221,11 +218,7 @@ func TestConfig_Validate(t *testing.T) {
 		{
 			name: "missing token",
 			config: Config{
-				GitLab: struct {
-					Token         string `yaml:"token"`
-					URL           string `yaml:"url"`
-					IncludeGroups []int  `yaml:"include_groups,omitempty"`
-				}{
+				GitLab: GitLabConfig{
 					Token: "",
 					URL:   "https://gitlab.com",
 				},
@@ -236,11 +229,7 @@ func TestConfig_Validate(t *testing.T) {
 		{
 			name: "missing URL",
 			config: Config{
-				GitLab: struct {
-					Token         string `yaml:"token"`
-					URL           string `yaml:"url"`
-					IncludeGroups []int  `yaml:"include_groups,omitempty"`
-				}{
+				GitLab: GitLabConfig{
 					Token: "valid-token",
 					URL:   "",
 				},
@@ -248,6 +237,77 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "gitlab.url is required",
 		},
+		{
+			name: "negative token expiry warning",
+			config: Config{
+				GitLab: GitLabConfig{
+					Token:              "valid-token",
+					URL:                "https://gitlab.com",
+					TokenExpiryWarning: -time.Hour,
+				},
+			},
+			wantErr: true,
+			errMsg:  "gitlab.token_expiry_warning must not be negative",
+		},
+		{
+			name: "client certificate without key",
+			config: Config{
+				GitLab: GitLabConfig{
+					Token:    "valid-token",
+					URL:      "https://gitlab.com",
+					CertFile: "client.pem",
+				},
+			},
+			wantErr: true,
+			errMsg:  "gitlab.cert_file and gitlab.key_file must be set together",
+		},
+		{
+			name: "relative proxy URL",
+			config: Config{
+				GitLab: GitLabConfig{
+					Token:    "valid-token",
+					URL:      "https://gitlab.com",
+					ProxyURL: "proxy.example.com:3128",
+				},
+			},
+			wantErr: true,
+			errMsg:  "gitlab.proxy_url must be an absolute URL",
+		},
+		{
+			name: "negative timeout",
+			config: Config{
+				GitLab: GitLabConfig{
+					Token:   "valid-token",
+					URL:     "https://gitlab.com",
+					Timeout: -time.Second,
+				},
+			},
+			wantErr: true,
+			errMsg:  "gitlab.timeout must not be negative",
+		},
+		{
+			name: "GraphQL API",
+			config: Config{
+				GitLab: GitLabConfig{
+					Token: "valid-token",
+					URL:   "https://gitlab.com",
+					API:   APIGraphQL,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown API",
+			config: Config{
+				GitLab: GitLabConfig{
+					Token: "valid-token",
+					URL:   "https://gitlab.com",
+					API:   "soap",
+				},
+			},
+			wantErr: true,
+			errMsg:  `gitlab.api must be "rest" or "graphql", got "soap"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,3 +322,344 @@ func TestConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfig_SlackNotifications(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+notify:
+  slack:
+    webhook_url: https://hooks.slack.com/services/T000/B000/XXXX
+    channel: "#releases"
+    top_n: 3
+    only_on_changes: true
+    routes:
+      - namespaces: [frontend]
+        channel: "#frontend"
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	assert.True(t, config.Notify.Slack.Enabled())
+	assert.Equal(t, "#releases", config.Notify.Slack.Channel)
+	assert.Equal(t, 3, config.Notify.Slack.TopN)
+	assert.True(t, config.Notify.Slack.OnlyOnChanges)
+	require.Len(t, config.Notify.Slack.Routes, 1)
+	assert.Equal(t, []string{"frontend"}, config.Notify.Slack.Routes[0].Namespaces)
+}
+
+func TestSlackConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SlackConfig
+		errMsg string
+	}{
+		{name: "disabled", config: SlackConfig{}},
+		{name: "valid route", config: SlackConfig{WebhookURL: "https://hooks.example.com", Routes: []SlackRoute{{Projects: []string{"a/b"}, Channel: "#a"}}}},
+		{name: "negative top_n", config: SlackConfig{WebhookURL: "https://hooks.example.com", TopN: -1}, errMsg: "top_n must not be negative"},
+		{name: "routes without webhook", config: SlackConfig{Routes: []SlackRoute{{Projects: []string{"a/b"}, Channel: "#a"}}}, errMsg: "webhook_url is required"},
+		{name: "route without match", config: SlackConfig{WebhookURL: "https://hooks.example.com", Routes: []SlackRoute{{Channel: "#a"}}}, errMsg: "must match at least one project or namespace"},
+		{name: "route without destination", config: SlackConfig{WebhookURL: "https://hooks.example.com", Routes: []SlackRoute{{Namespaces: []string{"a"}}}}, errMsg: "requires a channel or webhook_url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_NotifierSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+notify:
+  teams:
+    webhook_url: https://teams.example.com/webhook
+    timeout: 5s
+    retries: 3
+    retry_backoff: 500ms
+    filter:
+      namespaces: [backend]
+      min_conflicts: 2
+  webhooks:
+    - name: ops
+      url: https://ops.example.com/hook
+      method: PUT
+      headers:
+        X-Api-Key: secret
+      template: '{"conflicts": {{ .Report.TotalConflictingMRs }}}'
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	teams := config.Notify.Teams
+	assert.True(t, teams.Enabled())
+	assert.Equal(t, 5*time.Second, teams.Timeout)
+	assert.Equal(t, 3, teams.Retries)
+	assert.Equal(t, 500*time.Millisecond, teams.RetryBackoff)
+	assert.Equal(t, []string{"backend"}, teams.Filter.Namespaces)
+	assert.Equal(t, 2, teams.Filter.MinConflicts)
+
+	require.Len(t, config.Notify.Webhooks, 1)
+	assert.Equal(t, "ops", config.Notify.Webhooks[0].Name)
+	assert.Equal(t, "secret", config.Notify.Webhooks[0].Headers["X-Api-Key"])
+}
+
+func TestLoadConfig_EmailNotifications(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+notify:
+  email:
+    host: smtp.example.com
+    security: smtps
+    username: checker
+    password: secret
+    from: "MR Checker <checker@example.com>"
+    send_empty: true
+    recipients:
+      - address: team@example.com
+      - address: frontend@example.com
+        namespaces: [frontend]
+        authors: [alice]
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	email := config.Notify.Email
+	assert.True(t, email.Enabled())
+	assert.Equal(t, "smtp.example.com", email.Host)
+	assert.Equal(t, EmailSecuritySMTPS, email.Security)
+	assert.Equal(t, "checker", email.Username)
+	assert.True(t, email.SendEmpty)
+	require.Len(t, email.Recipients, 2)
+	assert.Equal(t, "frontend@example.com", email.Recipients[1].Address)
+	assert.Equal(t, []string{"frontend"}, email.Recipients[1].Namespaces)
+	assert.Equal(t, []string{"alice"}, email.Recipients[1].Authors)
+}
+
+func TestNotifyConfig_Secrets(t *testing.T) {
+	notify := NotifyConfig{
+		Slack: SlackConfig{
+			WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+			Routes:     []SlackRoute{{Channel: "#frontend"}, {WebhookURL: "https://hooks.slack.com/services/T000/B001/YYYY"}},
+		},
+		Email:    EmailConfig{Host: "smtp.example.com", Username: "bot", Password: "smtp-password"},
+		Webhooks: []WebhookConfig{{Name: "ops", URL: "https://ops.example.com/hook/abcdef"}},
+	}
+
+	secrets := notify.Secrets()
+	for _, secret := range []string{
+		"https://hooks.slack.com/services/T000/B000/XXXX",
+		"https://hooks.slack.com/services/T000/B001/YYYY",
+		"smtp-password",
+		"https://ops.example.com/hook/abcdef",
+	} {
+		assert.Contains(t, secrets, secret)
+	}
+}
+
+func TestNotifyConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config NotifyConfig
+		errMsg string
+	}{
+		{name: "empty", config: NotifyConfig{}},
+		{name: "webhook without name", config: NotifyConfig{Webhooks: []WebhookConfig{{URL: "https://a"}}}, errMsg: "notify.webhooks[0].name is required"},
+		{name: "webhook without url", config: NotifyConfig{Webhooks: []WebhookConfig{{Name: "a"}}}, errMsg: "notify.webhooks[0].url is required"},
+		{name: "duplicate webhook", config: NotifyConfig{Webhooks: []WebhookConfig{{Name: "a", URL: "https://a"}, {Name: "a", URL: "https://b"}}}, errMsg: "is used more than once"},
+		{name: "template conflict", config: NotifyConfig{Webhooks: []WebhookConfig{{Name: "a", URL: "https://a", Template: "x", TemplateFile: "y"}}}, errMsg: "mutually exclusive"},
+		{name: "bad method", config: NotifyConfig{Webhooks: []WebhookConfig{{Name: "a", URL: "https://a", Method: "GET"}}}, errMsg: "method must be POST, PUT or PATCH"},
+		{name: "negative retries", config: NotifyConfig{Teams: TeamsConfig{NotifierSettings: NotifierSettings{Retries: -1}}}, errMsg: "notify.teams.retries must not be negative"},
+		{name: "email without from", config: NotifyConfig{Email: EmailConfig{Host: "smtp", Recipients: []EmailRecipient{{Address: "a@example.com"}}}}, errMsg: "notify.email.from is required"},
+		{name: "email without recipients", config: NotifyConfig{Email: EmailConfig{Host: "smtp", From: "a@example.com"}}, errMsg: "notify.email.recipients must not be empty"},
+		{name: "email bad address", config: NotifyConfig{Email: EmailConfig{Host: "smtp", From: "a@example.com", Recipients: []EmailRecipient{{Address: "team"}}}}, errMsg: "is not a valid email address"},
+		{name: "email bad security", config: NotifyConfig{Email: EmailConfig{Host: "smtp", From: "a@example.com", Security: "ssl", Recipients: []EmailRecipient{{Address: "a@example.com"}}}}, errMsg: "notify.email.security must be one of"},
+		{name: "email username without password", config: NotifyConfig{Email: EmailConfig{Host: "smtp", From: "a@example.com", Username: "u", Recipients: []EmailRecipient{{Address: "a@example.com"}}}}, errMsg: "must be set together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestActionsConfig_Validate(t *testing.T) {
+	assert.NoError(t, ActionsConfig{}.Validate())
+	assert.NoError(t, ActionsConfig{Comment: CommentConfig{Enabled: true, OnResolved: CommentOnResolvedDelete}}.Validate())
+
+	err := ActionsConfig{Comment: CommentConfig{Enabled: true, OnResolved: "close"}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "actions.comment.on_resolved")
+
+	err = ActionsConfig{BackMerge: BackMergeConfig{Enabled: true, Mode: "cherry-pick"}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "actions.back_merge.mode")
+
+	err = ActionsConfig{Rebase: RebaseConfig{Enabled: true, Timeout: -time.Second}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "actions.rebase")
+
+	err = ActionsConfig{Label: LabelConfig{Enabled: true, Name: "a,b"}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "actions.label.name")
+}
+
+func TestLoadConfig_ReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+read_only: true
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	assert.True(t, config.ReadOnly)
+}
+
+func TestLoadConfig_ActionsDryRunIsReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+actions:
+  dry_run: true
+  comment:
+    enabled: true
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	assert.True(t, config.ReadOnly, "actions.dry_run must keep writes from reaching the server")
+}
+
+func TestLoadConfig_TransportSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.internal.example
+  ca_file: /etc/ssl/internal-ca.pem
+  cert_file: /etc/ssl/client.pem
+  key_file: /etc/ssl/client-key.pem
+  proxy_url: http://proxy.example.com:3128
+  no_proxy: localhost,.internal.example
+  timeout: 1m
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/etc/ssl/internal-ca.pem", config.GitLab.CAFile)
+	assert.Equal(t, "/etc/ssl/client.pem", config.GitLab.CertFile)
+	assert.Equal(t, "/etc/ssl/client-key.pem", config.GitLab.KeyFile)
+	assert.False(t, config.GitLab.InsecureSkipVerify)
+	assert.Equal(t, "http://proxy.example.com:3128", config.GitLab.ProxyURL)
+	assert.Equal(t, "localhost,.internal.example", config.GitLab.NoProxy)
+	assert.Equal(t, time.Minute, config.GitLab.Timeout)
+}
+
+func TestLoadConfig_Cache(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+  cache:
+    enabled: true
+    dir: /var/cache/checker
+    ttl:
+      projects: 2h
+      merge_requests: 0s
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	cache := config.GitLab.Cache
+	assert.True(t, cache.Enabled)
+	assert.Equal(t, "/var/cache/checker", cache.Dir)
+	require.NotNil(t, cache.TTL.Projects)
+	assert.Equal(t, 2*time.Hour, *cache.TTL.Projects)
+	require.NotNil(t, cache.TTL.MergeRequests)
+	assert.Equal(t, time.Duration(0), *cache.TTL.MergeRequests)
+	assert.Nil(t, cache.TTL.Changes, "unset values keep the default")
+}
+
+func TestLoadConfig_NegativeCacheTTL(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+  cache:
+    enabled: true
+    ttl:
+      changes: -5m
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	_, err := LoadConfig(configFile)
+	assert.ErrorContains(t, err, "gitlab.cache.ttl values must not be negative")
+}
+
+func TestLoadConfig_Incremental(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `gitlab:
+  token: test-token
+  url: https://gitlab.example.com
+incremental:
+  enabled: true
+  state_file: /var/lib/checker/state.json
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	assert.True(t, config.Incremental.Enabled)
+	assert.Equal(t, "/var/lib/checker/state.json", config.Incremental.StateFile)
+}
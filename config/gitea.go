@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// GiteaTokenEnv is the environment variable Gitea and Forgejo Actions provide the token in
+const GiteaTokenEnv = "GITEA_TOKEN"
+
+// GiteaConfig holds the Gitea or Forgejo connection settings
+type GiteaConfig struct {
+	Token         string        `yaml:"token"`                   // Defaults to $GITEA_TOKEN
+	URL           string        `yaml:"url"`                     // Instance URL, e.g. https://gitea.example.com
+	Organizations []string      `yaml:"organizations,omitempty"` // Only scan these organizations (empty = every repository of the user)
+	Timeout       time.Duration `yaml:"timeout,omitempty"`       // Per-request timeout (default 30s)
+}
+
+// AccessToken returns the configured token, falling back to GITEA_TOKEN
+func (g GiteaConfig) AccessToken() string {
+	if g.Token != "" {
+		return g.Token
+	}
+	return os.Getenv(GiteaTokenEnv)
+}
+
+// validate checks the Gitea settings
+func (g GiteaConfig) validate() error {
+	if g.AccessToken() == "" {
+		return fmt.Errorf("gitea.token or the %s environment variable is required", GiteaTokenEnv)
+	}
+	if instanceURL, err := url.Parse(g.URL); err != nil || instanceURL.Scheme == "" || instanceURL.Host == "" {
+		return fmt.Errorf("gitea.url must be an absolute URL such as https://gitea.example.com")
+	}
+	if g.Timeout < 0 {
+		return fmt.Errorf("gitea.timeout must not be negative")
+	}
+	return nil
+}
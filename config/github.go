@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// GitHubTokenEnv is the environment variable GitHub Actions and the gh CLI read the token from
+const GitHubTokenEnv = "GITHUB_TOKEN"
+
+// GitHubConfig holds the GitHub connection settings
+type GitHubConfig struct {
+	Token         string        `yaml:"token"`                   // Defaults to $GITHUB_TOKEN
+	URL           string        `yaml:"url,omitempty"`           // API URL: https://api.github.com (default) or https://HOST/api/v3 for GitHub Enterprise Server
+	Organizations []string      `yaml:"organizations,omitempty"` // Only scan these organizations (empty = every repository the token can access)
+	Timeout       time.Duration `yaml:"timeout,omitempty"`       // Per-request timeout (default 30s)
+}
+
+// AccessToken returns the configured token, falling back to GITHUB_TOKEN
+func (g GitHubConfig) AccessToken() string {
+	if g.Token != "" {
+		return g.Token
+	}
+	return os.Getenv(GitHubTokenEnv)
+}
+
+// validate checks the GitHub settings
+func (g GitHubConfig) validate() error {
+	if g.AccessToken() == "" {
+		return fmt.Errorf("github.token or the %s environment variable is required", GitHubTokenEnv)
+	}
+	if g.URL != "" {
+		if apiURL, err := url.Parse(g.URL); err != nil || apiURL.Scheme == "" || apiURL.Host == "" {
+			return fmt.Errorf("github.url must be an absolute URL such as https://github.example.com/api/v3")
+		}
+	}
+	if g.Timeout < 0 {
+		return fmt.Errorf("github.timeout must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_GitHub(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	yamlContent := `provider: github
+github:
+  token: ghp-test
+  url: https://github.example.com/api/v3
+  organizations: [acme, globex]
+  timeout: 10s
+actions:
+  comment:
+    enabled: true
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(yamlContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, ProviderGitHub, config.Provider)
+	assert.Equal(t, "ghp-test", config.GitHub.AccessToken())
+	assert.Equal(t, "https://github.example.com/api/v3", config.GitHub.URL)
+	assert.Equal(t, []string{"acme", "globex"}, config.GitHub.Organizations)
+	assert.Equal(t, 10*time.Second, config.GitHub.Timeout)
+}
+
+func TestGitHubConfig_AccessTokenFromEnvironment(t *testing.T) {
+	t.Setenv(GitHubTokenEnv, "ghp-from-env")
+
+	assert.Equal(t, "ghp-from-env", GitHubConfig{}.AccessToken())
+	assert.Equal(t, "ghp-configured", GitHubConfig{Token: "ghp-configured"}.AccessToken())
+	cfg := Config{Provider: ProviderGitHub}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_ValidateProvider(t *testing.T) {
+	t.Setenv(GitHubTokenEnv, "")
+
+	tests := []struct {
+		name   string
+		config Config
+		errMsg string
+	}{
+		{name: "github", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t"}}},
+		{name: "github ignores gitlab settings", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t"}, GitLab: GitLabConfig{API: "soap"}}},
+		{name: "explicit gitlab", config: Config{Provider: ProviderGitLab, GitLab: GitLabConfig{Token: "t", URL: "https://gitlab.com"}}},
+		{name: "unknown provider", config: Config{Provider: "svn"}, errMsg: `provider must be one of "gitlab", "github", "gitea" or "bitbucket", got "svn"`},
+		{name: "github without token", config: Config{Provider: ProviderGitHub}, errMsg: "github.token or the GITHUB_TOKEN environment variable is required"},
+		{name: "github relative URL", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t", URL: "github.example.com"}}, errMsg: "github.url must be an absolute URL"},
+		{name: "github negative timeout", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t", Timeout: -time.Second}}, errMsg: "github.timeout must not be negative"},
+		{name: "github label action", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t"}, Actions: ActionsConfig{Label: LabelConfig{Enabled: true}}}, errMsg: "actions.label is only supported with the gitlab provider"},
+		{name: "github back-merge action", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t"}, Actions: ActionsConfig{BackMerge: BackMergeConfig{Enabled: true}}}, errMsg: "actions.back_merge is only supported with the gitlab provider"},
+		{name: "github rebase action", config: Config{Provider: ProviderGitHub, GitHub: GitHubConfig{Token: "t"}, Actions: ActionsConfig{Rebase: RebaseConfig{Enabled: true}}}, errMsg: "actions.rebase is only supported with the gitlab provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
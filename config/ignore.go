@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Actions an ignore rule can take on the merge requests it matches
+const (
+	IgnoreActionSkip      = "skip"      // Listed with the reason instead of as conflicts
+	IgnoreActionDowngrade = "downgrade" // Still listed as conflicts, marked with the reason and at most low severity
+)
+
+// IgnoreRule skips or downgrades the conflicting merge requests matching every condition it sets
+type IgnoreRule struct {
+	Name      string        `yaml:"name,omitempty"`       // Reason shown in the report; defaults to the matched conditions
+	Action    string        `yaml:"action,omitempty"`     // skip or downgrade; defaults to skip
+	Labels    []string      `yaml:"labels,omitempty"`     // Carrying any of these labels
+	Draft     bool          `yaml:"draft,omitempty"`      // Marked as a draft
+	Title     string        `yaml:"title,omitempty"`      // Title matching this regular expression
+	Authors   []string      `yaml:"authors,omitempty"`    // Opened by one of these usernames
+	OlderThan time.Duration `yaml:"older_than,omitempty"` // Created longer ago than this
+}
+
+// validate checks an ignore rule; field is its position in the configuration, such as ignore[0]
+func (r IgnoreRule) validate(field string) error {
+	if len(r.Labels) == 0 && !r.Draft && r.Title == "" && len(r.Authors) == 0 && r.OlderThan == 0 {
+		return fmt.Errorf("%s must set at least one of labels, draft, title, authors or older_than", field)
+	}
+	for i, label := range r.Labels {
+		if label == "" {
+			return fmt.Errorf("%s.labels[%d] must not be empty", field, i)
+		}
+	}
+	for i, author := range r.Authors {
+		if author == "" {
+			return fmt.Errorf("%s.authors[%d] must not be empty", field, i)
+		}
+	}
+	if r.Title != "" {
+		if _, err := regexp.Compile(r.Title); err != nil {
+			return fmt.Errorf("%s.title is not a valid regular expression: %w", field, err)
+		}
+	}
+	if r.OlderThan < 0 {
+		return fmt.Errorf("%s.older_than must not be negative", field)
+	}
+	switch r.Action {
+	case "", IgnoreActionSkip, IgnoreActionDowngrade:
+	default:
+		return fmt.Errorf("%s.action must be %s or %s, got %q", field, IgnoreActionSkip, IgnoreActionDowngrade, r.Action)
+	}
+	return nil
+}
+
+// validateIgnoreRules checks every ignore rule
+func (c *Config) validateIgnoreRules() error {
+	for i, rule := range c.Ignore {
+		if err := rule.validate(fmt.Sprintf("ignore[%d]", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
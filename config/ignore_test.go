@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_Ignore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+gitlab:
+  token: glpat-test
+  url: https://gitlab.example.com
+ignore:
+  - name: parked until the freeze
+    labels: [on-hold]
+  - draft: true
+    title: "^(?i)wip"
+  - authors: [renovate-bot]
+    older_than: 720h
+    action: downgrade
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []IgnoreRule{
+		{Name: "parked until the freeze", Labels: []string{"on-hold"}},
+		{Draft: true, Title: "^(?i)wip"},
+		{Authors: []string{"renovate-bot"}, OlderThan: 720 * time.Hour, Action: IgnoreActionDowngrade},
+	}, cfg.Ignore)
+}
+
+func TestConfig_ValidateIgnoreRules(t *testing.T) {
+	gitLab := GitLabConfig{Token: "t", URL: "https://gitlab.com"}
+
+	tests := []struct {
+		name   string
+		rules  []IgnoreRule
+		errMsg string
+	}{
+		{name: "none"},
+		{name: "valid", rules: []IgnoreRule{{Labels: []string{"on-hold"}}, {Title: "^Draft:", Action: IgnoreActionSkip}, {Draft: true, Action: IgnoreActionDowngrade}}},
+		{name: "no condition", rules: []IgnoreRule{{Labels: []string{"on-hold"}}, {Name: "everything"}}, errMsg: "ignore[1] must set at least one of labels, draft, title, authors or older_than"},
+		{name: "empty label", rules: []IgnoreRule{{Labels: []string{""}}}, errMsg: "ignore[0].labels[0] must not be empty"},
+		{name: "empty author", rules: []IgnoreRule{{Authors: []string{"bot", ""}}}, errMsg: "ignore[0].authors[1] must not be empty"},
+		{name: "invalid title", rules: []IgnoreRule{{Title: "("}}, errMsg: "ignore[0].title is not a valid regular expression"},
+		{name: "negative age", rules: []IgnoreRule{{OlderThan: -time.Hour}}, errMsg: "ignore[0].older_than must not be negative"},
+		{name: "unknown action", rules: []IgnoreRule{{Draft: true, Action: "hide"}}, errMsg: `ignore[0].action must be skip or downgrade, got "hide"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{GitLab: gitLab, Ignore: tt.rules}
+			err := config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
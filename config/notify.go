@@ -0,0 +1,258 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// NotifyConfig holds the settings for the notifications sent after each scan
+type NotifyConfig struct {
+	Slack      SlackConfig      `yaml:"slack,omitempty"`
+	Teams      TeamsConfig      `yaml:"teams,omitempty"`
+	Mattermost MattermostConfig `yaml:"mattermost,omitempty"`
+	Webhooks   []WebhookConfig  `yaml:"webhooks,omitempty"`
+	Email      EmailConfig      `yaml:"email,omitempty"`
+}
+
+// NotifierSettings holds the delivery and filtering options shared by all notifiers
+type NotifierSettings struct {
+	Timeout       time.Duration `yaml:"timeout,omitempty"`       // Per-request timeout (default 10s)
+	Retries       int           `yaml:"retries,omitempty"`       // Extra attempts after a failed delivery
+	RetryBackoff  time.Duration `yaml:"retry_backoff,omitempty"` // Initial delay between attempts, doubled each retry (default 1s)
+	OnlyOnChanges bool          `yaml:"only_on_changes,omitempty"`
+	Filter        NotifyFilter  `yaml:"filter,omitempty"`
+}
+
+// NotifyFilter restricts which conflicts a notifier receives
+type NotifyFilter struct {
+	Projects     []string `yaml:"projects,omitempty"`      // Project paths with namespace, e.g. "group/project"
+	Namespaces   []string `yaml:"namespaces,omitempty"`    // Namespace paths, e.g. "group"
+	MinConflicts int      `yaml:"min_conflicts,omitempty"` // Skip the notification below this many conflicting MRs
+	MinSeverity  string   `yaml:"min_severity,omitempty"`  // Skip the notification unless a conflicting MR reaches this severity
+}
+
+// SlackConfig configures the Slack incoming webhook notifier
+type SlackConfig struct {
+	NotifierSettings `yaml:",inline"`
+	WebhookURL       string       `yaml:"webhook_url"`
+	Channel          string       `yaml:"channel,omitempty"`
+	TopN             int          `yaml:"top_n,omitempty"`
+	Routes           []SlackRoute `yaml:"routes,omitempty"`
+}
+
+// SlackRoute sends the conflicts of matching projects to a dedicated channel
+type SlackRoute struct {
+	Projects   []string `yaml:"projects,omitempty"`   // Project paths with namespace, e.g. "group/project"
+	Namespaces []string `yaml:"namespaces,omitempty"` // Namespace paths, e.g. "group"
+	Channel    string   `yaml:"channel,omitempty"`
+	WebhookURL string   `yaml:"webhook_url,omitempty"`
+}
+
+// TeamsConfig configures the Microsoft Teams notifier (Adaptive Cards via incoming webhook)
+type TeamsConfig struct {
+	NotifierSettings `yaml:",inline"`
+	WebhookURL       string `yaml:"webhook_url"`
+	TopN             int    `yaml:"top_n,omitempty"`
+}
+
+// MattermostConfig configures the Mattermost incoming webhook notifier
+type MattermostConfig struct {
+	NotifierSettings `yaml:",inline"`
+	WebhookURL       string `yaml:"webhook_url"`
+	Channel          string `yaml:"channel,omitempty"`
+	Username         string `yaml:"username,omitempty"`
+	IconURL          string `yaml:"icon_url,omitempty"`
+	TopN             int    `yaml:"top_n,omitempty"`
+}
+
+// WebhookConfig configures a generic webhook whose body is rendered from a Go template
+type WebhookConfig struct {
+	NotifierSettings `yaml:",inline"`
+	Name             string            `yaml:"name"`
+	URL              string            `yaml:"url"`
+	Method           string            `yaml:"method,omitempty"`       // Default POST
+	ContentType      string            `yaml:"content_type,omitempty"` // Default application/json
+	Headers          map[string]string `yaml:"headers,omitempty"`
+	Template         string            `yaml:"template,omitempty"`      // Inline Go template for the body
+	TemplateFile     string            `yaml:"template_file,omitempty"` // Path to a Go template file for the body
+}
+
+// EmailConfig configures the SMTP email digest notifier
+type EmailConfig struct {
+	NotifierSettings `yaml:",inline"`
+	Host             string           `yaml:"host"`
+	Port             int              `yaml:"port,omitempty"`     // Default 587 for starttls, 465 for smtps, 25 otherwise
+	Security         string           `yaml:"security,omitempty"` // starttls (default), smtps or none
+	Username         string           `yaml:"username,omitempty"`
+	Password         string           `yaml:"password,omitempty"`
+	From             string           `yaml:"from"`
+	Subject          string           `yaml:"subject,omitempty"`
+	TopN             int              `yaml:"top_n,omitempty"`
+	SendEmpty        bool             `yaml:"send_empty,omitempty"` // Send even when a recipient has no conflicts
+	Recipients       []EmailRecipient `yaml:"recipients"`
+}
+
+// EmailRecipient is a digest recipient, optionally limited to some namespaces or MR authors
+type EmailRecipient struct {
+	Address    string   `yaml:"address"`
+	Namespaces []string `yaml:"namespaces,omitempty"` // Namespace paths, e.g. "group"
+	Authors    []string `yaml:"authors,omitempty"`    // MR author usernames
+}
+
+// Email security modes
+const (
+	EmailSecurityStartTLS = "starttls"
+	EmailSecuritySMTPS    = "smtps"
+	EmailSecurityNone     = "none"
+)
+
+// Enabled returns true if email notifications are configured
+func (e EmailConfig) Enabled() bool {
+	return e.Host != ""
+}
+
+// Enabled returns true if Slack notifications are configured
+func (s SlackConfig) Enabled() bool {
+	return s.WebhookURL != ""
+}
+
+// Enabled returns true if Teams notifications are configured
+func (t TeamsConfig) Enabled() bool {
+	return t.WebhookURL != ""
+}
+
+// Enabled returns true if Mattermost notifications are configured
+func (m MattermostConfig) Enabled() bool {
+	return m.WebhookURL != ""
+}
+
+// Secrets returns the configured values that grant access on their own and must never be logged:
+// incoming webhook URLs, whose path is the credential, and the SMTP password
+func (n NotifyConfig) Secrets() []string {
+	secrets := []string{n.Slack.WebhookURL, n.Teams.WebhookURL, n.Mattermost.WebhookURL, n.Email.Password}
+	for _, route := range n.Slack.Routes {
+		secrets = append(secrets, route.WebhookURL)
+	}
+	for _, webhook := range n.Webhooks {
+		secrets = append(secrets, webhook.URL)
+	}
+	return secrets
+}
+
+// Validate checks all notifier settings
+func (n NotifyConfig) Validate() error {
+	if err := n.Slack.Validate(); err != nil {
+		return err
+	}
+	if err := n.Teams.NotifierSettings.validate("notify.teams"); err != nil {
+		return err
+	}
+	if err := n.Mattermost.NotifierSettings.validate("notify.mattermost"); err != nil {
+		return err
+	}
+
+	if err := n.Email.Validate(); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for i, webhook := range n.Webhooks {
+		prefix := fmt.Sprintf("notify.webhooks[%d]", i)
+		if webhook.Name == "" {
+			return fmt.Errorf("%s.name is required", prefix)
+		}
+		if names[webhook.Name] {
+			return fmt.Errorf("%s.name %q is used more than once", prefix, webhook.Name)
+		}
+		names[webhook.Name] = true
+		if webhook.URL == "" {
+			return fmt.Errorf("%s.url is required", prefix)
+		}
+		if webhook.Template != "" && webhook.TemplateFile != "" {
+			return fmt.Errorf("%s: template and template_file are mutually exclusive", prefix)
+		}
+		switch strings.ToUpper(webhook.Method) {
+		case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return fmt.Errorf("%s.method must be POST, PUT or PATCH", prefix)
+		}
+		if err := webhook.NotifierSettings.validate(prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the Slack notifier settings
+func (s SlackConfig) Validate() error {
+	if s.TopN < 0 {
+		return fmt.Errorf("notify.slack.top_n must not be negative")
+	}
+	if !s.Enabled() && len(s.Routes) > 0 {
+		return fmt.Errorf("notify.slack.webhook_url is required when routes are configured")
+	}
+	for i, route := range s.Routes {
+		if len(route.Projects) == 0 && len(route.Namespaces) == 0 {
+			return fmt.Errorf("notify.slack.routes[%d] must match at least one project or namespace", i)
+		}
+		if route.Channel == "" && route.WebhookURL == "" {
+			return fmt.Errorf("notify.slack.routes[%d] requires a channel or webhook_url", i)
+		}
+	}
+	return s.NotifierSettings.validate("notify.slack")
+}
+
+// Validate checks the email notifier settings
+func (e EmailConfig) Validate() error {
+	if !e.Enabled() {
+		return nil
+	}
+	if e.From == "" {
+		return fmt.Errorf("notify.email.from is required")
+	}
+	if len(e.Recipients) == 0 {
+		return fmt.Errorf("notify.email.recipients must not be empty")
+	}
+	for i, recipient := range e.Recipients {
+		if !strings.Contains(recipient.Address, "@") {
+			return fmt.Errorf("notify.email.recipients[%d].address %q is not a valid email address", i, recipient.Address)
+		}
+	}
+	switch e.Security {
+	case "", EmailSecurityStartTLS, EmailSecuritySMTPS, EmailSecurityNone:
+	default:
+		return fmt.Errorf("notify.email.security must be one of starttls, smtps or none")
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		return fmt.Errorf("notify.email.port must be between 1 and 65535")
+	}
+	if (e.Username == "") != (e.Password == "") {
+		return fmt.Errorf("notify.email.username and notify.email.password must be set together")
+	}
+	return e.NotifierSettings.validate("notify.email")
+}
+
+// validate checks the shared notifier settings
+func (s NotifierSettings) validate(prefix string) error {
+	if s.Timeout < 0 {
+		return fmt.Errorf("%s.timeout must not be negative", prefix)
+	}
+	if s.Retries < 0 {
+		return fmt.Errorf("%s.retries must not be negative", prefix)
+	}
+	if s.RetryBackoff < 0 {
+		return fmt.Errorf("%s.retry_backoff must not be negative", prefix)
+	}
+	if s.Filter.MinConflicts < 0 {
+		return fmt.Errorf("%s.filter.min_conflicts must not be negative", prefix)
+	}
+	if s.Filter.MinSeverity != "" && !validSeverityLevel(s.Filter.MinSeverity) {
+		return fmt.Errorf("%s.filter.min_severity must be one of %s, got %q", prefix, strings.Join(models.SeverityLevels, ", "), s.Filter.MinSeverity)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputConfig holds the report settings
+type OutputConfig struct {
+	Directory string       `yaml:"directory,omitempty"`
+	Filter    FilterConfig `yaml:"filter,omitempty"`
+}
+
+// FilterConfig selects the conflicting merge requests kept in the report; the others are left out
+// of the report, the notifications, the actions and the comparison with the previous report
+type FilterConfig struct {
+	ExcludeDrafts bool     `yaml:"exclude_drafts,omitempty"`
+	Labels        []string `yaml:"labels,omitempty"`         // Keep only merge requests carrying every one of these labels
+	ExcludeLabels []string `yaml:"exclude_labels,omitempty"` // Leave out merge requests carrying any of these labels
+	Assignees     []string `yaml:"assignees,omitempty"`      // Keep only merge requests assigned to one of these usernames
+	Milestones    []string `yaml:"milestones,omitempty"`     // Keep only merge requests planned for one of these milestone titles
+}
+
+// Enabled returns true if the filter leaves out any merge request
+func (f FilterConfig) Enabled() bool {
+	return f.ExcludeDrafts || len(f.Labels) > 0 || len(f.ExcludeLabels) > 0 || len(f.Assignees) > 0 || len(f.Milestones) > 0
+}
+
+// validate checks the report filter settings
+func (f FilterConfig) validate() error {
+	lists := []struct {
+		field  string
+		values []string
+	}{
+		{"labels", f.Labels},
+		{"exclude_labels", f.ExcludeLabels},
+		{"assignees", f.Assignees},
+		{"milestones", f.Milestones},
+	}
+	for _, list := range lists {
+		for i, value := range list.values {
+			if value == "" {
+				return fmt.Errorf("output.filter.%s[%d] must not be empty", list.field, i)
+			}
+		}
+	}
+
+	for _, label := range f.Labels {
+		for _, excluded := range f.ExcludeLabels {
+			if strings.EqualFold(label, excluded) {
+				return fmt.Errorf("output.filter: label %q is both required and excluded", label)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_OutputFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+gitlab:
+  token: glpat-test
+  url: https://gitlab.example.com
+output:
+  directory: ./reports
+  filter:
+    exclude_drafts: true
+    labels: [release]
+    exclude_labels: [wontfix]
+    assignees: [alice]
+    milestones: ["1.2"]
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "./reports", cfg.Output.Directory)
+	assert.Equal(t, FilterConfig{
+		ExcludeDrafts: true,
+		Labels:        []string{"release"},
+		ExcludeLabels: []string{"wontfix"},
+		Assignees:     []string{"alice"},
+		Milestones:    []string{"1.2"},
+	}, cfg.Output.Filter)
+	assert.True(t, cfg.Output.Filter.Enabled())
+}
+
+func TestFilterConfig_Enabled(t *testing.T) {
+	assert.False(t, FilterConfig{}.Enabled())
+	assert.True(t, FilterConfig{ExcludeDrafts: true}.Enabled())
+	assert.True(t, FilterConfig{ExcludeLabels: []string{"wontfix"}}.Enabled())
+}
+
+func TestFilterConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterConfig
+		errMsg string
+	}{
+		{name: "empty", filter: FilterConfig{}},
+		{name: "labels", filter: FilterConfig{Labels: []string{"release"}, ExcludeLabels: []string{"wontfix"}}},
+		{name: "empty label", filter: FilterConfig{Labels: []string{"release", ""}}, errMsg: "output.filter.labels[1] must not be empty"},
+		{name: "empty assignee", filter: FilterConfig{Assignees: []string{""}}, errMsg: "output.filter.assignees[0] must not be empty"},
+		{name: "required and excluded", filter: FilterConfig{Labels: []string{"Release"}, ExcludeLabels: []string{"release"}}, errMsg: `label "Release" is both required and excluded`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
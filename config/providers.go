@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Providers the checker can scan
+const (
+	ProviderGitLab    = "gitlab"
+	ProviderGitHub    = "github"
+	ProviderGitea     = "gitea"
+	ProviderBitbucket = "bitbucket"
+)
+
+// ProviderNames returns the providers to scan, in order: the providers list, else the single
+// provider, else GitLab
+func (c *Config) ProviderNames() []string {
+	switch {
+	case len(c.Providers) > 0:
+		return c.Providers
+	case c.Provider != "":
+		return []string{c.Provider}
+	default:
+		return []string{ProviderGitLab}
+	}
+}
+
+// Scans reports whether provider is one of the providers to scan
+func (c *Config) Scans(provider string) bool {
+	return slices.Contains(c.ProviderNames(), provider)
+}
+
+// validateProviders checks the provider selection and the settings of every selected provider
+func (c *Config) validateProviders() error {
+	if c.Provider != "" && len(c.Providers) > 0 {
+		return fmt.Errorf("provider and providers are mutually exclusive")
+	}
+
+	for i, name := range c.ProviderNames() {
+		field := "provider"
+		if len(c.Providers) > 0 {
+			field = fmt.Sprintf("providers[%d]", i)
+		}
+		if slices.Index(c.ProviderNames(), name) != i {
+			return fmt.Errorf("%s: %q is listed more than once", field, name)
+		}
+
+		var err error
+		switch name {
+		case ProviderGitLab:
+			err = c.GitLab.validate()
+		case ProviderGitHub:
+			err = c.GitHub.validate()
+		case ProviderGitea:
+			err = c.Gitea.validate()
+		case ProviderBitbucket:
+			err = c.Bitbucket.validate()
+		default:
+			return fmt.Errorf("%s must be one of %q, %q, %q or %q, got %q", field, ProviderGitLab, ProviderGitHub, ProviderGitea, ProviderBitbucket, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.ProviderNames()) > 1 || !c.Scans(ProviderGitLab) {
+		return c.Actions.validateGitLabOnlyActions()
+	}
+	return nil
+}
+
+// validateGitLabOnlyActions rejects the actions only a GitLab-only scan supports; comments work on
+// every provider
+func (a ActionsConfig) validateGitLabOnlyActions() error {
+	switch {
+	case a.Label.Enabled:
+		return fmt.Errorf("actions.label is only supported with the gitlab provider")
+	case a.BackMerge.Enabled:
+		return fmt.Errorf("actions.back_merge is only supported with the gitlab provider")
+	case a.Rebase.Enabled:
+		return fmt.Errorf("actions.rebase is only supported with the gitlab provider")
+	}
+	return nil
+}
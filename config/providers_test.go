@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_Providers(t *testing.T) {
+	t.Setenv(GiteaTokenEnv, "")
+	t.Setenv(BitbucketTokenEnv, "")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+providers: [gitlab, gitea, bitbucket]
+gitlab:
+  token: glpat-test
+  url: https://gitlab.example.com
+gitea:
+  token: gitea-test
+  url: https://gitea.example.com
+  organizations: [platform]
+bitbucket:
+  token: bb-test
+  url: https://bitbucket.example.com
+  projects: [PAY]
+  timeout: 10s
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{ProviderGitLab, ProviderGitea, ProviderBitbucket}, cfg.ProviderNames())
+	assert.True(t, cfg.Scans(ProviderGitea))
+	assert.False(t, cfg.Scans(ProviderGitHub))
+	assert.Equal(t, "https://gitea.example.com", cfg.Gitea.URL)
+	assert.Equal(t, []string{"platform"}, cfg.Gitea.Organizations)
+	assert.Equal(t, []string{"PAY"}, cfg.Bitbucket.Projects)
+	assert.Equal(t, "bb-test", cfg.Bitbucket.AccessToken())
+}
+
+func TestConfig_ProviderNames(t *testing.T) {
+	assert.Equal(t, []string{ProviderGitLab}, (&Config{}).ProviderNames())
+	assert.Equal(t, []string{ProviderGitea}, (&Config{Provider: ProviderGitea}).ProviderNames())
+	assert.Equal(t, []string{ProviderGitHub, ProviderGitLab}, (&Config{Providers: []string{ProviderGitHub, ProviderGitLab}}).ProviderNames())
+}
+
+func TestGiteaAndBitbucketConfig_AccessTokenFromEnvironment(t *testing.T) {
+	t.Setenv(GiteaTokenEnv, "gitea-from-env")
+	t.Setenv(BitbucketTokenEnv, "bb-from-env")
+
+	assert.Equal(t, "gitea-from-env", GiteaConfig{}.AccessToken())
+	assert.Equal(t, "gitea-configured", GiteaConfig{Token: "gitea-configured"}.AccessToken())
+	assert.Equal(t, "bb-from-env", BitbucketConfig{}.AccessToken())
+	assert.Equal(t, "bb-configured", BitbucketConfig{Token: "bb-configured"}.AccessToken())
+}
+
+func TestConfig_ValidateProviders(t *testing.T) {
+	t.Setenv(GiteaTokenEnv, "")
+	t.Setenv(BitbucketTokenEnv, "")
+
+	gitLab := GitLabConfig{Token: "t", URL: "https://gitlab.com"}
+	gitea := GiteaConfig{Token: "t", URL: "https://gitea.example.com"}
+	bitbucket := BitbucketConfig{Token: "t", URL: "https://bitbucket.example.com"}
+
+	tests := []struct {
+		name   string
+		config Config
+		errMsg string
+	}{
+		{name: "gitea", config: Config{Provider: ProviderGitea, Gitea: gitea}},
+		{name: "bitbucket", config: Config{Provider: ProviderBitbucket, Bitbucket: bitbucket}},
+		{name: "combined", config: Config{Providers: []string{ProviderGitLab, ProviderGitea, ProviderBitbucket}, GitLab: gitLab, Gitea: gitea, Bitbucket: bitbucket}},
+		{name: "combined comments", config: Config{Providers: []string{ProviderGitLab, ProviderGitea}, GitLab: gitLab, Gitea: gitea, Actions: ActionsConfig{Comment: CommentConfig{Enabled: true}}}},
+		{name: "provider and providers", config: Config{Provider: ProviderGitLab, Providers: []string{ProviderGitea}}, errMsg: "provider and providers are mutually exclusive"},
+		{name: "unknown listed provider", config: Config{Providers: []string{ProviderGitLab, "svn"}, GitLab: gitLab}, errMsg: `providers[1] must be one of "gitlab", "github", "gitea" or "bitbucket", got "svn"`},
+		{name: "duplicate provider", config: Config{Providers: []string{ProviderGitea, ProviderGitea}, Gitea: gitea}, errMsg: `providers[1]: "gitea" is listed more than once`},
+		{name: "listed provider settings", config: Config{Providers: []string{ProviderGitLab, ProviderBitbucket}, GitLab: gitLab}, errMsg: "bitbucket.token or the BITBUCKET_TOKEN environment variable is required"},
+		{name: "gitea without token", config: Config{Provider: ProviderGitea, Gitea: GiteaConfig{URL: gitea.URL}}, errMsg: "gitea.token or the GITEA_TOKEN environment variable is required"},
+		{name: "gitea without URL", config: Config{Provider: ProviderGitea, Gitea: GiteaConfig{Token: "t"}}, errMsg: "gitea.url must be an absolute URL"},
+		{name: "gitea negative timeout", config: Config{Provider: ProviderGitea, Gitea: GiteaConfig{Token: "t", URL: gitea.URL, Timeout: -1}}, errMsg: "gitea.timeout must not be negative"},
+		{name: "bitbucket without URL", config: Config{Provider: ProviderBitbucket, Bitbucket: BitbucketConfig{Token: "t", URL: "bitbucket"}}, errMsg: "bitbucket.url must be an absolute URL"},
+		{name: "bitbucket negative timeout", config: Config{Provider: ProviderBitbucket, Bitbucket: BitbucketConfig{Token: "t", URL: bitbucket.URL, Timeout: -1}}, errMsg: "bitbucket.timeout must not be negative"},
+		{name: "combined label action", config: Config{Providers: []string{ProviderGitLab, ProviderGitea}, GitLab: gitLab, Gitea: gitea, Actions: ActionsConfig{Label: LabelConfig{Enabled: true}}}, errMsg: "actions.label is only supported with the gitlab provider"},
+		{name: "bitbucket rebase action", config: Config{Provider: ProviderBitbucket, Bitbucket: bitbucket, Actions: ActionsConfig{Rebase: RebaseConfig{Enabled: true}}}, errMsg: "actions.rebase is only supported with the gitlab provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// SeverityConfig enables the severity scores of conflicting merge requests and the SLAs on how
+// long they may stay in conflict
+type SeverityConfig struct {
+	Enabled    bool           `yaml:"enabled"`
+	SLA        time.Duration  `yaml:"sla,omitempty"` // Time in conflict allowed outside the namespaces below; 0 for no SLA
+	Namespaces []NamespaceSLA `yaml:"namespaces,omitempty"`
+}
+
+// NamespaceSLA is the time in conflict allowed in a namespace and its subgroups
+type NamespaceSLA struct {
+	Namespace string        `yaml:"namespace"` // Namespace path, e.g. "group/subgroup"
+	SLA       time.Duration `yaml:"sla"`
+}
+
+// SLAFor returns the SLA of the most specific namespace containing the project path, or the
+// default SLA when no namespace does
+func (s SeverityConfig) SLAFor(projectPath string) time.Duration {
+	sla := s.SLA
+	longest := -1
+	for _, ns := range s.Namespaces {
+		namespace := strings.Trim(ns.Namespace, "/")
+		if len(namespace) > longest && strings.HasPrefix(strings.ToLower(projectPath), strings.ToLower(namespace)+"/") {
+			sla = ns.SLA
+			longest = len(namespace)
+		}
+	}
+	return sla
+}
+
+// validate checks the severity settings
+func (s SeverityConfig) validate() error {
+	if s.SLA < 0 {
+		return fmt.Errorf("severity.sla must not be negative")
+	}
+	for i, ns := range s.Namespaces {
+		if strings.Trim(ns.Namespace, "/") == "" {
+			return fmt.Errorf("severity.namespaces[%d].namespace is required", i)
+		}
+		if ns.SLA <= 0 {
+			return fmt.Errorf("severity.namespaces[%d].sla must be positive", i)
+		}
+	}
+	return nil
+}
+
+// validateSeverity checks the severity settings and that notifiers only key off severities when they are scored
+func (c *Config) validateSeverity() error {
+	if err := c.Severity.validate(); err != nil {
+		return err
+	}
+
+	type notifier struct {
+		prefix   string
+		settings NotifierSettings
+	}
+	notifiers := []notifier{
+		{"notify.slack", c.Notify.Slack.NotifierSettings},
+		{"notify.teams", c.Notify.Teams.NotifierSettings},
+		{"notify.mattermost", c.Notify.Mattermost.NotifierSettings},
+		{"notify.email", c.Notify.Email.NotifierSettings},
+	}
+	for i, webhook := range c.Notify.Webhooks {
+		notifiers = append(notifiers, notifier{fmt.Sprintf("notify.webhooks[%d]", i), webhook.NotifierSettings})
+	}
+	for _, n := range notifiers {
+		if n.settings.Filter.MinSeverity != "" && !c.Severity.Enabled {
+			return fmt.Errorf("%s.filter.min_severity requires severity.enabled", n.prefix)
+		}
+	}
+	return nil
+}
+
+// validSeverityLevel reports whether level is one of the severity levels
+func validSeverityLevel(level string) bool {
+	return models.SeverityRank(level) >= 0
+}
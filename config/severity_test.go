@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_Severity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+gitlab:
+  token: glpat-test
+  url: https://gitlab.example.com
+severity:
+  enabled: true
+  sla: 168h
+  namespaces:
+    - namespace: payments
+      sla: 48h
+notify:
+  slack:
+    webhook_url: https://hooks.slack.com/services/T/B/X
+    filter:
+      min_severity: high
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, SeverityConfig{
+		Enabled:    true,
+		SLA:        168 * time.Hour,
+		Namespaces: []NamespaceSLA{{Namespace: "payments", SLA: 48 * time.Hour}},
+	}, cfg.Severity)
+	assert.Equal(t, "high", cfg.Notify.Slack.Filter.MinSeverity)
+}
+
+func TestSeverityConfig_SLAFor(t *testing.T) {
+	cfg := SeverityConfig{
+		SLA: 168 * time.Hour,
+		Namespaces: []NamespaceSLA{
+			{Namespace: "payments", SLA: 48 * time.Hour},
+			{Namespace: "/payments/core/", SLA: 24 * time.Hour},
+		},
+	}
+
+	assert.Equal(t, 168*time.Hour, cfg.SLAFor("web/app"))
+	assert.Equal(t, 48*time.Hour, cfg.SLAFor("Payments/app"))
+	assert.Equal(t, 24*time.Hour, cfg.SLAFor("payments/core/ledger"))
+	assert.Equal(t, 168*time.Hour, cfg.SLAFor("payments-legacy/app"))
+	assert.Equal(t, time.Duration(0), SeverityConfig{}.SLAFor("web/app"))
+}
+
+func TestConfig_ValidateSeverity(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		errMsg string
+	}{
+		{name: "disabled", cfg: Config{}},
+		{name: "valid", cfg: Config{Severity: SeverityConfig{Enabled: true, SLA: time.Hour, Namespaces: []NamespaceSLA{{Namespace: "payments", SLA: time.Hour}}}}},
+		{name: "negative sla", cfg: Config{Severity: SeverityConfig{SLA: -time.Hour}}, errMsg: "severity.sla must not be negative"},
+		{name: "no namespace", cfg: Config{Severity: SeverityConfig{Namespaces: []NamespaceSLA{{SLA: time.Hour}}}}, errMsg: "severity.namespaces[0].namespace is required"},
+		{name: "no namespace sla", cfg: Config{Severity: SeverityConfig{Namespaces: []NamespaceSLA{{Namespace: "payments"}}}}, errMsg: "severity.namespaces[0].sla must be positive"},
+		{
+			name:   "min severity without scores",
+			cfg:    Config{Notify: NotifyConfig{Webhooks: []WebhookConfig{{NotifierSettings: NotifierSettings{Filter: NotifyFilter{MinSeverity: "high"}}}}}},
+			errMsg: "notify.webhooks[0].filter.min_severity requires severity.enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateSeverity()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestNotifierSettings_ValidateMinSeverity(t *testing.T) {
+	assert.NoError(t, NotifierSettings{Filter: NotifyFilter{MinSeverity: "critical"}}.validate("notify.teams"))
+	assert.ErrorContains(t, NotifierSettings{Filter: NotifyFilter{MinSeverity: "urgent"}}.validate("notify.teams"),
+		`notify.teams.filter.min_severity must be one of low, medium, high, critical, got "urgent"`)
+}
@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"sort"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// Compare determines which conflicting merge requests are new, resolved or still outstanding
+// between the previous and current report. First-seen times are carried forward from the
+// previous report's own diff so that persistence accumulates across runs. Conflicts the current
+// report leaves out are held: they are neither new nor resolved and keep their first-seen time.
+func Compare(previous, current *models.Report) *models.ReportDiff {
+	now := reportTime(current, time.Now().UTC())
+
+	result := &models.ReportDiff{
+		CurrentTimestamp: current.Timestamp,
+		New:              []models.ConflictChange{},
+		Resolved:         []models.ConflictChange{},
+		Persisting:       []models.ConflictChange{},
+	}
+
+	// Without a previous report every conflict is new
+	if previous == nil {
+		for _, entry := range current.ConflictingEntries() {
+			entry.FirstSeen = now
+			entry.LastSeen = now
+			result.New = append(result.New, entry)
+		}
+		for _, entry := range current.HeldEntries() {
+			entry.FirstSeen = now
+			entry.LastSeen = now
+			result.Held = append(result.Held, entry)
+		}
+		sortChanges(result.New)
+		sortChanges(result.Held)
+		return result
+	}
+
+	result.PreviousTimestamp = previous.Timestamp
+	previousTime := reportTime(previous, now)
+
+	// Index previous conflicts for lookup
+	previousEntries := make(map[string]models.ConflictChange)
+	for _, entry := range previous.ConflictingEntries() {
+		entry.FirstSeen = firstSeen(previous, entry.Key(), previousTime)
+		previousEntries[entry.Key()] = entry
+	}
+
+	// Classify current conflicts as new or persisting
+	currentKeys := make(map[string]bool)
+	for _, entry := range current.ConflictingEntries() {
+		key := entry.Key()
+		currentKeys[key] = true
+		entry.LastSeen = now
+
+		if prev, exists := previousEntries[key]; exists {
+			entry.FirstSeen = prev.FirstSeen
+			result.Persisting = append(result.Persisting, entry)
+		} else {
+			// Held in the previous report, or really new
+			entry.FirstSeen = firstSeen(previous, key, now)
+			result.New = append(result.New, entry)
+		}
+	}
+
+	for _, entry := range current.HeldEntries() {
+		key := entry.Key()
+		currentKeys[key] = true
+		entry.LastSeen = now
+
+		if prev, exists := previousEntries[key]; exists {
+			entry.FirstSeen = prev.FirstSeen
+		} else {
+			entry.FirstSeen = firstSeen(previous, key, now)
+		}
+		result.Held = append(result.Held, entry)
+	}
+
+	// Anything conflicting before but not anymore is resolved
+	for key, entry := range previousEntries {
+		if !currentKeys[key] {
+			entry.LastSeen = now
+			result.Resolved = append(result.Resolved, entry)
+		}
+	}
+
+	sortChanges(result.New)
+	sortChanges(result.Resolved)
+	sortChanges(result.Persisting)
+	sortChanges(result.Held)
+
+	return result
+}
+
+// firstSeen looks up when a conflict was first observed, defaulting to the report time
+func firstSeen(report *models.Report, key string, fallback time.Time) time.Time {
+	if seen, ok := report.Changes.FirstSeen(key); ok && !seen.IsZero() {
+		return seen
+	}
+	return fallback
+}
+
+// reportTime parses the report timestamp, returning fallback when it is missing or invalid
+func reportTime(report *models.Report, fallback time.Time) time.Time {
+	if report == nil || report.Timestamp == "" {
+		return fallback
+	}
+	t, err := models.ParseReportTimestamp(report.Timestamp)
+	if err != nil {
+		return fallback
+	}
+	return t
+}
+
+// sortChanges orders changes by how long they have persisted (longest first), then by repository and MR
+func sortChanges(changes []models.ConflictChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].FirstSeen.Equal(changes[j].FirstSeen) {
+			if changes[i].RepositoryName == changes[j].RepositoryName {
+				return changes[i].MergeRequest.ID < changes[j].MergeRequest.ID
+			}
+			return changes[i].RepositoryName < changes[j].RepositoryName
+		}
+		return changes[i].FirstSeen.Before(changes[j].FirstSeen)
+	})
+}
@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// buildTestReport creates a report where each repository ID maps to its conflicting MR IDs
+func buildTestReport(timestamp string, conflicts map[int][]int) *models.Report {
+	report := &models.Report{Timestamp: timestamp}
+	for repoID := 1; repoID <= 10; repoID++ {
+		mrIDs, ok := conflicts[repoID]
+		if !ok {
+			continue
+		}
+		repo := models.Repository{ID: repoID, Name: "repo", WebURL: "https://gitlab.example.com/repo"}
+		var mrs []models.MergeRequest
+		for _, id := range mrIDs {
+			mrs = append(mrs, models.MergeRequest{ID: id, Title: "MR", SourceBranch: "release", TargetBranch: "master", HasConflicts: true})
+		}
+		report.AddRepository(repo, mrs, models.StatusConflicts, "")
+	}
+	return report
+}
+
+func TestCompare_NoPreviousReport(t *testing.T) {
+	current := buildTestReport("2024-01-02T00-00-00", map[int][]int{1: {1, 2}})
+
+	result := Compare(nil, current)
+
+	assert.Len(t, result.New, 2)
+	assert.Empty(t, result.Resolved)
+	assert.Empty(t, result.Persisting)
+	assert.Empty(t, result.PreviousTimestamp)
+}
+
+func TestCompare_ClassifiesChanges(t *testing.T) {
+	previous := buildTestReport("2024-01-01T00-00-00", map[int][]int{1: {1, 2}, 2: {7}})
+	current := buildTestReport("2024-01-02T06-00-00", map[int][]int{1: {2, 3}})
+
+	result := Compare(previous, current)
+
+	require.Len(t, result.New, 1)
+	assert.Equal(t, 3, result.New[0].MergeRequest.ID)
+	assert.Equal(t, time.Duration(0), result.New[0].Persisted())
+
+	require.Len(t, result.Persisting, 1)
+	assert.Equal(t, 2, result.Persisting[0].MergeRequest.ID)
+	assert.Equal(t, 30*time.Hour, result.Persisting[0].Persisted())
+
+	require.Len(t, result.Resolved, 2)
+	assert.Equal(t, "2024-01-01T00-00-00", result.PreviousTimestamp)
+	assert.True(t, result.HasChanges())
+}
+
+func TestCompare_CarriesFirstSeenForward(t *testing.T) {
+	first := buildTestReport("2024-01-01T00-00-00", map[int][]int{1: {1}})
+	second := buildTestReport("2024-01-02T00-00-00", map[int][]int{1: {1}})
+	second.Changes = Compare(first, second)
+	third := buildTestReport("2024-01-03T00-00-00", map[int][]int{1: {1}})
+
+	result := Compare(second, third)
+
+	require.Len(t, result.Persisting, 1)
+	assert.Equal(t, 48*time.Hour, result.Persisting[0].Persisted())
+	assert.False(t, result.HasChanges())
+}
+
+func TestCompare_HoldsFilteredConflicts(t *testing.T) {
+	first := buildTestReport("2024-01-01T00-00-00", map[int][]int{1: {1, 2}})
+	first.Changes = Compare(nil, first)
+
+	// MR 1 is filtered out of the second report; it is neither resolved nor forgotten
+	second := buildTestReport("2024-01-02T00-00-00", map[int][]int{1: {2}})
+	second.Repositories[0].FilteredOutMRs = []models.MergeRequest{{ID: 1, HasConflicts: true}}
+	second.Changes = Compare(first, second)
+
+	assert.Empty(t, second.Changes.Resolved)
+	assert.Empty(t, second.Changes.New)
+	require.Len(t, second.Changes.Held, 1)
+	assert.Equal(t, 1, second.Changes.Held[0].MergeRequest.ID)
+	assert.Equal(t, 24*time.Hour, second.Changes.Held[0].Persisted())
+	assert.False(t, second.Changes.HasChanges())
+
+	// Back in the third report, it is new to the report but keeps its first-seen time
+	third := buildTestReport("2024-01-03T00-00-00", map[int][]int{1: {1, 2}})
+	result := Compare(second, third)
+
+	require.Len(t, result.New, 1)
+	assert.Equal(t, 48*time.Hour, result.New[0].Persisted())
+	assert.Empty(t, result.Held)
+}
+
+func TestCompare_HoldsSuppressedConflicts(t *testing.T) {
+	first := buildTestReport("2024-01-01T00-00-00", map[int][]int{1: {1, 2}})
+	first.Changes = Compare(nil, first)
+
+	// An ignore rule suppresses MR 1 in the second report; it is neither resolved nor forgotten
+	second := buildTestReport("2024-01-02T00-00-00", map[int][]int{1: {2}})
+	second.Repositories[0].SuppressedMRs = []models.SuppressedMR{{MergeRequest: models.MergeRequest{ID: 1, HasConflicts: true}, Reason: "parked"}}
+	second.Changes = Compare(first, second)
+
+	assert.Empty(t, second.Changes.Resolved)
+	require.Len(t, second.Changes.Held, 1)
+	assert.Equal(t, 1, second.Changes.Held[0].MergeRequest.ID)
+	assert.Equal(t, 24*time.Hour, second.Changes.Held[0].Persisted())
+
+	// Once the rule no longer matches, it keeps its first-seen time
+	third := buildTestReport("2024-01-03T00-00-00", map[int][]int{1: {1, 2}})
+	result := Compare(second, third)
+
+	require.Len(t, result.New, 1)
+	assert.Equal(t, 48*time.Hour, result.New[0].Persisted())
+}
+
+// Every conflict in either report must be classified exactly once
+func TestProperty_CompareClassifiesEveryConflict(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("new+persisting equals current and resolved+persisting equals previous", prop.ForAll(
+		func(previousIDs, currentIDs []int) bool {
+			previous := buildTestReport("2024-01-01T00-00-00", map[int][]int{1: unique(previousIDs)})
+			current := buildTestReport("2024-01-02T00-00-00", map[int][]int{1: unique(currentIDs)})
+
+			result := Compare(previous, current)
+
+			return len(result.New)+len(result.Persisting) == current.TotalConflictingMRs &&
+				len(result.Resolved)+len(result.Persisting) == previous.TotalConflictingMRs
+		},
+		gen.SliceOf(gen.IntRange(1, 20)),
+		gen.SliceOf(gen.IntRange(1, 20)),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+// unique removes duplicate IDs while preserving order
+func unique(ids []int) []int {
+	seen := make(map[int]bool)
+	var result []int
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
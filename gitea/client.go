@@ -0,0 +1,131 @@
+// Package gitea is a client for the parts of the Gitea and Forgejo REST API the checker uses
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// DefaultTimeout is the per-request timeout used when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultPageSize is the page size requested from list endpoints, Gitea's default maximum
+const DefaultPageSize = 50
+
+// apiPath is the path of the API below the instance URL
+const apiPath = "/api/v1"
+
+// Client represents a Gitea API client
+type Client struct {
+	api        *httpapi.Client
+	httpClient *http.Client
+	readOnly   bool
+
+	// Gitea calls a pull request unmergeable while it checks it for conflicts too, so such pull
+	// requests are fetched again before counting as conflicting
+	conflictCheckAttempts int
+	conflictCheckDelay    time.Duration
+
+	mu        sync.Mutex
+	fullNames map[int]string
+}
+
+// Option configures optional Client behaviour
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests through httpClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithReadOnly makes the client refuse every request other than GET and HEAD at the transport
+// layer; the refused writes are logged and available from SkippedWrites
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// WithConflictCheckRetry sets how many times a pull request Gitea calls unmergeable is fetched
+// again to confirm the conflict, and the delay before each attempt
+func WithConflictCheckRetry(attempts int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.conflictCheckAttempts = max(attempts, 1)
+		c.conflictCheckDelay = delay
+	}
+}
+
+// NewClient creates a new Gitea API client; baseURL is the instance URL, without /api/v1
+func NewClient(baseURL, token string, opts ...Option) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		fullNames:  make(map[int]string),
+
+		conflictCheckAttempts: 2,
+		conflictCheckDelay:    time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.api = httpapi.NewClient(httpapi.Config{
+		Service:    "Gitea",
+		BaseURL:    strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), apiPath) + apiPath,
+		HTTPClient: client.httpClient,
+		ReadOnly:   client.readOnly,
+		SetHeaders: func(req *http.Request) error {
+			req.Header.Set("Authorization", "token "+token)
+			req.Header.Set("Accept", "application/json")
+			return nil
+		},
+		UserEndpoint: "/user",
+	})
+
+	return client
+}
+
+// ReadOnly reports whether the client refuses write requests
+func (c *Client) ReadOnly() bool {
+	return c.api.ReadOnly()
+}
+
+// SkippedWrites returns the write requests refused so far in read-only mode
+func (c *Client) SkippedWrites() []models.SkippedWrite {
+	return c.api.SkippedWrites()
+}
+
+// TestConnection verifies that the token is accepted by Gitea
+func (c *Client) TestConnection(ctx context.Context) error {
+	return c.api.TestConnection(ctx)
+}
+
+// paginate fetches every page of a Gitea list endpoint, until the total count or a short page
+func paginate[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	endpoint = httpapi.SetQueryValue(endpoint, "limit", strconv.Itoa(DefaultPageSize))
+
+	page, fetched := 1, 0
+	return httpapi.PaginateJSON[T](ctx, c.api, httpapi.SetQueryValue(endpoint, "page", "1"), func(header http.Header, endpoint string, count int) string {
+		fetched += count
+
+		// Gitea caps limit at its MAX_RESPONSE_ITEMS setting, so the total decides when to stop
+		if total, err := strconv.Atoi(header.Get("X-Total-Count")); err == nil {
+			if fetched >= total || count == 0 {
+				return ""
+			}
+		} else if count < DefaultPageSize {
+			return ""
+		}
+		page++
+		return httpapi.SetQueryValue(endpoint, "page", strconv.Itoa(page))
+	})
+}
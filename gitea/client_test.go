@@ -0,0 +1,118 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/giteafake"
+)
+
+// newFakeClient starts a fake Gitea server and returns a client for it
+func newFakeClient(t *testing.T, opts ...Option) (*giteafake.Server, *Client) {
+	t.Helper()
+	server := giteafake.NewServer()
+	t.Cleanup(server.Close)
+	opts = append([]Option{WithConflictCheckRetry(2, 0)}, opts...)
+	return server, NewClient(server.URL(), giteafake.DefaultToken, opts...)
+}
+
+func TestNewClient_TrimsAPIPath(t *testing.T) {
+	assert.Equal(t, "https://gitea.example.com/api/v1", NewClient("https://gitea.example.com/", "token").api.BaseURL())
+	assert.Equal(t, "https://gitea.example.com/api/v1", NewClient("https://gitea.example.com/api/v1", "token").api.BaseURL())
+	assert.Equal(t, "https://gitea.example.com/api/v1", NewClient("https://gitea.example.com/api/v1/", "token").api.BaseURL())
+}
+
+func TestClient_TestConnection(t *testing.T) {
+	server, client := newFakeClient(t)
+
+	require.NoError(t, client.TestConnection(context.Background()))
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/api/v1/user", requests[0].Path)
+	assert.Equal(t, "token "+giteafake.DefaultToken, requests[0].Header.Get("Authorization"))
+}
+
+func TestClient_TestConnection_InvalidToken(t *testing.T) {
+	server := giteafake.NewServer()
+	defer server.Close()
+
+	err := NewClient(server.URL(), "wrong-token").TestConnection(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to Gitea: authentication failed: invalid token")
+}
+
+func TestClient_APIErrorMessage(t *testing.T) {
+	_, client := newFakeClient(t)
+
+	_, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{Organizations: []string{"missing"}})
+
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+	assert.Contains(t, err.Error(), "API error 404: The target couldn't be found.")
+}
+
+func TestClient_PaginatesWithTotalCount(t *testing.T) {
+	server, client := newFakeClient(t)
+	repos := make([]models.Repository, 120)
+	for i := range repos {
+		repos[i] = models.Repository{ID: i + 1, Name: "repo", Namespace: models.Namespace{Path: "acme"}}
+	}
+	server.SetRepositories(repos)
+	// Smaller than DefaultPageSize, like a lowered MAX_RESPONSE_ITEMS
+	server.SetMaxPageSize(20)
+
+	result, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{})
+
+	require.NoError(t, err)
+	assert.Len(t, result, 120)
+	assert.Len(t, server.Requests(), 6)
+}
+
+func TestClient_PaginatesUntilShortPage(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		if r.URL.Query().Get("page") == "1" {
+			w.Write([]byte("[" + repeat(`{"id":1}`, DefaultPageSize) + "]"))
+			return
+		}
+		w.Write([]byte(`[{"id":2}]`))
+	}))
+	defer server.Close()
+
+	repos, err := paginate[repository](context.Background(), NewClient(server.URL, "token"), "/user/repos")
+
+	require.NoError(t, err)
+	assert.Len(t, repos, DefaultPageSize+1)
+	assert.Equal(t, 2, pages)
+}
+
+func TestClient_ReadOnlySkipsWrites(t *testing.T) {
+	server, client := newFakeClient(t, WithReadOnly())
+	assert.True(t, client.ReadOnly())
+
+	resp, err := client.api.Do(context.Background(), http.MethodPost, "/repos/acme/app/issues/1/comments?x=1", nil)
+
+	assert.Nil(t, resp)
+	assert.True(t, httpapi.IsReadOnly(err))
+	assert.Empty(t, server.Requests())
+	assert.Equal(t, []models.SkippedWrite{{Method: http.MethodPost, Path: "/api/v1/repos/acme/app/issues/1/comments", Description: "POST /api/v1/repos/acme/app/issues/1/comments"}}, client.SkippedWrites())
+}
+
+// repeat joins n copies of item with commas
+func repeat(item string, n int) string {
+	result := item
+	for i := 1; i < n; i++ {
+		result += "," + item
+	}
+	return result
+}
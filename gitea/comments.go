@@ -0,0 +1,120 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// comment is an issue comment as returned by the Gitea API; pull request conversations are issue comments
+type comment struct {
+	ID   int    `json:"id"`
+	Body string `json:"body"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// model converts the comment to the shared note model
+func (c comment) model() models.Note {
+	return models.Note{
+		ID:        c.ID,
+		Body:      c.Body,
+		Author:    models.Author{Name: c.User.Login, Username: c.User.Login},
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// commentRequest is the body of comment create and update requests
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+// ListPullRequestComments retrieves all conversation comments on a pull request
+func (c *Client) ListPullRequestComments(ctx context.Context, repoID, number int) ([]models.Note, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gitea returns every comment of an issue at once
+	var comments []comment
+	if err := c.api.Get(ctx, fmt.Sprintf("/repos/%s/issues/%d/comments", fullName, number), &comments); err != nil {
+		return nil, fmt.Errorf("failed to list comments of pull request %d for repository %s: %w", number, fullName, err)
+	}
+
+	notes := make([]models.Note, len(comments))
+	for i, comment := range comments {
+		notes[i] = comment.model()
+	}
+	return notes, nil
+}
+
+// CreatePullRequestComment adds a conversation comment to a pull request
+func (c *Client) CreatePullRequestComment(ctx context.Context, repoID, number int, body string) (*models.Note, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/issues/%d/comments", fullName, number)
+	description := fmt.Sprintf("Comment on PR #%d in %s", number, fullName)
+	resp, err := c.api.Write(ctx, http.MethodPost, endpoint, commentRequest{Body: body}, description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment on pull request %d for repository %s: %w", number, fullName, err)
+	}
+	return decodeComment(resp)
+}
+
+// UpdatePullRequestComment replaces the body of an existing pull request comment
+func (c *Client) UpdatePullRequestComment(ctx context.Context, repoID, number, commentID int, body string) (*models.Note, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/issues/comments/%d", fullName, commentID)
+	description := fmt.Sprintf("Update comment on PR #%d in %s", number, fullName)
+	resp, err := c.api.Write(ctx, http.MethodPatch, endpoint, commentRequest{Body: body}, description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment %d on pull request %d for repository %s: %w", commentID, number, fullName, err)
+	}
+	return decodeComment(resp)
+}
+
+// DeletePullRequestComment removes a comment from a pull request
+func (c *Client) DeletePullRequestComment(ctx context.Context, repoID, number, commentID int) error {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/issues/comments/%d", fullName, commentID)
+	description := fmt.Sprintf("Delete comment on PR #%d in %s", number, fullName)
+	resp, err := c.api.Write(ctx, http.MethodDelete, endpoint, nil, description)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment %d on pull request %d for repository %s: %w", commentID, number, fullName, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// decodeComment decodes a comment response into a note
+func decodeComment(resp *http.Response) (*models.Note, error) {
+	defer resp.Body.Close()
+
+	var result comment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode comment response: %w", err)
+	}
+	note := result.model()
+	return &note, nil
+}
@@ -0,0 +1,71 @@
+package gitea
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/giteafake"
+)
+
+func TestClient_PullRequestComments(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []giteafake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	ctx := context.Background()
+
+	note, err := client.CreatePullRequestComment(ctx, 1, 7, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", note.Body)
+	assert.Equal(t, "gitea-bot", note.Author.Username)
+
+	notes, err := client.ListPullRequestComments(ctx, 1, 7)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, note.ID, notes[0].ID)
+
+	updated, err := client.UpdatePullRequestComment(ctx, 1, 7, note.ID, "edited")
+	require.NoError(t, err)
+	assert.Equal(t, "edited", updated.Body)
+	assert.Equal(t, "edited", server.Comments(1, 7)[0].Body)
+
+	require.NoError(t, client.DeletePullRequestComment(ctx, 1, 7, note.ID))
+	assert.Empty(t, server.Comments(1, 7))
+}
+
+func TestClient_PullRequestComments_Errors(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []giteafake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	ctx := context.Background()
+
+	_, err := client.CreatePullRequestComment(ctx, 1, 8, "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create comment on pull request 8 for repository acme/app")
+	assert.True(t, httpapi.IsNotFound(err))
+
+	err = client.DeletePullRequestComment(ctx, 1, 7, 99)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestClient_ReadOnlySkipsCommentChanges(t *testing.T) {
+	server, client := newFakeClient(t, WithReadOnly())
+	setUpPulls(server, []giteafake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	ctx := context.Background()
+
+	_, err := client.CreatePullRequestComment(ctx, 1, 7, "hello")
+	assert.True(t, httpapi.IsReadOnly(err))
+	_, err = client.UpdatePullRequestComment(ctx, 1, 7, 3, "edited")
+	assert.True(t, httpapi.IsReadOnly(err))
+	assert.True(t, httpapi.IsReadOnly(client.DeletePullRequestComment(ctx, 1, 7, 3)))
+
+	assert.Empty(t, server.Comments(1, 7))
+	assert.Equal(t, []models.SkippedWrite{
+		{Method: "POST", Path: "/api/v1/repos/acme/app/issues/7/comments", Description: "Comment on PR #7 in acme/app"},
+		{Method: "PATCH", Path: "/api/v1/repos/acme/app/issues/comments/3", Description: "Update comment on PR #7 in acme/app"},
+		{Method: "DELETE", Path: "/api/v1/repos/acme/app/issues/comments/3", Description: "Delete comment on PR #7 in acme/app"},
+	}, client.SkippedWrites())
+}
@@ -0,0 +1,253 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// workInProgressPrefixes are the title prefixes Gitea treats as a draft by default
+var workInProgressPrefixes = []string{"WIP:", "[WIP]"}
+
+// pullRequest is a pull request as returned by the Gitea API
+type pullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Draft   bool   `json:"draft"`
+	User    user   `json:"user"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees          []user `json:"assignees"`
+	RequestedReviewers []user `json:"requested_reviewers"`
+	Milestone          *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Merged       bool      `json:"merged"`
+	Mergeable    bool      `json:"mergeable"`
+	ChangedFiles int       `json:"changed_files"`
+	Comments     int       `json:"comments"`
+}
+
+// user is a Gitea account referenced by a pull request
+type user struct {
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+}
+
+// author converts the user to the shared model, named by the login when the full name is empty
+func (u user) author() models.Author {
+	name := u.FullName
+	if name == "" {
+		name = u.Login
+	}
+	return models.Author{Name: name, Username: u.Login}
+}
+
+// isDraft reports whether the pull request is a draft, flagged or by a work in progress title
+func (pr pullRequest) isDraft() bool {
+	if pr.Draft {
+		return true
+	}
+	for _, prefix := range workInProgressPrefixes {
+		if strings.HasPrefix(strings.ToUpper(pr.Title), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// model converts the pull request to the shared model, with GitLab's state and merge status names.
+// Gitea only reports whether a pull request is mergeable, and calls it unmergeable while checking
+// it too, so an open pull request that is neither mergeable nor a draft is only checking until
+// confirmConflict confirms the conflict.
+func (pr pullRequest) model() models.MergeRequest {
+	mr := models.MergeRequest{
+		ID:             pr.Number,
+		Title:          pr.Title,
+		Author:         pr.User.author(),
+		WebURL:         pr.HTMLURL,
+		SourceBranch:   pr.Head.Ref,
+		TargetBranch:   pr.Base.Ref,
+		CreatedAt:      pr.CreatedAt,
+		UpdatedAt:      pr.UpdatedAt,
+		State:          "opened",
+		Draft:          pr.isDraft(),
+		ChangesCount:   models.ChangeCount(pr.ChangedFiles),
+		SHA:            pr.Head.SHA,
+		UserNotesCount: pr.Comments,
+	}
+	for _, label := range pr.Labels {
+		mr.Labels = append(mr.Labels, label.Name)
+	}
+	for _, assignee := range pr.Assignees {
+		mr.Assignees = append(mr.Assignees, assignee.author())
+	}
+	for _, reviewer := range pr.RequestedReviewers {
+		mr.Reviewers = append(mr.Reviewers, reviewer.author())
+	}
+	if pr.Milestone != nil {
+		mr.Milestone = &models.Milestone{Title: pr.Milestone.Title}
+	}
+
+	switch {
+	case pr.Merged:
+		mr.State = "merged"
+	case pr.State == "closed":
+		mr.State = "closed"
+	}
+
+	mr.MergeStatus = "cannot_be_merged"
+	if pr.Mergeable {
+		mr.MergeStatus = "can_be_merged"
+	}
+
+	switch {
+	case pr.isDraft():
+		mr.DetailedMergeStatus = "draft_status"
+	case pr.Mergeable:
+		mr.DetailedMergeStatus = "mergeable"
+	case mr.State == "opened":
+		mr.MergeStatus = "checking"
+		mr.DetailedMergeStatus = "checking"
+	}
+
+	return mr
+}
+
+// confirmConflict fetches a pull request Gitea called unmergeable again until it is mergeable, or
+// until it stayed unmergeable for every attempt, which confirms the conflict. A pull request
+// without changed files has nothing to conflict and stays checking.
+func (c *Client) confirmConflict(ctx context.Context, fullName string, mr models.MergeRequest) (models.MergeRequest, error) {
+	for attempt := 1; mr.MergeStatus == "checking"; attempt++ {
+		if attempt > c.conflictCheckAttempts {
+			if mr.ChangesCount > 0 {
+				mr.MergeStatus = "cannot_be_merged"
+				mr.DetailedMergeStatus = "conflict"
+				mr.HasConflicts = true
+			}
+			break
+		}
+
+		slog.Debug("Gitea may still be checking the pull request, fetching it again", "repository", fullName, "pull_request", mr.ID, "attempt", attempt)
+		select {
+		case <-ctx.Done():
+			return mr, ctx.Err()
+		case <-time.After(c.conflictCheckDelay):
+		}
+
+		var pr pullRequest
+		if err := c.api.Get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", fullName, mr.ID), &pr); err != nil {
+			return mr, err
+		}
+		mr = pr.model()
+	}
+	return mr, nil
+}
+
+// PullRequestListOptions filters the pull requests returned by ListPullRequests
+type PullRequestListOptions struct {
+	State        string // "opened" (default), "closed", "merged" or "all", as for GitLab merge requests
+	SourceBranch string // Head branch in the repository itself
+	TargetBranch string
+	Labels       []string  // Only pull requests carrying all of these labels
+	UpdatedAfter time.Time // Only pull requests updated after this time, when set
+}
+
+// ListPullRequests retrieves the pull requests of a repository. Gitea cannot filter them on
+// branches, labels or update time, so those filters are applied to the listed pull requests.
+func (c *Client) ListPullRequests(ctx context.Context, repoID int, opts PullRequestListOptions) ([]models.MergeRequest, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	switch opts.State {
+	case "", "opened":
+		params.Set("state", "open")
+	case "closed", "merged":
+		params.Set("state", "closed")
+	default:
+		params.Set("state", "all")
+	}
+	params.Set("sort", "recentupdate")
+
+	pulls, err := paginate[pullRequest](ctx, c, fmt.Sprintf("/repos/%s/pulls?%s", fullName, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for repository %s: %w", fullName, err)
+	}
+
+	var result []models.MergeRequest
+	for _, pr := range pulls {
+		mr := pr.model()
+		switch {
+		case opts.SourceBranch != "" && mr.SourceBranch != opts.SourceBranch,
+			opts.TargetBranch != "" && mr.TargetBranch != opts.TargetBranch,
+			!opts.UpdatedAfter.IsZero() && !mr.UpdatedAt.After(opts.UpdatedAfter),
+			(opts.State == "closed" || opts.State == "merged") && mr.State != opts.State,
+			!hasLabels(mr.Labels, opts.Labels):
+			continue
+		}
+		if mr, err = c.confirmConflict(ctx, fullName, mr); err != nil {
+			return nil, fmt.Errorf("failed to get pull request %d for repository %s: %w", mr.ID, fullName, err)
+		}
+		result = append(result, mr)
+	}
+
+	return result, nil
+}
+
+// GetPullRequest retrieves a single pull request
+func (c *Client) GetPullRequest(ctx context.Context, repoID, number int) (*models.MergeRequest, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr pullRequest
+	if err := c.api.Get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", fullName, number), &pr); err != nil {
+		return nil, fmt.Errorf("failed to get pull request %d for repository %s: %w", number, fullName, err)
+	}
+	mr, err := c.confirmConflict(ctx, fullName, pr.model())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request %d for repository %s: %w", number, fullName, err)
+	}
+	return &mr, nil
+}
+
+// GetPullRequestChanges retrieves the number of files changed by a pull request
+func (c *Client) GetPullRequestChanges(ctx context.Context, repoID, number int) (int, error) {
+	mr, err := c.GetPullRequest(ctx, repoID, number)
+	if err != nil {
+		return 0, err
+	}
+	return int(mr.ChangesCount), nil
+}
+
+// hasLabels reports whether labels holds every one of required
+func hasLabels(labels, required []string) bool {
+	for _, label := range required {
+		if !slices.Contains(labels, label) {
+			return false
+		}
+	}
+	return true
+}
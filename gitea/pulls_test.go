@@ -0,0 +1,157 @@
+package gitea
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/giteafake"
+)
+
+// setUpPulls stores acme/app with ID 1 and its pull requests on the server
+func setUpPulls(server *giteafake.Server, pulls []giteafake.PullRequest) {
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.SetPullRequests(1, pulls)
+}
+
+func TestClient_ListPullRequests(t *testing.T) {
+	server, client := newFakeClient(t)
+	created := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	setUpPulls(server, []giteafake.PullRequest{
+		{Number: 4, Title: "Release 1.2", Author: "alice", Head: "release", Base: "master", ChangedFiles: 5, Labels: []string{"release"}, CreatedAt: created, UpdatedAt: created.Add(time.Hour),
+			Assignees: []string{"carol"}, Reviewers: []string{"dave"}, Milestone: "1.2", SHA: "abc123", Comments: 3},
+	})
+
+	mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	mr := mrs[0]
+	assert.Equal(t, 4, mr.ID)
+	assert.Equal(t, "Release 1.2", mr.Title)
+	assert.Equal(t, models.Author{Name: "alice", Username: "alice"}, mr.Author)
+	assert.Equal(t, server.URL()+"/acme/app/pulls/4", mr.WebURL)
+	assert.Equal(t, "release", mr.SourceBranch)
+	assert.Equal(t, "master", mr.TargetBranch)
+	assert.Equal(t, "opened", mr.State)
+	assert.Equal(t, "cannot_be_merged", mr.MergeStatus)
+	assert.Equal(t, "conflict", mr.DetailedMergeStatus)
+	assert.True(t, mr.HasConflicts)
+	assert.Equal(t, models.ChangeCount(5), mr.ChangesCount)
+	assert.Equal(t, []string{"release"}, mr.Labels)
+	assert.Equal(t, created, mr.CreatedAt)
+	assert.Equal(t, []models.Author{{Name: "carol", Username: "carol"}}, mr.Assignees)
+	assert.Equal(t, []models.Author{{Name: "dave", Username: "dave"}}, mr.Reviewers)
+	assert.Equal(t, &models.Milestone{Title: "1.2"}, mr.Milestone)
+	assert.Equal(t, "abc123", mr.SHA)
+	assert.Equal(t, 3, mr.UserNotesCount)
+	assert.False(t, mr.IsDraft())
+
+	// The pull request Gitea called unmergeable was fetched again to confirm the conflict
+	var paths []string
+	for _, request := range server.Requests() {
+		paths = append(paths, request.Path)
+		if request.Path == "/api/v1/repos/acme/app/pulls" {
+			assert.Equal(t, "open", request.Query.Get("state"))
+			assert.Equal(t, "recentupdate", request.Query.Get("sort"))
+		}
+	}
+	assert.Equal(t, []string{"/api/v1/repos/acme/app/pulls/4", "/api/v1/repos/acme/app/pulls/4"}, paths[len(paths)-2:])
+}
+
+func TestClient_ListPullRequests_MergeStatus(t *testing.T) {
+	tests := []struct {
+		name                string
+		pull                giteafake.PullRequest
+		state               string
+		mergeStatus         string
+		detailedMergeStatus string
+		hasConflicts        bool
+	}{
+		{name: "mergeable", pull: giteafake.PullRequest{Mergeable: true}, state: "opened", mergeStatus: "can_be_merged", detailedMergeStatus: "mergeable"},
+		{name: "conflicting", pull: giteafake.PullRequest{ChangedFiles: 2}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "conflict", hasConflicts: true},
+		{name: "checked before the retries run out", pull: giteafake.PullRequest{Mergeable: true, CheckingFor: 2, ChangedFiles: 2}, state: "opened", mergeStatus: "can_be_merged", detailedMergeStatus: "mergeable"},
+		{name: "still checking", pull: giteafake.PullRequest{Mergeable: true, CheckingFor: 3, ChangedFiles: 2}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "conflict", hasConflicts: true},
+		{name: "empty", pull: giteafake.PullRequest{}, state: "opened", mergeStatus: "checking", detailedMergeStatus: "checking"},
+		{name: "draft", pull: giteafake.PullRequest{Draft: true}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "draft_status"},
+		{name: "work in progress title", pull: giteafake.PullRequest{Title: "WIP: release", Mergeable: true}, state: "opened", mergeStatus: "can_be_merged", detailedMergeStatus: "draft_status"},
+		{name: "bracketed work in progress title", pull: giteafake.PullRequest{Title: "[wip] release"}, state: "opened", mergeStatus: "cannot_be_merged", detailedMergeStatus: "draft_status"},
+		{name: "merged", pull: giteafake.PullRequest{State: "closed", Merged: true}, state: "merged", mergeStatus: "cannot_be_merged"},
+		{name: "closed", pull: giteafake.PullRequest{State: "closed"}, state: "closed", mergeStatus: "cannot_be_merged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newFakeClient(t)
+			pull := tt.pull
+			pull.Number = 1
+			pull.Head, pull.Base = "release", "master"
+			setUpPulls(server, []giteafake.PullRequest{pull})
+
+			mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{State: "all"})
+
+			require.NoError(t, err)
+			require.Len(t, mrs, 1)
+			assert.Equal(t, tt.state, mrs[0].State)
+			assert.Equal(t, tt.mergeStatus, mrs[0].MergeStatus)
+			assert.Equal(t, tt.detailedMergeStatus, mrs[0].DetailedMergeStatus)
+			assert.Equal(t, tt.hasConflicts, mrs[0].HasConflicts)
+		})
+	}
+}
+
+func TestClient_ListPullRequests_Filters(t *testing.T) {
+	server, client := newFakeClient(t)
+	updated := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	setUpPulls(server, []giteafake.PullRequest{
+		{Number: 1, Head: "release", Base: "master", Labels: []string{"release", "urgent"}, UpdatedAt: updated},
+		{Number: 2, Head: "feature", Base: "master", UpdatedAt: updated},
+		{Number: 3, Head: "release", Base: "develop", UpdatedAt: updated},
+		{Number: 4, Head: "release", Base: "master", UpdatedAt: updated.Add(-48 * time.Hour)},
+		{Number: 5, Head: "release", Base: "master", State: "closed", Merged: true, UpdatedAt: updated},
+		{Number: 6, Head: "release", Base: "master", State: "closed", UpdatedAt: updated},
+	})
+	ctx := context.Background()
+
+	numbers := func(opts PullRequestListOptions) []int {
+		mrs, err := client.ListPullRequests(ctx, 1, opts)
+		require.NoError(t, err)
+		var result []int
+		for _, mr := range mrs {
+			result = append(result, mr.ID)
+		}
+		return result
+	}
+
+	assert.ElementsMatch(t, []int{1, 4}, numbers(PullRequestListOptions{SourceBranch: "release", TargetBranch: "master"}))
+	assert.ElementsMatch(t, []int{1}, numbers(PullRequestListOptions{SourceBranch: "release", TargetBranch: "master", UpdatedAfter: updated.Add(-time.Hour)}))
+	assert.ElementsMatch(t, []int{1}, numbers(PullRequestListOptions{Labels: []string{"urgent"}}))
+	assert.ElementsMatch(t, []int{5}, numbers(PullRequestListOptions{State: "merged"}))
+	assert.ElementsMatch(t, []int{6}, numbers(PullRequestListOptions{State: "closed"}))
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6}, numbers(PullRequestListOptions{State: "all"}))
+}
+
+func TestClient_GetPullRequestChanges(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []giteafake.PullRequest{{Number: 1, Head: "release", Base: "master", ChangedFiles: 12}})
+	ctx := context.Background()
+
+	changes, err := client.GetPullRequestChanges(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 12, changes)
+
+	_, err = client.GetPullRequestChanges(ctx, 1, 2)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestHasLabels(t *testing.T) {
+	assert.True(t, hasLabels([]string{"a", "b"}, nil))
+	assert.True(t, hasLabels([]string{"a", "b"}, []string{"b"}))
+	assert.False(t, hasLabels([]string{"a"}, []string{"a", "b"}))
+}
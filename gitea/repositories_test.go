@@ -0,0 +1,102 @@
+package gitea
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_ListRepositories(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{ID: 10, Path: "acme"}, LastActivityAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "dotfiles", Namespace: models.Namespace{ID: 20, Path: "alice"}, LastActivityAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, 1, repos[0].ID)
+	assert.Equal(t, "acme/app", repos[0].PathWithNamespace)
+	assert.Equal(t, server.URL()+"/acme/app", repos[0].WebURL)
+	assert.Equal(t, models.Namespace{ID: 10, Name: "acme", Path: "acme", FullPath: "acme"}, repos[0].Namespace)
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), repos[0].LastActivityAt)
+	assert.Equal(t, "/api/v1/user/repos", server.Requests()[0].Path)
+}
+
+func TestClient_ListRepositories_Organizations(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}},
+		{ID: 2, Name: "web", Namespace: models.Namespace{Path: "globex"}},
+		{ID: 3, Name: "dotfiles", Namespace: models.Namespace{Path: "alice"}},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{Organizations: []string{"acme", "globex"}})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "acme/app", repos[0].PathWithNamespace)
+	assert.Equal(t, "globex/web", repos[1].PathWithNamespace)
+}
+
+func TestClient_ListRepositories_LastActivityAfter(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}, LastActivityAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "old", Namespace: models.Namespace{Path: "acme"}, LastActivityAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{LastActivityAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "app", repos[0].Name)
+}
+
+func TestClient_FullNameLookup(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 7, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	ctx := context.Background()
+
+	name, err := client.fullName(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "acme/app", name)
+
+	// The second lookup is answered from the cache
+	_, err = client.fullName(ctx, 7)
+	require.NoError(t, err)
+	assert.Len(t, server.Requests(), 1)
+
+	_, err = client.fullName(ctx, 8)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestClient_BranchProtected(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 7, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.ProtectBranch(7, "master")
+	server.ProtectBranch(7, "release/1.2")
+	ctx := context.Background()
+
+	protected, err := client.BranchProtected(ctx, 7, "master")
+	require.NoError(t, err)
+	assert.True(t, protected)
+
+	protected, err = client.BranchProtected(ctx, 7, "develop")
+	require.NoError(t, err)
+	assert.False(t, protected)
+
+	// Branch names may contain slashes
+	protected, err = client.BranchProtected(ctx, 7, "release/1.2")
+	require.NoError(t, err)
+	assert.True(t, protected)
+}
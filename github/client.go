@@ -0,0 +1,137 @@
+// Package github is a client for the parts of the GitHub REST API the checker uses
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// DefaultURL is the API URL of github.com; GitHub Enterprise Server serves the API below /api/v3
+const DefaultURL = "https://api.github.com"
+
+// DefaultTimeout is the per-request timeout used when none is configured
+const DefaultTimeout = 30 * time.Second
+
+// DefaultPerPage is the page size requested from list endpoints, the largest GitHub allows
+const DefaultPerPage = 100
+
+// apiVersion is the REST API version the client is written against
+const apiVersion = "2022-11-28"
+
+// Client represents a GitHub API client
+type Client struct {
+	api        *httpapi.Client
+	httpClient *http.Client
+	readOnly   bool
+
+	// GitHub computes mergeability in the background, so pull requests are fetched again while it is unknown
+	mergeabilityAttempts int
+	mergeabilityDelay    time.Duration
+
+	mu        sync.Mutex
+	fullNames map[int]string
+}
+
+// Option configures optional Client behaviour
+type Option func(*Client)
+
+// WithHTTPClient makes the client send its requests through httpClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithReadOnly makes the client refuse every request other than GET and HEAD at the transport
+// layer; the refused writes are logged and available from SkippedWrites
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// WithMergeabilityRetry sets how many times a pull request whose mergeability GitHub is still
+// computing is fetched, and the delay between the attempts
+func WithMergeabilityRetry(attempts int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.mergeabilityAttempts = max(attempts, 1)
+		c.mergeabilityDelay = delay
+	}
+}
+
+// NewClient creates a new GitHub API client; baseURL is the API URL, DefaultURL when empty
+func NewClient(baseURL, token string, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+
+	client := &Client{
+		httpClient:           &http.Client{Timeout: DefaultTimeout},
+		mergeabilityAttempts: 3,
+		mergeabilityDelay:    time.Second,
+		fullNames:            make(map[int]string),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.api = httpapi.NewClient(httpapi.Config{
+		Service:    "GitHub",
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: client.httpClient,
+		ReadOnly:   client.readOnly,
+		SetHeaders: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("X-GitHub-Api-Version", apiVersion)
+			return nil
+		},
+		CheckResponse: checkRateLimit,
+		UserEndpoint:  "/user",
+	})
+
+	return client
+}
+
+// ReadOnly reports whether the client refuses write requests
+func (c *Client) ReadOnly() bool {
+	return c.api.ReadOnly()
+}
+
+// SkippedWrites returns the write requests refused so far by a read-only client
+func (c *Client) SkippedWrites() []models.SkippedWrite {
+	return c.api.SkippedWrites()
+}
+
+// TestConnection verifies that the token is accepted by GitHub
+func (c *Client) TestConnection(ctx context.Context) error {
+	return c.api.TestConnection(ctx)
+}
+
+// checkRateLimit fails the responses GitHub answers with 403 or 429 once the rate limit is used up
+func checkRateLimit(resp *http.Response) error {
+	if (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests) || resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return nil
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return fmt.Errorf("rate limit exceeded, resets at %s", time.Unix(reset, 0).UTC().Format(time.RFC3339))
+	}
+	return fmt.Errorf("rate limit exceeded")
+}
+
+// paginate fetches every page of a GitHub list endpoint by following the next link of the Link header
+func paginate[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	endpoint = httpapi.SetQueryValue(endpoint, "per_page", strconv.Itoa(DefaultPerPage))
+	return httpapi.PaginateJSON[T](ctx, c.api, endpoint, func(header http.Header, endpoint string, count int) string {
+		return c.api.NextLink(header)
+	})
+}
@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/githubfake"
+)
+
+// newFakeClient starts a fake GitHub server and returns a client for it that does not wait between
+// mergeability attempts
+func newFakeClient(t *testing.T, opts ...Option) (*githubfake.Server, *Client) {
+	t.Helper()
+	server := githubfake.NewServer()
+	t.Cleanup(server.Close)
+	opts = append([]Option{WithMergeabilityRetry(3, 0)}, opts...)
+	return server, NewClient(server.URL(), githubfake.DefaultToken, opts...)
+}
+
+func TestNewClient_DefaultURL(t *testing.T) {
+	client := NewClient("", "token")
+	assert.Equal(t, DefaultURL, client.api.BaseURL())
+
+	client = NewClient("https://github.example.com/api/v3/", "token")
+	assert.Equal(t, "https://github.example.com/api/v3", client.api.BaseURL())
+}
+
+func TestClient_TestConnection(t *testing.T) {
+	server, client := newFakeClient(t)
+
+	require.NoError(t, client.TestConnection(context.Background()))
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/user", requests[0].Path)
+	assert.Equal(t, "Bearer "+githubfake.DefaultToken, requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "application/vnd.github+json", requests[0].Header.Get("Accept"))
+	assert.Equal(t, apiVersion, requests[0].Header.Get("X-GitHub-Api-Version"))
+}
+
+func TestClient_TestConnection_InvalidToken(t *testing.T) {
+	server := githubfake.NewServer()
+	defer server.Close()
+
+	client := NewClient(server.URL(), "wrong-token")
+	err := client.TestConnection(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authentication failed: invalid token")
+}
+
+func TestClient_RateLimited(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRateLimited(true)
+
+	err := client.TestConnection(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded, resets at 2024-01-15T11:00:00Z")
+}
+
+func TestClient_APIErrorMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found","documentation_url":"https://docs.github.com"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	_, err := client.GetPullRequest(context.Background(), 1, 1)
+
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+	assert.Contains(t, err.Error(), "API error 404: Not Found")
+}
+
+func TestClient_PaginatesWithLinkHeader(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetMaxPerPage(2)
+
+	var repos []models.Repository
+	for id := 1; id <= 5; id++ {
+		repos = append(repos, models.Repository{ID: id, Name: "repo", Namespace: models.Namespace{Path: "owner"}})
+	}
+	server.SetRepositories(repos)
+
+	listed, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{})
+
+	require.NoError(t, err)
+	assert.Len(t, listed, 5)
+	assert.Len(t, server.Requests(), 3)
+	for _, request := range server.Requests() {
+		assert.Equal(t, "100", request.Query.Get("per_page"))
+	}
+}
+
+func TestClient_ReadOnlySkipsWrites(t *testing.T) {
+	server, client := newFakeClient(t, WithReadOnly())
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.SetPullRequests(1, []githubfake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+
+	assert.True(t, client.ReadOnly())
+	_, err := client.CreatePullRequestComment(context.Background(), 1, 7, "conflict")
+
+	require.Error(t, err)
+	assert.True(t, httpapi.IsReadOnly(err))
+	assert.Empty(t, server.Comments(1, 7))
+	assert.Equal(t, []models.SkippedWrite{{
+		Method:      http.MethodPost,
+		Path:        "/repos/acme/app/issues/7/comments",
+		Description: "Comment on PR #7 in acme/app",
+	}}, client.SkippedWrites())
+}
+
+func TestClient_ReadOnlyWrapsHTTPClientTransport(t *testing.T) {
+	server, _ := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.SetPullRequests(1, []githubfake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+
+	shared := &http.Client{Transport: http.DefaultTransport}
+	client := NewClient(server.URL(), githubfake.DefaultToken, WithHTTPClient(shared), WithReadOnly())
+
+	_, err := client.GetPullRequest(context.Background(), 1, 7)
+	require.NoError(t, err)
+	err = client.DeletePullRequestComment(context.Background(), 1, 7, 3)
+	assert.True(t, httpapi.IsReadOnly(err))
+
+	// The refused write never reached GitHub, and the shared client is left alone
+	for _, request := range server.Requests() {
+		assert.Equal(t, http.MethodGet, request.Method)
+	}
+	assert.Equal(t, http.DefaultTransport, shared.Transport)
+}
+
+func TestClient_ContextCancelledWhileWaitingForMergeability(t *testing.T) {
+	server, _ := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.SetPullRequests(1, []githubfake.PullRequest{{Number: 1, Head: "release", Base: "master", UnknownFor: 5}})
+	client := NewClient(server.URL(), githubfake.DefaultToken, WithMergeabilityRetry(3, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetPullRequest(ctx, 1, 1)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
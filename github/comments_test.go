@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/testing/githubfake"
+)
+
+func TestClient_PullRequestComments(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	ctx := context.Background()
+
+	created, err := client.CreatePullRequestComment(ctx, 1, 7, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "first", created.Body)
+	assert.Equal(t, "octocat", created.Author.Username)
+
+	updated, err := client.UpdatePullRequestComment(ctx, 1, 7, created.ID, "second")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, "second", updated.Body)
+
+	notes, err := client.ListPullRequestComments(ctx, 1, 7)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "second", notes[0].Body)
+
+	require.NoError(t, client.DeletePullRequestComment(ctx, 1, 7, created.ID))
+	assert.Empty(t, server.Comments(1, 7))
+
+	var methods []string
+	for _, request := range server.Requests() {
+		methods = append(methods, request.Method+" "+request.Path)
+	}
+	assert.Equal(t, []string{
+		"GET /repositories/1",
+		"POST /repos/acme/app/issues/7/comments",
+		"PATCH /repos/acme/app/issues/comments/1",
+		"GET /repos/acme/app/issues/7/comments",
+		"DELETE /repos/acme/app/issues/comments/1",
+	}, methods)
+}
+
+func TestClient_ListPullRequestComments_Paginated(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetMaxPerPage(2)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	for i := 0; i < 5; i++ {
+		_, err := client.CreatePullRequestComment(context.Background(), 1, 7, "comment")
+		require.NoError(t, err)
+	}
+
+	notes, err := client.ListPullRequestComments(context.Background(), 1, 7)
+
+	require.NoError(t, err)
+	assert.Len(t, notes, 5)
+}
+
+func TestClient_PullRequestComments_Errors(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	ctx := context.Background()
+
+	_, err := client.CreatePullRequestComment(ctx, 1, 8, "body")
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+	assert.Contains(t, err.Error(), "failed to create comment on pull request 8 for repository acme/app")
+
+	_, err = client.UpdatePullRequestComment(ctx, 1, 7, 99, "body")
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+
+	err = client.DeletePullRequestComment(ctx, 1, 7, 99)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestClient_ReadOnlySkipsCommentChanges(t *testing.T) {
+	server, client := newFakeClient(t, WithReadOnly())
+	setUpPulls(server, []githubfake.PullRequest{{Number: 7, Head: "release", Base: "master"}})
+	ctx := context.Background()
+
+	_, err := client.UpdatePullRequestComment(ctx, 1, 7, 3, "body")
+	assert.True(t, httpapi.IsReadOnly(err))
+	err = client.DeletePullRequestComment(ctx, 1, 7, 3)
+	assert.True(t, httpapi.IsReadOnly(err))
+
+	skipped := client.SkippedWrites()
+	require.Len(t, skipped, 2)
+	assert.Equal(t, http.MethodPatch, skipped[0].Method)
+	assert.Equal(t, http.MethodDelete, skipped[1].Method)
+	for _, request := range server.Requests() {
+		assert.Equal(t, http.MethodGet, request.Method)
+	}
+}
@@ -0,0 +1,254 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// Mergeable states of a pull request
+const (
+	MergeableStateDirty   = "dirty"   // Conflicts with the base branch
+	MergeableStateBehind  = "behind"  // The head branch is out of date
+	MergeableStateClean   = "clean"   // Can be merged
+	MergeableStateDraft   = "draft"   // Draft pull requests cannot be merged
+	MergeableStateUnknown = "unknown" // Still being computed
+)
+
+// pullRequest is a pull request as returned by the GitHub API; the mergeability fields and
+// changed_files are only sent for a single pull request
+type pullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Draft   bool   `json:"draft"`
+	User    user   `json:"user"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees          []user `json:"assignees"`
+	RequestedReviewers []user `json:"requested_reviewers"`
+	Milestone          *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	MergedAt       *time.Time `json:"merged_at"`
+	Mergeable      *bool      `json:"mergeable"`
+	MergeableState string     `json:"mergeable_state"`
+	ChangedFiles   int        `json:"changed_files"`
+	Comments       int        `json:"comments"`
+}
+
+// user is a GitHub account referenced by a pull request
+type user struct {
+	Login string `json:"login"`
+}
+
+// author converts the user to the shared model, which only has the login on GitHub
+func (u user) author() models.Author {
+	return models.Author{Name: u.Login, Username: u.Login}
+}
+
+// model converts the pull request to the shared model, with GitLab's state and merge status names
+func (pr pullRequest) model() models.MergeRequest {
+	mr := models.MergeRequest{
+		ID:             pr.Number,
+		Title:          pr.Title,
+		Author:         pr.User.author(),
+		WebURL:         pr.HTMLURL,
+		SourceBranch:   pr.Head.Ref,
+		TargetBranch:   pr.Base.Ref,
+		HasConflicts:   pr.MergeableState == MergeableStateDirty,
+		CreatedAt:      pr.CreatedAt,
+		UpdatedAt:      pr.UpdatedAt,
+		State:          "opened",
+		Draft:          pr.Draft,
+		ChangesCount:   models.ChangeCount(pr.ChangedFiles),
+		SHA:            pr.Head.SHA,
+		UserNotesCount: pr.Comments,
+	}
+	for _, label := range pr.Labels {
+		mr.Labels = append(mr.Labels, label.Name)
+	}
+	for _, assignee := range pr.Assignees {
+		mr.Assignees = append(mr.Assignees, assignee.author())
+	}
+	for _, reviewer := range pr.RequestedReviewers {
+		mr.Reviewers = append(mr.Reviewers, reviewer.author())
+	}
+	if pr.Milestone != nil {
+		mr.Milestone = &models.Milestone{Title: pr.Milestone.Title}
+	}
+
+	switch {
+	case pr.MergedAt != nil:
+		mr.State = "merged"
+	case pr.State == "closed":
+		mr.State = "closed"
+	}
+
+	switch {
+	case pr.Mergeable == nil:
+		mr.MergeStatus = "checking"
+	case *pr.Mergeable:
+		mr.MergeStatus = "can_be_merged"
+	default:
+		mr.MergeStatus = "cannot_be_merged"
+	}
+
+	switch pr.MergeableState {
+	case MergeableStateDirty:
+		mr.DetailedMergeStatus = "conflict"
+	case MergeableStateBehind:
+		mr.DetailedMergeStatus = models.DetailedMergeStatusNeedRebase
+	case MergeableStateClean:
+		mr.DetailedMergeStatus = "mergeable"
+	case MergeableStateDraft:
+		mr.DetailedMergeStatus = "draft_status"
+	case MergeableStateUnknown, "":
+		mr.DetailedMergeStatus = "checking"
+	default:
+		mr.DetailedMergeStatus = pr.MergeableState
+	}
+
+	return mr
+}
+
+// PullRequestListOptions filters the pull requests returned by ListPullRequests
+type PullRequestListOptions struct {
+	State        string // "opened" (default), "closed", "merged" or "all", as for GitLab merge requests
+	SourceBranch string // Head branch in the repository itself
+	TargetBranch string
+	Labels       []string  // Only pull requests carrying all of these labels
+	UpdatedAfter time.Time // Only pull requests updated after this time, when set
+}
+
+// ListPullRequests retrieves the pull requests of a repository. Open pull requests are fetched one
+// by one as well, since GitHub only reports their mergeability and changed files individually.
+func (c *Client) ListPullRequests(ctx context.Context, repoID int, opts PullRequestListOptions) ([]models.MergeRequest, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	switch opts.State {
+	case "", "opened":
+		params.Set("state", "open")
+	case "closed", "merged":
+		params.Set("state", "closed")
+	default:
+		params.Set("state", "all")
+	}
+	if opts.SourceBranch != "" {
+		// GitHub matches the head branch as owner:branch
+		owner, _, _ := strings.Cut(fullName, "/")
+		params.Set("head", owner+":"+opts.SourceBranch)
+	}
+	if opts.TargetBranch != "" {
+		params.Set("base", opts.TargetBranch)
+	}
+	params.Set("sort", "updated")
+	params.Set("direction", "desc")
+
+	pulls, err := paginate[pullRequest](ctx, c, fmt.Sprintf("/repos/%s/pulls?%s", fullName, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for repository %s: %w", fullName, err)
+	}
+
+	var result []models.MergeRequest
+	for _, pr := range pulls {
+		if !opts.UpdatedAfter.IsZero() && !pr.UpdatedAt.After(opts.UpdatedAfter) {
+			continue
+		}
+		mr := pr.model()
+		if (opts.State == "closed" || opts.State == "merged") && mr.State != opts.State {
+			continue
+		}
+		if !hasLabels(mr.Labels, opts.Labels) {
+			continue
+		}
+
+		if mr.State == "opened" {
+			detailed, err := c.getPullRequest(ctx, fullName, pr.Number)
+			if err != nil {
+				return nil, err
+			}
+			mr = detailed.model()
+		}
+		result = append(result, mr)
+	}
+
+	return result, nil
+}
+
+// GetPullRequest retrieves a single pull request with its mergeability
+func (c *Client) GetPullRequest(ctx context.Context, repoID, number int) (*models.MergeRequest, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := c.getPullRequest(ctx, fullName, number)
+	if err != nil {
+		return nil, err
+	}
+	mr := pr.model()
+	return &mr, nil
+}
+
+// GetPullRequestChanges retrieves the number of files changed by a pull request
+func (c *Client) GetPullRequestChanges(ctx context.Context, repoID, number int) (int, error) {
+	mr, err := c.GetPullRequest(ctx, repoID, number)
+	if err != nil {
+		return 0, err
+	}
+	return int(mr.ChangesCount), nil
+}
+
+// getPullRequest fetches a pull request, again while GitHub is still computing its mergeability
+func (c *Client) getPullRequest(ctx context.Context, fullName string, number int) (pullRequest, error) {
+	endpoint := fmt.Sprintf("/repos/%s/pulls/%d", fullName, number)
+
+	for attempt := 1; ; attempt++ {
+		var pr pullRequest
+		if err := c.api.Get(ctx, endpoint, &pr); err != nil {
+			return pullRequest{}, fmt.Errorf("failed to get pull request %d for repository %s: %w", number, fullName, err)
+		}
+		if pr.State != "open" || (pr.Mergeable != nil && pr.MergeableState != MergeableStateUnknown) || attempt >= c.mergeabilityAttempts {
+			return pr, nil
+		}
+
+		slog.Debug("GitHub is still computing mergeability, retrying", "repository", fullName, "pull_request", number, "attempt", attempt)
+		select {
+		case <-time.After(c.mergeabilityDelay):
+		case <-ctx.Done():
+			return pullRequest{}, ctx.Err()
+		}
+	}
+}
+
+// hasLabels reports whether labels holds every one of required
+func hasLabels(labels, required []string) bool {
+	for _, label := range required {
+		if !slices.Contains(labels, label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,201 @@
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/githubfake"
+)
+
+// setUpPulls stores one repository, acme/app with ID 1, holding pulls
+func setUpPulls(server *githubfake.Server, pulls []githubfake.PullRequest) {
+	server.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.SetPullRequests(1, pulls)
+}
+
+func TestClient_ListPullRequests(t *testing.T) {
+	server, client := newFakeClient(t)
+	created := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	setUpPulls(server, []githubfake.PullRequest{
+		{Number: 1, Title: "Release 1.2", Author: "alice", Head: "release", Base: "master", MergeableState: MergeableStateDirty, ChangedFiles: 4, Labels: []string{"release"}, CreatedAt: created, UpdatedAt: created,
+			Assignees: []string{"carol"}, Reviewers: []string{"dave"}, Milestone: "1.2", SHA: "abc123"},
+		{Number: 2, Title: "Feature", Author: "bob", Head: "feature", Base: "master"},
+	})
+	_, err := client.CreatePullRequestComment(context.Background(), 1, 1, "Looks good")
+	require.NoError(t, err)
+	server.ResetRequests()
+
+	mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{SourceBranch: "release", TargetBranch: "master"})
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, models.MergeRequest{
+		ID:                  1,
+		Title:               "Release 1.2",
+		Author:              models.Author{Name: "alice", Username: "alice"},
+		WebURL:              "https://github.example.com/acme/app/pull/1",
+		SourceBranch:        "release",
+		TargetBranch:        "master",
+		HasConflicts:        true,
+		CreatedAt:           created,
+		UpdatedAt:           created,
+		State:               "opened",
+		MergeStatus:         "cannot_be_merged",
+		DetailedMergeStatus: "conflict",
+		ChangesCount:        4,
+		Labels:              []string{"release"},
+		Assignees:           []models.Author{{Name: "carol", Username: "carol"}},
+		Reviewers:           []models.Author{{Name: "dave", Username: "dave"}},
+		Milestone:           &models.Milestone{Title: "1.2"},
+		SHA:                 "abc123",
+		UserNotesCount:      1,
+	}, mrs[0])
+
+	list := server.Requests()[0]
+	assert.Equal(t, "/repos/acme/app/pulls", list.Path)
+	assert.Equal(t, "open", list.Query.Get("state"))
+	assert.Equal(t, "acme:release", list.Query.Get("head"))
+	assert.Equal(t, "master", list.Query.Get("base"))
+}
+
+func TestClient_ListPullRequests_MergeableStates(t *testing.T) {
+	tests := []struct {
+		state               string
+		hasConflicts        bool
+		mergeStatus         string
+		detailedMergeStatus string
+	}{
+		{MergeableStateDirty, true, "cannot_be_merged", "conflict"},
+		{MergeableStateBehind, false, "can_be_merged", models.DetailedMergeStatusNeedRebase},
+		{MergeableStateClean, false, "can_be_merged", "mergeable"},
+		{MergeableStateDraft, false, "cannot_be_merged", "draft_status"},
+		{"blocked", false, "can_be_merged", "blocked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			server, client := newFakeClient(t)
+			setUpPulls(server, []githubfake.PullRequest{{Number: 1, Head: "release", Base: "master", MergeableState: tt.state}})
+
+			mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{})
+
+			require.NoError(t, err)
+			require.Len(t, mrs, 1)
+			assert.Equal(t, tt.hasConflicts, mrs[0].HasConflicts)
+			assert.Equal(t, tt.mergeStatus, mrs[0].MergeStatus)
+			assert.Equal(t, tt.detailedMergeStatus, mrs[0].DetailedMergeStatus)
+		})
+	}
+}
+
+func TestClient_ListPullRequests_RetriesUnknownMergeability(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 1, Head: "release", Base: "master", MergeableState: MergeableStateDirty, UnknownFor: 2}})
+
+	mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.True(t, mrs[0].HasConflicts)
+	assert.Equal(t, "conflict", mrs[0].DetailedMergeStatus)
+
+	var details int
+	for _, request := range server.Requests() {
+		if request.Path == "/repos/acme/app/pulls/1" {
+			details++
+		}
+	}
+	assert.Equal(t, 3, details)
+}
+
+func TestClient_ListPullRequests_MergeabilityStillUnknown(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 1, Head: "release", Base: "master", MergeableState: MergeableStateDirty, UnknownFor: 10}})
+
+	mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.False(t, mrs[0].HasConflicts)
+	assert.Equal(t, "checking", mrs[0].MergeStatus)
+	assert.Equal(t, "checking", mrs[0].DetailedMergeStatus)
+}
+
+func TestClient_ListPullRequests_Filters(t *testing.T) {
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	pulls := []githubfake.PullRequest{
+		{Number: 1, Head: "a", Base: "master", UpdatedAt: since.Add(time.Hour), Labels: []string{"release", "urgent"}},
+		{Number: 2, Head: "b", Base: "master", UpdatedAt: since.Add(-time.Hour), Labels: []string{"release"}},
+		{Number: 3, Head: "c", Base: "master", State: "closed", Merged: true, UpdatedAt: since.Add(2 * time.Hour)},
+		{Number: 4, Head: "d", Base: "master", State: "closed", UpdatedAt: since.Add(3 * time.Hour)},
+	}
+
+	tests := []struct {
+		name    string
+		opts    PullRequestListOptions
+		numbers []int
+	}{
+		{"open by default", PullRequestListOptions{}, []int{1, 2}},
+		{"updated after", PullRequestListOptions{UpdatedAfter: since}, []int{1}},
+		{"labels", PullRequestListOptions{Labels: []string{"urgent"}}, []int{1}},
+		{"merged", PullRequestListOptions{State: "merged"}, []int{3}},
+		{"closed", PullRequestListOptions{State: "closed"}, []int{4}},
+		{"all", PullRequestListOptions{State: "all"}, []int{4, 3, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newFakeClient(t)
+			setUpPulls(server, pulls)
+
+			mrs, err := client.ListPullRequests(context.Background(), 1, tt.opts)
+
+			require.NoError(t, err)
+			var numbers []int
+			for _, mr := range mrs {
+				numbers = append(numbers, mr.ID)
+			}
+			assert.Equal(t, tt.numbers, numbers)
+		})
+	}
+}
+
+func TestClient_ListPullRequests_ClosedNotFetchedIndividually(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 1, Head: "a", Base: "master", State: "closed", Merged: true}})
+
+	mrs, err := client.ListPullRequests(context.Background(), 1, PullRequestListOptions{State: "all"})
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, "merged", mrs[0].State)
+	for _, request := range server.Requests() {
+		assert.NotEqual(t, "/repos/acme/app/pulls/1", request.Path)
+	}
+}
+
+func TestClient_GetPullRequestChanges(t *testing.T) {
+	server, client := newFakeClient(t)
+	setUpPulls(server, []githubfake.PullRequest{{Number: 5, Head: "release", Base: "master", ChangedFiles: 12}})
+
+	changes, err := client.GetPullRequestChanges(context.Background(), 1, 5)
+
+	require.NoError(t, err)
+	assert.Equal(t, 12, changes)
+
+	_, err = client.GetPullRequestChanges(context.Background(), 1, 6)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestHasLabels(t *testing.T) {
+	assert.True(t, hasLabels([]string{"a", "b"}, nil))
+	assert.True(t, hasLabels([]string{"a", "b"}, []string{"b", "a"}))
+	assert.False(t, hasLabels([]string{"a"}, []string{"a", "b"}))
+}
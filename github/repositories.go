@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// repository is a repository as returned by the GitHub API
+type repository struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+	Owner    struct {
+		ID    int    `json:"id"`
+		Login string `json:"login"`
+	} `json:"owner"`
+	PushedAt  time.Time `json:"pushed_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// model converts the repository to the shared model; the owner stands in for the namespace
+func (r repository) model() models.Repository {
+	lastActivity := r.UpdatedAt
+	if r.PushedAt.After(lastActivity) {
+		lastActivity = r.PushedAt
+	}
+	return models.Repository{
+		ID:                r.ID,
+		Name:              r.Name,
+		PathWithNamespace: r.FullName,
+		WebURL:            r.HTMLURL,
+		Namespace: models.Namespace{
+			ID:       r.Owner.ID,
+			Name:     r.Owner.Login,
+			Path:     r.Owner.Login,
+			FullPath: r.Owner.Login,
+		},
+		LastActivityAt: lastActivity,
+	}
+}
+
+// ListRepositoriesOptions filters the repositories returned by ListRepositories
+type ListRepositoriesOptions struct {
+	Organizations     []string  // Only repositories of these organizations; every repository the token can access when empty
+	LastActivityAfter time.Time // Only repositories pushed or updated after this time, when set
+}
+
+// ListRepositories retrieves the repositories accessible to the authenticated user. GitHub cannot
+// filter repositories on activity, so LastActivityAfter is applied to the listed repositories.
+func (c *Client) ListRepositories(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	var repos []repository
+	if len(opts.Organizations) == 0 {
+		all, err := paginate[repository](ctx, c, "/user/repos?affiliation=owner,collaborator,organization_member")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		repos = all
+	}
+	for _, org := range opts.Organizations {
+		orgRepos, err := paginate[repository](ctx, c, fmt.Sprintf("/orgs/%s/repos?type=all", url.PathEscape(org)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of organization %s: %w", org, err)
+		}
+		repos = append(repos, orgRepos...)
+	}
+
+	var result []models.Repository
+	for _, repo := range repos {
+		c.rememberFullName(repo.ID, repo.FullName)
+		if !opts.LastActivityAfter.IsZero() && !repo.model().LastActivityAt.After(opts.LastActivityAfter) {
+			continue
+		}
+		result = append(result, repo.model())
+	}
+
+	return result, nil
+}
+
+// fullName returns the owner/name of a repository, looking it up by ID when it was not listed
+func (c *Client) fullName(ctx context.Context, repoID int) (string, error) {
+	c.mu.Lock()
+	name, ok := c.fullNames[repoID]
+	c.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+
+	var repo repository
+	if err := c.api.Get(ctx, fmt.Sprintf("/repositories/%d", repoID), &repo); err != nil {
+		return "", fmt.Errorf("failed to get repository %d: %w", repoID, err)
+	}
+	c.rememberFullName(repo.ID, repo.FullName)
+	return repo.FullName, nil
+}
+
+// rememberFullName keeps the owner/name of a repository for the requests made by ID
+func (c *Client) rememberFullName(repoID int, fullName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fullNames[repoID] = fullName
+}
+
+// BranchProtected reports whether a branch of a repository is protected; a missing branch is not
+func (c *Client) BranchProtected(ctx context.Context, repoID int, branch string) (bool, error) {
+	fullName, err := c.fullName(ctx, repoID)
+	if err != nil {
+		return false, err
+	}
+
+	var result struct {
+		Protected bool `json:"protected"`
+	}
+	err = c.api.Get(ctx, fmt.Sprintf("/repos/%s/branches/%s", fullName, url.PathEscape(branch)), &result)
+	if httpapi.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get branch %s for repository %s: %w", branch, fullName, err)
+	}
+	return result.Protected, nil
+}
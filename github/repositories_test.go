@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_ListRepositories(t *testing.T) {
+	server, client := newFakeClient(t)
+	activity := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "api", Namespace: models.Namespace{ID: 10, Path: "acme"}, LastActivityAt: activity},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, models.Repository{
+		ID:                1,
+		Name:              "api",
+		PathWithNamespace: "acme/api",
+		WebURL:            "https://github.example.com/acme/api",
+		Namespace:         models.Namespace{ID: 10, Name: "acme", Path: "acme", FullPath: "acme"},
+		LastActivityAt:    activity,
+	}, repos[0])
+	assert.Equal(t, "/user/repos", server.Requests()[0].Path)
+	assert.Equal(t, "owner,collaborator,organization_member", server.Requests()[0].Query.Get("affiliation"))
+}
+
+func TestClient_ListRepositories_Organizations(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "api", Namespace: models.Namespace{Path: "acme"}},
+		{ID: 2, Name: "web", Namespace: models.Namespace{Path: "globex"}},
+		{ID: 3, Name: "dotfiles", Namespace: models.Namespace{Path: "octocat"}},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{Organizations: []string{"acme", "globex"}})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "acme/api", repos[0].PathWithNamespace)
+	assert.Equal(t, "globex/web", repos[1].PathWithNamespace)
+	assert.Equal(t, "all", server.Requests()[0].Query.Get("type"))
+}
+
+func TestClient_ListRepositories_UnknownOrganization(t *testing.T) {
+	_, client := newFakeClient(t)
+
+	_, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{Organizations: []string{"missing"}})
+
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+	assert.Contains(t, err.Error(), "failed to list repositories of organization missing")
+}
+
+func TestClient_ListRepositories_LastActivityAfter(t *testing.T) {
+	server, client := newFakeClient(t)
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "active", Namespace: models.Namespace{Path: "acme"}, LastActivityAt: since.Add(time.Hour)},
+		{ID: 2, Name: "stale", Namespace: models.Namespace{Path: "acme"}, LastActivityAt: since.Add(-time.Hour)},
+	})
+
+	repos, err := client.ListRepositories(context.Background(), ListRepositoriesOptions{LastActivityAfter: since})
+
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "active", repos[0].Name)
+
+	// Filtered repositories are still known by ID
+	name, err := client.fullName(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "acme/stale", name)
+	assert.Len(t, server.Requests(), 1)
+}
+
+func TestClient_FullNameLookup(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 42, Name: "api", Namespace: models.Namespace{Path: "acme"}}})
+
+	name, err := client.fullName(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, "acme/api", name)
+
+	// The name is looked up once
+	_, err = client.fullName(context.Background(), 42)
+	require.NoError(t, err)
+	require.Len(t, server.Requests(), 1)
+	assert.Equal(t, "/repositories/42", server.Requests()[0].Path)
+
+	_, err = client.fullName(context.Background(), 99)
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestClient_BranchProtected(t *testing.T) {
+	server, client := newFakeClient(t)
+	server.SetRepositories([]models.Repository{{ID: 7, Name: "app", Namespace: models.Namespace{Path: "acme"}}})
+	server.ProtectBranch(7, "master")
+	server.ProtectBranch(7, "release/1.2")
+	ctx := context.Background()
+
+	protected, err := client.BranchProtected(ctx, 7, "master")
+	require.NoError(t, err)
+	assert.True(t, protected)
+
+	protected, err = client.BranchProtected(ctx, 7, "develop")
+	require.NoError(t, err)
+	assert.False(t, protected)
+
+	// Branch names may contain slashes
+	protected, err = client.BranchProtected(ctx, 7, "release/1.2")
+	require.NoError(t, err)
+	assert.True(t, protected)
+}
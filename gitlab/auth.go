@@ -0,0 +1,339 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/redact"
+)
+
+// Authenticator adds credentials to GitLab API requests
+type Authenticator interface {
+	// Authenticate sets the credential headers on a request
+	Authenticate(req *http.Request) error
+	// Refresh renews the credentials after req was rejected with 401; it reports whether
+	// the request is worth retrying
+	Refresh(ctx context.Context, req *http.Request) (bool, error)
+}
+
+// WithAuth replaces the personal access token authentication of the client
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// TokenAuth authenticates with a personal, group or project access token
+type TokenAuth struct {
+	token string
+}
+
+// NewTokenAuth creates an authenticator for an access token
+func NewTokenAuth(token string) *TokenAuth {
+	redact.AddSecret(token)
+	return &TokenAuth{token: token}
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// Refresh implements Authenticator; access tokens cannot be renewed
+func (a *TokenAuth) Refresh(ctx context.Context, req *http.Request) (bool, error) {
+	return false, nil
+}
+
+// JobTokenAuth authenticates with the CI_JOB_TOKEN of a GitLab CI job
+type JobTokenAuth struct {
+	token string
+}
+
+// NewJobTokenAuth creates an authenticator for a CI job token
+func NewJobTokenAuth(token string) *JobTokenAuth {
+	redact.AddSecret(token)
+	return &JobTokenAuth{token: token}
+}
+
+// Authenticate implements Authenticator
+func (a *JobTokenAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("JOB-TOKEN", a.token)
+	return nil
+}
+
+// Refresh implements Authenticator; job tokens live as long as the job
+func (a *JobTokenAuth) Refresh(ctx context.Context, req *http.Request) (bool, error) {
+	return false, nil
+}
+
+// OAuth2Token is an OAuth2 access token issued by GitLab
+type OAuth2Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	CreatedAt    int64  `json:"created_at,omitempty"` // Unix seconds
+}
+
+// Expired reports whether the access token has expired at now
+func (t *OAuth2Token) Expired(now time.Time) bool {
+	if t.ExpiresIn == 0 || t.CreatedAt == 0 {
+		return false
+	}
+	return !now.Before(time.Unix(t.CreatedAt+int64(t.ExpiresIn), 0))
+}
+
+// LoadOAuth2Token reads a token saved by SaveOAuth2Token
+func LoadOAuth2Token(path string) (*OAuth2Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth token file: %w", err)
+	}
+	var token OAuth2Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth token file: %w", err)
+	}
+	redact.AddSecret(token.AccessToken)
+	redact.AddSecret(token.RefreshToken)
+	return &token, nil
+}
+
+// SaveOAuth2Token writes a token readable only by the current user
+func SaveOAuth2Token(path string, token *OAuth2Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode OAuth token: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create OAuth token directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write OAuth token file: %w", err)
+	}
+	return nil
+}
+
+// OAuth2Auth authenticates with an OAuth2 access token and renews it with the refresh token
+type OAuth2Auth struct {
+	baseURL    string
+	clientID   string
+	httpClient *http.Client
+	onRefresh  func(*OAuth2Token) error
+
+	mu    sync.Mutex
+	token *OAuth2Token
+}
+
+// NewOAuth2Auth creates an authenticator for an OAuth2 token; onRefresh, if set, is called
+// with every renewed token so it can be persisted
+func NewOAuth2Auth(baseURL, clientID string, token *OAuth2Token, httpClient *http.Client, onRefresh func(*OAuth2Token) error) *OAuth2Auth {
+	redact.AddSecret(token.AccessToken)
+	redact.AddSecret(token.RefreshToken)
+	return &OAuth2Auth{
+		baseURL:    normalizeBaseURL(baseURL),
+		clientID:   clientID,
+		httpClient: httpClient,
+		onRefresh:  onRefresh,
+		token:      token,
+	}
+}
+
+// Authenticate implements Authenticator
+func (a *OAuth2Auth) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	req.Header.Set("Authorization", "Bearer "+a.token.AccessToken)
+	return nil
+}
+
+// Refresh implements Authenticator by exchanging the refresh token for a new access token
+func (a *OAuth2Auth) Refresh(ctx context.Context, req *http.Request) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Another request already renewed the token this one was sent with
+	if req.Header.Get("Authorization") != "Bearer "+a.token.AccessToken {
+		return true, nil
+	}
+	if a.token.RefreshToken == "" {
+		return false, nil
+	}
+
+	token, err := requestToken(ctx, a.httpClient, a.baseURL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {a.token.RefreshToken},
+		"client_id":     {a.clientID},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh OAuth token: %w", err)
+	}
+	a.token = token
+
+	if a.onRefresh != nil {
+		if err := a.onRefresh(token); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Token returns the current OAuth2 token
+func (a *OAuth2Auth) Token() *OAuth2Token {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.token
+}
+
+// oauthError is the error body of the GitLab OAuth endpoints
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// requestToken posts a grant to the GitLab token endpoint
+func requestToken(ctx context.Context, httpClient *http.Client, baseURL string, form url.Values) (*OAuth2Token, error) {
+	resp, err := postForm(ctx, httpClient, baseURL+"/oauth/token", form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token OAuth2Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.CreatedAt == 0 {
+		token.CreatedAt = time.Now().Unix()
+	}
+	redact.AddSecret(token.AccessToken)
+	redact.AddSecret(token.RefreshToken)
+	return &token, nil
+}
+
+// postForm posts a form and turns OAuth error responses into *oauthError
+func postForm(ctx context.Context, httpClient *http.Client, endpoint string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		var oauthErr oauthError
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Code != "" {
+			return nil, &oauthErr
+		}
+		return nil, &httpapi.APIError{StatusCode: resp.StatusCode, Message: redact.String(string(body))}
+	}
+	return resp, nil
+}
+
+// DeviceCode is the code the user enters to authorize a device flow
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+var (
+	// devicePollInterval is used when the server does not say how often to poll
+	devicePollInterval = 5 * time.Second
+	// deviceSlowDown is added to the poll interval when the server asks to slow down
+	deviceSlowDown = 5 * time.Second
+)
+
+// ErrDeviceAuthorizationDenied is returned when the user declines the device authorization
+var ErrDeviceAuthorizationDenied = errors.New("device authorization was denied")
+
+// DeviceAuthorization runs the OAuth2 device authorization grant: it shows the user code through
+// prompt and waits until the user approves it in the browser
+func DeviceAuthorization(ctx context.Context, httpClient *http.Client, baseURL, clientID string, scopes []string, prompt func(DeviceCode)) (*OAuth2Token, error) {
+	baseURL = normalizeBaseURL(baseURL)
+
+	resp, err := postForm(ctx, httpClient, baseURL+"/oauth/authorize_device", url.Values{
+		"client_id": {clientID},
+		"scope":     {strings.Join(scopes, " ")},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device authorization: %w", err)
+	}
+	var code DeviceCode
+	err = json.NewDecoder(resp.Body).Decode(&code)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode device authorization response: %w", err)
+	}
+
+	prompt(code)
+
+	interval := devicePollInterval
+	if code.Interval > 0 {
+		interval = time.Duration(code.Interval) * time.Second
+	}
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	// Poll until the user approves or denies the request
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("device authorization was not completed: %w", ctx.Err())
+		}
+
+		token, err := requestToken(ctx, httpClient, baseURL, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {code.DeviceCode},
+			"client_id":   {clientID},
+		})
+		if err == nil {
+			return token, nil
+		}
+
+		var oauthErr *oauthError
+		if !errors.As(err, &oauthErr) {
+			return nil, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += deviceSlowDown
+		case "access_denied":
+			return nil, ErrDeviceAuthorizationDenied
+		default:
+			return nil, fmt.Errorf("device authorization failed: %w", err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer group-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "group-access-token")
+	defer client.Close()
+	assert.NoError(t, client.TestConnection(context.Background()))
+
+	wrong := NewClient(server.URL, "wrong-token")
+	defer wrong.Close()
+	err := wrong.TestConnection(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authentication failed: invalid token")
+}
+
+func TestJobTokenAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "ci-job-token-value", r.Header.Get("JOB-TOKEN"))
+		// Job tokens can only read their own job
+		assert.Equal(t, "/api/v4/job", r.URL.Path)
+		w.Write([]byte(`{"id":99}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", WithAuth(NewJobTokenAuth("ci-job-token-value")))
+	defer client.Close()
+
+	assert.NoError(t, client.TestConnection(context.Background()))
+}
+
+// newOAuthServer fakes the GitLab API and token endpoint, accepting only the latest access token
+func newOAuthServer(t *testing.T) (*httptest.Server, *int32) {
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+			assert.Equal(t, "old-refresh-token", r.Form.Get("refresh_token"))
+			assert.Equal(t, "client-id", r.Form.Get("client_id"))
+			atomic.AddInt32(&refreshes, 1)
+			w.Write([]byte(`{"access_token":"new-access-token","refresh_token":"new-refresh-token","token_type":"Bearer","expires_in":7200,"created_at":1700000000}`))
+		default:
+			if r.Header.Get("Authorization") != "Bearer new-access-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"id":1}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &refreshes
+}
+
+func TestOAuth2Auth_RefreshesOnUnauthorized(t *testing.T) {
+	server, refreshes := newOAuthServer(t)
+
+	var saved *OAuth2Token
+	auth := NewOAuth2Auth(server.URL, "client-id",
+		&OAuth2Token{AccessToken: "old-access-token", RefreshToken: "old-refresh-token"},
+		server.Client(), func(token *OAuth2Token) error {
+			saved = token
+			return nil
+		})
+
+	client := NewClient(server.URL, "", WithAuth(auth))
+	defer client.Close()
+
+	require.NoError(t, client.TestConnection(context.Background()))
+	require.NoError(t, client.TestConnection(context.Background()))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(refreshes))
+	require.NotNil(t, saved)
+	assert.Equal(t, "new-refresh-token", saved.RefreshToken)
+	assert.Equal(t, "new-access-token", auth.Token().AccessToken)
+}
+
+func TestOAuth2Auth_RefreshBypassesReadOnly(t *testing.T) {
+	server, refreshes := newOAuthServer(t)
+
+	httpClient := server.Client()
+	auth := NewOAuth2Auth(server.URL, "client-id",
+		&OAuth2Token{AccessToken: "old-access-token", RefreshToken: "old-refresh-token"}, httpClient, nil)
+
+	client := NewClient(server.URL, "", WithReadOnly(), WithHTTPClient(httpClient), WithAuth(auth))
+	defer client.Close()
+
+	require.NoError(t, client.TestConnection(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(refreshes))
+	assert.Empty(t, client.SkippedWrites())
+}
+
+func TestOAuth2Auth_NoRefreshToken(t *testing.T) {
+	server, refreshes := newOAuthServer(t)
+
+	auth := NewOAuth2Auth(server.URL, "client-id", &OAuth2Token{AccessToken: "old-access-token"}, server.Client(), nil)
+	client := NewClient(server.URL, "", WithAuth(auth))
+	defer client.Close()
+
+	err := client.TestConnection(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authentication failed: invalid token")
+	assert.Zero(t, atomic.LoadInt32(refreshes))
+}
+
+func TestOAuth2Token_Expired(t *testing.T) {
+	token := &OAuth2Token{ExpiresIn: 7200, CreatedAt: 1700000000}
+
+	assert.False(t, token.Expired(time.Unix(1700000000+3600, 0)))
+	assert.True(t, token.Expired(time.Unix(1700000000+7200, 0)))
+	assert.False(t, (&OAuth2Token{}).Expired(time.Now()))
+}
+
+func TestSaveAndLoadOAuth2Token(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "oauth-token.json")
+	token := &OAuth2Token{AccessToken: "saved-access-token", RefreshToken: "saved-refresh-token", ExpiresIn: 7200, CreatedAt: 1700000000}
+
+	require.NoError(t, SaveOAuth2Token(path, token))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadOAuth2Token(path)
+	require.NoError(t, err)
+	assert.Equal(t, token, loaded)
+
+	_, err = LoadOAuth2Token(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+// useFastDevicePolling shortens the device flow polling for the duration of a test
+func useFastDevicePolling(t *testing.T) {
+	interval, slowDown := devicePollInterval, deviceSlowDown
+	devicePollInterval, deviceSlowDown = time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		devicePollInterval, deviceSlowDown = interval, slowDown
+	})
+}
+
+func TestDeviceAuthorization(t *testing.T) {
+	useFastDevicePolling(t)
+
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/oauth/authorize_device":
+			assert.Equal(t, "client-id", r.Form.Get("client_id"))
+			assert.Equal(t, "read_api", r.Form.Get("scope"))
+			w.Write([]byte(`{"device_code":"device-code","user_code":"ABCD-EFGH","verification_uri":"https://gitlab.example.com/oauth/device","expires_in":300}`))
+		case "/oauth/token":
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.Form.Get("grant_type"))
+			assert.Equal(t, "device-code", r.Form.Get("device_code"))
+			switch atomic.AddInt32(&polls, 1) {
+			case 1:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+			case 2:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"slow_down"}`))
+			default:
+				w.Write([]byte(`{"access_token":"device-access-token","refresh_token":"device-refresh-token","expires_in":7200}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	var prompted DeviceCode
+	token, err := DeviceAuthorization(context.Background(), server.Client(), server.URL+"/api/v4", "client-id", []string{"read_api"}, func(code DeviceCode) {
+		prompted = code
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ABCD-EFGH", prompted.UserCode)
+	assert.Equal(t, "https://gitlab.example.com/oauth/device", prompted.VerificationURI)
+	assert.Equal(t, "device-access-token", token.AccessToken)
+	assert.Equal(t, "device-refresh-token", token.RefreshToken)
+	assert.NotZero(t, token.CreatedAt)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&polls))
+}
+
+func TestDeviceAuthorization_Denied(t *testing.T) {
+	useFastDevicePolling(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/oauth/authorize_device" {
+			w.Write([]byte(`{"device_code":"device-code","user_code":"ABCD-EFGH","verification_uri":"https://gitlab.example.com/oauth/device"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"access_denied","error_description":"The resource owner denied the request"}`))
+	}))
+	defer server.Close()
+
+	_, err := DeviceAuthorization(context.Background(), server.Client(), server.URL, "client-id", nil, func(DeviceCode) {})
+	assert.ErrorIs(t, err, ErrDeviceAuthorizationDenied)
+}
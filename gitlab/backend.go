@@ -0,0 +1,22 @@
+package gitlab
+
+import (
+	"context"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// Backend is the read access to GitLab the scanner and analyzer need; Client implements it with the
+// REST API and GraphQLBackend with the GraphQL API
+type Backend interface {
+	ListRepositories(ctx context.Context) ([]models.Repository, error)
+	ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error)
+	ListMergeRequests(ctx context.Context, projectID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error)
+	ListMergeRequestsWithOptions(ctx context.Context, projectID int, opts MergeRequestListOptions) ([]models.MergeRequest, error)
+	GetMergeRequestChanges(ctx context.Context, projectID, mrID int) (int, error)
+}
+
+var (
+	_ Backend = (*Client)(nil)
+	_ Backend = (*GraphQLBackend)(nil)
+)
@@ -0,0 +1,351 @@
+package gitlab
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups endpoints that share a cache time-to-live
+type EndpointClass int
+
+const (
+	EndpointOther EndpointClass = iota
+	EndpointProjects
+	EndpointMergeRequests
+	EndpointChanges
+)
+
+// CacheTTLs sets how long cached responses are reused without asking GitLab; once expired,
+// a response is revalidated with its ETag and reused if GitLab answers 304 Not Modified
+type CacheTTLs struct {
+	Projects      time.Duration // Project lists, including those of groups
+	MergeRequests time.Duration // Merge request lists
+	Changes       time.Duration // Merge request changes
+	Other         time.Duration // Every other GET request
+}
+
+// DefaultCacheTTLs reuses project lists for an hour and changes for 15 minutes, and revalidates
+// everything else on each request so that merge request states are never stale
+var DefaultCacheTTLs = CacheTTLs{
+	Projects: time.Hour,
+	Changes:  15 * time.Minute,
+}
+
+// DefaultCacheMaxAge keeps entries for a day after they were last stored or revalidated, so that
+// daily scans can still revalidate them
+const DefaultCacheMaxAge = 24 * time.Hour
+
+// CacheOptions configures a response cache
+type CacheOptions struct {
+	Dir     string        // Directory holding the cached responses
+	TTLs    CacheTTLs     // Time-to-live of each endpoint class
+	Refresh bool          // Ignore cached responses but store the new ones
+	MaxAge  time.Duration // Entries older than this are removed when the cache opens; DefaultCacheMaxAge if zero, and never less than the longest TTL
+}
+
+// CacheStats counts how requests were answered
+type CacheStats struct {
+	Hits        int // Answered from the cache without a request
+	Revalidated int // Answered from the cache after a 304 Not Modified
+	Misses      int // Fetched in full
+}
+
+// Cache stores GitLab GET responses on disk with their ETags
+type Cache struct {
+	dir     string
+	ttls    CacheTTLs
+	refresh bool
+	now     func() time.Time
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// cacheEntry is a cached response as stored on disk
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// volatileParams are query parameters that change on every run, such as the cutoffs of incremental
+// scans; requests with them are never answered again, so they are not cached
+var volatileParams = []string{"last_activity_after", "updated_after"}
+
+// cachedHeaders are the response headers kept in the cache
+var cachedHeaders = []string{"Content-Type", "ETag", "Link", "X-Next-Page", "X-Page", "X-Per-Page", "X-Prev-Page", "X-Total", "X-Total-Pages"}
+
+// NewCache creates a response cache in opts.Dir, creating the directory if needed
+func NewCache(opts CacheOptions) (*Cache, error) {
+	// Cached responses hold private project data
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	cache := &Cache{dir: opts.Dir, ttls: opts.TTLs, refresh: opts.Refresh, now: time.Now}
+	cache.prune(opts.MaxAge)
+	return cache, nil
+}
+
+// longestTTL returns the longest time-to-live of the endpoint classes
+func (t CacheTTLs) longestTTL() time.Duration {
+	longest := t.Other
+	for _, ttl := range []time.Duration{t.Projects, t.MergeRequests, t.Changes} {
+		if ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest
+}
+
+// prune removes the entries that were not stored or revalidated within maxAge, as well as
+// temporary files left by interrupted writes
+func (c *Cache) prune(maxAge time.Duration) {
+	if maxAge == 0 {
+		maxAge = DefaultCacheMaxAge
+	}
+	if longest := c.ttls.longestTTL(); maxAge < longest {
+		maxAge = longest
+	}
+
+	cutoff := c.now().Add(-maxAge)
+	removed := 0
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().Before(cutoff) && os.Remove(path) == nil {
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Debug("Failed to prune cache", "dir", c.dir, "error", err)
+	}
+	if removed > 0 {
+		slog.Debug("Pruned cache entries", "dir", c.dir, "removed", removed, "max_age", maxAge)
+	}
+}
+
+// WithCache answers GET requests from the response cache when possible
+func WithCache(cache *Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// Stats returns how the requests made so far were answered
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// ClassifyEndpoint returns the cache class of a request path
+func ClassifyEndpoint(path string) EndpointClass {
+	switch {
+	case strings.HasSuffix(path, "/changes"):
+		return EndpointChanges
+	case strings.HasSuffix(path, "/merge_requests"):
+		return EndpointMergeRequests
+	case strings.HasSuffix(path, "/api/v4/projects"),
+		strings.Contains(path, "/api/v4/groups/") && strings.HasSuffix(path, "/projects"):
+		return EndpointProjects
+	default:
+		return EndpointOther
+	}
+}
+
+// ttl returns the time-to-live of a request path
+func (c *Cache) ttl(path string) time.Duration {
+	switch ClassifyEndpoint(path) {
+	case EndpointProjects:
+		return c.ttls.Projects
+	case EndpointMergeRequests:
+		return c.ttls.MergeRequests
+	case EndpointChanges:
+		return c.ttls.Changes
+	default:
+		return c.ttls.Other
+	}
+}
+
+// count records how a request was answered
+func (c *Cache) count(field *int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*field++
+}
+
+// key identifies a cached response; the credentials are part of it because what GitLab
+// returns depends on who asks, and are hashed so that no token reaches the disk
+func key(req *http.Request) string {
+	hash := sha256.New()
+	for _, header := range []string{"Authorization", "Private-Token", "Job-Token"} {
+		fmt.Fprintf(hash, "%s\n", req.Header.Get(header))
+	}
+	fmt.Fprintf(hash, "%s %s", req.Method, req.URL.String())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// path returns the file of a cache key
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// load reads a cached response, returning nil if there is none or it cannot be read
+func (c *Cache) load(key string) *cacheEntry {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		slog.Debug("Ignoring unreadable cache entry", "file", c.path(key), "error", err)
+		return nil
+	}
+	return &entry
+}
+
+// store writes a response to the cache, replacing the file atomically
+func (c *Cache) store(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		slog.Debug("Failed to create cache directory", "error", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		slog.Debug("Failed to write cache entry", "error", err)
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+		slog.Debug("Failed to write cache entry", "file", path)
+	}
+}
+
+// cacheTransport answers GET requests from a Cache and sends the rest through next
+type cacheTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+// RoundTrip serves fresh cached responses, revalidates stale ones and stores new ones
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || hasVolatileParams(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	key := key(req)
+	var entry *cacheEntry
+	if !t.cache.refresh {
+		entry = t.cache.load(key)
+	}
+
+	// Fresh entries are reused without asking GitLab
+	if entry != nil && t.cache.now().Sub(entry.StoredAt) < t.cache.ttl(req.URL.Path) {
+		t.cache.count(&t.cache.stats.Hits)
+		return entry.response(req), nil
+	}
+
+	// Stale entries are revalidated with their ETag
+	if etag := entryETag(entry); etag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		entry.StoredAt = t.cache.now()
+		t.cache.store(key, entry)
+		t.cache.count(&t.cache.stats.Revalidated)
+		return entry.response(req), nil
+	}
+	t.cache.count(&t.cache.stats.Misses)
+
+	// Only successful responses that can be reused or revalidated are worth storing
+	reusable := resp.Header.Get("ETag") != "" || t.cache.ttl(req.URL.Path) > 0
+	if resp.StatusCode != http.StatusOK || !reusable || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	stored := &cacheEntry{URL: req.URL.String(), StatusCode: resp.StatusCode, Header: http.Header{}, Body: body, StoredAt: t.cache.now()}
+	for _, name := range cachedHeaders {
+		if values, ok := resp.Header[http.CanonicalHeaderKey(name)]; ok {
+			stored.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	t.cache.store(key, stored)
+
+	return resp, nil
+}
+
+// hasVolatileParams reports whether a request has a query parameter that changes on every run
+func hasVolatileParams(req *http.Request) bool {
+	query := req.URL.Query()
+	for _, param := range volatileParams {
+		if query.Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryETag returns the ETag of a cached response, or "" without one
+func entryETag(entry *cacheEntry) string {
+	if entry == nil {
+		return ""
+	}
+	return entry.Header.Get("ETag")
+}
+
+// response rebuilds an HTTP response from a cached entry
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
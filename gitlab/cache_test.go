@@ -0,0 +1,276 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+)
+
+// newCachedClient creates a client for the fake server that caches responses in dir
+func newCachedClient(t *testing.T, server *gitlabfake.Server, opts CacheOptions) (*Client, *Cache) {
+	t.Helper()
+	cache, err := NewCache(opts)
+	require.NoError(t, err)
+	client := NewClient(server.URL(), gitlabfake.DefaultToken, WithCache(cache))
+	t.Cleanup(client.Close)
+	return client, cache
+}
+
+func TestCache_FreshResponseSkipsRequest(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(3))
+
+	client, cache := newCachedClient(t, server, CacheOptions{Dir: t.TempDir(), TTLs: CacheTTLs{Projects: time.Hour}})
+
+	for i := 0; i < 2; i++ {
+		repos, err := client.ListRepositories(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, repos, 3)
+	}
+
+	assert.Equal(t, 1, countRequests(server, "/api/v4/projects"))
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, cache.Stats())
+}
+
+func TestCache_RevalidatesStaleResponse(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetMergeRequests(1, []models.MergeRequest{{ID: 1, SourceBranch: "release", TargetBranch: "master"}})
+
+	client, cache := newCachedClient(t, server, CacheOptions{Dir: t.TempDir()})
+
+	first, err := client.ListMergeRequests(context.Background(), 1, "release", "master")
+	require.NoError(t, err)
+	second, err := client.ListMergeRequests(context.Background(), 1, "release", "master")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, CacheStats{Revalidated: 1, Misses: 1}, cache.Stats())
+
+	requests := server.Requests()
+	require.Len(t, requests, 2)
+	assert.Empty(t, requests[0].Header.Get("If-None-Match"))
+	assert.NotEmpty(t, requests[1].Header.Get("If-None-Match"))
+}
+
+func TestCache_RefetchesChangedResponse(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetMergeRequests(1, []models.MergeRequest{{ID: 1, SourceBranch: "release", TargetBranch: "master"}})
+
+	client, cache := newCachedClient(t, server, CacheOptions{Dir: t.TempDir()})
+
+	_, err := client.ListMergeRequests(context.Background(), 1, "release", "master")
+	require.NoError(t, err)
+
+	server.AddMergeRequest(1, models.MergeRequest{ID: 2, SourceBranch: "release", TargetBranch: "master"})
+
+	mrs, err := client.ListMergeRequests(context.Background(), 1, "release", "master")
+	require.NoError(t, err)
+	assert.Len(t, mrs, 2)
+	assert.Equal(t, CacheStats{Misses: 2}, cache.Stats())
+}
+
+func TestCache_ExpiredResponseIsRevalidated(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(3))
+
+	client, cache := newCachedClient(t, server, CacheOptions{Dir: t.TempDir(), TTLs: CacheTTLs{Projects: time.Hour}})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	_, err := client.ListRepositories(context.Background())
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = client.ListRepositories(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, countRequests(server, "/api/v4/projects"))
+	assert.Equal(t, CacheStats{Revalidated: 1, Misses: 1}, cache.Stats())
+}
+
+func TestCache_Refresh(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(3))
+	dir := t.TempDir()
+
+	client, _ := newCachedClient(t, server, CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}})
+	_, err := client.ListRepositories(context.Background())
+	require.NoError(t, err)
+
+	// A refresh ignores the fresh entry and sends no If-None-Match
+	refreshing, cache := newCachedClient(t, server, CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}, Refresh: true})
+	_, err = refreshing.ListRepositories(context.Background())
+	require.NoError(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 2)
+	assert.Empty(t, requests[1].Header.Get("If-None-Match"))
+	assert.Equal(t, CacheStats{Misses: 1}, cache.Stats())
+
+	// The refetched response is stored for the next run
+	client, cache = newCachedClient(t, server, CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}})
+	_, err = client.ListRepositories(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 1}, cache.Stats())
+}
+
+func TestCache_WritesAreNotCached(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetMergeRequests(1, []models.MergeRequest{{ID: 1, SourceBranch: "release", TargetBranch: "master"}})
+
+	client, cache := newCachedClient(t, server, CacheOptions{Dir: t.TempDir(), TTLs: CacheTTLs{Other: time.Hour}})
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, client.RebaseMergeRequest(context.Background(), 1, 1))
+	}
+
+	assert.Equal(t, 2, countRequests(server, "/api/v4/projects/1/merge_requests/1/rebase"))
+	assert.Equal(t, CacheStats{}, cache.Stats())
+}
+
+func TestCache_KeepsTokensOffDisk(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(3))
+	dir := t.TempDir()
+
+	client, _ := newCachedClient(t, server, CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}})
+	_, err := client.ListRepositories(context.Background())
+	require.NoError(t, err)
+
+	files := 0
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files++
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), gitlabfake.DefaultToken)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, files)
+}
+
+func TestCache_IncrementalScansAreNotCached(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(3))
+	dir := t.TempDir()
+
+	client, cache := newCachedClient(t, server, CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}})
+	for i := 0; i < 2; i++ {
+		_, err := client.ListRepositoriesWithOptions(context.Background(), ListRepositoriesOptions{LastActivityAfter: time.Now().Add(-time.Hour)})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, countRequests(server, "/api/v4/projects"))
+	assert.Equal(t, CacheStats{}, cache.Stats())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCache_PrunesOldEntries(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(3))
+	server.SetMergeRequests(1, []models.MergeRequest{{ID: 1, SourceBranch: "release", TargetBranch: "master"}})
+	dir := t.TempDir()
+
+	client, _ := newCachedClient(t, server, CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}})
+	_, err := client.ListRepositories(context.Background())
+	require.NoError(t, err)
+	_, err = client.ListMergeRequests(context.Background(), 1, "release", "master")
+	require.NoError(t, err)
+
+	var files []string
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	// Age one entry past the maximum age
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+
+	_, err = NewCache(CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}, MaxAge: 90 * time.Minute})
+	require.NoError(t, err)
+
+	_, err = os.Stat(files[0])
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(files[1])
+	assert.NoError(t, err)
+}
+
+func TestCache_PruningKeepsEntriesWithinTheLongestTTL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ab", "abcdef.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0600))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	// The maximum age is raised to the longest TTL, so the entry is still fresh
+	_, err := NewCache(CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: 3 * time.Hour}, MaxAge: time.Hour})
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+
+	_, err = NewCache(CacheOptions{Dir: dir, TTLs: CacheTTLs{Projects: time.Hour}, MaxAge: time.Hour})
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCacheKey_DependsOnCredentials(t *testing.T) {
+	request := func(token string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "https://gitlab.example.com/api/v4/projects", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	assert.Equal(t, key(request("alice")), key(request("alice")))
+	assert.NotEqual(t, key(request("alice")), key(request("bob")))
+}
+
+func TestClassifyEndpoint(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected EndpointClass
+	}{
+		{"/api/v4/projects", EndpointProjects},
+		{"/api/v4/groups/platform%2Fbackend/projects", EndpointProjects},
+		{"/api/v4/projects/42/merge_requests", EndpointMergeRequests},
+		{"/api/v4/projects/42/merge_requests/7/changes", EndpointChanges},
+		{"/api/v4/projects/42/merge_requests/7", EndpointOther},
+		{"/api/v4/user", EndpointOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyEndpoint(tt.path))
+		})
+	}
+}
@@ -4,43 +4,85 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
+	"mr-conflict-checker/internal/httpapi"
 	"mr-conflict-checker/internal/models"
 )
 
 // Client represents a GitLab API client
 type Client struct {
-	baseURL     string
+	api         *httpapi.Client
 	token       string
 	httpClient  *http.Client
 	rateLimiter *time.Ticker
+	readOnly    bool
+	cache       *Cache
+	auth        Authenticator
 }
 
-// NewClient creates a new GitLab API client with authentication and rate limiting
-func NewClient(baseURL, token string) *Client {
-	// Remove trailing slash from baseURL if present
-	baseURL = strings.TrimSuffix(baseURL, "/")
+// Option configures optional Client behaviour
+type Option func(*Client)
 
-	// If baseURL already contains /api/v4, remove it since we'll add it in endpoints
-	if strings.HasSuffix(baseURL, "/api/v4") {
-		baseURL = strings.TrimSuffix(baseURL, "/api/v4")
-	}
-
-	return &Client{
-		baseURL: baseURL,
-		token:   token,
+// NewClient creates a new GitLab API client with authentication and rate limiting
+func NewClient(baseURL, token string, opts ...Option) *Client {
+	client := &Client{
+		token: token,
+		auth:  NewTokenAuth(token),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultTimeout,
 		},
 		// Rate limit to 10 requests per second to be conservative with GitLab API
 		rateLimiter: time.NewTicker(100 * time.Millisecond),
 	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	// The cache sits below the read-only check, on a copy of the HTTP client like it
+	httpClient := client.httpClient
+	if client.cache != nil {
+		next := httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		cached := *httpClient
+		cached.Transport = &cacheTransport{cache: client.cache, next: next}
+		httpClient = &cached
+	}
+
+	// The read-only check wraps whatever transport the options installed, on a copy of the HTTP
+	// client so that clients shared with the authenticator can still write
+	client.api = httpapi.NewClient(httpapi.Config{
+		Service:    "GitLab",
+		BaseURL:    normalizeBaseURL(baseURL),
+		HTTPClient: httpClient,
+		ReadOnly:   client.readOnly,
+		ReadOnlyPolicy: httpapi.ReadOnlyPolicy{
+			Reads:    isGraphQLQuery,
+			Describe: describeWrite,
+		},
+		SetHeaders:    client.auth.Authenticate,
+		Refresh:       client.auth.Refresh,
+		Throttle:      client.throttle,
+		CheckResponse: checkRateLimit,
+		ErrorMessage:  func(body []byte) string { return string(body) },
+	})
+
+	return client
+}
+
+// normalizeBaseURL strips the trailing slash and /api/v4 suffix from a GitLab URL
+func normalizeBaseURL(baseURL string) string {
+	// Remove trailing slash from baseURL if present
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	// If baseURL already contains /api/v4, remove it since we'll add it in endpoints
+	return strings.TrimSuffix(baseURL, "/api/v4")
 }
 
 // Close cleans up the client resources
@@ -50,148 +92,109 @@ func (c *Client) Close() {
 	}
 }
 
-// makeRequest performs an authenticated HTTP request with rate limiting
-func (c *Client) makeRequest(ctx context.Context, method, endpoint string) (*http.Response, error) {
-	// Wait for rate limiter
+// throttle waits for the rate limiter
+func (c *Client) throttle(ctx context.Context) error {
 	select {
 	case <-c.rateLimiter.C:
-		// Continue with request
+		return nil
 	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	// Construct full URL
-	fullURL := c.baseURL + endpoint
-
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add authentication header
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Make request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-
-	// Check for authentication errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		resp.Body.Close()
-		return nil, fmt.Errorf("authentication failed: invalid token")
+		return ctx.Err()
 	}
+}
 
-	// Check for rate limiting
+// checkRateLimit fails the responses GitLab answers with 429 once the rate limit is used up
+func checkRateLimit(resp *http.Response) error {
 	if resp.StatusCode == http.StatusTooManyRequests {
-		resp.Body.Close()
-		return nil, fmt.Errorf("rate limit exceeded")
-	}
-
-	// Check for other client/server errors
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("rate limit exceeded")
 	}
+	return nil
+}
 
-	return resp, nil
+// ListRepositoriesOptions filters the repositories returned by ListRepositoriesWithOptions
+type ListRepositoriesOptions struct {
+	LastActivityAfter time.Time // Only repositories with activity after this time, when set
 }
 
-// ListRepositories retrieves all repositories accessible to the authenticated user with pagination
+// ListRepositories retrieves all repositories accessible to the authenticated user
 func (c *Client) ListRepositories(ctx context.Context) ([]models.Repository, error) {
-	var allRepos []models.Repository
-	page := 1
-	perPage := 100 // GitLab API default max per page
-
-	for {
-		// Construct endpoint with pagination parameters
-		endpoint := fmt.Sprintf("/api/v4/projects?membership=true&page=%d&per_page=%d", page, perPage)
-
-		resp, err := c.makeRequest(ctx, "GET", endpoint)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories (page %d): %w", page, err)
-		}
-
-		// Parse response body
-		var repos []models.Repository
-		if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode repositories response: %w", err)
-		}
-		resp.Body.Close()
-
-		// Add to results
-		allRepos = append(allRepos, repos...)
+	return c.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{})
+}
 
-		// Check if we've reached the end (no more pages)
-		if len(repos) < perPage {
-			break
-		}
+// ListRepositoriesWithOptions retrieves the repositories accessible to the authenticated user, using
+// the keyset pagination GitLab requires for large offsets
+func (c *Client) ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	params := url.Values{}
+	params.Set("membership", "true")
+	params.Set("pagination", "keyset")
+	params.Set("order_by", "id")
+	params.Set("sort", "asc")
+	if !opts.LastActivityAfter.IsZero() {
+		params.Set("last_activity_after", opts.LastActivityAfter.UTC().Format(time.RFC3339))
+	}
 
-		page++
+	repos, err := paginate[models.Repository](ctx, c, "/api/v4/projects?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
 	}
 
-	return allRepos, nil
+	return repos, nil
+}
+
+// MergeRequestListOptions filters the merge requests returned by ListMergeRequestsWithOptions
+type MergeRequestListOptions struct {
+	State        string // Defaults to "opened"
+	SourceBranch string
+	TargetBranch string
+	Labels       []string  // Only merge requests carrying all of these labels
+	UpdatedAfter time.Time // Only merge requests updated after this time, when set
 }
 
 // ListMergeRequests retrieves merge requests for a specific repository with filtering
 func (c *Client) ListMergeRequests(ctx context.Context, projectID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error) {
-	var allMRs []models.MergeRequest
-	page := 1
-	perPage := 100
-
-	for {
-		// Construct endpoint with filtering parameters
-		params := url.Values{}
-		params.Set("state", "opened")
-		params.Set("page", strconv.Itoa(page))
-		params.Set("per_page", strconv.Itoa(perPage))
-
-		if sourceBranch != "" {
-			params.Set("source_branch", sourceBranch)
-		}
-		if targetBranch != "" {
-			params.Set("target_branch", targetBranch)
-		}
-
-		endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests?%s", projectID, params.Encode())
-
-		resp, err := c.makeRequest(ctx, "GET", endpoint)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list merge requests for project %d (page %d): %w", projectID, page, err)
-		}
+	return c.ListMergeRequestsWithOptions(ctx, projectID, MergeRequestListOptions{
+		SourceBranch: sourceBranch,
+		TargetBranch: targetBranch,
+	})
+}
 
-		// Parse response body
-		var mrs []models.MergeRequest
-		if err := json.NewDecoder(resp.Body).Decode(&mrs); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode merge requests response: %w", err)
-		}
-		resp.Body.Close()
+// ListMergeRequestsWithOptions retrieves merge requests for a specific repository with pagination
+func (c *Client) ListMergeRequestsWithOptions(ctx context.Context, projectID int, opts MergeRequestListOptions) ([]models.MergeRequest, error) {
+	state := opts.State
+	if state == "" {
+		state = "opened"
+	}
 
-		// Add to results
-		allMRs = append(allMRs, mrs...)
+	// Construct endpoint with filtering parameters
+	params := url.Values{}
+	params.Set("state", state)
+	if opts.SourceBranch != "" {
+		params.Set("source_branch", opts.SourceBranch)
+	}
+	if opts.TargetBranch != "" {
+		params.Set("target_branch", opts.TargetBranch)
+	}
+	if len(opts.Labels) > 0 {
+		params.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if !opts.UpdatedAfter.IsZero() {
+		params.Set("updated_after", opts.UpdatedAfter.UTC().Format(time.RFC3339))
+	}
 
-		// Check if we've reached the end (no more pages)
-		if len(mrs) < perPage {
-			break
-		}
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests?%s", projectID, params.Encode())
 
-		page++
+	mrs, err := paginate[models.MergeRequest](ctx, c, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests for project %d: %w", projectID, err)
 	}
 
-	return allMRs, nil
+	return mrs, nil
 }
 
 // GetMergeRequest retrieves a specific merge request by ID
 func (c *Client) GetMergeRequest(ctx context.Context, projectID, mrID int) (*models.MergeRequest, error) {
-	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d", projectID, mrID)
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d?include_rebase_in_progress=true", projectID, mrID)
 
-	resp, err := c.makeRequest(ctx, "GET", endpoint)
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get merge request %d for project %d: %w", mrID, projectID, err)
 	}
@@ -205,11 +208,52 @@ func (c *Client) GetMergeRequest(ctx context.Context, projectID, mrID int) (*mod
 	return &mr, nil
 }
 
+// CreateMergeRequestOptions describes a merge request to create
+type CreateMergeRequestOptions struct {
+	SourceBranch       string `json:"source_branch"`
+	TargetBranch       string `json:"target_branch"`
+	Title              string `json:"title"`
+	Description        string `json:"description,omitempty"`
+	Labels             string `json:"labels,omitempty"` // Comma-separated label names
+	RemoveSourceBranch bool   `json:"remove_source_branch,omitempty"`
+}
+
+// CreateMergeRequest opens a new merge request
+func (c *Client) CreateMergeRequest(ctx context.Context, projectID int, opts CreateMergeRequestOptions) (*models.MergeRequest, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests", projectID)
+
+	resp, err := c.api.Do(ctx, "POST", endpoint, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge request %s -> %s for project %d: %w", opts.SourceBranch, opts.TargetBranch, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var mr models.MergeRequest
+	if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
+		return nil, fmt.Errorf("failed to decode merge request response: %w", err)
+	}
+
+	return &mr, nil
+}
+
+// RebaseMergeRequest asks GitLab to rebase a merge request onto its target branch; the rebase runs asynchronously
+func (c *Client) RebaseMergeRequest(ctx context.Context, projectID, mrID int) error {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/rebase", projectID, mrID)
+
+	resp, err := c.api.Do(ctx, "PUT", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to rebase merge request %d for project %d: %w", mrID, projectID, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
 // GetMergeRequestChanges retrieves the changes/diff information for a merge request
 func (c *Client) GetMergeRequestChanges(ctx context.Context, projectID, mrID int) (int, error) {
 	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/changes", projectID, mrID)
 
-	resp, err := c.makeRequest(ctx, "GET", endpoint)
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get merge request changes %d for project %d: %w", mrID, projectID, err)
 	}
@@ -240,11 +284,35 @@ func (c *Client) GetMergeRequestChanges(ctx context.Context, projectID, mrID int
 	return actualChanges, nil
 }
 
+// GetMergeRequestChangedFiles retrieves the paths of the files changed by a merge request
+func (c *Client) GetMergeRequestChangedFiles(ctx context.Context, projectID, mrID int) ([]string, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/changes", projectID, mrID)
+
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge request changes %d for project %d: %w", mrID, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var changes struct {
+		Changes []fileChange `json:"changes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&changes); err != nil {
+		return nil, fmt.Errorf("failed to decode merge request changes response: %w", err)
+	}
+
+	return changedPaths(changes.Changes), nil
+}
+
 // TestConnection verifies that the client can authenticate with GitLab
 func (c *Client) TestConnection(ctx context.Context) error {
 	endpoint := "/api/v4/user"
+	// Job tokens cannot read the current user, only the job they belong to
+	if _, ok := c.auth.(*JobTokenAuth); ok {
+		endpoint = "/api/v4/job"
+	}
 
-	resp, err := c.makeRequest(ctx, "GET", endpoint)
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return fmt.Errorf("connection test failed: %w", err)
 	}
@@ -0,0 +1,567 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+const graphQLEndpoint = "/api/graphql"
+
+const (
+	// DefaultGraphQLPageSize is the number of projects asked for per GraphQL query
+	DefaultGraphQLPageSize = 20
+
+	// DefaultGraphQLMergeRequestPageSize is the number of merge requests asked for per project and query
+	DefaultGraphQLMergeRequestPageSize = 50
+)
+
+// projectsQuery fetches a page of projects with the merge requests matching the filters; the
+// filters left null are not applied
+const projectsQuery = `query ProjectsWithMergeRequests($first: Int!, $after: String, $ids: [ID!], $state: MergeRequestState, $sourceBranches: [String!], $targetBranches: [String!], $labels: [String!], $updatedAfter: Time, $mrFirst: Int!, $mrAfter: String) {
+  projects(membership: true, ids: $ids, first: $first, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      id
+      name
+      fullPath
+      webUrl
+      lastActivityAt
+      namespace { id name path fullPath }
+      mergeRequests(state: $state, sourceBranches: $sourceBranches, targetBranches: $targetBranches, labels: $labels, updatedAfter: $updatedAfter, first: $mrFirst, after: $mrAfter) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          iid
+          title
+          webUrl
+          sourceBranch
+          targetBranch
+          state
+          draft
+          conflicts
+          createdAt
+          updatedAt
+          mergeStatusEnum
+          detailedMergeStatus
+          rebaseInProgress
+          mergeError
+          userNotesCount
+          diffHeadSha
+          diffRefs { baseSha headSha startSha }
+          author { name username publicEmail }
+          assignees { nodes { name username publicEmail } }
+          reviewers { nodes { name username publicEmail } }
+          labels { nodes { title } }
+          milestone { id iid title dueDate }
+          headPipeline { id status }
+          diffStatsSummary { fileCount }
+        }
+      }
+    }
+  }
+}`
+
+// GraphQLOptions configures a GraphQL backend
+type GraphQLOptions struct {
+	PageSize             int                     // Projects per query, halved whenever GitLab finds a query too complex
+	MergeRequestPageSize int                     // Merge requests per project and query, halved once a single project per query is still too complex
+	Prefetch             MergeRequestListOptions // Merge requests fetched along with every project
+}
+
+// GraphQLBackend reads projects together with their merge requests through the GraphQL API, which
+// takes a handful of requests where the REST API needs a few per project. Merge requests matching
+// the Prefetch options are kept from the project list; other lists are queried per project.
+type GraphQLBackend struct {
+	client   *Client
+	prefetch MergeRequestListOptions
+
+	mu            sync.Mutex
+	pageSize      int
+	mrPageSize    int
+	mergeRequests map[int][]models.MergeRequest
+	fileCounts    map[[2]int]int // Keyed by project ID and merge request IID
+}
+
+// NewGraphQLBackend creates a GraphQL backend sending its queries through client
+func NewGraphQLBackend(client *Client, opts GraphQLOptions) *GraphQLBackend {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultGraphQLPageSize
+	}
+	mrPageSize := opts.MergeRequestPageSize
+	if mrPageSize <= 0 {
+		mrPageSize = DefaultGraphQLMergeRequestPageSize
+	}
+	return &GraphQLBackend{
+		client:        client,
+		prefetch:      opts.Prefetch,
+		pageSize:      pageSize,
+		mrPageSize:    mrPageSize,
+		mergeRequests: make(map[int][]models.MergeRequest),
+		fileCounts:    make(map[[2]int]int),
+	}
+}
+
+// GraphQLError is returned when GitLab answers a GraphQL query with errors
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "GraphQL error: " + strings.Join(e.Messages, "; ")
+}
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLProject struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	FullPath       string    `json:"fullPath"`
+	WebURL         string    `json:"webUrl"`
+	LastActivityAt time.Time `json:"lastActivityAt"`
+	Namespace      struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Path     string `json:"path"`
+		FullPath string `json:"fullPath"`
+	} `json:"namespace"`
+	MergeRequests struct {
+		PageInfo graphQLPageInfo       `json:"pageInfo"`
+		Nodes    []graphQLMergeRequest `json:"nodes"`
+	} `json:"mergeRequests"`
+}
+
+type graphQLMergeRequest struct {
+	IID                 string    `json:"iid"`
+	Title               string    `json:"title"`
+	WebURL              string    `json:"webUrl"`
+	SourceBranch        string    `json:"sourceBranch"`
+	TargetBranch        string    `json:"targetBranch"`
+	State               string    `json:"state"`
+	Draft               bool      `json:"draft"`
+	Conflicts           bool      `json:"conflicts"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+	MergeStatusEnum     string    `json:"mergeStatusEnum"`
+	DetailedMergeStatus string    `json:"detailedMergeStatus"`
+	RebaseInProgress    bool      `json:"rebaseInProgress"`
+	MergeError          string    `json:"mergeError"`
+	UserNotesCount      int       `json:"userNotesCount"`
+	DiffHeadSHA         string    `json:"diffHeadSha"`
+	DiffRefs            *struct {
+		BaseSHA  string `json:"baseSha"`
+		HeadSHA  string `json:"headSha"`
+		StartSHA string `json:"startSha"`
+	} `json:"diffRefs"`
+	Author    graphQLUser `json:"author"`
+	Assignees struct {
+		Nodes []graphQLUser `json:"nodes"`
+	} `json:"assignees"`
+	Reviewers struct {
+		Nodes []graphQLUser `json:"nodes"`
+	} `json:"reviewers"`
+	Labels struct {
+		Nodes []struct {
+			Title string `json:"title"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Milestone *struct {
+		ID      string `json:"id"`
+		IID     string `json:"iid"`
+		Title   string `json:"title"`
+		DueDate string `json:"dueDate"`
+	} `json:"milestone"`
+	HeadPipeline *struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	} `json:"headPipeline"`
+	DiffStatsSummary *struct {
+		FileCount int `json:"fileCount"`
+	} `json:"diffStatsSummary"`
+}
+
+type graphQLUser struct {
+	Name        string `json:"name"`
+	Username    string `json:"username"`
+	PublicEmail string `json:"publicEmail"`
+}
+
+// author converts a GraphQL user to the REST model
+func (u graphQLUser) author() models.Author {
+	return models.Author{Name: u.Name, Username: u.Username, Email: u.PublicEmail}
+}
+
+// ListRepositories retrieves all repositories accessible to the authenticated user
+func (b *GraphQLBackend) ListRepositories(ctx context.Context) ([]models.Repository, error) {
+	return b.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{})
+}
+
+// ListRepositoriesWithOptions retrieves the accessible repositories with their prefetched merge
+// requests; GraphQL cannot filter projects by activity, so LastActivityAfter is applied here
+func (b *GraphQLBackend) ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	projects, err := b.fetchProjects(ctx, nil, b.prefetch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	var repos []models.Repository
+	for _, project := range projects {
+		repo := project.repository()
+		mrs, fileCounts := project.mergeRequests()
+		b.remember(repo.ID, mrs, fileCounts, true)
+		if !opts.LastActivityAfter.IsZero() && !repo.LastActivityAt.After(opts.LastActivityAfter) {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
+
+// ListMergeRequests retrieves merge requests for a specific repository with filtering
+func (b *GraphQLBackend) ListMergeRequests(ctx context.Context, projectID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error) {
+	return b.ListMergeRequestsWithOptions(ctx, projectID, MergeRequestListOptions{
+		SourceBranch: sourceBranch,
+		TargetBranch: targetBranch,
+	})
+}
+
+// ListMergeRequestsWithOptions returns the prefetched merge requests of a project when opts match
+// the Prefetch options, and queries them otherwise
+func (b *GraphQLBackend) ListMergeRequestsWithOptions(ctx context.Context, projectID int, opts MergeRequestListOptions) ([]models.MergeRequest, error) {
+	prefetched := sameListOptions(opts, b.prefetch)
+	if prefetched {
+		b.mu.Lock()
+		mrs, ok := b.mergeRequests[projectID]
+		b.mu.Unlock()
+		if ok {
+			return slices.Clone(mrs), nil
+		}
+	}
+
+	projects, err := b.fetchProjects(ctx, []string{projectGID(projectID)}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests for project %d: %w", projectID, err)
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("failed to list merge requests for project %d: %w", projectID, &httpapi.APIError{StatusCode: http.StatusNotFound, Message: "project not found"})
+	}
+
+	mrs, fileCounts := projects[0].mergeRequests()
+	b.remember(projectID, mrs, fileCounts, prefetched)
+	return mrs, nil
+}
+
+// GetMergeRequestChanges returns the number of files changed by a merge request, from its diff
+// stats when it was fetched through GraphQL and from the REST API otherwise
+func (b *GraphQLBackend) GetMergeRequestChanges(ctx context.Context, projectID, mrID int) (int, error) {
+	b.mu.Lock()
+	count, ok := b.fileCounts[[2]int{projectID, mrID}]
+	b.mu.Unlock()
+	if ok {
+		return count, nil
+	}
+	return b.client.GetMergeRequestChanges(ctx, projectID, mrID)
+}
+
+// remember keeps the file counts of merge requests, and the list of a project when it was prefetched
+func (b *GraphQLBackend) remember(projectID int, mrs []models.MergeRequest, fileCounts map[int]int, prefetched bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for iid, count := range fileCounts {
+		b.fileCounts[[2]int{projectID, iid}] = count
+	}
+	if prefetched {
+		b.mergeRequests[projectID] = slices.Clone(mrs)
+	}
+}
+
+// fetchProjects runs the projects query page by page, then completes the merge requests of projects
+// that have more than one page of them; ids limits the query to some projects
+func (b *GraphQLBackend) fetchProjects(ctx context.Context, ids []string, opts MergeRequestListOptions) ([]graphQLProject, error) {
+	variables := mergeRequestVariables(opts)
+	if len(ids) > 0 {
+		variables["ids"] = ids
+	}
+
+	var projects []graphQLProject
+	var after interface{}
+	for {
+		variables["first"], variables["mrFirst"] = b.pageSizes()
+		variables["after"] = after
+
+		var data struct {
+			Projects struct {
+				PageInfo graphQLPageInfo  `json:"pageInfo"`
+				Nodes    []graphQLProject `json:"nodes"`
+			} `json:"projects"`
+		}
+		err := b.query(ctx, variables, &data)
+		if isComplexityError(err) && b.shrinkPageSize() {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, data.Projects.Nodes...)
+		if !data.Projects.PageInfo.HasNextPage {
+			break
+		}
+		after = data.Projects.PageInfo.EndCursor
+	}
+
+	// Rare projects with more merge requests than fit in one page are completed one by one
+	for i := range projects {
+		for page := projects[i].MergeRequests.PageInfo; page.HasNextPage; {
+			variables := mergeRequestVariables(opts)
+			variables["ids"] = []string{projects[i].ID}
+			variables["first"] = 1
+			_, variables["mrFirst"] = b.pageSizes()
+			variables["mrAfter"] = page.EndCursor
+
+			var data struct {
+				Projects struct {
+					Nodes []graphQLProject `json:"nodes"`
+				} `json:"projects"`
+			}
+			err := b.query(ctx, variables, &data)
+			if isComplexityError(err) && b.shrinkMergeRequestPageSize() {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(data.Projects.Nodes) == 0 {
+				break
+			}
+			more := data.Projects.Nodes[0].MergeRequests
+			projects[i].MergeRequests.Nodes = append(projects[i].MergeRequests.Nodes, more.Nodes...)
+			page = more.PageInfo
+		}
+	}
+
+	return projects, nil
+}
+
+// query sends a GraphQL query and decodes its data into data
+func (b *GraphQLBackend) query(ctx context.Context, variables map[string]interface{}, data interface{}) error {
+	resp, err := b.client.api.Do(ctx, http.MethodPost, graphQLEndpoint, graphQLRequest{Query: projectsQuery, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("GraphQL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode GraphQL response: %w", err)
+	}
+
+	if len(result.Errors) > 0 {
+		graphQLErr := &GraphQLError{}
+		for _, e := range result.Errors {
+			graphQLErr.Messages = append(graphQLErr.Messages, e.Message)
+		}
+		return graphQLErr
+	}
+
+	if err := json.Unmarshal(result.Data, data); err != nil {
+		return fmt.Errorf("failed to decode GraphQL data: %w", err)
+	}
+	return nil
+}
+
+// pageSizes returns the number of projects, and of merge requests per project, to ask for
+func (b *GraphQLBackend) pageSizes() (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pageSize, b.mrPageSize
+}
+
+// shrinkPageSize halves the number of projects per query after a query was too complex, then the
+// number of merge requests per project once a single project is too complex; it returns false when
+// neither can shrink
+func (b *GraphQLBackend) shrinkPageSize() bool {
+	b.mu.Lock()
+	if b.pageSize > 1 {
+		b.pageSize /= 2
+		slog.Debug("GraphQL query too complex, asking for fewer projects per query", "page_size", b.pageSize)
+		b.mu.Unlock()
+		return true
+	}
+	b.mu.Unlock()
+	return b.shrinkMergeRequestPageSize()
+}
+
+// shrinkMergeRequestPageSize halves the number of merge requests per project and query after a query
+// was too complex, returning false when it cannot shrink
+func (b *GraphQLBackend) shrinkMergeRequestPageSize() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mrPageSize <= 1 {
+		return false
+	}
+	b.mrPageSize /= 2
+	slog.Debug("GraphQL query too complex, asking for fewer merge requests per query", "merge_request_page_size", b.mrPageSize)
+	return true
+}
+
+// isComplexityError reports whether GitLab rejected a query for exceeding its complexity limit
+func isComplexityError(err error) bool {
+	var graphQLErr *GraphQLError
+	if !errors.As(err, &graphQLErr) {
+		return false
+	}
+	for _, message := range graphQLErr.Messages {
+		if strings.Contains(message, "exceeds max complexity") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRequestVariables turns merge request list options into query variables
+func mergeRequestVariables(opts MergeRequestListOptions) map[string]interface{} {
+	state := opts.State
+	if state == "" {
+		state = "opened"
+	}
+
+	variables := map[string]interface{}{
+		"state": state,
+	}
+	if opts.SourceBranch != "" {
+		variables["sourceBranches"] = []string{opts.SourceBranch}
+	}
+	if opts.TargetBranch != "" {
+		variables["targetBranches"] = []string{opts.TargetBranch}
+	}
+	if len(opts.Labels) > 0 {
+		variables["labels"] = opts.Labels
+	}
+	if !opts.UpdatedAfter.IsZero() {
+		variables["updatedAfter"] = opts.UpdatedAfter.UTC().Format(time.RFC3339)
+	}
+	return variables
+}
+
+// sameListOptions reports whether two merge request lists select the same merge requests
+func sameListOptions(a, b MergeRequestListOptions) bool {
+	stateA, stateB := a.State, b.State
+	if stateA == "" {
+		stateA = "opened"
+	}
+	if stateB == "" {
+		stateB = "opened"
+	}
+	return stateA == stateB && a.SourceBranch == b.SourceBranch && a.TargetBranch == b.TargetBranch &&
+		slices.Equal(a.Labels, b.Labels) && a.UpdatedAfter.Equal(b.UpdatedAfter)
+}
+
+// projectGID returns the global ID of a project
+func projectGID(projectID int) string {
+	return fmt.Sprintf("gid://gitlab/Project/%d", projectID)
+}
+
+// parseGID returns the numeric ID at the end of a global ID such as gid://gitlab/Project/42
+func parseGID(gid string) int {
+	id, _ := strconv.Atoi(gid[strings.LastIndex(gid, "/")+1:])
+	return id
+}
+
+// repository converts a GraphQL project to the REST model
+func (p graphQLProject) repository() models.Repository {
+	return models.Repository{
+		ID:                parseGID(p.ID),
+		Name:              p.Name,
+		PathWithNamespace: p.FullPath,
+		WebURL:            p.WebURL,
+		LastActivityAt:    p.LastActivityAt,
+		Namespace: models.Namespace{
+			ID:       parseGID(p.Namespace.ID),
+			Name:     p.Namespace.Name,
+			Path:     p.Namespace.Path,
+			FullPath: p.Namespace.FullPath,
+		},
+	}
+}
+
+// mergeRequests converts the merge requests of a GraphQL project to the REST model, and returns
+// the number of files each one changes when GitLab knows it
+func (p graphQLProject) mergeRequests() ([]models.MergeRequest, map[int]int) {
+	mrs := make([]models.MergeRequest, 0, len(p.MergeRequests.Nodes))
+	fileCounts := make(map[int]int)
+	for _, node := range p.MergeRequests.Nodes {
+		iid, _ := strconv.Atoi(node.IID)
+		mr := models.MergeRequest{
+			ID:                  iid,
+			ProjectID:           parseGID(p.ID),
+			Title:               node.Title,
+			Author:              node.Author.author(),
+			WebURL:              node.WebURL,
+			SourceBranch:        node.SourceBranch,
+			TargetBranch:        node.TargetBranch,
+			HasConflicts:        node.Conflicts,
+			CreatedAt:           node.CreatedAt,
+			UpdatedAt:           node.UpdatedAt,
+			State:               node.State,
+			Draft:               node.Draft,
+			MergeStatus:         strings.ToLower(node.MergeStatusEnum),
+			DetailedMergeStatus: strings.ToLower(node.DetailedMergeStatus),
+			RebaseInProgress:    node.RebaseInProgress,
+			MergeError:          node.MergeError,
+			SHA:                 node.DiffHeadSHA,
+			UserNotesCount:      node.UserNotesCount,
+		}
+		for _, assignee := range node.Assignees.Nodes {
+			mr.Assignees = append(mr.Assignees, assignee.author())
+		}
+		for _, reviewer := range node.Reviewers.Nodes {
+			mr.Reviewers = append(mr.Reviewers, reviewer.author())
+		}
+		for _, label := range node.Labels.Nodes {
+			mr.Labels = append(mr.Labels, label.Title)
+		}
+		if node.DiffRefs != nil {
+			mr.DiffRefs = &models.DiffRefs{BaseSHA: node.DiffRefs.BaseSHA, HeadSHA: node.DiffRefs.HeadSHA, StartSHA: node.DiffRefs.StartSHA}
+		}
+		if node.Milestone != nil {
+			milestoneIID, _ := strconv.Atoi(node.Milestone.IID)
+			mr.Milestone = &models.Milestone{ID: parseGID(node.Milestone.ID), IID: milestoneIID, Title: node.Milestone.Title, DueDate: node.Milestone.DueDate}
+		}
+		if node.HeadPipeline != nil {
+			mr.HeadPipeline = &models.Pipeline{ID: parseGID(node.HeadPipeline.ID), Status: strings.ToLower(node.HeadPipeline.Status)}
+		}
+		if node.DiffStatsSummary != nil {
+			mr.ChangesCount = models.ChangeCount(node.DiffStatsSummary.FileCount)
+			fileCounts[iid] = node.DiffStatsSummary.FileCount
+		}
+		mrs = append(mrs, mr)
+	}
+	return mrs, fileCounts
+}
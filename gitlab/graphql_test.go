@@ -0,0 +1,310 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+)
+
+// releaseToMaster is the merge request list the checker scans
+var releaseToMaster = MergeRequestListOptions{SourceBranch: "release", TargetBranch: "master"}
+
+// newGraphQLBackend creates a GraphQL backend for the fake server that prefetches release to master MRs
+func newGraphQLBackend(t *testing.T, server *gitlabfake.Server) *GraphQLBackend {
+	client := NewClient(server.URL(), gitlabfake.DefaultToken)
+	t.Cleanup(client.Close)
+	return NewGraphQLBackend(client, GraphQLOptions{Prefetch: releaseToMaster})
+}
+
+// graphQLRequests returns the variables of the GraphQL requests the fake server received
+func graphQLRequests(t *testing.T, server *gitlabfake.Server) []map[string]interface{} {
+	var variables []map[string]interface{}
+	for _, request := range server.Requests() {
+		if request.Path != graphQLEndpoint {
+			continue
+		}
+		var body graphQLRequest
+		require.NoError(t, json.Unmarshal([]byte(request.Body), &body))
+		variables = append(variables, body.Variables)
+	}
+	return variables
+}
+
+func TestGraphQLBackend_MatchesREST(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(45))
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for id := 1; id <= 45; id += 4 {
+		server.SetMergeRequests(id, []models.MergeRequest{
+			{ID: 1, Title: "Release", Author: models.Author{Name: "Alice", Username: "alice"}, SourceBranch: "release", TargetBranch: "master", HasConflicts: id%8 == 1, CreatedAt: created, MergeStatus: "cannot_be_merged", DetailedMergeStatus: "need_rebase", Labels: []string{"release"}},
+			{ID: 2, Title: "Feature", SourceBranch: "feature", TargetBranch: "master", CreatedAt: created},
+		})
+		server.SetChanges(id, 1, "main.go", "go.mod")
+	}
+
+	rest := NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer rest.Close()
+	graphQL := newGraphQLBackend(t, server)
+	ctx := context.Background()
+
+	restRepos, err := rest.ListRepositories(ctx)
+	require.NoError(t, err)
+	server.ResetRequests()
+
+	graphQLRepos, err := graphQL.ListRepositories(ctx)
+	require.NoError(t, err)
+	require.Len(t, graphQLRepos, 45)
+	for i := range restRepos {
+		assert.Equal(t, restRepos[i].ID, graphQLRepos[i].ID)
+		assert.Equal(t, restRepos[i].Name, graphQLRepos[i].Name)
+	}
+
+	// 45 projects with their merge requests take three queries of 20
+	assert.Len(t, graphQLRequests(t, server), 3)
+	server.ResetRequests()
+
+	for id := 1; id <= 45; id++ {
+		restMRs, err := rest.ListMergeRequests(ctx, id, "release", "master")
+		require.NoError(t, err)
+		graphQLMRs, err := graphQL.ListMergeRequests(ctx, id, "release", "master")
+		require.NoError(t, err)
+		assert.Equal(t, len(restMRs), len(graphQLMRs), "project %d", id)
+	}
+
+	mrs, err := graphQL.ListMergeRequests(ctx, 9, "release", "master")
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 1, mrs[0].ID)
+	assert.Equal(t, "Release", mrs[0].Title)
+	assert.Equal(t, "alice", mrs[0].Author.Username)
+	assert.True(t, mrs[0].HasConflicts)
+	assert.Equal(t, created, mrs[0].CreatedAt)
+	assert.Equal(t, "opened", mrs[0].State)
+	assert.Equal(t, "cannot_be_merged", mrs[0].MergeStatus)
+	assert.Equal(t, models.DetailedMergeStatusNeedRebase, mrs[0].DetailedMergeStatus)
+	assert.Equal(t, []string{"release"}, mrs[0].Labels)
+	assert.Equal(t, models.ChangeCount(2), mrs[0].ChangesCount)
+
+	// The changes come from the diff stats fetched with the projects
+	changes, err := graphQL.GetMergeRequestChanges(ctx, 9, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changes)
+
+	// Merge requests were prefetched, so only the REST calls reached the server
+	assert.Empty(t, graphQLRequests(t, server))
+}
+
+func TestGraphQLBackend_MergeRequestDetails(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(1))
+	server.SetMergeRequests(1, []models.MergeRequest{{
+		ID:           1,
+		Title:        "Draft: Release",
+		SourceBranch: "release",
+		TargetBranch: "master",
+		Draft:        true,
+		Assignees:    []models.Author{{Name: "Carol", Username: "carol"}},
+		Reviewers:    []models.Author{{Name: "Dave", Username: "dave"}},
+		SHA:          "abc123",
+		DiffRefs:     &models.DiffRefs{BaseSHA: "base", HeadSHA: "abc123", StartSHA: "start"},
+		Milestone:    &models.Milestone{ID: 7, IID: 2, Title: "1.2", DueDate: "2024-02-01"},
+		HeadPipeline: &models.Pipeline{ID: 99, Status: "failed"},
+	}})
+
+	mrs, err := newGraphQLBackend(t, server).ListMergeRequests(context.Background(), 1, "release", "master")
+
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	mr := mrs[0]
+	assert.Equal(t, 1, mr.ProjectID)
+	assert.True(t, mr.IsDraft())
+	assert.Equal(t, []models.Author{{Name: "Carol", Username: "carol"}}, mr.Assignees)
+	assert.Equal(t, []models.Author{{Name: "Dave", Username: "dave"}}, mr.Reviewers)
+	assert.Equal(t, "abc123", mr.SHA)
+	assert.Equal(t, &models.DiffRefs{BaseSHA: "base", HeadSHA: "abc123", StartSHA: "start"}, mr.DiffRefs)
+	assert.Equal(t, &models.Milestone{ID: 7, IID: 2, Title: "1.2", DueDate: "2024-02-01"}, mr.Milestone)
+	assert.Equal(t, &models.Pipeline{ID: 99, Status: "failed"}, mr.HeadPipeline)
+	assert.Zero(t, mr.UserNotesCount)
+}
+
+func TestGraphQLBackend_ShrinksComplexQueries(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(12))
+	server.SetMaxComplexity(300)
+
+	backend := newGraphQLBackend(t, server)
+
+	repos, err := backend.ListRepositories(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, repos, 12)
+
+	// 20 and 10 projects with 50 merge requests each are too complex, 5 are not
+	var pageSizes []float64
+	for _, variables := range graphQLRequests(t, server) {
+		pageSizes = append(pageSizes, variables["first"].(float64))
+	}
+	assert.Equal(t, []float64{20, 10, 5, 5, 5}, pageSizes)
+}
+
+func TestGraphQLBackend_ShrinksMergeRequestPages(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(1))
+	var mrs []models.MergeRequest
+	for i := 1; i <= 20; i++ {
+		mrs = append(mrs, models.MergeRequest{ID: i, SourceBranch: "release", TargetBranch: "master"})
+	}
+	server.SetMergeRequests(1, mrs)
+	server.SetMaxComplexity(10)
+
+	backend := newGraphQLBackend(t, server)
+	ctx := context.Background()
+
+	_, err := backend.ListRepositories(ctx)
+	require.NoError(t, err)
+	result, err := backend.ListMergeRequests(ctx, 1, "release", "master")
+	require.NoError(t, err)
+	assert.Len(t, result, 20)
+
+	// A single project with 50, 25 or 12 merge requests is too complex, 6 is not; the remaining
+	// merge requests are paged with the smaller size too
+	var sizes [][2]float64
+	for _, variables := range graphQLRequests(t, server) {
+		sizes = append(sizes, [2]float64{variables["first"].(float64), variables["mrFirst"].(float64)})
+	}
+	assert.Equal(t, [][2]float64{{20, 50}, {10, 50}, {5, 50}, {2, 50}, {1, 50}, {1, 25}, {1, 12}, {1, 6}, {1, 6}, {1, 6}, {1, 6}}, sizes)
+}
+
+func TestGraphQLBackend_TooComplexEvenForOneMergeRequest(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(2))
+	server.SetMaxComplexity(1)
+
+	_, err := newGraphQLBackend(t, server).ListRepositories(context.Background())
+	assert.ErrorContains(t, err, "failed to list repositories: GraphQL error: Query has complexity of 2, which exceeds max complexity of 1")
+}
+
+func TestGraphQLBackend_PaginatesMergeRequests(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(1))
+	var mrs []models.MergeRequest
+	for i := 1; i <= 120; i++ {
+		mrs = append(mrs, models.MergeRequest{ID: i, SourceBranch: "release", TargetBranch: "master"})
+	}
+	server.SetMergeRequests(1, mrs)
+
+	backend := newGraphQLBackend(t, server)
+	ctx := context.Background()
+
+	_, err := backend.ListRepositories(ctx)
+	require.NoError(t, err)
+	result, err := backend.ListMergeRequests(ctx, 1, "release", "master")
+	require.NoError(t, err)
+	assert.Len(t, result, 120)
+	assert.Equal(t, 120, result[119].ID)
+
+	// The project list, then the two remaining pages of merge requests of the project
+	requests := graphQLRequests(t, server)
+	require.Len(t, requests, 3)
+	assert.Equal(t, []interface{}{"gid://gitlab/Project/1"}, requests[1]["ids"])
+	assert.Equal(t, "50", requests[1]["mrAfter"])
+	assert.Equal(t, "100", requests[2]["mrAfter"])
+}
+
+func TestGraphQLBackend_QueriesOtherLists(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(2))
+	server.SetMergeRequests(2, []models.MergeRequest{
+		{ID: 1, SourceBranch: "release", TargetBranch: "master", UpdatedAt: since.Add(-time.Hour)},
+		{ID: 2, SourceBranch: "release", TargetBranch: "master", UpdatedAt: since.Add(time.Hour)},
+	})
+	server.SetMergeRequestState(2, 2, "merged")
+
+	backend := newGraphQLBackend(t, server)
+
+	mrs, err := backend.ListMergeRequestsWithOptions(context.Background(), 2, MergeRequestListOptions{
+		State:        "all",
+		SourceBranch: "release",
+		TargetBranch: "master",
+		UpdatedAfter: since,
+	})
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 2, mrs[0].ID)
+	assert.Equal(t, "merged", mrs[0].State)
+
+	requests := graphQLRequests(t, server)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []interface{}{"gid://gitlab/Project/2"}, requests[0]["ids"])
+	assert.Equal(t, "2024-03-01T12:00:00Z", requests[0]["updatedAfter"])
+
+	// Unknown projects are reported like a REST 404
+	_, err = backend.ListMergeRequests(context.Background(), 99, "release", "master")
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestGraphQLBackend_LastActivityAfter(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects([]models.Repository{
+		{ID: 1, Name: "quiet", LastActivityAt: since.Add(-time.Hour)},
+		{ID: 2, Name: "busy", LastActivityAt: since.Add(time.Minute)},
+	})
+
+	repos, err := newGraphQLBackend(t, server).ListRepositoriesWithOptions(context.Background(), ListRepositoriesOptions{LastActivityAfter: since})
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "busy", repos[0].Name)
+}
+
+func TestGraphQLBackend_ChangesFallBackToREST(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetMergeRequests(1, []models.MergeRequest{{ID: 1, SourceBranch: "feature", TargetBranch: "master"}})
+	server.SetChanges(1, 1, "README.md")
+
+	changes, err := newGraphQLBackend(t, server).GetMergeRequestChanges(context.Background(), 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes)
+	assert.Equal(t, 1, countRequests(server, "/api/v4/projects/1/merge_requests/1/changes"))
+}
+
+func TestGraphQLBackend_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errors":[{"message":"Field 'diffStatsSummary' doesn't exist on type 'MergeRequest'"},{"message":"Timeout"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	_, err := NewGraphQLBackend(client, GraphQLOptions{}).ListRepositories(context.Background())
+	assert.EqualError(t, err, "failed to list repositories: GraphQL error: Field 'diffStatsSummary' doesn't exist on type 'MergeRequest'; Timeout")
+}
+
+func TestParseGID(t *testing.T) {
+	assert.Equal(t, 42, parseGID("gid://gitlab/Project/42"))
+	assert.Equal(t, 7, parseGID("gid://gitlab/Group/7"))
+	assert.Equal(t, 0, parseGID(""))
+}
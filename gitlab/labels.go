@@ -0,0 +1,38 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// AddMergeRequestLabels adds labels to a merge request, keeping its existing labels
+func (c *Client) AddMergeRequestLabels(ctx context.Context, projectID, mrID int, labels ...string) (*models.MergeRequest, error) {
+	return c.updateMergeRequestLabels(ctx, projectID, mrID, "add_labels", labels)
+}
+
+// RemoveMergeRequestLabels removes labels from a merge request, keeping its other labels
+func (c *Client) RemoveMergeRequestLabels(ctx context.Context, projectID, mrID int, labels ...string) (*models.MergeRequest, error) {
+	return c.updateMergeRequestLabels(ctx, projectID, mrID, "remove_labels", labels)
+}
+
+// updateMergeRequestLabels sends an add_labels or remove_labels update for a merge request
+func (c *Client) updateMergeRequestLabels(ctx context.Context, projectID, mrID int, field string, labels []string) (*models.MergeRequest, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d", projectID, mrID)
+
+	resp, err := c.api.Do(ctx, "PUT", endpoint, map[string]string{field: strings.Join(labels, ",")})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update labels of merge request %d for project %d: %w", mrID, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var mr models.MergeRequest
+	if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
+		return nil, fmt.Errorf("failed to decode merge request response: %w", err)
+	}
+
+	return &mr, nil
+}
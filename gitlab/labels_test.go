@@ -0,0 +1,72 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_UpdateMergeRequestLabels(t *testing.T) {
+	var bodies []map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/api/v4/projects/1/merge_requests/5", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MergeRequest{ID: 5, Labels: []string{"merge-conflict"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	ctx := context.Background()
+	mr, err := client.AddMergeRequestLabels(ctx, 1, 5, "merge-conflict", "release")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"merge-conflict"}, mr.Labels)
+
+	_, err = client.RemoveMergeRequestLabels(ctx, 1, 5, "merge-conflict")
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]string{
+		{"add_labels": "merge-conflict,release"},
+		{"remove_labels": "merge-conflict"},
+	}, bodies)
+}
+
+func TestClient_ListMergeRequestsWithOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "opened", query.Get("state"))
+		assert.Equal(t, "merge-conflict,urgent", query.Get("labels"))
+		assert.Equal(t, "release", query.Get("source_branch"))
+		assert.Empty(t, query.Get("target_branch"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]models.MergeRequest{{ID: 1, Labels: []string{"merge-conflict", "urgent"}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	mrs, err := client.ListMergeRequestsWithOptions(context.Background(), 1, MergeRequestListOptions{
+		SourceBranch: "release",
+		Labels:       []string{"merge-conflict", "urgent"},
+	})
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, []string{"merge-conflict", "urgent"}, mrs[0].Labels)
+}
@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// noteRequest is the body of note create and update requests
+type noteRequest struct {
+	Body string `json:"body"`
+}
+
+// ListMergeRequestNotes retrieves all notes on a merge request with pagination
+func (c *Client) ListMergeRequestNotes(ctx context.Context, projectID, mrID int) ([]models.Note, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/notes?sort=asc&order_by=created_at", projectID, mrID)
+
+	notes, err := paginate[models.Note](ctx, c, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes of merge request %d for project %d: %w", mrID, projectID, err)
+	}
+
+	return notes, nil
+}
+
+// CreateMergeRequestNote adds a note to a merge request
+func (c *Client) CreateMergeRequestNote(ctx context.Context, projectID, mrID int, body string) (*models.Note, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/notes", projectID, mrID)
+
+	resp, err := c.api.Do(ctx, "POST", endpoint, noteRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create note on merge request %d for project %d: %w", mrID, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var note models.Note
+	if err := json.NewDecoder(resp.Body).Decode(&note); err != nil {
+		return nil, fmt.Errorf("failed to decode note response: %w", err)
+	}
+
+	return &note, nil
+}
+
+// UpdateMergeRequestNote replaces the body of an existing merge request note
+func (c *Client) UpdateMergeRequestNote(ctx context.Context, projectID, mrID, noteID int, body string) (*models.Note, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/notes/%d", projectID, mrID, noteID)
+
+	resp, err := c.api.Do(ctx, "PUT", endpoint, noteRequest{Body: body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update note %d on merge request %d for project %d: %w", noteID, mrID, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var note models.Note
+	if err := json.NewDecoder(resp.Body).Decode(&note); err != nil {
+		return nil, fmt.Errorf("failed to decode note response: %w", err)
+	}
+
+	return &note, nil
+}
+
+// DeleteMergeRequestNote removes a note from a merge request
+func (c *Client) DeleteMergeRequestNote(ctx context.Context, projectID, mrID, noteID int) error {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/notes/%d", projectID, mrID, noteID)
+
+	resp, err := c.api.Do(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete note %d on merge request %d for project %d: %w", noteID, mrID, projectID, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_ListMergeRequestNotes_Pagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects/1/merge_requests/5/notes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// 100 notes on the first page, 3 on the second
+		count := 100
+		offset := 0
+		if r.URL.Query().Get("page") == "2" {
+			count = 3
+			offset = 100
+		}
+		notes := make([]models.Note, count)
+		for i := range notes {
+			notes[i] = models.Note{ID: offset + i + 1, Body: fmt.Sprintf("note %d", offset+i+1)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(notes)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	notes, err := client.ListMergeRequestNotes(context.Background(), 1, 5)
+	require.NoError(t, err)
+	assert.Len(t, notes, 103)
+	assert.Equal(t, "note 103", notes[102].Body)
+}
+
+func TestClient_CreateAndUpdateMergeRequestNote(t *testing.T) {
+	var requests []string
+	var bodies []map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.Note{ID: 42, Body: body["body"]})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	ctx := context.Background()
+	note, err := client.CreateMergeRequestNote(ctx, 1, 5, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, 42, note.ID)
+
+	note, err = client.UpdateMergeRequestNote(ctx, 1, 5, 42, "updated")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", note.Body)
+
+	assert.Equal(t, []string{
+		"POST /api/v4/projects/1/merge_requests/5/notes",
+		"PUT /api/v4/projects/1/merge_requests/5/notes/42",
+	}, requests)
+	assert.Equal(t, "hello", bodies[0]["body"])
+	assert.Equal(t, "updated", bodies[1]["body"])
+}
+
+func TestClient_DeleteMergeRequestNote(t *testing.T) {
+	var request string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	err := client.DeleteMergeRequestNote(context.Background(), 1, 5, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE /api/v4/projects/1/merge_requests/5/notes/42", request)
+}
+
+func TestClient_CreateMergeRequestNote_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"403 Forbidden"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	_, err := client.CreateMergeRequestNote(context.Background(), 1, 5, "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "API error 403")
+}
@@ -0,0 +1,67 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"mr-conflict-checker/internal/httpapi"
+)
+
+// DefaultPerPage is the page size requested from list endpoints, the largest GitLab allows
+const DefaultPerPage = 100
+
+// paginate fetches every page of a GitLab list endpoint and returns the decoded items.
+//
+// It follows the Link header (the only one sent with keyset pagination) and the X-Next-Page
+// header. Only when the server sends neither does it keep requesting pages until one holds
+// fewer than per_page items. per_page is set to DefaultPerPage unless endpoint already has it.
+func paginate[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	perPage := DefaultPerPage
+	if value := queryValue(endpoint, "per_page"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			perPage = parsed
+		}
+	} else {
+		endpoint = httpapi.SetQueryValue(endpoint, "per_page", strconv.Itoa(perPage))
+	}
+
+	return httpapi.PaginateJSON[T](ctx, c.api, endpoint, func(header http.Header, endpoint string, count int) string {
+		return c.nextPage(header, endpoint, count, perPage)
+	})
+}
+
+// nextPage returns the endpoint of the page after endpoint, and "" on the last page
+func (c *Client) nextPage(header http.Header, endpoint string, count, perPage int) string {
+	// Link is authoritative whenever it is sent, and keyset pagination sends nothing else
+	if header.Get("Link") != "" || queryValue(endpoint, "pagination") == "keyset" {
+		return c.api.NextLink(header)
+	}
+
+	// X-Next-Page is sent, and empty on the last page, with offset pagination
+	if values, ok := header[http.CanonicalHeaderKey("X-Next-Page")]; ok {
+		if len(values) == 0 || values[0] == "" {
+			return ""
+		}
+		return httpapi.SetQueryValue(endpoint, "page", values[0])
+	}
+
+	// Servers without pagination headers: a short page is the last one
+	if count < perPage {
+		return ""
+	}
+	page := 1
+	if parsed, err := strconv.Atoi(queryValue(endpoint, "page")); err == nil && parsed > 0 {
+		page = parsed
+	}
+	return httpapi.SetQueryValue(endpoint, "page", strconv.Itoa(page+1))
+}
+
+// queryValue returns a query parameter of an endpoint
+func queryValue(endpoint, key string) string {
+	_, rawQuery, _ := strings.Cut(endpoint, "?")
+	query, _ := url.ParseQuery(rawQuery)
+	return query.Get(key)
+}
@@ -0,0 +1,118 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+)
+
+// fakeRepositories creates count repositories numbered from 1
+func fakeRepositories(count int) []models.Repository {
+	repos := make([]models.Repository, count)
+	for i := range repos {
+		repos[i] = models.Repository{ID: i + 1, Name: fmt.Sprintf("repo-%d", i+1)}
+	}
+	return repos
+}
+
+// countRequests counts the requests the fake server received for a path
+func countRequests(server *gitlabfake.Server, path string) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if request.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+func TestListRepositories_KeysetPagination(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(250))
+
+	client := NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	repos, err := client.ListRepositories(context.Background())
+	require.NoError(t, err)
+	require.Len(t, repos, 250)
+	assert.Equal(t, 1, repos[0].ID)
+	assert.Equal(t, 250, repos[249].ID)
+
+	requests := server.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "keyset", requests[0].Query.Get("pagination"))
+	assert.Equal(t, "id", requests[0].Query.Get("order_by"))
+	assert.Equal(t, "200", requests[2].Query.Get("id_after"))
+}
+
+func TestListRepositories_NoExtraRequestOnExactMultiple(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects(fakeRepositories(200))
+
+	client := NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	repos, err := client.ListRepositories(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, repos, 200)
+	assert.Equal(t, 2, countRequests(server, "/api/v4/projects"))
+}
+
+func TestListMergeRequests_CappedPerPage(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetMaxPerPage(20)
+
+	var mrs []models.MergeRequest
+	for i := 1; i <= 45; i++ {
+		mrs = append(mrs, models.MergeRequest{ID: i, SourceBranch: "release", TargetBranch: "master"})
+	}
+	server.SetMergeRequests(1, mrs)
+
+	client := NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	// Pages of 20 are followed through X-Next-Page even though 100 were asked for
+	result, err := client.ListMergeRequests(context.Background(), 1, "release", "master")
+	require.NoError(t, err)
+	assert.Len(t, result, 45)
+	assert.Equal(t, 3, countRequests(server, "/api/v4/projects/1/merge_requests"))
+
+	for _, request := range server.Requests() {
+		assert.Equal(t, "release", request.Query.Get("source_branch"), "filters are kept on every page")
+	}
+}
+
+func TestPaginate_ShortPageFallback(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		// No pagination headers: a full page, then a short one
+		if r.URL.Query().Get("page") == "" {
+			json.NewEncoder(w).Encode([]int{1, 2})
+			return
+		}
+		json.NewEncoder(w).Encode([]int{3})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	items, err := paginate[int](context.Background(), client, "/api/v4/items?per_page=2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+	assert.Equal(t, []string{"", "2"}, pages)
+}
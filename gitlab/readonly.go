@@ -0,0 +1,107 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// WithReadOnly makes the client refuse every request other than GET, HEAD and GraphQL queries at
+// the transport layer; the refused writes are logged and available from SkippedWrites
+func WithReadOnly() Option {
+	return func(c *Client) {
+		c.readOnly = true
+	}
+}
+
+// ReadOnly reports whether the client refuses write requests
+func (c *Client) ReadOnly() bool {
+	return c.api.ReadOnly()
+}
+
+// SkippedWrites returns the write requests refused so far by a read-only client
+func (c *Client) SkippedWrites() []models.SkippedWrite {
+	return c.api.SkippedWrites()
+}
+
+// isGraphQLQuery reports whether a POST body is a GraphQL query rather than a mutation, which a
+// read-only client lets through
+func isGraphQLQuery(path string, body []byte) bool {
+	if !strings.HasSuffix(path, graphQLEndpoint) {
+		return false
+	}
+	var request graphQLRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return false
+	}
+	// A document may hold several operations, so any mutation in it counts as a write
+	query := strings.TrimSpace(request.Query)
+	if strings.Contains(query, "mutation") {
+		return false
+	}
+	return strings.HasPrefix(query, "{") || strings.HasPrefix(query, "query")
+}
+
+var (
+	notesPattern        = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)/notes(?:/\d+)?$`)
+	rebasePattern       = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)/rebase$`)
+	mergeRequestPattern = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests/(\d+)$`)
+	createMRPattern     = regexp.MustCompile(`^/api/v4/projects/(\d+)/merge_requests$`)
+	branchesPattern     = regexp.MustCompile(`^/api/v4/projects/(\d+)/repository/branches$`)
+)
+
+// describeWrite renders a human readable summary of a write request for the dry-run report
+func describeWrite(method, path string, body []byte) string {
+	var fields map[string]interface{}
+	json.Unmarshal(body, &fields)
+	field := func(name string) string {
+		if value, ok := fields[name]; ok {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+
+	switch {
+	case notesPattern.MatchString(path):
+		match := notesPattern.FindStringSubmatch(path)
+		verb := map[string]string{http.MethodPost: "Comment on", http.MethodPut: "Update comment on", http.MethodDelete: "Delete comment on"}[method]
+		if verb == "" {
+			verb = method + " note on"
+		}
+		return fmt.Sprintf("%s MR !%s in project %s", verb, match[2], match[1])
+	case rebasePattern.MatchString(path):
+		match := rebasePattern.FindStringSubmatch(path)
+		return fmt.Sprintf("Rebase MR !%s in project %s", match[2], match[1])
+	case mergeRequestPattern.MatchString(path) && method == http.MethodPut:
+		match := mergeRequestPattern.FindStringSubmatch(path)
+		var changes []string
+		if labels := field("add_labels"); labels != "" {
+			changes = append(changes, fmt.Sprintf("add label %q", labels))
+		}
+		if labels := field("remove_labels"); labels != "" {
+			changes = append(changes, fmt.Sprintf("remove label %q", labels))
+		}
+		if len(changes) == 0 {
+			changes = append(changes, "update")
+		}
+		description := strings.Join(changes, " and ")
+		return fmt.Sprintf("%s%s on MR !%s in project %s", strings.ToUpper(description[:1]), description[1:], match[2], match[1])
+	case createMRPattern.MatchString(path) && method == http.MethodPost:
+		match := createMRPattern.FindStringSubmatch(path)
+		return fmt.Sprintf("Open MR %s -> %s in project %s", field("source_branch"), field("target_branch"), match[1])
+	case branchesPattern.MatchString(path) && method == http.MethodPost:
+		match := branchesPattern.FindStringSubmatch(path)
+		return fmt.Sprintf("Create branch %s from %s in project %s", field("branch"), field("ref"), match[1])
+	}
+
+	// Unknown endpoint, show the raw request
+	if len(body) > 0 {
+		return fmt.Sprintf("%s %s %s", method, path, string(bytes.TrimSpace(body)))
+	}
+	return fmt.Sprintf("%s %s", method, path)
+}
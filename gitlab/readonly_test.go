@@ -0,0 +1,154 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_ReadOnlyRefusesWrites(t *testing.T) {
+	var writes int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			atomic.AddInt32(&writes, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MergeRequest{ID: 5, Title: "Release"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token", WithReadOnly())
+	defer client.Close()
+	require.True(t, client.ReadOnly())
+
+	ctx := context.Background()
+
+	// Reads still reach the server
+	mr, err := client.GetMergeRequest(ctx, 1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "Release", mr.Title)
+
+	_, err = client.CreateMergeRequestNote(ctx, 1, 5, "conflict")
+	assert.True(t, httpapi.IsReadOnly(err))
+	_, err = client.AddMergeRequestLabels(ctx, 1, 5, "merge-conflict")
+	assert.True(t, httpapi.IsReadOnly(err))
+	err = client.RebaseMergeRequest(ctx, 1, 5)
+	assert.True(t, httpapi.IsReadOnly(err))
+
+	assert.Zero(t, atomic.LoadInt32(&writes))
+	assert.Equal(t, []models.SkippedWrite{
+		{Method: http.MethodPost, Path: "/api/v4/projects/1/merge_requests/5/notes", Description: "Comment on MR !5 in project 1"},
+		{Method: http.MethodPut, Path: "/api/v4/projects/1/merge_requests/5", Description: `Add label "merge-conflict" on MR !5 in project 1`},
+		{Method: http.MethodPut, Path: "/api/v4/projects/1/merge_requests/5/rebase", Description: "Rebase MR !5 in project 1"},
+	}, client.SkippedWrites())
+}
+
+func TestClient_ReadOnlyAllowsGraphQLQueries(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"projects":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token", WithReadOnly())
+	defer client.Close()
+
+	_, err := NewGraphQLBackend(client, GraphQLOptions{}).ListRepositories(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Empty(t, client.SkippedWrites())
+
+	// Mutations are writes like any other
+	mutation := graphQLRequest{Query: `mutation { mergeRequestSetLabels(input: {projectPath: "a/b", iid: "5", labelIds: []}) { errors } }`}
+	_, err = client.api.Do(context.Background(), http.MethodPost, graphQLEndpoint, mutation)
+	assert.True(t, httpapi.IsReadOnly(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Len(t, client.SkippedWrites(), 1)
+}
+
+func TestIsGraphQLQuery(t *testing.T) {
+	assert.True(t, isGraphQLQuery("/api/graphql", []byte(`{"query":"query Projects { projects { nodes { id } } }"}`)))
+	assert.True(t, isGraphQLQuery("/gitlab/api/graphql", []byte(`{"query":"  { currentUser { id } }"}`)))
+	assert.False(t, isGraphQLQuery("/api/graphql", []byte(`{"query":"mutation { destroy }"}`)))
+	assert.False(t, isGraphQLQuery("/api/graphql", []byte(`{"query":"query A { a } mutation B { b }"}`)))
+	assert.False(t, isGraphQLQuery("/api/graphql", []byte(`not json`)))
+	assert.False(t, isGraphQLQuery("/api/v4/projects/1/hooks", []byte(`{"query":"query { a }"}`)))
+}
+
+func TestClient_WritableByDefault(t *testing.T) {
+	client := NewClient("https://gitlab.example.com", "test-token")
+	defer client.Close()
+
+	assert.False(t, client.ReadOnly())
+	assert.Nil(t, client.SkippedWrites())
+}
+
+func TestDescribeWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   string
+	}{
+		{
+			name:   "update note",
+			method: http.MethodPut,
+			path:   "/api/v4/projects/1/merge_requests/5/notes/9",
+			want:   "Update comment on MR !5 in project 1",
+		},
+		{
+			name:   "delete note",
+			method: http.MethodDelete,
+			path:   "/api/v4/projects/1/merge_requests/5/notes/9",
+			want:   "Delete comment on MR !5 in project 1",
+		},
+		{
+			name:   "remove label",
+			method: http.MethodPut,
+			path:   "/api/v4/projects/1/merge_requests/5",
+			body:   `{"remove_labels":"merge-conflict"}`,
+			want:   `Remove label "merge-conflict" on MR !5 in project 1`,
+		},
+		{
+			name:   "create merge request",
+			method: http.MethodPost,
+			path:   "/api/v4/projects/1/merge_requests",
+			body:   `{"source_branch":"master","target_branch":"release"}`,
+			want:   "Open MR master -> release in project 1",
+		},
+		{
+			name:   "create branch",
+			method: http.MethodPost,
+			path:   "/api/v4/projects/1/repository/branches",
+			body:   `{"branch":"sync/release-2024-01-11","ref":"master"}`,
+			want:   "Create branch sync/release-2024-01-11 from master in project 1",
+		},
+		{
+			name:   "unknown endpoint",
+			method: http.MethodPost,
+			path:   "/api/v4/projects/1/hooks",
+			body:   `{"url":"https://example.com"}`,
+			want:   `POST /api/v4/projects/1/hooks {"url":"https://example.com"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, describeWrite(tt.method, tt.path, []byte(tt.body)))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// fileChange is a single file entry of a GitLab diff
+type fileChange struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+	Diff        string `json:"diff"`
+}
+
+// GetMergeBase retrieves the SHA of the common ancestor of two refs
+func (c *Client) GetMergeBase(ctx context.Context, projectID int, ref1, ref2 string) (string, error) {
+	params := url.Values{}
+	params.Add("refs[]", ref1)
+	params.Add("refs[]", ref2)
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/repository/merge_base?%s", projectID, params.Encode())
+
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get merge base of %s and %s for project %d: %w", ref1, ref2, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&commit); err != nil {
+		return "", fmt.Errorf("failed to decode merge base response: %w", err)
+	}
+
+	return commit.ID, nil
+}
+
+// CompareChangedFiles retrieves the paths of the files that differ between two refs
+func (c *Client) CompareChangedFiles(ctx context.Context, projectID int, from, to string) ([]string, error) {
+	params := url.Values{}
+	params.Set("from", from)
+	params.Set("to", to)
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/repository/compare?%s", projectID, params.Encode())
+
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s for project %d: %w", from, to, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var comparison struct {
+		Diffs []fileChange `json:"diffs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&comparison); err != nil {
+		return nil, fmt.Errorf("failed to decode compare response: %w", err)
+	}
+
+	return changedPaths(comparison.Diffs), nil
+}
+
+// ConflictingFiles estimates the files a merge request conflicts on: those changed both by the
+// merge request and on the target branch since the two branches diverged
+func (c *Client) ConflictingFiles(ctx context.Context, projectID int, mr models.MergeRequest) ([]string, error) {
+	mrFiles, err := c.GetMergeRequestChangedFiles(ctx, projectID, mr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeBase, err := c.GetMergeBase(ctx, projectID, mr.SourceBranch, mr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	targetFiles, err := c.CompareChangedFiles(ctx, projectID, mergeBase, mr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	changedOnTarget := make(map[string]bool, len(targetFiles))
+	for _, path := range targetFiles {
+		changedOnTarget[path] = true
+	}
+
+	var conflicting []string
+	for _, path := range mrFiles {
+		if changedOnTarget[path] {
+			conflicting = append(conflicting, path)
+		}
+	}
+
+	return conflicting, nil
+}
+
+// BranchProtected reports whether a branch of the project is protected; a missing branch is not
+func (c *Client) BranchProtected(ctx context.Context, projectID int, branch string) (bool, error) {
+	result, err := c.GetBranch(ctx, projectID, branch)
+	if err != nil || result == nil {
+		return false, err
+	}
+	return result.Protected, nil
+}
+
+// GetBranch retrieves a branch, returning nil if it does not exist
+func (c *Client) GetBranch(ctx context.Context, projectID int, branch string) (*models.Branch, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/repository/branches/%s", projectID, url.PathEscape(branch))
+
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
+	if httpapi.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch %s for project %d: %w", branch, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var result models.Branch
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode branch response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// CreateBranch creates a branch from a ref
+func (c *Client) CreateBranch(ctx context.Context, projectID int, branch, ref string) (*models.Branch, error) {
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/repository/branches", projectID)
+
+	resp, err := c.api.Do(ctx, "POST", endpoint, map[string]string{"branch": branch, "ref": ref})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch %s from %s for project %d: %w", branch, ref, projectID, err)
+	}
+	defer resp.Body.Close()
+
+	var result models.Branch
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode branch response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// changedPaths returns the sorted, de-duplicated paths touched by a list of file changes
+func changedPaths(changes []fileChange) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, change := range changes {
+		for _, path := range []string{change.OldPath, change.NewPath} {
+			if path != "" && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,131 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+)
+
+func TestClient_ConflictingFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v4/projects/1/merge_requests/5/changes":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"changes": []map[string]interface{}{
+					{"old_path": "README.md", "new_path": "README.md", "diff": "@@"},
+					{"old_path": "main.go", "new_path": "main.go", "diff": "@@"},
+					{"old_path": "old.go", "new_path": "new.go", "renamed_file": true},
+				},
+			})
+		case "/api/v4/projects/1/repository/merge_base":
+			assert.Equal(t, []string{"release", "master"}, r.URL.Query()["refs[]"])
+			json.NewEncoder(w).Encode(map[string]string{"id": "abc123"})
+		case "/api/v4/projects/1/repository/compare":
+			assert.Equal(t, "abc123", r.URL.Query().Get("from"))
+			assert.Equal(t, "master", r.URL.Query().Get("to"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"diffs": []map[string]interface{}{
+					{"old_path": "main.go", "new_path": "main.go"},
+					{"old_path": "old.go", "new_path": "old.go"},
+					{"old_path": "docs/guide.md", "new_path": "docs/guide.md"},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	mr := models.MergeRequest{ID: 5, SourceBranch: "release", TargetBranch: "master"}
+	files, err := client.ConflictingFiles(context.Background(), 1, mr)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "old.go"}, files)
+}
+
+func TestChangedPaths(t *testing.T) {
+	paths := changedPaths([]fileChange{
+		{OldPath: "b.go", NewPath: "b.go"},
+		{OldPath: "a.go", NewPath: "c.go"},
+		{NewPath: "a.go"},
+	})
+	assert.Equal(t, []string{"a.go", "b.go", "c.go"}, paths)
+}
+
+func TestClient_GetAndCreateBranch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/projects/1/repository/branches/sync%2Frelease-2024-01-10":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Branch Not Found"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/1/repository/branches/master":
+			json.NewEncoder(w).Encode(models.Branch{Name: "master"})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/1/repository/branches":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]string{"branch": "sync/release-2024-01-10", "ref": "master"}, body)
+			json.NewEncoder(w).Encode(models.Branch{Name: body["branch"]})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	ctx := context.Background()
+
+	branch, err := client.GetBranch(ctx, 1, "master")
+	require.NoError(t, err)
+	require.NotNil(t, branch)
+	assert.Equal(t, "master", branch.Name)
+
+	branch, err = client.GetBranch(ctx, 1, "sync/release-2024-01-10")
+	require.NoError(t, err)
+	assert.Nil(t, branch)
+
+	branch, err = client.CreateBranch(ctx, 1, "sync/release-2024-01-10", "master")
+	require.NoError(t, err)
+	assert.Equal(t, "sync/release-2024-01-10", branch.Name)
+}
+
+func TestClient_BranchProtected(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.AddProject(models.Repository{ID: 1, Name: "api"})
+	server.ProtectBranch(1, "master")
+	server.AddBranch(1, "develop")
+
+	client := NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+	ctx := context.Background()
+
+	protected, err := client.BranchProtected(ctx, 1, "master")
+	require.NoError(t, err)
+	assert.True(t, protected)
+
+	protected, err = client.BranchProtected(ctx, 1, "develop")
+	require.NoError(t, err)
+	assert.False(t, protected)
+
+	// A missing branch is not protected
+	protected, err = client.BranchProtected(ctx, 1, "gone")
+	require.NoError(t, err)
+	assert.False(t, protected)
+}
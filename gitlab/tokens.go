@@ -0,0 +1,77 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// GitLab token scopes used by this tool
+const (
+	ScopeAPI     = "api"
+	ScopeReadAPI = "read_api"
+)
+
+// ScopeRequirement is a token scope needed by an enabled feature
+type ScopeRequirement struct {
+	Scope   string
+	Feature string
+}
+
+// String renders the requirement for error messages
+func (r ScopeRequirement) String() string {
+	return fmt.Sprintf("%s (needed for %s)", r.Scope, r.Feature)
+}
+
+// GetTokenInfo retrieves the scopes and expiry of the personal access token the client uses
+func (c *Client) GetTokenInfo(ctx context.Context) (*models.TokenInfo, error) {
+	endpoint := "/api/v4/personal_access_tokens/self"
+
+	resp, err := c.api.Do(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token information: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var info models.TokenInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode token information: %w", err)
+	}
+
+	return &info, nil
+}
+
+// CheckTokenActive returns an error when GitLab reports the token as revoked or no longer active
+func CheckTokenActive(info *models.TokenInfo) error {
+	switch {
+	case info.Revoked:
+		return fmt.Errorf("GitLab token %q has been revoked", info.Name)
+	case info.Active:
+		return nil
+	case info.ExpiresAt != "":
+		return fmt.Errorf("GitLab token %q is no longer active, it expired on %s", info.Name, info.ExpiresAt)
+	default:
+		return fmt.Errorf("GitLab token %q is no longer active", info.Name)
+	}
+}
+
+// MissingScopes returns the requirements the token's scopes do not satisfy; api grants everything read_api does
+func MissingScopes(info *models.TokenInfo, required []ScopeRequirement) []ScopeRequirement {
+	granted := make(map[string]bool)
+	for _, scope := range info.Scopes {
+		granted[scope] = true
+	}
+	if granted[ScopeAPI] {
+		granted[ScopeReadAPI] = true
+	}
+
+	var missing []ScopeRequirement
+	for _, requirement := range required {
+		if !granted[requirement.Scope] {
+			missing = append(missing, requirement)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,81 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestClient_GetTokenInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/personal_access_tokens/self", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":42,"name":"conflict-checker","scopes":["read_api"],"active":true,"revoked":false,"expires_at":"2024-10-15"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	info, err := client.GetTokenInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "conflict-checker", info.Name)
+	assert.Equal(t, []string{"read_api"}, info.Scopes)
+	assert.Equal(t, "2024-10-15", info.ExpiresAt)
+}
+
+func TestClient_GetTokenInfo_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	defer client.Close()
+
+	_, err := client.GetTokenInfo(context.Background())
+	require.Error(t, err)
+	assert.True(t, httpapi.IsNotFound(err))
+}
+
+func TestCheckTokenActive(t *testing.T) {
+	assert.NoError(t, CheckTokenActive(&models.TokenInfo{Name: "checker", Active: true}))
+	assert.EqualError(t, CheckTokenActive(&models.TokenInfo{Name: "checker", Active: true, Revoked: true}),
+		`GitLab token "checker" has been revoked`)
+	assert.EqualError(t, CheckTokenActive(&models.TokenInfo{Name: "checker", ExpiresAt: "2024-10-15"}),
+		`GitLab token "checker" is no longer active, it expired on 2024-10-15`)
+	assert.EqualError(t, CheckTokenActive(&models.TokenInfo{Name: "checker"}),
+		`GitLab token "checker" is no longer active`)
+}
+
+func TestMissingScopes(t *testing.T) {
+	scanning := ScopeRequirement{Scope: ScopeReadAPI, Feature: "scanning repositories"}
+	writes := ScopeRequirement{Scope: ScopeAPI, Feature: "write actions"}
+
+	tests := []struct {
+		name     string
+		scopes   []string
+		required []ScopeRequirement
+		want     []ScopeRequirement
+	}{
+		{name: "read_api for scanning", scopes: []string{"read_api"}, required: []ScopeRequirement{scanning}},
+		{name: "api implies read_api", scopes: []string{"api"}, required: []ScopeRequirement{scanning, writes}},
+		{name: "read_api is not enough for writes", scopes: []string{"read_api"}, required: []ScopeRequirement{scanning, writes}, want: []ScopeRequirement{writes}},
+		{name: "unrelated scopes", scopes: []string{"read_user"}, required: []ScopeRequirement{scanning}, want: []ScopeRequirement{scanning}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MissingScopes(&models.TokenInfo{Scopes: tt.scopes}, tt.required))
+		})
+	}
+
+	assert.Equal(t, "api (needed for write actions)", writes.String())
+}
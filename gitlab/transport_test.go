@@ -0,0 +1,193 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/httpapi"
+)
+
+// newTLSUserServer starts a TLS server answering the connection test endpoint
+func newTLSUserServer(t *testing.T) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writePEM writes a PEM block to a file in the test's temporary directory
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+// testConnection runs the connection test through an HTTP client built from opts
+func testConnection(t *testing.T, serverURL string, opts TransportOptions) error {
+	httpClient, err := NewHTTPClient(opts)
+	require.NoError(t, err)
+
+	client := NewClient(serverURL, "test-token", WithHTTPClient(httpClient))
+	defer client.Close()
+	return client.TestConnection(context.Background())
+}
+
+func TestNewHTTPClient_CAFile(t *testing.T) {
+	server := newTLSUserServer(t)
+
+	// The test server's certificate is not trusted by default
+	assert.Error(t, testConnection(t, server.URL, TransportOptions{}))
+
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	assert.NoError(t, testConnection(t, server.URL, TransportOptions{CAFile: caFile}))
+}
+
+func TestNewHTTPClient_InsecureSkipVerify(t *testing.T) {
+	server := newTLSUserServer(t)
+
+	assert.NoError(t, testConnection(t, server.URL, TransportOptions{InsecureSkipVerify: true}))
+}
+
+func TestNewHTTPClient_ClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mr-conflict-checker"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	clientCert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	assert.Error(t, testConnection(t, server.URL, TransportOptions{CAFile: caFile}))
+
+	certFile := writePEM(t, "client.pem", "CERTIFICATE", certDER)
+	keyFile := writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDER)
+	assert.NoError(t, testConnection(t, server.URL, TransportOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}))
+}
+
+func TestNewHTTPClient_InvalidFiles(t *testing.T) {
+	_, err := NewHTTPClient(TransportOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "failed to read CA file")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+	_, err = NewHTTPClient(TransportOptions{CAFile: notPEM})
+	assert.ErrorContains(t, err, "no PEM certificates found")
+
+	_, err = NewHTTPClient(TransportOptions{CertFile: notPEM, KeyFile: notPEM})
+	assert.ErrorContains(t, err, "failed to load client certificate")
+
+	_, err = NewHTTPClient(TransportOptions{ProxyURL: "not a url"})
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		atomic.AddInt32(&proxied, 1)
+		assert.Equal(t, "gitlab.internal.example", r.URL.Hostname())
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer proxy.Close()
+
+	assert.NoError(t, testConnection(t, "http://gitlab.internal.example", TransportOptions{ProxyURL: proxy.URL}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+
+	// Hosts listed in NO_PROXY are reached directly
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer direct.Close()
+
+	assert.NoError(t, testConnection(t, direct.URL, TransportOptions{ProxyURL: proxy.URL, NoProxy: "127.0.0.0/8"}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+}
+
+func TestNewHTTPClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	err := testConnection(t, server.URL, TransportOptions{Timeout: 20 * time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Client.Timeout")
+}
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		target  string
+		noProxy string
+		want    bool
+	}{
+		{"https://gitlab.example.com", "", false},
+		{"https://gitlab.example.com", "*", true},
+		{"https://gitlab.example.com", "example.com", true},
+		{"https://gitlab.example.com", ".example.com", true},
+		{"https://example.com", ".example.com", true},
+		{"https://notexample.com", "example.com", false},
+		{"https://gitlab.example.com", "other.com, gitlab.example.com", true},
+		{"https://gitlab.example.com", "gitlab.example.com:443", true},
+		{"https://gitlab.example.com:8443", "gitlab.example.com:443", false},
+		{"http://10.1.2.3:8080", "10.0.0.0/8", true},
+		{"http://192.168.1.1", "10.0.0.0/8", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" "+tt.noProxy, func(t *testing.T) {
+			target, err := url.Parse(tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, bypassProxy(target, tt.noProxy))
+		})
+	}
+}
+
+func TestWithHTTPClient_KeepsReadOnly(t *testing.T) {
+	server := newTLSUserServer(t)
+
+	client := NewClient(server.URL, "test-token", WithReadOnly(), WithHTTPClient(server.Client()))
+	defer client.Close()
+
+	require.NoError(t, client.TestConnection(context.Background()))
+	_, err := client.CreateMergeRequestNote(context.Background(), 1, 1, "note")
+	assert.True(t, httpapi.IsReadOnly(err))
+}
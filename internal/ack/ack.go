@@ -0,0 +1,191 @@
+// Package ack keeps the conflicting merge requests acknowledged until a date with the ack subcommand.
+package ack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// DefaultFileName is the acknowledgements file kept in the output directory unless one is configured
+const DefaultFileName = ".mr-conflict-checker-acks.json"
+
+// Acknowledgement is a conflicting merge request someone knows about, until it expires
+type Acknowledgement struct {
+	Project        string    `json:"project"` // Path with namespace, or numeric ID
+	MergeRequestID int       `json:"mr_iid"`
+	By             string    `json:"by"`
+	Reason         string    `json:"reason"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// Active reports whether the acknowledgement has not expired at now
+func (a Acknowledgement) Active(now time.Time) bool {
+	return now.Before(a.ExpiresAt)
+}
+
+// matches reports whether the acknowledgement is for the merge request of the repository
+func (a Acknowledgement) matches(repo models.Repository, mrID int) bool {
+	if a.MergeRequestID != mrID {
+		return false
+	}
+	return (repo.PathWithNamespace != "" && strings.EqualFold(a.Project, repo.PathWithNamespace)) ||
+		(repo.ID != 0 && a.Project == strconv.Itoa(repo.ID))
+}
+
+// Store is the content of an acknowledgements file
+type Store struct {
+	Acknowledgements []Acknowledgement `json:"acknowledgements"`
+}
+
+// Load reads an acknowledgements file, returning an empty store when there is none yet
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Store{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read acknowledgements file: %w", err)
+	}
+
+	var store Store
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgements file %s: %w", path, err)
+	}
+	return &store, nil
+}
+
+// Save writes the acknowledgements file, replacing the previous one atomically
+func (s *Store) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode acknowledgements: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create acknowledgements directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write acknowledgements file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write acknowledgements file: %w", err)
+	}
+	return nil
+}
+
+// Add records an acknowledgement, replacing an earlier one for the same merge request
+func (s *Store) Add(a Acknowledgement) {
+	s.Remove(a.Project, a.MergeRequestID)
+	s.Acknowledgements = append(s.Acknowledgements, a)
+	sort.SliceStable(s.Acknowledgements, func(i, j int) bool {
+		if s.Acknowledgements[i].Project == s.Acknowledgements[j].Project {
+			return s.Acknowledgements[i].MergeRequestID < s.Acknowledgements[j].MergeRequestID
+		}
+		return s.Acknowledgements[i].Project < s.Acknowledgements[j].Project
+	})
+}
+
+// Remove drops the acknowledgement of a merge request, reporting whether there was one
+func (s *Store) Remove(project string, mrID int) bool {
+	removed := false
+	kept := s.Acknowledgements[:0]
+	for _, a := range s.Acknowledgements {
+		if strings.EqualFold(a.Project, project) && a.MergeRequestID == mrID {
+			removed = true
+			continue
+		}
+		kept = append(kept, a)
+	}
+	s.Acknowledgements = kept
+	return removed
+}
+
+// RemoveRepository drops the acknowledgements of a merge request of the repository, whether they
+// name it by path or by ID, reporting whether there was one
+func (s *Store) RemoveRepository(repo models.Repository, mrID int) bool {
+	removed := false
+	kept := s.Acknowledgements[:0]
+	for _, a := range s.Acknowledgements {
+		if a.matches(repo, mrID) {
+			removed = true
+			continue
+		}
+		kept = append(kept, a)
+	}
+	s.Acknowledgements = kept
+	return removed
+}
+
+// Prune drops the acknowledgements expired at now and returns them
+func (s *Store) Prune(now time.Time) []Acknowledgement {
+	var expired []Acknowledgement
+	kept := s.Acknowledgements[:0]
+	for _, a := range s.Acknowledgements {
+		if a.Active(now) {
+			kept = append(kept, a)
+		} else {
+			expired = append(expired, a)
+		}
+	}
+	s.Acknowledgements = kept
+	return expired
+}
+
+// Find returns the acknowledgement of a merge request of the repository that is still active at now
+func (s *Store) Find(repo models.Repository, mrID int, now time.Time) (Acknowledgement, bool) {
+	for _, a := range s.Acknowledgements {
+		if a.matches(repo, mrID) && a.Active(now) {
+			return a, true
+		}
+	}
+	return Acknowledgement{}, false
+}
+
+// Mark records on the report which conflicting merge requests are acknowledged at now
+func (s *Store) Mark(report *models.Report, now time.Time) {
+	report.TotalAcknowledgedMRs = 0
+	for i := range report.Repositories {
+		repoReport := &report.Repositories[i]
+		repoReport.Acknowledgements = nil
+		for _, mr := range repoReport.ConflictingMRs {
+			a, ok := s.Find(repoReport.Repository, mr.ID, now)
+			if !ok {
+				continue
+			}
+			repoReport.Acknowledgements = append(repoReport.Acknowledgements, models.Acknowledgement{
+				MergeRequestID: mr.ID,
+				By:             a.By,
+				Reason:         a.Reason,
+				AcknowledgedAt: a.AcknowledgedAt,
+				ExpiresAt:      a.ExpiresAt,
+			})
+		}
+		report.TotalAcknowledgedMRs += len(repoReport.Acknowledgements)
+	}
+}
+
+// ParseExpiry parses the end of an acknowledgement: a date acknowledges through the end of that day
+// in UTC, an RFC 3339 time until that time
+func ParseExpiry(value string) (time.Time, error) {
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		return day.AddDate(0, 0, 1), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q, expected a date such as 2024-04-01 or an RFC 3339 time", value)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,131 @@
+package ack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", DefaultFileName)
+	saved := &Store{Acknowledgements: []Acknowledgement{{
+		Project:        "group/app",
+		MergeRequestID: 12,
+		By:             "lead",
+		Reason:         "fix after the freeze",
+		AcknowledgedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		ExpiresAt:      time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC),
+	}}}
+
+	require.NoError(t, saved.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved, loaded)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	loaded, err := Load(filepath.Join(t.TempDir(), DefaultFileName))
+	require.NoError(t, err)
+	assert.Empty(t, loaded.Acknowledgements)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "failed to parse acknowledgements file")
+}
+
+func TestStore_AddReplacesAndRemove(t *testing.T) {
+	store := &Store{}
+	store.Add(Acknowledgement{Project: "group/web", MergeRequestID: 3, Reason: "first"})
+	store.Add(Acknowledgement{Project: "group/app", MergeRequestID: 12, Reason: "first"})
+	store.Add(Acknowledgement{Project: "group/web", MergeRequestID: 3, Reason: "second"})
+
+	require.Len(t, store.Acknowledgements, 2)
+	assert.Equal(t, "group/app", store.Acknowledgements[0].Project)
+	assert.Equal(t, "second", store.Acknowledgements[1].Reason)
+
+	assert.True(t, store.Remove("Group/Web", 3))
+	assert.False(t, store.Remove("group/web", 3))
+	require.Len(t, store.Acknowledgements, 1)
+}
+
+func TestStore_RemoveRepository(t *testing.T) {
+	store := &Store{}
+	store.Add(Acknowledgement{Project: "group/app", MergeRequestID: 12})
+	store.Add(Acknowledgement{Project: "42", MergeRequestID: 12})
+	store.Add(Acknowledgement{Project: "group/app", MergeRequestID: 13})
+
+	// Known by both path and ID, the repository matches the acknowledgements naming it either way
+	assert.True(t, store.RemoveRepository(models.Repository{ID: 42, PathWithNamespace: "Group/App"}, 12))
+	assert.Equal(t, []Acknowledgement{{Project: "group/app", MergeRequestID: 13}}, store.Acknowledgements)
+
+	// An ID alone does not match an acknowledgement naming the path
+	assert.False(t, store.RemoveRepository(models.Repository{ID: 42}, 13))
+	assert.True(t, store.RemoveRepository(models.Repository{PathWithNamespace: "group/app"}, 13))
+	assert.Empty(t, store.Acknowledgements)
+}
+
+func TestStore_Prune(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &Store{Acknowledgements: []Acknowledgement{
+		{Project: "group/app", MergeRequestID: 1, ExpiresAt: now},
+		{Project: "group/app", MergeRequestID: 2, ExpiresAt: now.Add(time.Second)},
+	}}
+
+	expired := store.Prune(now)
+
+	require.Len(t, expired, 1)
+	assert.Equal(t, 1, expired[0].MergeRequestID)
+	require.Len(t, store.Acknowledgements, 1)
+	assert.Equal(t, 2, store.Acknowledgements[0].MergeRequestID)
+}
+
+func TestStore_Mark(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(24 * time.Hour)
+	store := &Store{Acknowledgements: []Acknowledgement{
+		{Project: "group/app", MergeRequestID: 1, By: "lead", Reason: "after the freeze", ExpiresAt: expiresAt},
+		{Project: "2", MergeRequestID: 5, By: "lead", Reason: "by ID", ExpiresAt: expiresAt},
+		{Project: "group/app", MergeRequestID: 2, By: "lead", Reason: "expired", ExpiresAt: now.Add(-time.Hour)},
+	}}
+
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1, PathWithNamespace: "Group/App"},
+		[]models.MergeRequest{{ID: 1}, {ID: 2}}, models.StatusConflicts, "")
+	report.AddRepository(models.Repository{ID: 2, PathWithNamespace: "group/web"},
+		[]models.MergeRequest{{ID: 5}}, models.StatusConflicts, "")
+
+	store.Mark(report, now)
+
+	assert.Equal(t, 2, report.TotalAcknowledgedMRs)
+	assert.Equal(t, []models.Acknowledgement{{MergeRequestID: 1, By: "lead", Reason: "after the freeze", ExpiresAt: expiresAt}},
+		report.Repositories[0].Acknowledgements)
+	_, ok := report.Repositories[0].Acknowledgement(2)
+	assert.False(t, ok, "expired acknowledgements mark nothing")
+	a, ok := report.Repositories[1].Acknowledgement(5)
+	assert.True(t, ok)
+	assert.Equal(t, "by ID", a.Reason)
+}
+
+func TestParseExpiry(t *testing.T) {
+	expiresAt, err := ParseExpiry("2024-04-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), expiresAt)
+
+	expiresAt, err = ParseExpiry("2024-04-01T09:30:00+02:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 1, 7, 30, 0, 0, time.UTC), expiresAt)
+
+	_, err = ParseExpiry("next week")
+	assert.ErrorContains(t, err, `invalid expiry "next week"`)
+}
@@ -0,0 +1,204 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"mr-conflict-checker/internal/redact"
+)
+
+// credentialHeaders are never written to a cassette
+var credentialHeaders = []string{"Authorization", "Private-Token", "Job-Token", "Cookie", "Set-Cookie"}
+
+// Cassette is a recorded sequence of HTTP interactions
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded part of an HTTP request; the host is left out so a cassette
+// replays against any base URL
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"` // Path and query
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// key identifies the requests an interaction answers
+func (r Request) key() string {
+	return r.Method + " " + r.URL
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette with every secret known at this point scrubbed
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(redact.String(string(data))), 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder is an http.RoundTripper that sends requests through next and records them
+type Recorder struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a recorder in front of next, or http.DefaultTransport when next is nil
+func NewRecorder(next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	headers := resp.Header.Clone()
+	for _, name := range credentialHeaders {
+		headers.Del(name)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Body:   string(requestBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    headers,
+			Body:       string(responseBody),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Cassette returns a copy of everything recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette without touching the network
+type Replayer struct {
+	mu      sync.Mutex
+	pending map[string][]Response
+	last    map[string]Response
+}
+
+// NewReplayer creates a replayer; requests seen several times get their recorded responses
+// in order, and the last one once those run out
+func NewReplayer(c *Cassette) *Replayer {
+	r := &Replayer{
+		pending: make(map[string][]Response),
+		last:    make(map[string]Response),
+	}
+	for _, interaction := range c.Interactions {
+		key := interaction.Request.key()
+		r.pending[key] = append(r.pending[key], interaction.Response)
+		r.last[key] = interaction.Response
+	}
+	return r
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := Request{Method: req.Method, URL: req.URL.RequestURI()}.key()
+
+	r.mu.Lock()
+	recorded, ok := r.last[key]
+	if queue := r.pending[key]; len(queue) > 0 {
+		recorded, r.pending[key] = queue[0], queue[1:]
+	}
+	r.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("cassette has no recorded response for %s", key)
+	}
+
+	headers := recorded.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
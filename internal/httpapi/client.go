@@ -0,0 +1,228 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
+)
+
+// Config describes a JSON REST API for NewClient; the hooks cover what differs between APIs
+type Config struct {
+	Service    string // Name of the service in connection errors and logs, e.g. "GitHub"
+	BaseURL    string // URL the endpoints are appended to, API path included
+	HTTPClient *http.Client
+	ReadOnly   bool // Refuse every request other than GET and HEAD at the transport layer
+	// ReadOnlyPolicy adapts the read-only check to the API
+	ReadOnlyPolicy ReadOnlyPolicy
+
+	// SetHeaders sets the authentication and Accept headers of every request
+	SetHeaders func(req *http.Request) error
+	// Refresh renews the credentials after req was rejected with 401 and reports whether the
+	// request is worth sending once more; optional
+	Refresh func(ctx context.Context, req *http.Request) (bool, error)
+	// Throttle blocks until the next request may be sent; optional
+	Throttle func(ctx context.Context) error
+	// CheckResponse returns an error for a response the API fails in its own way, e.g. an
+	// exhausted rate limit, before the status is checked; optional
+	CheckResponse func(resp *http.Response) error
+	// ErrorMessage extracts the message of an error response body; defaults to MessageField
+	ErrorMessage func(body []byte) string
+
+	// UserEndpoint is requested by TestConnection
+	UserEndpoint string
+	// User returns the authenticated user named by the response to UserEndpoint; defaults to LoginField
+	User func(resp *http.Response) (string, error)
+}
+
+// Client sends the requests of a JSON REST API: it builds and authenticates them, encodes and
+// decodes their bodies and turns error statuses into APIErrors
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	skipped    *ReadOnlyTransport
+}
+
+// NewClient creates a client for the API described by cfg; in read-only mode the read-only check
+// wraps the transport of cfg.HTTPClient
+func NewClient(cfg Config) *Client {
+	if cfg.ErrorMessage == nil {
+		cfg.ErrorMessage = MessageField
+	}
+	if cfg.User == nil {
+		cfg.User = LoginField
+	}
+
+	client := &Client{cfg: cfg, httpClient: cfg.HTTPClient}
+	if cfg.ReadOnly {
+		client.httpClient, client.skipped = ReadOnlyClient(cfg.HTTPClient, cfg.ReadOnlyPolicy)
+	}
+	return client
+}
+
+// BaseURL returns the URL the endpoints are appended to
+func (c *Client) BaseURL() string {
+	return c.cfg.BaseURL
+}
+
+// ReadOnly reports whether the client refuses write requests
+func (c *Client) ReadOnly() bool {
+	return c.cfg.ReadOnly
+}
+
+// SkippedWrites returns the write requests refused so far by a read-only client
+func (c *Client) SkippedWrites() []models.SkippedWrite {
+	if c.skipped == nil {
+		return nil
+	}
+	return c.skipped.SkippedWrites()
+}
+
+// TestConnection verifies that the token is accepted by requesting UserEndpoint
+func (c *Client) TestConnection(ctx context.Context) error {
+	resp, err := c.Do(ctx, http.MethodGet, c.cfg.UserEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.cfg.Service, err)
+	}
+	defer resp.Body.Close()
+
+	user, err := c.cfg.User(resp)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.cfg.Service, err)
+	}
+	slog.Debug("Authenticated to "+c.cfg.Service, "user", user)
+	return nil
+}
+
+// Get performs a GET request and decodes the JSON response into result
+func (c *Client) Get(ctx context.Context, endpoint string, result interface{}) error {
+	resp, err := c.Do(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Write performs a write request, which a read-only client records under description
+func (c *Client) Write(ctx context.Context, method, endpoint string, body interface{}, description string) (*http.Response, error) {
+	return c.Do(WithDescription(ctx, description), method, endpoint, body)
+}
+
+// Do performs an authenticated HTTP request, sending body as JSON if it is not nil; error statuses
+// are returned as an *APIError with a redacted message
+func (c *Client) Do(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+
+	resp, req, err := c.send(ctx, method, endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Renew expired credentials once and retry
+	if resp.StatusCode == http.StatusUnauthorized && c.cfg.Refresh != nil {
+		resp.Body.Close()
+		refreshed, err := c.cfg.Refresh(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+		if !refreshed {
+			return nil, fmt.Errorf("authentication failed: invalid token")
+		}
+		if resp, _, err = c.send(ctx, method, endpoint, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, fmt.Errorf("authentication failed: invalid token")
+	}
+
+	if c.cfg.CheckResponse != nil {
+		if err := c.cfg.CheckResponse(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: redact.String(c.cfg.ErrorMessage(respBody))}
+	}
+
+	return resp, nil
+}
+
+// send builds, authenticates and sends a single request
+func (c *Client) send(ctx context.Context, method, endpoint string, data []byte) (*http.Response, *http.Request, error) {
+	if c.cfg.Throttle != nil {
+		if err := c.cfg.Throttle(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var bodyReader io.Reader
+	if data != nil {
+		bodyReader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.BaseURL+endpoint, bodyReader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.cfg.SetHeaders(req); err != nil {
+		return nil, nil, fmt.Errorf("failed to authenticate request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if IsReadOnly(err) {
+		return nil, nil, ErrReadOnly
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, req, nil
+}
+
+// MessageField returns the message field of a JSON error response, or the body as is
+func MessageField(body []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		return apiErr.Message
+	}
+	return string(body)
+}
+
+// LoginField returns the login field of a JSON user response
+func LoginField(resp *http.Response) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return user.Login, nil
+}
@@ -0,0 +1,196 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// newTestClient starts a server answering with handler and a client for it below /api
+func newTestClient(t *testing.T, handler http.HandlerFunc, configure func(*Config)) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := Config{
+		Service:    "Example",
+		BaseURL:    server.URL + "/api",
+		HTTPClient: &http.Client{},
+		SetHeaders: func(req *http.Request) error {
+			req.Header.Set("Authorization", "token secret")
+			return nil
+		},
+		UserEndpoint: "/user",
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+	return NewClient(cfg)
+}
+
+func TestClient_Do(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/repos/1/comments", r.URL.Path)
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"body":"conflict"}`, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}, nil)
+
+	resp, err := client.Write(context.Background(), http.MethodPost, "/repos/1/comments", map[string]string{"body": "conflict"}, "Comment")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestClient_Get(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Type"))
+		w.Write([]byte(`{"id":7}`))
+	}, nil)
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, client.Get(context.Background(), "/repos/7", &result))
+	assert.Equal(t, 7, result.ID)
+}
+
+func TestClient_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		configure func(*Config)
+		errMsg    string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, errMsg: "authentication failed: invalid token"},
+		{name: "message field", status: http.StatusNotFound, body: `{"message":"Not Found"}`, errMsg: "API error 404: Not Found"},
+		{name: "plain body", status: http.StatusBadGateway, body: "bad gateway", errMsg: "API error 502: bad gateway"},
+		{
+			name:   "error message hook",
+			status: http.StatusConflict,
+			body:   `{"errors":["stale"]}`,
+			configure: func(cfg *Config) {
+				cfg.ErrorMessage = func([]byte) string { return "stale version" }
+			},
+			errMsg: "API error 409: stale version",
+		},
+		{
+			name:   "response check",
+			status: http.StatusForbidden,
+			configure: func(cfg *Config) {
+				cfg.CheckResponse = func(resp *http.Response) error { return errors.New("rate limit exceeded") }
+			},
+			errMsg: "rate limit exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}, tt.configure)
+
+			_, err := client.Do(context.Background(), http.MethodGet, "/repos", nil)
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestClient_Refresh(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token renewed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":7}`))
+	}, func(cfg *Config) {
+		token := "expired"
+		cfg.SetHeaders = func(req *http.Request) error {
+			req.Header.Set("Authorization", "token "+token)
+			return nil
+		}
+		cfg.Refresh = func(ctx context.Context, req *http.Request) (bool, error) {
+			token = "renewed"
+			return true, nil
+		}
+	})
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, client.Get(context.Background(), "/repos/7", &result))
+	assert.Equal(t, 7, result.ID)
+
+	rejected := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}, func(cfg *Config) {
+		cfg.Refresh = func(ctx context.Context, req *http.Request) (bool, error) {
+			return false, nil
+		}
+	})
+	_, err := rejected.Do(context.Background(), http.MethodGet, "/repos", nil)
+	assert.EqualError(t, err, "authentication failed: invalid token")
+}
+
+func TestClient_Throttle(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}, func(cfg *Config) {
+		cfg.Throttle = func(ctx context.Context) error {
+			return errors.New("throttled")
+		}
+	})
+
+	_, err := client.Do(context.Background(), http.MethodGet, "/repos", nil)
+	assert.EqualError(t, err, "throttled")
+	assert.Zero(t, requests)
+}
+
+func TestClient_ReadOnly(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}, func(cfg *Config) {
+		cfg.ReadOnly = true
+	})
+	assert.True(t, client.ReadOnly())
+
+	_, err := client.Write(context.Background(), http.MethodDelete, "/repos/1/comments/2", nil, "Delete comment 2")
+	assert.True(t, IsReadOnly(err))
+	assert.Zero(t, requests)
+	assert.Equal(t, []models.SkippedWrite{{Method: http.MethodDelete, Path: "/api/repos/1/comments/2", Description: "Delete comment 2"}}, client.SkippedWrites())
+
+	writable := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {}, nil)
+	assert.False(t, writable.ReadOnly())
+	assert.Nil(t, writable.SkippedWrites())
+}
+
+func TestClient_TestConnection(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/user", r.URL.Path)
+		w.Write([]byte(`{"login":"octocat"}`))
+	}, nil)
+	assert.NoError(t, client.TestConnection(context.Background()))
+
+	anonymous := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {}, func(cfg *Config) {
+		cfg.User = func(resp *http.Response) (string, error) {
+			return "", errors.New("authentication failed: the token was not accepted")
+		}
+	})
+	assert.EqualError(t, anonymous.TestConnection(context.Background()), "failed to connect to Example: authentication failed: the token was not accepted")
+}
@@ -0,0 +1,33 @@
+// Package httpapi holds the HTTP plumbing shared by the GitLab, GitHub, Gitea and Bitbucket clients:
+// the JSON request client, API errors, the read-only transport and pagination
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrReadOnly is returned for write requests refused by a read-only client
+var ErrReadOnly = errors.New("write request refused: client is read-only")
+
+// IsReadOnly reports whether err comes from a write refused by a read-only client
+func IsReadOnly(err error) bool {
+	return errors.Is(err, ErrReadOnly)
+}
+
+// APIError is returned for API responses with a 4xx or 5xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an API 404 response
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
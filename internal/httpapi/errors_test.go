@@ -0,0 +1,23 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	err := fmt.Errorf("failed to get pull request: %w", &APIError{StatusCode: http.StatusNotFound, Message: "Not Found"})
+
+	assert.EqualError(t, err, "failed to get pull request: API error 404: Not Found")
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsNotFound(&APIError{StatusCode: http.StatusForbidden}))
+	assert.False(t, IsNotFound(fmt.Errorf("request failed")))
+}
+
+func TestIsReadOnly(t *testing.T) {
+	assert.True(t, IsReadOnly(fmt.Errorf("failed to create comment: %w", ErrReadOnly)))
+	assert.False(t, IsReadOnly(&APIError{StatusCode: http.StatusForbidden}))
+}
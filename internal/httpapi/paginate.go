@@ -0,0 +1,103 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Page is one page of a list endpoint: its items and the endpoint of the next page, empty on the
+// last page
+type Page[T any] struct {
+	Items []T
+	Next  string
+}
+
+// PageFunc fetches the page of a list endpoint at endpoint
+type PageFunc[T any] func(ctx context.Context, endpoint string) (Page[T], error)
+
+// Paginate fetches every page of a list endpoint, starting at endpoint and following the Next of
+// each page until one has none
+func Paginate[T any](ctx context.Context, endpoint string, fetch PageFunc[T]) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		p, err := fetch(ctx, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+		all = append(all, p.Items...)
+
+		if p.Next == "" {
+			return all, nil
+		}
+		endpoint = p.Next
+	}
+}
+
+// NextFunc returns the endpoint of the page after the one fetched from endpoint, given the headers
+// and item count of its response, or "" on the last page
+type NextFunc func(header http.Header, endpoint string, count int) string
+
+// PaginateJSON fetches every page of a list endpoint answering with a JSON array, asking next for
+// the endpoint of each following page
+func PaginateJSON[T any](ctx context.Context, c *Client, endpoint string, next NextFunc) ([]T, error) {
+	return Paginate(ctx, endpoint, func(ctx context.Context, endpoint string) (Page[T], error) {
+		var p Page[T]
+		resp, err := c.Do(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return p, err
+		}
+		defer resp.Body.Close()
+
+		if err := json.NewDecoder(resp.Body).Decode(&p.Items); err != nil {
+			return p, fmt.Errorf("failed to decode response: %w", err)
+		}
+		p.Next = next(resp.Header, endpoint, len(p.Items))
+		return p, nil
+	})
+}
+
+// NextLink returns the endpoint of the next page named by the Link header, or "" if there is
+// none. The page is fetched from the client's base URL rather than the host of the link, which
+// may be an external URL the client cannot reach, and below its path.
+func (c *Client) NextLink(header http.Header) string {
+	next, err := url.Parse(LinkWithRel(header.Get("Link"), "next"))
+	if err != nil || next.String() == "" {
+		return ""
+	}
+	endpoint := next.RequestURI()
+	if base, err := url.Parse(c.cfg.BaseURL); err == nil && base.Path != "" {
+		endpoint = strings.TrimPrefix(endpoint, strings.TrimSuffix(base.Path, "/"))
+	}
+	return endpoint
+}
+
+// LinkWithRel returns the URL of the Link header entry with the given rel, or "" if there is none;
+// the rel may be quoted or not and come with other parameters
+func LinkWithRel(header, rel string) string {
+	for _, entry := range strings.Split(header, ",") {
+		parts := strings.Split(entry, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if found && strings.TrimSpace(name) == "rel" && strings.Trim(strings.TrimSpace(value), `"`) == rel {
+				return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+		}
+	}
+	return ""
+}
+
+// SetQueryValue returns endpoint with a query parameter set
+func SetQueryValue(endpoint, key, value string) string {
+	path, rawQuery, _ := strings.Cut(endpoint, "?")
+	query, _ := url.ParseQuery(rawQuery)
+	query.Set(key, value)
+	return path + "?" + query.Encode()
+}
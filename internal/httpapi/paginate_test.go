@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := map[string]Page[int]{
+		"/items":        {Items: []int{1, 2}, Next: "/items?page=2"},
+		"/items?page=2": {Items: []int{3}, Next: "/items?page=3"},
+		"/items?page=3": {},
+	}
+	var fetched []string
+
+	items, err := Paginate(context.Background(), "/items", func(ctx context.Context, endpoint string) (Page[int], error) {
+		fetched = append(fetched, endpoint)
+		return pages[endpoint], nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+	assert.Equal(t, []string{"/items", "/items?page=2", "/items?page=3"}, fetched)
+}
+
+func TestPaginate_Error(t *testing.T) {
+	_, err := Paginate(context.Background(), "/items", func(ctx context.Context, endpoint string) (Page[int], error) {
+		if endpoint == "/items" {
+			return Page[int]{Items: []int{1}, Next: "/items?page=2"}, nil
+		}
+		return Page[int]{}, fmt.Errorf("request failed")
+	})
+
+	assert.EqualError(t, err, "failed to fetch page 2: request failed")
+}
+
+func TestPaginateJSON(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("X-Next-Page", "2")
+			w.Write([]byte(`[1,2]`))
+			return
+		}
+		w.Write([]byte(`[3]`))
+	}))
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL + "/api", HTTPClient: &http.Client{}, SetHeaders: func(*http.Request) error { return nil }})
+
+	var counts []int
+	items, err := PaginateJSON[int](context.Background(), client, "/items", func(header http.Header, endpoint string, count int) string {
+		counts = append(counts, count)
+		if page := header.Get("X-Next-Page"); page != "" {
+			return SetQueryValue(endpoint, "page", page)
+		}
+		return ""
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+	assert.Equal(t, []int{2, 1}, counts)
+	assert.Equal(t, []string{"/api/items", "/api/items?page=2"}, paths)
+}
+
+func TestClient_NextLink(t *testing.T) {
+	client := NewClient(Config{BaseURL: "http://127.0.0.1:8080/gitlab/api/v4", HTTPClient: &http.Client{}})
+	header := http.Header{}
+
+	assert.Empty(t, client.NextLink(header))
+
+	// The link names the external URL, which may not be the host the client uses
+	header.Set("Link", `<https://gitlab.example.com/gitlab/api/v4/items?cursor=abc&per_page=100>; rel="next", <https://gitlab.example.com/gitlab/api/v4/items?per_page=100>; rel="first"`)
+	assert.Equal(t, "/items?cursor=abc&per_page=100", client.NextLink(header))
+
+	header.Set("Link", `<https://gitlab.example.com/gitlab/api/v4/items?per_page=100>; rel="first"`)
+	assert.Empty(t, client.NextLink(header))
+}
+
+func TestLinkWithRel(t *testing.T) {
+	header := `<https://api.github.com/user/repos?page=2>; rel="next", <https://api.github.com/user/repos?page=5>; rel="last"`
+
+	assert.Equal(t, "https://api.github.com/user/repos?page=2", LinkWithRel(header, "next"))
+	assert.Equal(t, "https://api.github.com/user/repos?page=5", LinkWithRel(header, "last"))
+	assert.Empty(t, LinkWithRel(header, "prev"))
+	assert.Empty(t, LinkWithRel("", "next"))
+	assert.Equal(t, "https://x/?a=1", LinkWithRel(`<https://x/?a=1>; type="json"; rel=next`, "next"))
+	assert.Empty(t, LinkWithRel(`<https://x/?a=1>; rel="nextpage"`, "next"))
+}
+
+func TestSetQueryValue(t *testing.T) {
+	assert.Equal(t, "/user/repos?per_page=100", SetQueryValue("/user/repos", "per_page", "100"))
+	assert.Equal(t, "/orgs/acme/repos?per_page=100&type=all", SetQueryValue("/orgs/acme/repos?type=all&per_page=30", "per_page", "100"))
+}
@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// descriptionKey is the context key of the description a refused write is recorded under
+type descriptionKey struct{}
+
+// WithDescription returns a context under which a write refused by a ReadOnlyTransport is recorded
+// with description instead of its method and path
+func WithDescription(ctx context.Context, description string) context.Context {
+	return context.WithValue(ctx, descriptionKey{}, description)
+}
+
+// ReadOnlyPolicy adapts a ReadOnlyTransport to an API; both hooks are optional
+type ReadOnlyPolicy struct {
+	// Reads reports whether a request other than GET or HEAD only reads, like a GraphQL query
+	Reads func(path string, body []byte) bool
+	// Describe describes a refused write sent without WithDescription; it defaults to the method
+	// and path of the request
+	Describe func(method, path string, body []byte) string
+}
+
+// ReadOnlyTransport passes GET and HEAD requests through and records and refuses everything else
+type ReadOnlyTransport struct {
+	next   http.RoundTripper
+	policy ReadOnlyPolicy
+
+	mu      sync.Mutex
+	skipped []models.SkippedWrite
+}
+
+// NewReadOnlyTransport wraps next, http.DefaultTransport when nil, in a ReadOnlyTransport
+func NewReadOnlyTransport(next http.RoundTripper, policy ReadOnlyPolicy) *ReadOnlyTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &ReadOnlyTransport{next: next, policy: policy}
+}
+
+// ReadOnlyClient returns a copy of httpClient that sends its requests through a ReadOnlyTransport,
+// so that the original client can still write, along with the transport
+func ReadOnlyClient(httpClient *http.Client, policy ReadOnlyPolicy) (*http.Client, *ReadOnlyTransport) {
+	transport := NewReadOnlyTransport(httpClient.Transport, policy)
+	readOnly := *httpClient
+	readOnly.Transport = transport
+	return &readOnly, transport
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ReadOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	if t.policy.Reads != nil && t.policy.Reads(req.URL.Path, body) {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return t.next.RoundTrip(req)
+	}
+
+	description, _ := req.Context().Value(descriptionKey{}).(string)
+	if description == "" && t.policy.Describe != nil {
+		description = t.policy.Describe(req.Method, req.URL.Path, body)
+	}
+	if description == "" {
+		description = req.Method + " " + req.URL.Path
+	}
+	write := models.SkippedWrite{Method: req.Method, Path: req.URL.Path, Description: description}
+	slog.Info("Read-only mode: skipped write request", "method", write.Method, "path", write.Path, "action", write.Description)
+
+	t.mu.Lock()
+	t.skipped = append(t.skipped, write)
+	t.mu.Unlock()
+
+	return nil, ErrReadOnly
+}
+
+// SkippedWrites returns a copy of the write requests refused so far
+func (t *ReadOnlyTransport) SkippedWrites() []models.SkippedWrite {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]models.SkippedWrite(nil), t.skipped...)
+}
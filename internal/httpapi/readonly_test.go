@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+func TestReadOnlyClient(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	defer server.Close()
+
+	original := &http.Client{}
+	client, transport := ReadOnlyClient(original, ReadOnlyPolicy{})
+
+	resp, err := client.Get(server.URL + "/repos")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	req, err := http.NewRequestWithContext(WithDescription(context.Background(), "Comment on PR #7"), http.MethodPost, server.URL+"/repos/acme/app/issues/7/comments", strings.NewReader(`{"body":"conflict"}`))
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	assert.True(t, IsReadOnly(err))
+
+	req, err = http.NewRequest(http.MethodDelete, server.URL+"/repos/acme/app/issues/comments/3?force=true", nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	assert.True(t, IsReadOnly(err))
+
+	assert.Equal(t, []string{http.MethodGet}, methods)
+	assert.Equal(t, []models.SkippedWrite{
+		{Method: http.MethodPost, Path: "/repos/acme/app/issues/7/comments", Description: "Comment on PR #7"},
+		{Method: http.MethodDelete, Path: "/repos/acme/app/issues/comments/3", Description: "DELETE /repos/acme/app/issues/comments/3"},
+	}, transport.SkippedWrites())
+
+	// The client the read-only one was copied from can still write
+	resp, err = original.Post(server.URL+"/repos", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{http.MethodGet, http.MethodPost}, methods)
+	assert.Nil(t, original.Transport)
+}
+
+func TestReadOnlyClient_Policy(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	client, transport := ReadOnlyClient(&http.Client{}, ReadOnlyPolicy{
+		Reads: func(path string, body []byte) bool {
+			return path == "/graphql" && strings.HasPrefix(string(body), "query")
+		},
+		Describe: func(method, path string, body []byte) string {
+			return "Send " + string(body)
+		},
+	})
+
+	resp, err := client.Post(server.URL+"/graphql", "text/plain", strings.NewReader("query { a }"))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = client.Post(server.URL+"/graphql", "text/plain", strings.NewReader("mutation { b }"))
+	assert.True(t, IsReadOnly(err))
+
+	assert.Equal(t, []string{"query { a }"}, bodies)
+	assert.Equal(t, []models.SkippedWrite{{Method: http.MethodPost, Path: "/graphql", Description: "Send mutation { b }"}}, transport.SkippedWrites())
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampLayout is the layout used for report timestamps and report file names
+const TimestampLayout = "2006-01-02T15-04-05"
+
+// ParseReportTimestamp parses a report timestamp produced with TimestampLayout
+func ParseReportTimestamp(timestamp string) (time.Time, error) {
+	t, err := time.Parse(TimestampLayout, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid report timestamp %q: %w", timestamp, err)
+	}
+	return t, nil
+}
+
+// ConflictChange describes a single conflicting merge request when comparing two reports
+type ConflictChange struct {
+	RepositoryID   int          `json:"repository_id"`
+	RepositoryName string       `json:"repository_name"`
+	RepositoryPath string       `json:"repository_path,omitempty"`
+	RepositoryURL  string       `json:"repository_url"`
+	MergeRequest   MergeRequest `json:"merge_request"`
+	FirstSeen      time.Time    `json:"first_seen"`
+	LastSeen       time.Time    `json:"last_seen"`
+}
+
+// Key returns the identifier used to match the same merge request across reports
+func (c ConflictChange) Key() string {
+	return ConflictKey(c.RepositoryID, c.MergeRequest.ID)
+}
+
+// Persisted returns how long the merge request has been (or was) in conflict
+func (c ConflictChange) Persisted() time.Duration {
+	if c.FirstSeen.IsZero() || c.LastSeen.Before(c.FirstSeen) {
+		return 0
+	}
+	return c.LastSeen.Sub(c.FirstSeen)
+}
+
+// ConflictKey builds the identifier for a merge request within a repository
+func ConflictKey(repositoryID, mrID int) string {
+	return fmt.Sprintf("%d!%d", repositoryID, mrID)
+}
+
+// ReportDiff holds the conflicting merge requests that changed between two reports
+type ReportDiff struct {
+	PreviousTimestamp string           `json:"previous_timestamp"`
+	CurrentTimestamp  string           `json:"current_timestamp"`
+	New               []ConflictChange `json:"new"`
+	Resolved          []ConflictChange `json:"resolved"`
+	Persisting        []ConflictChange `json:"persisting"`
+	Held              []ConflictChange `json:"held,omitempty"` // Still conflicting but left out of the report
+}
+
+// HasChanges returns true if any merge request started or stopped conflicting
+func (d *ReportDiff) HasChanges() bool {
+	return d != nil && (len(d.New) > 0 || len(d.Resolved) > 0)
+}
+
+// FirstSeen returns when the merge request was first seen in conflict according to this diff
+func (d *ReportDiff) FirstSeen(key string) (time.Time, bool) {
+	if d == nil {
+		return time.Time{}, false
+	}
+	for _, changes := range [][]ConflictChange{d.New, d.Persisting, d.Held} {
+		for _, change := range changes {
+			if change.Key() == key {
+				return change.FirstSeen, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -1,10 +1,12 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRepositoryStatus_String(t *testing.T) {
@@ -104,6 +106,82 @@ func TestReport_AddRepository(t *testing.T) {
 	assert.Len(t, report.Repositories, 2)
 }
 
+func TestReport_AddRepository_Suppressed(t *testing.T) {
+	report := &Report{}
+	repo := Repository{ID: 1, Name: "test-repo", SuppressedMRs: []SuppressedMR{{MergeRequest: MergeRequest{ID: 3}, Reason: "draft"}}}
+
+	report.AddRepository(repo, []MergeRequest{}, StatusAccessible, "")
+
+	assert.Equal(t, 1, report.TotalSuppressedMRs)
+	assert.Equal(t, 0, report.TotalConflictingMRs)
+	assert.Equal(t, repo.SuppressedMRs, report.Repositories[0].SuppressedMRs)
+}
+
+func TestReport_Unacknowledged(t *testing.T) {
+	report := &Report{Timestamp: "2024-03-01T12-00-00"}
+	report.AddRepository(Repository{ID: 1, Name: "app"}, []MergeRequest{{ID: 1}, {ID: 2}}, StatusConflicts, "")
+	report.AddRepository(Repository{ID: 2, Name: "web"}, []MergeRequest{{ID: 3}}, StatusConflicts, "")
+	report.Repositories[0].Acknowledgements = []Acknowledgement{{MergeRequestID: 1, By: "lead"}}
+	report.Repositories[1].Acknowledgements = []Acknowledgement{{MergeRequestID: 3, By: "lead"}}
+	report.TotalAcknowledgedMRs = 2
+	report.Changes = &ReportDiff{
+		New:        []ConflictChange{{RepositoryID: 1, MergeRequest: MergeRequest{ID: 1}}, {RepositoryID: 1, MergeRequest: MergeRequest{ID: 2}}},
+		Persisting: []ConflictChange{{RepositoryID: 2, MergeRequest: MergeRequest{ID: 3}}},
+		Resolved:   []ConflictChange{{RepositoryID: 2, MergeRequest: MergeRequest{ID: 4}}},
+	}
+
+	filtered := report.Unacknowledged()
+
+	assert.Equal(t, 1, filtered.TotalConflictingMRs)
+	assert.Equal(t, 1, filtered.RepositoriesWithConflicts)
+	assert.Equal(t, 0, filtered.TotalAcknowledgedMRs)
+	assert.Equal(t, []MergeRequest{{ID: 2}}, filtered.Repositories[0].ConflictingMRs)
+	assert.Equal(t, StatusAccessible, filtered.Repositories[1].Status)
+	assert.Empty(t, filtered.Repositories[1].ConflictingMRs)
+	require.Len(t, filtered.Changes.New, 1)
+	assert.Equal(t, 2, filtered.Changes.New[0].MergeRequest.ID)
+	assert.Empty(t, filtered.Changes.Persisting)
+	assert.Len(t, filtered.Changes.Resolved, 1)
+
+	// The report itself keeps its acknowledged conflicts
+	assert.Equal(t, 3, report.TotalConflictingMRs)
+	assert.Len(t, report.Changes.New, 2)
+	assert.Len(t, report.Repositories[0].ConflictingMRs, 2)
+}
+
+func TestReport_Severities(t *testing.T) {
+	report := &Report{}
+	report.AddRepository(Repository{ID: 1}, []MergeRequest{{ID: 1}, {ID: 2}, {ID: 3}}, StatusConflicts, "")
+	report.Repositories[0].Severities = []Severity{
+		{MergeRequestID: 1, Score: 50, Level: SeverityHigh, Breached: true},
+		{MergeRequestID: 2, Score: 80, Level: SeverityCritical},
+		{MergeRequestID: 3, Score: 5, Level: SeverityLow, Breached: true},
+	}
+	report.Repositories[0].Acknowledgements = []Acknowledgement{{MergeRequestID: 2}}
+	report.SLABreaches = 2
+
+	highest, ok := report.Repositories[0].HighestSeverity()
+	require.True(t, ok)
+	assert.Equal(t, 2, highest.MergeRequestID)
+	assert.Equal(t, 3, report.CountAtLeast(SeverityLow))
+	assert.Equal(t, 2, report.CountAtLeast(SeverityHigh))
+
+	// Acknowledged conflicts take their severity with them
+	unacknowledged := report.Unacknowledged()
+	assert.Equal(t, 1, unacknowledged.CountAtLeast(SeverityHigh))
+	assert.Equal(t, 2, unacknowledged.SLABreaches)
+	assert.Len(t, unacknowledged.Repositories[0].Severities, 2)
+
+	_, ok = RepositoryReport{ConflictingMRs: []MergeRequest{{ID: 1}}}.HighestSeverity()
+	assert.False(t, ok)
+}
+
+func TestSeverityRank(t *testing.T) {
+	assert.Equal(t, 0, SeverityRank(SeverityLow))
+	assert.Equal(t, 3, SeverityRank(SeverityCritical))
+	assert.Equal(t, -1, SeverityRank("urgent"))
+}
+
 func TestReport_GetSummaryStats(t *testing.T) {
 	report := &Report{
 		TotalRepositories:         5,
@@ -144,6 +222,75 @@ func TestMergeRequest_JSONTags(t *testing.T) {
 	assert.True(t, mr.HasConflicts)
 }
 
+func TestMergeRequest_DecodesGitLabResponse(t *testing.T) {
+	data := `{
+		"iid": 7,
+		"project_id": 42,
+		"title": "Draft: Release",
+		"author": {"name": "Alice", "username": "alice"},
+		"assignees": [{"name": "Carol", "username": "carol"}],
+		"reviewers": [{"name": "Dave", "username": "dave"}],
+		"draft": true,
+		"work_in_progress": true,
+		"sha": "abc123",
+		"diff_refs": {"base_sha": "base", "head_sha": "abc123", "start_sha": "start"},
+		"milestone": {"id": 3, "iid": 1, "title": "1.2", "due_date": "2024-02-01", "web_url": "https://gitlab.com/m/1"},
+		"head_pipeline": {"id": 99, "status": "failed", "web_url": "https://gitlab.com/p/99"},
+		"user_notes_count": 4,
+		"changes_count": "1000+",
+		"labels": ["release"]
+	}`
+
+	var mr MergeRequest
+	require.NoError(t, json.Unmarshal([]byte(data), &mr))
+
+	assert.Equal(t, 42, mr.ProjectID)
+	assert.True(t, mr.IsDraft())
+	assert.Equal(t, []Author{{Name: "Carol", Username: "carol"}}, mr.Assignees)
+	assert.Equal(t, []Author{{Name: "Dave", Username: "dave"}}, mr.Reviewers)
+	assert.Equal(t, "abc123", mr.SHA)
+	assert.Equal(t, &DiffRefs{BaseSHA: "base", HeadSHA: "abc123", StartSHA: "start"}, mr.DiffRefs)
+	assert.Equal(t, "1.2", mr.Milestone.Title)
+	assert.Equal(t, "2024-02-01", mr.Milestone.DueDate)
+	assert.Equal(t, "failed", mr.HeadPipeline.Status)
+	assert.Equal(t, 4, mr.UserNotesCount)
+	assert.Equal(t, ChangeCount(1000), mr.ChangesCount)
+	assert.True(t, mr.HasLabel("Release"))
+	assert.True(t, mr.IsAssignedTo("carol"))
+	assert.False(t, mr.IsAssignedTo("alice"))
+}
+
+func TestMergeRequest_IsDraft(t *testing.T) {
+	assert.False(t, (&MergeRequest{}).IsDraft())
+	assert.True(t, (&MergeRequest{Draft: true}).IsDraft())
+	assert.True(t, (&MergeRequest{WorkInProgress: true}).IsDraft())
+}
+
+func TestChangeCount_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected ChangeCount
+	}{
+		{`"12"`, 12},
+		{`"1000+"`, 1000},
+		{`12`, 12},
+		{`""`, 0},
+		{`null`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			count := ChangeCount(5)
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &count))
+			assert.Equal(t, tt.expected, count)
+		})
+	}
+
+	var count ChangeCount
+	assert.Error(t, json.Unmarshal([]byte(`"many"`), &count))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &count))
+}
+
 func TestRepository_JSONTags(t *testing.T) {
 	// Test that the struct can be properly constructed
 	// This validates that our JSON tags are correct for GitLab API responses
@@ -162,3 +309,19 @@ func TestRepository_JSONTags(t *testing.T) {
 	assert.Equal(t, StatusAccessible, repo.Status)
 	assert.Nil(t, repo.Error)
 }
+
+func TestTokenInfo_Expiry(t *testing.T) {
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	expiring := &TokenInfo{ExpiresAt: "2024-10-15"}
+	expiry, ok := expiring.Expiry()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC), expiry)
+	assert.True(t, expiring.ExpiresWithin(7*24*time.Hour, now))
+	assert.False(t, expiring.ExpiresWithin(24*time.Hour, now))
+
+	never := &TokenInfo{}
+	_, ok = never.Expiry()
+	assert.False(t, ok)
+	assert.False(t, never.ExpiresWithin(7*24*time.Hour, now))
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Note represents a comment on a GitLab merge request
+type Note struct {
+	ID        int       `json:"id"`
+	Body      string    `json:"body"`
+	Author    Author    `json:"author"`
+	System    bool      `json:"system"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Report represents the summary statistics and data for the conflict report
 type Report struct {
 	Timestamp                 string             `json:"timestamp"`
@@ -7,27 +9,162 @@ type Report struct {
 	RepositoriesWithConflicts int                `json:"repositories_with_conflicts"`
 	TotalConflictingMRs       int                `json:"total_conflicting_mrs"`
 	Repositories              []RepositoryReport `json:"repositories"`
+	Changes                   *ReportDiff        `json:"changes,omitempty"`
+	DryRun                    bool               `json:"dry_run,omitempty"`
+	TokenExpiresAt            string             `json:"token_expires_at,omitempty"`
+	IncrementalSince          string             `json:"incremental_since,omitempty"` // Set when only activity since this time was rescanned
+	SkippedWrites             []SkippedWrite     `json:"skipped_writes,omitempty"`
+	FilteredMRs               int                `json:"filtered_mrs,omitempty"` // Conflicting MRs left out by the report filter
+	TotalSuppressedMRs        int                `json:"total_suppressed_mrs,omitempty"`
+	TotalAcknowledgedMRs      int                `json:"total_acknowledged_mrs,omitempty"` // Conflicting MRs acknowledged until a date
+	SLABreaches               int                `json:"sla_breaches,omitempty"`           // Conflicting MRs in conflict for longer than their SLA
+}
+
+// SkippedWrite is a GitLab write request that was not sent because the client was read-only
+type SkippedWrite struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
 }
 
 // RepositoryReport represents a repository's data in the report
 type RepositoryReport struct {
-	Repository     Repository       `json:"repository"`
-	ConflictingMRs []MergeRequest   `json:"conflicting_mrs"`
-	Status         RepositoryStatus `json:"status"`
-	ErrorMessage   string           `json:"error_message,omitempty"`
+	Repository       Repository        `json:"repository"`
+	ConflictingMRs   []MergeRequest    `json:"conflicting_mrs"`
+	Status           RepositoryStatus  `json:"status"`
+	ErrorMessage     string            `json:"error_message,omitempty"`
+	BackMergeMR      *MergeRequest     `json:"back_merge_mr,omitempty"`
+	Rebases          []RebaseResult    `json:"rebases,omitempty"`
+	SuppressedMRs    []SuppressedMR    `json:"suppressed_mrs,omitempty"`
+	FilteredOutMRs   []MergeRequest    `json:"filtered_out_mrs,omitempty"` // Conflicting MRs left out by the report filter
+	Downgrades       []Downgrade       `json:"downgrades,omitempty"`
+	Acknowledgements []Acknowledgement `json:"acknowledgements,omitempty"`
+	Severities       []Severity        `json:"severities,omitempty"`
+}
+
+// Acknowledgement marks a conflicting merge request someone acknowledged until it expires
+type Acknowledgement struct {
+	MergeRequestID int       `json:"mr_iid"`
+	By             string    `json:"by"`
+	Reason         string    `json:"reason"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// Severity levels, from the least to the most severe
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// SeverityLevels lists the severity levels from the least to the most severe
+var SeverityLevels = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// SeverityRank returns the position of a level in SeverityLevels, or -1 for an unknown level
+func SeverityRank(level string) int {
+	for i, l := range SeverityLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// Severity scores how urgently a conflicting merge request needs attention
+type Severity struct {
+	MergeRequestID   int       `json:"mr_iid"`
+	Score            int       `json:"score"` // 0 to 100
+	Level            string    `json:"level"`
+	InConflictSince  time.Time `json:"in_conflict_since"`
+	ConflictingFiles int       `json:"conflicting_files"`
+	SLADeadline      time.Time `json:"sla_deadline,omitempty"` // Unset when the namespace has no SLA
+	Breached         bool      `json:"breached,omitempty"`
+}
+
+// Severity returns the severity of one of the repository's conflicting merge requests
+func (r RepositoryReport) Severity(mrID int) (Severity, bool) {
+	for _, s := range r.Severities {
+		if s.MergeRequestID == mrID {
+			return s, true
+		}
+	}
+	return Severity{}, false
+}
+
+// HighestSeverity returns the highest severity among the repository's conflicting merge requests
+func (r RepositoryReport) HighestSeverity() (Severity, bool) {
+	var highest Severity
+	found := false
+	for _, mr := range r.ConflictingMRs {
+		if s, ok := r.Severity(mr.ID); ok && (!found || s.Score > highest.Score) {
+			highest, found = s, true
+		}
+	}
+	return highest, found
+}
+
+// Acknowledgement returns the acknowledgement of one of the repository's conflicting merge requests
+func (r RepositoryReport) Acknowledgement(mrID int) (Acknowledgement, bool) {
+	for _, a := range r.Acknowledgements {
+		if a.MergeRequestID == mrID {
+			return a, true
+		}
+	}
+	return Acknowledgement{}, false
+}
+
+// Downgrade returns the downgrade of one of the repository's conflicting merge requests
+func (r RepositoryReport) Downgrade(mrID int) (Downgrade, bool) {
+	for _, d := range r.Downgrades {
+		if d.MergeRequestID == mrID {
+			return d, true
+		}
+	}
+	return Downgrade{}, false
+}
+
+// Downgrade marks a conflicting merge request matched by an ignore rule that downgrades instead of suppressing
+type Downgrade struct {
+	MergeRequestID int    `json:"mr_iid"`
+	Reason         string `json:"reason"`
+}
+
+// SuppressedMR is a conflicting merge request matched by an ignore rule
+type SuppressedMR struct {
+	MergeRequest MergeRequest `json:"merge_request"`
+	Reason       string       `json:"reason"`
 }
 
-// AddRepository adds a repository to the report with its conflicting MRs
+// Rebase outcomes
+const (
+	RebaseSucceeded = "rebased"
+	RebaseFailed    = "failed"
+	RebaseTimedOut  = "timed_out"
+)
+
+// RebaseResult records a server-side rebase triggered for a merge request
+type RebaseResult struct {
+	MergeRequest MergeRequest `json:"merge_request"`
+	Status       string       `json:"status"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// AddRepository adds a repository to the report with its conflicting and suppressed MRs
 func (r *Report) AddRepository(repo Repository, conflictingMRs []MergeRequest, status RepositoryStatus, errorMsg string) {
 	repoReport := RepositoryReport{
 		Repository:     repo,
 		ConflictingMRs: conflictingMRs,
 		Status:         status,
 		ErrorMessage:   errorMsg,
+		SuppressedMRs:  repo.SuppressedMRs,
+		Downgrades:     repo.Downgrades,
 	}
 
 	r.Repositories = append(r.Repositories, repoReport)
 	r.TotalRepositories++
+	r.TotalSuppressedMRs += len(repo.SuppressedMRs)
 
 	if status == StatusConflicts {
 		r.RepositoriesWithConflicts++
@@ -39,3 +176,114 @@ func (r *Report) AddRepository(repo Repository, conflictingMRs []MergeRequest, s
 func (r *Report) GetSummaryStats() (int, int, int) {
 	return r.TotalRepositories, r.RepositoriesWithConflicts, r.TotalConflictingMRs
 }
+
+// ConflictingEntries flattens the report into one ConflictChange per conflicting merge request
+func (r *Report) ConflictingEntries() []ConflictChange {
+	var entries []ConflictChange
+	for _, repoReport := range r.Repositories {
+		for _, mr := range repoReport.ConflictingMRs {
+			entries = append(entries, repoReport.change(mr))
+		}
+	}
+	return entries
+}
+
+// HeldEntries flattens the conflicting merge requests the report leaves out, because the report
+// filter does not keep them or an ignore rule suppresses them, into one ConflictChange each
+func (r *Report) HeldEntries() []ConflictChange {
+	var entries []ConflictChange
+	for _, repoReport := range r.Repositories {
+		for _, mr := range repoReport.FilteredOutMRs {
+			entries = append(entries, repoReport.change(mr))
+		}
+		for _, suppressed := range repoReport.SuppressedMRs {
+			entries = append(entries, repoReport.change(suppressed.MergeRequest))
+		}
+	}
+	return entries
+}
+
+// change describes a merge request of the repository as a ConflictChange
+func (rr *RepositoryReport) change(mr MergeRequest) ConflictChange {
+	return ConflictChange{
+		RepositoryID:   rr.Repository.ID,
+		RepositoryName: rr.Repository.Name,
+		RepositoryPath: rr.Repository.PathWithNamespace,
+		RepositoryURL:  rr.Repository.WebURL,
+		MergeRequest:   mr,
+	}
+}
+
+// Unacknowledged returns a copy of the report, and of its changes, without the acknowledged conflicting merge requests
+func (r *Report) Unacknowledged() *Report {
+	filtered := *r
+	filtered.Repositories = make([]RepositoryReport, 0, len(r.Repositories))
+	filtered.RepositoriesWithConflicts = 0
+	filtered.TotalConflictingMRs = 0
+	filtered.TotalAcknowledgedMRs = 0
+	filtered.SLABreaches = 0
+
+	acknowledged := make(map[string]bool)
+	for _, repoReport := range r.Repositories {
+		mrs := []MergeRequest{}
+		var severities []Severity
+		for _, mr := range repoReport.ConflictingMRs {
+			if _, ok := repoReport.Acknowledgement(mr.ID); ok {
+				acknowledged[ConflictKey(repoReport.Repository.ID, mr.ID)] = true
+				continue
+			}
+			mrs = append(mrs, mr)
+			if s, ok := repoReport.Severity(mr.ID); ok {
+				severities = append(severities, s)
+				if s.Breached {
+					filtered.SLABreaches++
+				}
+			}
+		}
+		repoReport.ConflictingMRs = mrs
+		repoReport.Acknowledgements = nil
+		repoReport.Severities = severities
+		if repoReport.Status == StatusConflicts {
+			if len(mrs) == 0 {
+				// Every conflict is acknowledged
+				repoReport.Status = StatusAccessible
+			} else {
+				filtered.RepositoriesWithConflicts++
+				filtered.TotalConflictingMRs += len(mrs)
+			}
+		}
+		filtered.Repositories = append(filtered.Repositories, repoReport)
+	}
+
+	if r.Changes != nil {
+		keep := func(changes []ConflictChange) []ConflictChange {
+			var kept []ConflictChange
+			for _, change := range changes {
+				if !acknowledged[change.Key()] {
+					kept = append(kept, change)
+				}
+			}
+			return kept
+		}
+		changes := *r.Changes
+		changes.New = keep(r.Changes.New)
+		changes.Persisting = keep(r.Changes.Persisting)
+		filtered.Changes = &changes
+	}
+
+	return &filtered
+}
+
+// CountAtLeast returns how many conflicting merge requests have a severity of at least the level
+func (r *Report) CountAtLeast(level string) int {
+	rank := SeverityRank(level)
+	count := 0
+	for _, repoReport := range r.Repositories {
+		for _, mr := range repoReport.ConflictingMRs {
+			if s, ok := repoReport.Severity(mr.ID); ok && SeverityRank(s.Level) >= rank {
+				count++
+			}
+		}
+	}
+	return count
+}
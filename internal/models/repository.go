@@ -1,6 +1,13 @@
 package models
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // RepositoryStatus represents the status of a repository during scanning
 type RepositoryStatus int
@@ -40,19 +47,25 @@ func (rs RepositoryStatus) HasConflicts() bool {
 
 // Namespace represents the GitLab namespace (group) information
 type Namespace struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Path string `json:"path"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	FullPath string `json:"full_path"`
 }
 
 // Repository represents a GitLab repository
 type Repository struct {
-	ID        int              `json:"id"`
-	Name      string           `json:"name"`
-	WebURL    string           `json:"web_url"`
-	Namespace Namespace        `json:"namespace"`
-	Status    RepositoryStatus `json:"-"`
-	Error     error            `json:"-"`
+	ID                int              `json:"id"`
+	Name              string           `json:"name"`
+	PathWithNamespace string           `json:"path_with_namespace"`
+	WebURL            string           `json:"web_url"`
+	Namespace         Namespace        `json:"namespace"`
+	LastActivityAt    time.Time        `json:"last_activity_at"`
+	Provider          string           `json:"provider,omitempty"` // Service the repository was read from, set when several are scanned
+	Status            RepositoryStatus `json:"-"`
+	Error             error            `json:"-"`
+	SuppressedMRs     []SuppressedMR   `json:"-"` // Conflicting MRs matched by an ignore rule
+	Downgrades        []Downgrade      `json:"-"` // Conflicting MRs matched by a downgrading ignore rule
 }
 
 // Author represents the author of a merge request
@@ -64,14 +77,140 @@ type Author struct {
 
 // MergeRequest represents a GitLab merge request
 type MergeRequest struct {
-	ID           int       `json:"iid"`
-	Title        string    `json:"title"`
-	Author       Author    `json:"author"`
-	WebURL       string    `json:"web_url"`
-	SourceBranch string    `json:"source_branch"`
-	TargetBranch string    `json:"target_branch"`
-	HasConflicts bool      `json:"has_conflicts"`
-	CreatedAt    time.Time `json:"created_at"`
-	MergeStatus  string    `json:"merge_status"`
-	ChangesCount string    `json:"changes_count"`
+	ID             int         `json:"iid"`
+	ProjectID      int         `json:"project_id,omitempty"`
+	Title          string      `json:"title"`
+	Author         Author      `json:"author"`
+	Assignees      []Author    `json:"assignees,omitempty"`
+	Reviewers      []Author    `json:"reviewers,omitempty"`
+	WebURL         string      `json:"web_url"`
+	SourceBranch   string      `json:"source_branch"`
+	TargetBranch   string      `json:"target_branch"`
+	HasConflicts   bool        `json:"has_conflicts"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	State          string      `json:"state,omitempty"`
+	Draft          bool        `json:"draft,omitempty"`
+	WorkInProgress bool        `json:"work_in_progress,omitempty"` // Deprecated GitLab name of draft, still set by older servers
+	MergeStatus    string      `json:"merge_status"`
+	ChangesCount   ChangeCount `json:"changes_count"`
+	Labels         []string    `json:"labels,omitempty"`
+	Milestone      *Milestone  `json:"milestone,omitempty"`
+	SHA            string      `json:"sha,omitempty"`
+	DiffRefs       *DiffRefs   `json:"diff_refs,omitempty"`
+	HeadPipeline   *Pipeline   `json:"head_pipeline,omitempty"`
+	UserNotesCount int         `json:"user_notes_count,omitempty"`
+
+	DetailedMergeStatus string `json:"detailed_merge_status,omitempty"`
+	RebaseInProgress    bool   `json:"rebase_in_progress,omitempty"`
+	MergeError          string `json:"merge_error,omitempty"`
+}
+
+// IsDraft returns true if the merge request is marked as a draft under either GitLab name
+func (mr *MergeRequest) IsDraft() bool {
+	return mr.Draft || mr.WorkInProgress
+}
+
+// ConflictChecked returns true once the provider has finished checking the merge request for
+// conflicts, so that HasConflicts can be trusted either way
+func (mr *MergeRequest) ConflictChecked() bool {
+	switch mr.MergeStatus {
+	case "", "unchecked", "checking", "cannot_be_merged_recheck":
+		return false
+	}
+	return true
+}
+
+// HasLabel returns true if the merge request carries the label, compared case-insensitively
+func (mr *MergeRequest) HasLabel(label string) bool {
+	for _, l := range mr.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAssignedTo returns true if the user is one of the assignees of the merge request
+func (mr *MergeRequest) IsAssignedTo(username string) bool {
+	for _, assignee := range mr.Assignees {
+		if strings.EqualFold(assignee.Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeCount is the number of files changed by a merge request; GitLab sends it as a string
+// and caps it at "1000+", which decodes as 1000
+type ChangeCount int
+
+// UnmarshalJSON accepts a number, a numeric string with an optional "+" suffix, an empty string or null
+func (c *ChangeCount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*c = 0
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("failed to decode changes count %s: %w", data, err)
+		}
+		*c = ChangeCount(n)
+		return nil
+	}
+
+	text = strings.TrimSuffix(strings.TrimSpace(text), "+")
+	if text == "" {
+		*c = 0
+		return nil
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return fmt.Errorf("failed to decode changes count %q: %w", text, err)
+	}
+	*c = ChangeCount(n)
+	return nil
+}
+
+// DiffRefs holds the commits a merge request diff is computed between
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// Milestone represents the milestone a merge request is planned for
+type Milestone struct {
+	ID      int    `json:"id,omitempty"`
+	IID     int    `json:"iid,omitempty"`
+	Title   string `json:"title"`
+	DueDate string `json:"due_date,omitempty"` // Date in YYYY-MM-DD form
+	WebURL  string `json:"web_url,omitempty"`
+}
+
+// Pipeline represents the latest pipeline of a merge request
+type Pipeline struct {
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	WebURL string `json:"web_url,omitempty"`
+}
+
+// CheckedSourceBranch and CheckedTargetBranch are the branches of the merge requests the checker
+// scans, reports and acts on
+const (
+	CheckedSourceBranch = "release"
+	CheckedTargetBranch = "master"
+)
+
+// DetailedMergeStatusNeedRebase is the detailed merge status of MRs that can be fixed by a rebase
+const DetailedMergeStatusNeedRebase = "need_rebase"
+
+// Branch represents a GitLab repository branch
+type Branch struct {
+	Name      string `json:"name"`
+	WebURL    string `json:"web_url"`
+	Protected bool   `json:"protected"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// TokenInfo describes the personal access token used to talk to GitLab
+type TokenInfo struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Active    bool     `json:"active"`
+	Revoked   bool     `json:"revoked"`
+	ExpiresAt string   `json:"expires_at"` // Date in YYYY-MM-DD form, empty when the token never expires
+}
+
+// Expiry returns the moment the token stops working, and false when it never expires
+func (t *TokenInfo) Expiry() (time.Time, bool) {
+	if t.ExpiresAt == "" {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse("2006-01-02", t.ExpiresAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// ExpiresWithin reports whether the token expires less than window after now
+func (t *TokenInfo) ExpiresWithin(window time.Duration, now time.Time) bool {
+	expiry, ok := t.Expiry()
+	return ok && expiry.Sub(now) < window
+}
@@ -0,0 +1,88 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// sensitiveKeys are log attribute keys whose values are always dropped
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"private-token": true,
+	"job-token":     true,
+	"token":         true,
+	"password":      true,
+}
+
+// Handler is a slog.Handler that scrubs secrets from messages and attributes before passing records on
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler wraps next so that nothing it writes contains a secret
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(Attr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = Attr(attr)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// Attr scrubs a log attribute, dropping the value of credential keys entirely
+func Attr(attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Placeholder)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, member := range group {
+			redacted[i] = Attr(member)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.Any(attr.Key, Error(v))
+		default:
+			// Structs, maps and headers are rendered only when they hold something to scrub
+			rendered := fmt.Sprintf("%+v", v)
+			if scrubbed := String(rendered); scrubbed != rendered {
+				return slog.String(attr.Key, scrubbed)
+			}
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
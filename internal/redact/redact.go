@@ -0,0 +1,114 @@
+package redact
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces every redacted value
+const Placeholder = "[REDACTED]"
+
+// minSecretLength keeps very short values from blanking out unrelated text
+const minSecretLength = 8
+
+var (
+	// tokenPattern matches GitLab personal, OAuth, deploy, trigger and runner tokens
+	tokenPattern = regexp.MustCompile(`\b(?:glpat|gloas|gldt|glptt|glrt|glcbt|glft|glsoat)-[0-9A-Za-z_.\-]+`)
+	// headerPattern matches credential headers where only a header can appear: a header line of a
+	// request dump, a quoted JSON key or a Go header map, so prose mentioning them is left alone
+	headerPattern = regexp.MustCompile(`(?im)(^[ \t]*(?:authorization|private-token|job-token)[ \t]*:[ \t]*|["'](?:authorization|private-token|job-token)["'][ \t]*:[ \t]*["']?|\b(?:authorization|private-token|job-token):\[)((?:bearer|basic|token)\s+)?[^\s"',;\]]+`)
+)
+
+var secrets struct {
+	sync.RWMutex
+	values   []string
+	replacer *strings.Replacer
+}
+
+// AddSecret registers a value, such as the configured GitLab token, to be scrubbed from all output
+func AddSecret(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	for _, existing := range secrets.values {
+		if existing == secret {
+			return
+		}
+	}
+	secrets.values = append(secrets.values, secret)
+
+	// Longest secrets first so a secret containing another is replaced whole
+	sort.Slice(secrets.values, func(i, j int) bool {
+		return len(secrets.values[i]) > len(secrets.values[j])
+	})
+	pairs := make([]string, 0, 2*len(secrets.values))
+	for _, value := range secrets.values {
+		pairs = append(pairs, value, Placeholder)
+	}
+	secrets.replacer = strings.NewReplacer(pairs...)
+}
+
+// SaveSecrets returns a function restoring the registered secrets to the current set, so that tests
+// registering secrets do not change the redaction seen by other tests
+func SaveSecrets() (restore func()) {
+	secrets.RLock()
+	values, replacer := append([]string(nil), secrets.values...), secrets.replacer
+	secrets.RUnlock()
+
+	return func() {
+		secrets.Lock()
+		defer secrets.Unlock()
+		secrets.values, secrets.replacer = values, replacer
+	}
+}
+
+// String scrubs registered secrets, GitLab tokens and credential header values from s; it is meant
+// for errors, logs and recorded traffic
+func String(s string) string {
+	return headerPattern.ReplaceAllString(Content(s), "${1}${2}"+Placeholder)
+}
+
+// Content scrubs registered secrets and GitLab tokens from rendered reports and notifications,
+// whose merge request titles and descriptions may mention credential headers in prose
+func Content(s string) string {
+	secrets.RLock()
+	replacer := secrets.replacer
+	secrets.RUnlock()
+
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+	return tokenPattern.ReplaceAllString(s, Placeholder)
+}
+
+// Error returns err with its message scrubbed; errors.Is and errors.As still see the original
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := String(err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{message: message, err: err}
+}
+
+// redactedError carries a scrubbed message while keeping the wrapped chain
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string { return e.message }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Is keeps sentinel comparisons working through the redacted wrapper
+func (e *redactedError) Is(target error) bool { return errors.Is(e.err, target) }
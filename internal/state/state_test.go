@@ -0,0 +1,39 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", DefaultFileName)
+	saved := &State{
+		LastSuccessfulScan: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Report:             "reports/MR-conflict-2024-03-01T12-00-00.json",
+	}
+
+	require.NoError(t, saved.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved, loaded)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	loaded, err := Load(filepath.Join(t.TempDir(), DefaultFileName))
+	assert.NoError(t, err)
+	assert.Nil(t, loaded)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "failed to parse state file")
+}
@@ -0,0 +1,286 @@
+package bitbucketfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+// route dispatches a request by method and path segments below /rest/api/1.0; the caller holds the lock
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string, body []byte) {
+	switch {
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "application-properties"):
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]string{"version": "8.19.0", "displayName": "Bitbucket"})
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "repos"):
+		s.listRepositories(w, r, "")
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "projects", "*", "repos"):
+		s.listRepositories(w, r, segments[1])
+	case len(segments) >= 6 && segments[0] == "projects" && segments[2] == "repos" && segments[4] == "pull-requests":
+		repo := fakeapi.ByPath(s.repos, segments[1], segments[3])
+		if repo == nil {
+			writeErrors(w, http.StatusNotFound, fmt.Sprintf("Repository %s/%s does not exist.", segments[1], segments[3]))
+			return
+		}
+		s.routePullRequests(w, r, repo, segments[5:], body)
+	case r.Method == http.MethodGet && len(segments) == 5 && segments[0] == "projects" && segments[2] == "repos" && segments[4] == "pull-requests":
+		repo := fakeapi.ByPath(s.repos, segments[1], segments[3])
+		if repo == nil {
+			writeErrors(w, http.StatusNotFound, fmt.Sprintf("Repository %s/%s does not exist.", segments[1], segments[3]))
+			return
+		}
+		s.listPullRequests(w, r, repo)
+	default:
+		writeErrors(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// routePullRequests dispatches the requests below /projects/{key}/repos/{slug}/pull-requests/{id}
+func (s *Server) routePullRequests(w http.ResponseWriter, r *http.Request, repo *repository, segments []string, body []byte) {
+	id, _ := strconv.Atoi(segments[0])
+	pr := repo.pullRequest(id)
+	if pr == nil {
+		writeErrors(w, http.StatusNotFound, fmt.Sprintf("Pull request %s does not exist in %s/%s.", segments[0], repo.repo.Namespace.Path, repo.repo.Name))
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "*", "merge"):
+		fakeapi.WriteJSON(w, http.StatusOK, mergeJSON(pr))
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "*", "changes"):
+		changes := make([]interface{}, pr.ChangedFiles)
+		for i := range changes {
+			changes[i] = map[string]interface{}{"path": map[string]string{"toString": fmt.Sprintf("file%d.go", i+1)}, "type": "MODIFY"}
+		}
+		s.writePage(w, r, changes)
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "*", "activities"):
+		activities := repo.activities[id]
+		values := make([]interface{}, 0, len(activities))
+		for i := len(activities) - 1; i >= 0; i-- {
+			values = append(values, activityJSON(activities[i]))
+		}
+		s.writePage(w, r, values)
+	case r.Method == http.MethodPost && fakeapi.Match(segments, "*", "comments"):
+		var request struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil || request.Text == "" {
+			writeErrors(w, http.StatusBadRequest, "Please enter a non-empty value")
+			return
+		}
+		now := time.Now().UTC()
+		c := &comment{note: models.Note{ID: s.nextCommentID, Body: request.Text, Author: models.Author{Username: s.user}, CreatedAt: now, UpdatedAt: now}}
+		s.nextCommentID++
+		repo.comments[id] = append(repo.comments[id], c)
+		repo.activities[id] = append(repo.activities[id], &activity{commentAction: "ADDED", comment: c})
+		fakeapi.WriteJSON(w, http.StatusCreated, commentJSON(c))
+	case fakeapi.Match(segments, "*", "comments", "*"):
+		commentID, _ := strconv.Atoi(segments[2])
+		s.changeComment(w, r, repo, id, commentID, body)
+	default:
+		writeErrors(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// listRepositories answers the repositories of every project, or of one project
+func (s *Server) listRepositories(w http.ResponseWriter, r *http.Request, projectKey string) {
+	var values []interface{}
+	for _, repo := range s.repos {
+		if projectKey == "" || repo.repo.Namespace.Path == projectKey {
+			values = append(values, s.repositoryJSON(repo))
+		}
+	}
+	if projectKey != "" && len(values) == 0 {
+		writeErrors(w, http.StatusNotFound, fmt.Sprintf("Project %s does not exist.", projectKey))
+		return
+	}
+	s.writePage(w, r, values)
+}
+
+// listPullRequests answers the pull requests of a repository filtered by state and, with at and
+// direction=INCOMING, by target branch. Like Bitbucket, the list leaves out the merge status.
+func (s *Server) listPullRequests(w http.ResponseWriter, r *http.Request, repo *repository) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "OPEN"
+	}
+
+	var values []interface{}
+	for i := len(repo.pulls) - 1; i >= 0; i-- {
+		pr := repo.pulls[i]
+		if state != "ALL" && pr.State != state {
+			continue
+		}
+		if at := query.Get("at"); at != "" && query.Get("direction") == "INCOMING" && at != "refs/heads/"+pr.Target {
+			continue
+		}
+		values = append(values, s.pullRequestJSON(repo, pr))
+	}
+	s.writePage(w, r, values)
+}
+
+// changeComment reads, updates or deletes a comment; updates and deletes must name its current version
+func (s *Server) changeComment(w http.ResponseWriter, r *http.Request, repo *repository, id, commentID int, body []byte) {
+	var c *comment
+	for _, candidate := range repo.comments[id] {
+		if candidate.note.ID == commentID && !candidate.deleted {
+			c = candidate
+		}
+	}
+	if c == nil {
+		writeErrors(w, http.StatusNotFound, fmt.Sprintf("Comment %d does not exist.", commentID))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		fakeapi.WriteJSON(w, http.StatusOK, commentJSON(c))
+	case http.MethodPut:
+		var request struct {
+			Text    string `json:"text"`
+			Version *int   `json:"version"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil || request.Text == "" || request.Version == nil {
+			writeErrors(w, http.StatusBadRequest, "Please enter a non-empty value")
+			return
+		}
+		if *request.Version != c.version {
+			writeErrors(w, http.StatusConflict, "You are attempting to modify a comment based on out-of-date information.")
+			return
+		}
+		c.note.Body = request.Text
+		c.note.UpdatedAt = time.Now().UTC()
+		c.version++
+		repo.activities[id] = append(repo.activities[id], &activity{commentAction: "EDITED", comment: c})
+		fakeapi.WriteJSON(w, http.StatusOK, commentJSON(c))
+	case http.MethodDelete:
+		if version, err := strconv.Atoi(r.URL.Query().Get("version")); err != nil || version != c.version {
+			writeErrors(w, http.StatusConflict, "You are attempting to modify a comment based on out-of-date information.")
+			return
+		}
+		c.deleted = true
+		repo.activities[id] = append(repo.activities[id], &activity{commentAction: "DELETED", comment: c})
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeErrors(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// pullRequest looks up a pull request by ID
+func (r *repository) pullRequest(id int) *PullRequest {
+	for _, pr := range r.pulls {
+		if pr.ID == id {
+			return pr
+		}
+	}
+	return nil
+}
+
+// repositoryJSON renders a repository like the Bitbucket API
+func (s *Server) repositoryJSON(r *repository) map[string]interface{} {
+	repo := r.repo
+	key := repo.Namespace.Path
+	return map[string]interface{}{
+		"id":      repo.ID,
+		"slug":    repo.Name,
+		"name":    repo.Name,
+		"project": map[string]interface{}{"id": repo.Namespace.ID, "key": key, "name": repo.Namespace.Name},
+		"links": map[string]interface{}{
+			"self": []map[string]string{{"href": fmt.Sprintf("%s/projects/%s/repos/%s/browse", s.URL(), key, repo.Name)}},
+		},
+	}
+}
+
+// pullRequestJSON renders a pull request like the Bitbucket API, with millisecond timestamps
+func (s *Server) pullRequestJSON(r *repository, pr *PullRequest) map[string]interface{} {
+	key := r.repo.Namespace.Path
+	reviewers := make([]map[string]interface{}, 0, len(pr.Reviewers))
+	for _, name := range pr.Reviewers {
+		reviewers = append(reviewers, map[string]interface{}{
+			"user": map[string]string{"name": name, "displayName": name},
+			"role": "REVIEWER",
+		})
+	}
+	return map[string]interface{}{
+		"id":          pr.ID,
+		"title":       pr.Title,
+		"state":       pr.State,
+		"draft":       pr.Draft,
+		"createdDate": millis(pr.CreatedAt),
+		"updatedDate": millis(pr.UpdatedAt),
+		"fromRef":     map[string]string{"id": "refs/heads/" + pr.Source, "displayId": pr.Source, "latestCommit": pr.SHA},
+		"toRef":       map[string]string{"id": "refs/heads/" + pr.Target, "displayId": pr.Target},
+		"author":      map[string]interface{}{"user": map[string]string{"name": pr.Author, "displayName": pr.Author}},
+		"reviewers":   reviewers,
+		"properties":  map[string]int{"commentCount": r.commentCount(pr.ID)},
+		"links": map[string]interface{}{
+			"self": []map[string]string{{"href": fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%d", s.URL(), key, r.repo.Name, pr.ID)}},
+		},
+	}
+}
+
+// commentCount returns the number of comments of a pull request that were not deleted
+func (r *repository) commentCount(id int) int {
+	count := 0
+	for _, c := range r.comments[id] {
+		if !c.deleted {
+			count++
+		}
+	}
+	return count
+}
+
+// mergeJSON renders the answer of the merge endpoint of a pull request
+func mergeJSON(pr *PullRequest) map[string]interface{} {
+	outcome := "CLEAN"
+	switch {
+	case pr.Unknown:
+		outcome = "UNKNOWN"
+	case pr.Conflicted:
+		outcome = "CONFLICTED"
+	}
+	vetoes := make([]map[string]string, 0, len(pr.Vetoes))
+	for _, veto := range pr.Vetoes {
+		vetoes = append(vetoes, map[string]string{"summaryMessage": veto})
+	}
+	return map[string]interface{}{
+		"canMerge":   !pr.Conflicted && !pr.Unknown && len(pr.Vetoes) == 0,
+		"conflicted": pr.Conflicted,
+		"outcome":    outcome,
+		"vetoes":     vetoes,
+	}
+}
+
+// activityJSON renders a comment activity like the Bitbucket API
+func activityJSON(a *activity) map[string]interface{} {
+	return map[string]interface{}{
+		"action":        "COMMENTED",
+		"commentAction": a.commentAction,
+		"comment":       commentJSON(a.comment),
+	}
+}
+
+// commentJSON renders a comment like the Bitbucket API
+func commentJSON(c *comment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          c.note.ID,
+		"version":     c.version,
+		"text":        c.note.Body,
+		"author":      map[string]string{"name": c.note.Author.Username, "displayName": c.note.Author.Username},
+		"createdDate": millis(c.note.CreatedAt),
+		"updatedDate": millis(c.note.UpdatedAt),
+	}
+}
+
+// millis renders a time as milliseconds since the epoch, and an unset time as 0
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
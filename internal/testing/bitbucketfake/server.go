@@ -0,0 +1,208 @@
+// Package bitbucketfake provides a stateful fake Bitbucket Server REST API server for integration
+// tests.
+//
+// The server keeps repositories, pull requests and their comments in memory below /rest/api/1.0.
+// It pages with start and limit like Bitbucket, answers the merge endpoint of every pull request,
+// keeps comment versions and serves comments through the activity stream of a pull request.
+package bitbucketfake
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+const (
+	// DefaultToken is the token accepted by a new server
+	DefaultToken = "bitbucket-test-token"
+	// apiPath is the path the API is served below
+	apiPath = "/rest/api/1.0"
+	// defaultLimit is the page size Bitbucket uses when limit is not given
+	defaultLimit = 25
+	// maxLimit is the largest page size Bitbucket answers with by default
+	maxLimit = 1000
+)
+
+// PullRequest is a pull request kept by the server
+type PullRequest struct {
+	ID           int
+	Title        string
+	Author       string
+	Source       string // Source branch, in the repository itself
+	Target       string
+	State        string // "OPEN" (default), "MERGED" or "DECLINED"
+	Draft        bool
+	Conflicted   bool
+	Unknown      bool     // The merge outcome is still being computed
+	Vetoes       []string // Merge checks blocking the merge
+	ChangedFiles int
+	Reviewers    []string // User names of the reviewers
+	SHA          string   // Latest commit of the source branch
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Request is a request received by the server
+type Request = fakeapi.Request
+
+// comment is a stored comment; deleted comments stay for the activity stream
+type comment struct {
+	note    models.Note
+	version int
+	deleted bool
+}
+
+// activity is an entry of the activity stream of a pull request
+type activity struct {
+	commentAction string // ADDED, EDITED or DELETED
+	comment       *comment
+}
+
+// repository is a stored repository with its pull requests, comments and activities
+type repository struct {
+	repo       models.Repository
+	pulls      []*PullRequest
+	comments   map[int][]*comment  // Pull request ID -> comments
+	activities map[int][]*activity // Pull request ID -> activities, oldest first
+}
+
+// Repository implements fakeapi.Stored
+func (r *repository) Repository() models.Repository {
+	return r.repo
+}
+
+// Server is a fake Bitbucket Server API server
+type Server struct {
+	fakeapi.Server
+
+	mu            sync.Mutex
+	token         string
+	user          string
+	repos         []*repository
+	maxLimit      int
+	nextCommentID int
+}
+
+// NewServer starts a fake Bitbucket server accepting DefaultToken
+func NewServer() *Server {
+	s := &Server{
+		token:         DefaultToken,
+		user:          "bitbucket-bot",
+		maxLimit:      maxLimit,
+		nextCommentID: 1,
+	}
+	s.Start(s.handle)
+	return s
+}
+
+// SetMaxLimit lowers the largest page size the server answers with
+func (s *Server) SetMaxLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxLimit = limit
+}
+
+// SetRepositories replaces the repositories; the namespace of each is its project, with the
+// namespace path as project key, and the name of each is its slug
+func (s *Server) SetRepositories(repos []models.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos = nil
+	for _, repo := range repos {
+		s.repos = append(s.repos, &repository{repo: repo, comments: make(map[int][]*comment), activities: make(map[int][]*activity)})
+	}
+}
+
+// SetPullRequests replaces the pull requests of a repository
+func (s *Server) SetPullRequests(repoID int, pulls []PullRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := fakeapi.ByID(s.repos, repoID)
+	if r == nil {
+		return
+	}
+	r.pulls = nil
+	for i := range pulls {
+		pr := pulls[i]
+		if pr.State == "" {
+			pr.State = "OPEN"
+		}
+		r.pulls = append(r.pulls, &pr)
+	}
+}
+
+// Comments returns the comments of a pull request that were not deleted
+func (s *Server) Comments(repoID, id int) []models.Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := fakeapi.ByID(s.repos, repoID)
+	if r == nil {
+		return nil
+	}
+	var notes []models.Note
+	for _, c := range r.comments[id] {
+		if !c.deleted {
+			notes = append(notes, c.note)
+		}
+	}
+	return notes
+}
+
+// handle checks the token of a request and dispatches it to the API handlers. Requests
+// without a token are served anonymously, like on an instance allowing public access.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, ok := strings.CutPrefix(r.URL.Path, apiPath+"/")
+	if !ok {
+		writeErrors(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	switch r.Header.Get("Authorization") {
+	case "":
+	case "Bearer " + s.token:
+		w.Header().Set("X-AUSERNAME", s.user)
+	default:
+		writeErrors(w, http.StatusUnauthorized, "Authentication failed. Please check your credentials and try again.")
+		return
+	}
+
+	s.route(w, r, strings.Split(strings.Trim(path, "/"), "/"), body)
+}
+
+// writePage answers with the page of values requested by start and limit
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, values []interface{}) {
+	query := r.URL.Query()
+	start := 0
+	if st, err := strconv.Atoi(query.Get("start")); err == nil && st > 0 {
+		start = min(st, len(values))
+	}
+	limit := defaultLimit
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	limit = min(limit, s.maxLimit)
+
+	end := min(start+limit, len(values))
+	page := map[string]interface{}{
+		"values":     append([]interface{}{}, values[start:end]...),
+		"size":       end - start,
+		"start":      start,
+		"limit":      limit,
+		"isLastPage": end == len(values),
+	}
+	if end < len(values) {
+		page["nextPageStart"] = end
+	}
+	fakeapi.WriteJSON(w, http.StatusOK, page)
+}
+
+// writeErrors answers with a Bitbucket style error list
+func writeErrors(w http.ResponseWriter, status int, message string) {
+	fakeapi.WriteJSON(w, status, map[string]interface{}{"errors": []map[string]string{{"message": message}}})
+}
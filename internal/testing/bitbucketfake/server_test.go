@@ -0,0 +1,187 @@
+package bitbucketfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// do performs an authenticated request against the API of the fake server
+func do(t *testing.T, s *Server, method, path, body string) *http.Response {
+	req, err := http.NewRequest(method, s.URL()+apiPath+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+DefaultToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// page is a decoded page of a list endpoint
+type page struct {
+	Values        []map[string]interface{} `json:"values"`
+	IsLastPage    bool                     `json:"isLastPage"`
+	NextPageStart int                      `json:"nextPageStart"`
+}
+
+// decode decodes a JSON response body into result
+func decode(t *testing.T, resp *http.Response, result interface{}) {
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+}
+
+// newServerWithRepository starts a server holding PAY/app with ID 1
+func newServerWithRepository(t *testing.T) *Server {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	s.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{ID: 10, Name: "Payments", Path: "PAY"}}})
+	return s
+}
+
+func TestServer_Authentication(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + apiPath + "/application-properties")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-AUSERNAME"))
+
+	resp = do(t, s, http.MethodGet, "/application-properties", "")
+	assert.Equal(t, "bitbucket-bot", resp.Header.Get("X-AUSERNAME"))
+
+	req, err := http.NewRequest(http.MethodGet, s.URL()+apiPath+"/application-properties", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Len(t, s.Requests(), 3)
+}
+
+func TestServer_Paging(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	repos := make([]models.Repository, 45)
+	for i := range repos {
+		repos[i] = models.Repository{ID: i + 1, Name: "repo", Namespace: models.Namespace{Path: "PAY"}}
+	}
+	s.SetRepositories(repos)
+
+	var p page
+	decode(t, do(t, s, http.MethodGet, "/repos", ""), &p)
+	assert.Len(t, p.Values, 25)
+	assert.False(t, p.IsLastPage)
+	assert.Equal(t, 25, p.NextPageStart)
+
+	decode(t, do(t, s, http.MethodGet, "/repos?start=25&limit=100", ""), &p)
+	assert.Len(t, p.Values, 20)
+	assert.True(t, p.IsLastPage)
+	assert.Equal(t, float64(26), p.Values[0]["id"])
+
+	s.SetMaxLimit(10)
+	decode(t, do(t, s, http.MethodGet, "/repos?limit=100", ""), &p)
+	assert.Len(t, p.Values, 10)
+	assert.Equal(t, 10, p.NextPageStart)
+}
+
+func TestServer_ProjectRepositories(t *testing.T) {
+	s := newServerWithRepository(t)
+
+	var p page
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos", ""), &p)
+	require.Len(t, p.Values, 1)
+	assert.Equal(t, "app", p.Values[0]["slug"])
+	assert.Equal(t, "PAY", p.Values[0]["project"].(map[string]interface{})["key"])
+
+	resp := do(t, s, http.MethodGet, "/projects/OPS/repos", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_PullRequests(t *testing.T) {
+	s := newServerWithRepository(t)
+	created := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	s.SetPullRequests(1, []PullRequest{
+		{ID: 1, Source: "release", Target: "master", Conflicted: true, ChangedFiles: 3, CreatedAt: created},
+		{ID: 2, Source: "feature", Target: "develop", Vetoes: []string{"Requires approvals"}},
+		{ID: 3, Source: "release", Target: "master", State: "MERGED"},
+		{ID: 4, Source: "hotfix", Target: "master", Unknown: true},
+	})
+
+	var p page
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests?at=refs/heads/master&direction=INCOMING", ""), &p)
+	require.Len(t, p.Values, 2)
+	assert.Equal(t, float64(4), p.Values[0]["id"])
+	assert.Equal(t, float64(created.UnixMilli()), p.Values[1]["createdDate"])
+	assert.NotContains(t, p.Values[0], "conflicted")
+
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests?state=ALL", ""), &p)
+	assert.Len(t, p.Values, 4)
+
+	var merge map[string]interface{}
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests/1/merge", ""), &merge)
+	assert.Equal(t, true, merge["conflicted"])
+	assert.Equal(t, "CONFLICTED", merge["outcome"])
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests/2/merge", ""), &merge)
+	assert.Equal(t, false, merge["canMerge"])
+	assert.Len(t, merge["vetoes"], 1)
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests/4/merge", ""), &merge)
+	assert.Equal(t, "UNKNOWN", merge["outcome"])
+
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests/1/changes", ""), &p)
+	assert.Len(t, p.Values, 3)
+
+	resp := do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests/9/merge", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_Comments(t *testing.T) {
+	s := newServerWithRepository(t)
+	s.SetPullRequests(1, []PullRequest{{ID: 7, Source: "release", Target: "master"}})
+	comments := "/projects/PAY/repos/app/pull-requests/7/comments"
+
+	resp := do(t, s, http.MethodPost, comments, `{"text":"hello"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = do(t, s, http.MethodPost, comments, `{}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = do(t, s, http.MethodPut, comments+"/1", `{"text":"edited","version":1}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp = do(t, s, http.MethodPut, comments+"/1", `{"text":"edited","version":0}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, s.Comments(1, 7), 1)
+	assert.Equal(t, "edited", s.Comments(1, 7)[0].Body)
+
+	var p page
+	decode(t, do(t, s, http.MethodGet, "/projects/PAY/repos/app/pull-requests/7/activities", ""), &p)
+	require.Len(t, p.Values, 2)
+	assert.Equal(t, "EDITED", p.Values[0]["commentAction"])
+	assert.Equal(t, "ADDED", p.Values[1]["commentAction"])
+
+	resp = do(t, s, http.MethodDelete, comments+"/1?version=0", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp = do(t, s, http.MethodDelete, comments+"/1?version=1", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, s.Comments(1, 7))
+
+	resp = do(t, s, http.MethodGet, comments+"/1", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_ResetRequests(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	do(t, s, http.MethodGet, "/application-properties", "")
+	require.Len(t, s.Requests(), 1)
+	s.ResetRequests()
+	assert.Empty(t, s.Requests())
+}
@@ -0,0 +1,117 @@
+// Package fakeapi holds what the fake API servers of gitlabfake, githubfake, giteafake and
+// bitbucketfake share: the HTTP server recording the requests it receives, JSON answers, path
+// matching and the lookup of the repositories they keep.
+package fakeapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// Request is a request received by a fake server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   string
+}
+
+// HandlerFunc answers a request whose body was already read
+type HandlerFunc func(w http.ResponseWriter, r *http.Request, body []byte)
+
+// Server runs a fake API and records the requests it receives; the fake servers embed it
+type Server struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Start starts serving, passing every request to handler once it is recorded
+func (s *Server) Start(handler HandlerFunc) {
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: string(body)})
+		s.mu.Unlock()
+		handler(w, r, body)
+	}))
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests forgets the requests received so far
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// WriteJSON answers with a JSON body
+func WriteJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// Match reports whether path segments equal pattern, where "*" matches any single segment
+func Match(segments []string, pattern ...string) bool {
+	if len(segments) != len(pattern) {
+		return false
+	}
+	for i, want := range pattern {
+		if want != "*" && segments[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Stored is a repository a fake server keeps along with its API-specific state
+type Stored interface {
+	Repository() models.Repository
+}
+
+// ByID returns the stored repository with the ID, or nil when there is none
+func ByID[T Stored](stored []T, id int) T {
+	for _, s := range stored {
+		if s.Repository().ID == id {
+			return s
+		}
+	}
+	var none T
+	return none
+}
+
+// ByPath returns the stored repository with the namespace path and name, or nil when there is none
+func ByPath[T Stored](stored []T, namespace, name string) T {
+	for _, s := range stored {
+		if repo := s.Repository(); repo.Namespace.Path == namespace && repo.Name == name {
+			return s
+		}
+	}
+	var none T
+	return none
+}
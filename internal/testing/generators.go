@@ -138,15 +138,15 @@ func (g *PropertyTestGenerators) GenBranchName() gopter.Gen {
 
 // GenAlphaNumericString generates alphanumeric strings within a length range
 func (g *PropertyTestGenerators) GenAlphaNumericString(minLen, maxLen int) gopter.Gen {
-	return gen.AlphaString().SuchThat(func(s string) bool {
-		return len(s) >= minLen && len(s) <= maxLen
-	}).Map(func(s string) string {
+	// Built to length rather than filtered, so that sampling never runs out of attempts
+	return gopter.CombineGens(
+		gen.IntRange(minLen, maxLen),
+		gen.SliceOfN(maxLen, gen.AlphaChar()),
+	).Map(func(values []interface{}) string {
+		s := string(values[1].([]rune)[:values[0].(int)])
 		if len(s) == 0 {
 			return "test" // Fallback for empty strings
 		}
-		if len(s) > maxLen {
-			return s[:maxLen]
-		}
 		return s
 	})
 }
@@ -0,0 +1,182 @@
+// Package giteafake provides a stateful fake Gitea REST API server for integration tests.
+//
+// The server keeps repositories, pull requests and their comments in memory below /api/v1, and
+// paginates with page and limit like Gitea, reporting the total in X-Total-Count and capping the
+// page size like its MAX_RESPONSE_ITEMS setting. Forgejo serves the same API.
+package giteafake
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+const (
+	// DefaultToken is the token accepted by a new server
+	DefaultToken = "gitea-test-token"
+	// apiPath is the path the API is served below
+	apiPath = "/api/v1"
+	// maxResponseItems is the largest page size Gitea answers with by default
+	maxResponseItems = 50
+)
+
+// PullRequest is a pull request kept by the server
+type PullRequest struct {
+	Number       int
+	Title        string
+	Author       string
+	Head         string // Head branch, in the repository itself
+	Base         string
+	State        string // "open" (default) or "closed"
+	Merged       bool
+	Draft        bool
+	Mergeable    bool
+	CheckingFor  int // Number of responses that call the pull request unmergeable first, while Gitea checks it
+	ChangedFiles int
+	Assignees    []string // Logins of the assignees
+	Reviewers    []string // Logins of the requested reviewers
+	Milestone    string   // Milestone title, empty for none
+	SHA          string   // Head commit
+	Comments     int      // Comments besides the stored conversation comments, such as review comments
+	Labels       []string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Request is a request received by the server
+type Request = fakeapi.Request
+
+// repository is a stored repository with its pull requests and comments
+type repository struct {
+	repo      models.Repository
+	pulls     []*PullRequest
+	comments  map[int][]models.Note // Pull request number -> conversation comments
+	protected map[string]bool       // Names of the protected branches
+}
+
+// Repository implements fakeapi.Stored
+func (r *repository) Repository() models.Repository {
+	return r.repo
+}
+
+// Server is a fake Gitea API server
+type Server struct {
+	fakeapi.Server
+
+	mu            sync.Mutex
+	token         string
+	login         string
+	repos         []*repository
+	maxPageSize   int
+	nextCommentID int
+}
+
+// NewServer starts a fake Gitea server accepting DefaultToken
+func NewServer() *Server {
+	s := &Server{
+		token:         DefaultToken,
+		login:         "gitea-bot",
+		maxPageSize:   maxResponseItems,
+		nextCommentID: 1,
+	}
+	s.Start(s.handle)
+	return s
+}
+
+// SetMaxPageSize lowers the largest page size the server answers with
+func (s *Server) SetMaxPageSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPageSize = size
+}
+
+// SetRepositories replaces the repositories; the namespace path of each is its owner
+func (s *Server) SetRepositories(repos []models.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos = nil
+	for _, repo := range repos {
+		s.repos = append(s.repos, &repository{repo: repo, comments: make(map[int][]models.Note), protected: make(map[string]bool)})
+	}
+}
+
+// SetPullRequests replaces the pull requests of a repository
+func (s *Server) SetPullRequests(repoID int, pulls []PullRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := fakeapi.ByID(s.repos, repoID)
+	if r == nil {
+		return
+	}
+	r.pulls = nil
+	for i := range pulls {
+		pr := pulls[i]
+		if pr.State == "" {
+			pr.State = "open"
+		}
+		r.pulls = append(r.pulls, &pr)
+	}
+}
+
+// ProtectBranch protects a branch of a repository; every other branch exists unprotected
+func (s *Server) ProtectBranch(repoID int, branch string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := fakeapi.ByID(s.repos, repoID); r != nil {
+		r.protected[branch] = true
+	}
+}
+
+// Comments returns the conversation comments of a pull request
+func (s *Server) Comments(repoID, number int) []models.Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := fakeapi.ByID(s.repos, repoID); r != nil {
+		return append([]models.Note(nil), r.comments[number]...)
+	}
+	return nil
+}
+
+// handle checks the token of a request and dispatches it to the API handlers
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, ok := strings.CutPrefix(r.URL.Path, apiPath+"/")
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if r.Header.Get("Authorization") != "token "+s.token {
+		writeMessage(w, http.StatusUnauthorized, "user does not exist [uid: 0, name: ]")
+		return
+	}
+
+	s.route(w, r, strings.Split(strings.Trim(path, "/"), "/"), body)
+}
+
+// paginate writes X-Total-Count for total items and returns the bounds of the requested page
+func (s *Server) paginate(w http.ResponseWriter, r *http.Request, total int) (int, int) {
+	query := r.URL.Query()
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := s.maxPageSize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = min(l, s.maxPageSize)
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	start := min((page-1)*limit, total)
+	return start, min(start+limit, total)
+}
+
+// writeMessage answers with a Gitea style error message
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	fakeapi.WriteJSON(w, status, map[string]string{"message": message})
+}
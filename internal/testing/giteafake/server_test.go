@@ -0,0 +1,173 @@
+package giteafake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// do performs an authenticated request against the API of the fake server
+func do(t *testing.T, s *Server, method, path, body string) *http.Response {
+	req, err := http.NewRequest(method, s.URL()+apiPath+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token "+DefaultToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// decode decodes a JSON response body into result
+func decode(t *testing.T, resp *http.Response, result interface{}) {
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+}
+
+// newServerWithRepository starts a server holding acme/app with ID 1
+func newServerWithRepository(t *testing.T) *Server {
+	s := NewServer()
+	t.Cleanup(s.Close)
+	s.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{ID: 10, Path: "acme"}}})
+	return s
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	resp, err := http.Get(s.URL() + "/api/v1/user")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = do(t, s, http.MethodGet, "/user", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, s.Requests(), 2)
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	repos := make([]models.Repository, 45)
+	for i := range repos {
+		repos[i] = models.Repository{ID: i + 1, Name: "repo", Namespace: models.Namespace{Path: "acme"}}
+	}
+	s.SetRepositories(repos)
+
+	resp := do(t, s, http.MethodGet, "/user/repos?page=2&limit=30", "")
+	var page []map[string]interface{}
+	decode(t, resp, &page)
+	assert.Len(t, page, 15)
+	assert.Equal(t, float64(31), page[0]["id"])
+	assert.Equal(t, "45", resp.Header.Get("X-Total-Count"))
+
+	s.SetMaxPageSize(10)
+	resp = do(t, s, http.MethodGet, "/user/repos?limit=50", "")
+	decode(t, resp, &page)
+	assert.Len(t, page, 10)
+}
+
+func TestServer_OrganizationRepositories(t *testing.T) {
+	s := newServerWithRepository(t)
+
+	resp := do(t, s, http.MethodGet, "/orgs/acme/repos", "")
+	var repos []map[string]interface{}
+	decode(t, resp, &repos)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "acme/app", repos[0]["full_name"])
+
+	resp = do(t, s, http.MethodGet, "/orgs/missing/repos", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = do(t, s, http.MethodGet, "/repositories/1", "")
+	var repo map[string]interface{}
+	decode(t, resp, &repo)
+	assert.Equal(t, s.URL()+"/acme/app", repo["html_url"])
+}
+
+func TestServer_PullRequestCheckingFor(t *testing.T) {
+	s := newServerWithRepository(t)
+	s.SetPullRequests(1, []PullRequest{{Number: 1, Head: "release", Base: "master", Mergeable: true, CheckingFor: 1}})
+
+	var pull map[string]interface{}
+	decode(t, do(t, s, http.MethodGet, "/repos/acme/app/pulls/1", ""), &pull)
+	assert.Equal(t, false, pull["mergeable"])
+	decode(t, do(t, s, http.MethodGet, "/repos/acme/app/pulls/1", ""), &pull)
+	assert.Equal(t, true, pull["mergeable"])
+}
+
+func TestServer_PullRequests(t *testing.T) {
+	s := newServerWithRepository(t)
+	older := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	s.SetPullRequests(1, []PullRequest{
+		{Number: 1, Head: "release", Base: "master", ChangedFiles: 3, UpdatedAt: older},
+		{Number: 2, Head: "feature", Base: "master", Mergeable: true, UpdatedAt: older.Add(time.Hour)},
+		{Number: 3, Head: "release", Base: "master", State: "closed", Merged: true},
+	})
+
+	resp := do(t, s, http.MethodGet, "/repos/acme/app/pulls?sort=recentupdate", "")
+	var pulls []map[string]interface{}
+	decode(t, resp, &pulls)
+	require.Len(t, pulls, 2)
+	assert.Equal(t, float64(2), pulls[0]["number"])
+	assert.Equal(t, true, pulls[0]["mergeable"])
+
+	resp = do(t, s, http.MethodGet, "/repos/acme/app/pulls?state=closed", "")
+	decode(t, resp, &pulls)
+	require.Len(t, pulls, 1)
+	assert.Equal(t, true, pulls[0]["merged"])
+
+	resp = do(t, s, http.MethodGet, "/repos/acme/app/pulls/1", "")
+	var pull map[string]interface{}
+	decode(t, resp, &pull)
+	assert.Equal(t, false, pull["mergeable"])
+	assert.Equal(t, float64(3), pull["changed_files"])
+
+	resp = do(t, s, http.MethodGet, "/repos/acme/app/pulls/9", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_Comments(t *testing.T) {
+	s := newServerWithRepository(t)
+	s.SetPullRequests(1, []PullRequest{{Number: 7, Head: "release", Base: "master"}})
+
+	resp := do(t, s, http.MethodPost, "/repos/acme/app/issues/7/comments", `{"body":"hello"}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = do(t, s, http.MethodPost, "/repos/acme/app/issues/7/comments", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	resp = do(t, s, http.MethodPost, "/repos/acme/app/issues/8/comments", `{"body":"hello"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var comments []map[string]interface{}
+	decode(t, do(t, s, http.MethodGet, "/repos/acme/app/issues/7/comments", ""), &comments)
+	require.Len(t, comments, 1)
+
+	resp = do(t, s, http.MethodPatch, "/repos/acme/app/issues/comments/1", `{"body":"edited"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, s.Comments(1, 7), 1)
+	assert.Equal(t, "edited", s.Comments(1, 7)[0].Body)
+	assert.Equal(t, "gitea-bot", s.Comments(1, 7)[0].Author.Username)
+
+	resp = do(t, s, http.MethodDelete, "/repos/acme/app/issues/comments/1", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, s.Comments(1, 7))
+
+	resp = do(t, s, http.MethodDelete, "/repos/acme/app/issues/comments/1", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestServer_ResetRequests(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	do(t, s, http.MethodGet, "/user", "")
+	require.Len(t, s.Requests(), 1)
+	s.ResetRequests()
+	assert.Empty(t, s.Requests())
+}
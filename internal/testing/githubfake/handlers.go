@@ -0,0 +1,281 @@
+package githubfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+// route dispatches a request by method and path segments; the caller holds the lock
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string, body []byte) {
+	switch {
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "user"):
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{"login": s.login, "id": 1})
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "user", "repos"):
+		s.listRepositories(w, r, "")
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "orgs", "*", "repos"):
+		s.listRepositories(w, r, segments[1])
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "repositories", "*"):
+		id, _ := strconv.Atoi(segments[1])
+		repo := fakeapi.ByID(s.repos, id)
+		if repo == nil {
+			writeMessage(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, s.repositoryJSON(repo))
+	case len(segments) >= 4 && segments[0] == "repos":
+		repo := fakeapi.ByPath(s.repos, segments[1], segments[2])
+		if repo == nil {
+			writeMessage(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		s.routeRepository(w, r, repo, segments[3:], body)
+	default:
+		writeMessage(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// routeRepository dispatches the requests below /repos/{owner}/{repo}
+func (s *Server) routeRepository(w http.ResponseWriter, r *http.Request, repo *repository, segments []string, body []byte) {
+	switch {
+	case r.Method == http.MethodGet && len(segments) >= 2 && segments[0] == "branches":
+		// Branch names may contain slashes
+		name := strings.Join(segments[1:], "/")
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{"name": name, "protected": repo.protected[name]})
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "pulls"):
+		s.listPullRequests(w, r, repo)
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "pulls", "*"):
+		pr := repo.pullRequest(segments[1])
+		if pr == nil {
+			writeMessage(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, s.pullRequestJSON(repo, pr, true))
+	case r.Method == http.MethodGet && fakeapi.Match(segments, "issues", "*", "comments"):
+		number, _ := strconv.Atoi(segments[1])
+		comments := repo.comments[number]
+		start, end := s.paginate(w, r, len(comments))
+		page := make([]map[string]interface{}, 0, end-start)
+		for _, comment := range comments[start:end] {
+			page = append(page, commentJSON(comment))
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, page)
+	case r.Method == http.MethodPost && fakeapi.Match(segments, "issues", "*", "comments"):
+		number, _ := strconv.Atoi(segments[1])
+		if repo.pullRequest(segments[1]) == nil {
+			writeMessage(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		var request struct {
+			Body string `json:"body"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil || request.Body == "" {
+			writeMessage(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		comment := models.Note{ID: s.nextCommentID, Body: request.Body, Author: models.Author{Username: s.login}, CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC()}
+		s.nextCommentID++
+		repo.comments[number] = append(repo.comments[number], comment)
+		fakeapi.WriteJSON(w, http.StatusCreated, commentJSON(comment))
+	case (r.Method == http.MethodPatch || r.Method == http.MethodDelete) && fakeapi.Match(segments, "issues", "comments", "*"):
+		s.changeComment(w, r, repo, segments[2], body)
+	default:
+		writeMessage(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// listRepositories answers the repositories of the token's user, or of one organization
+func (s *Server) listRepositories(w http.ResponseWriter, r *http.Request, org string) {
+	var repos []*repository
+	for _, repo := range s.repos {
+		if org == "" || repo.repo.Namespace.Path == org {
+			repos = append(repos, repo)
+		}
+	}
+	if org != "" && len(repos) == 0 {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	start, end := s.paginate(w, r, len(repos))
+	page := make([]map[string]interface{}, 0, end-start)
+	for _, repo := range repos[start:end] {
+		page = append(page, s.repositoryJSON(repo))
+	}
+	fakeapi.WriteJSON(w, http.StatusOK, page)
+}
+
+// listPullRequests answers the pull requests of a repository filtered by state, head and base.
+// Like GitHub, the list leaves out the mergeability fields, changed_files and comments.
+func (s *Server) listPullRequests(w http.ResponseWriter, r *http.Request, repo *repository) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "open"
+	}
+
+	var pulls []*PullRequest
+	for _, pr := range repo.pulls {
+		if state != "all" && pr.State != state {
+			continue
+		}
+		if head := query.Get("head"); head != "" && head != repo.repo.Namespace.Path+":"+pr.Head {
+			continue
+		}
+		if base := query.Get("base"); base != "" && base != pr.Base {
+			continue
+		}
+		pulls = append(pulls, pr)
+	}
+	if query.Get("sort") == "updated" {
+		slices.SortStableFunc(pulls, func(a, b *PullRequest) int {
+			if query.Get("direction") == "asc" {
+				return a.UpdatedAt.Compare(b.UpdatedAt)
+			}
+			return b.UpdatedAt.Compare(a.UpdatedAt)
+		})
+	}
+
+	start, end := s.paginate(w, r, len(pulls))
+	page := make([]map[string]interface{}, 0, end-start)
+	for _, pr := range pulls[start:end] {
+		page = append(page, s.pullRequestJSON(repo, pr, false))
+	}
+	fakeapi.WriteJSON(w, http.StatusOK, page)
+}
+
+// changeComment updates or deletes a conversation comment
+func (s *Server) changeComment(w http.ResponseWriter, r *http.Request, repo *repository, id string, body []byte) {
+	commentID, _ := strconv.Atoi(id)
+	for number, comments := range repo.comments {
+		for i := range comments {
+			if comments[i].ID != commentID {
+				continue
+			}
+			if r.Method == http.MethodDelete {
+				repo.comments[number] = append(comments[:i], comments[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			var request struct {
+				Body string `json:"body"`
+			}
+			if err := json.Unmarshal(body, &request); err != nil || request.Body == "" {
+				writeMessage(w, http.StatusUnprocessableEntity, "Validation Failed")
+				return
+			}
+			comments[i].Body = request.Body
+			comments[i].UpdatedAt = time.Now().UTC()
+			fakeapi.WriteJSON(w, http.StatusOK, commentJSON(comments[i]))
+			return
+		}
+	}
+	writeMessage(w, http.StatusNotFound, "Not Found")
+}
+
+// pullRequest looks up a pull request by number
+func (r *repository) pullRequest(number string) *PullRequest {
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil
+	}
+	for _, pr := range r.pulls {
+		if pr.Number == n {
+			return pr
+		}
+	}
+	return nil
+}
+
+// repositoryJSON renders a repository like the GitHub API
+func (s *Server) repositoryJSON(r *repository) map[string]interface{} {
+	repo := r.repo
+	owner := repo.Namespace.Path
+	return map[string]interface{}{
+		"id":         repo.ID,
+		"name":       repo.Name,
+		"full_name":  owner + "/" + repo.Name,
+		"html_url":   "https://github.example.com/" + owner + "/" + repo.Name,
+		"owner":      map[string]interface{}{"id": repo.Namespace.ID, "login": owner},
+		"pushed_at":  repo.LastActivityAt,
+		"updated_at": repo.LastActivityAt,
+	}
+}
+
+// pullRequestJSON renders a pull request like the GitHub API; detailed adds the fields only sent
+// for a single pull request, and uses up one of the answers with an unknown mergeability
+func (s *Server) pullRequestJSON(r *repository, pr *PullRequest, detailed bool) map[string]interface{} {
+	labels := make([]map[string]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, map[string]string{"name": label})
+	}
+	owner := r.repo.Namespace.Path
+	result := map[string]interface{}{
+		"number":              pr.Number,
+		"title":               pr.Title,
+		"html_url":            "https://github.example.com/" + owner + "/" + r.repo.Name + "/pull/" + strconv.Itoa(pr.Number),
+		"state":               pr.State,
+		"draft":               pr.Draft,
+		"user":                map[string]string{"login": pr.Author},
+		"head":                map[string]string{"ref": pr.Head, "label": owner + ":" + pr.Head, "sha": pr.SHA},
+		"base":                map[string]string{"ref": pr.Base, "label": owner + ":" + pr.Base},
+		"labels":              labels,
+		"assignees":           users(pr.Assignees),
+		"requested_reviewers": users(pr.Reviewers),
+		"milestone":           nil,
+		"created_at":          pr.CreatedAt,
+		"updated_at":          pr.UpdatedAt,
+		"merged_at":           nil,
+	}
+	if pr.Milestone != "" {
+		result["milestone"] = map[string]string{"title": pr.Milestone}
+	}
+	if pr.Merged {
+		result["merged_at"] = pr.UpdatedAt
+	}
+	if !detailed {
+		return result
+	}
+
+	mergeableState := pr.MergeableState
+	if mergeableState == "" {
+		mergeableState = "clean"
+	}
+	if pr.UnknownFor > 0 {
+		pr.UnknownFor--
+		result["mergeable"] = nil
+		result["mergeable_state"] = "unknown"
+	} else {
+		result["mergeable"] = mergeableState != "dirty" && mergeableState != "draft"
+		result["mergeable_state"] = mergeableState
+	}
+	result["changed_files"] = pr.ChangedFiles
+	result["comments"] = len(r.comments[pr.Number])
+	return result
+}
+
+// users renders logins as a list of GitHub users
+func users(logins []string) []map[string]string {
+	result := make([]map[string]string, 0, len(logins))
+	for _, login := range logins {
+		result = append(result, map[string]string{"login": login})
+	}
+	return result
+}
+
+// commentJSON renders a conversation comment like the GitHub API
+func commentJSON(comment models.Note) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         comment.ID,
+		"body":       comment.Body,
+		"user":       map[string]string{"login": comment.Author.Username},
+		"created_at": comment.CreatedAt,
+		"updated_at": comment.UpdatedAt,
+	}
+}
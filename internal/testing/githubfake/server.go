@@ -0,0 +1,208 @@
+// Package githubfake provides a stateful fake GitHub REST API server for integration tests.
+//
+// The server keeps repositories, pull requests and their comments in memory, paginates with the
+// Link header of the real API, can report mergeability as still being computed and can simulate
+// an exhausted rate limit.
+package githubfake
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+const (
+	// DefaultToken is the token accepted by a new server
+	DefaultToken = "github-test-token"
+	// defaultPerPage is the page size GitHub uses when per_page is not given
+	defaultPerPage = 30
+	// maxPerPage is the largest page size GitHub accepts
+	maxPerPage = 100
+)
+
+// PullRequest is a pull request kept by the server
+type PullRequest struct {
+	Number         int
+	Title          string
+	Author         string
+	Head           string // Head branch, in the repository itself
+	Base           string
+	State          string // "open" (default) or "closed"
+	Merged         bool
+	Draft          bool
+	MergeableState string // "clean" (default), "dirty", "behind", ...
+	UnknownFor     int    // Number of requests for the pull request answered with an unknown mergeability first
+	ChangedFiles   int
+	Assignees      []string // Logins of the assignees
+	Reviewers      []string // Logins of the requested reviewers
+	Milestone      string   // Milestone title, empty for none
+	SHA            string   // Head commit
+	Labels         []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Request is a request received by the server
+type Request = fakeapi.Request
+
+// repository is a stored repository with its pull requests and comments
+type repository struct {
+	repo      models.Repository
+	pulls     []*PullRequest
+	comments  map[int][]models.Note // Pull request number -> conversation comments
+	protected map[string]bool       // Names of the protected branches
+}
+
+// Repository implements fakeapi.Stored
+func (r *repository) Repository() models.Repository {
+	return r.repo
+}
+
+// Server is a fake GitHub API server
+type Server struct {
+	fakeapi.Server
+
+	mu            sync.Mutex
+	token         string
+	login         string
+	repos         []*repository
+	maxPerPage    int
+	rateLimited   bool
+	nextCommentID int
+}
+
+// NewServer starts a fake GitHub server accepting DefaultToken
+func NewServer() *Server {
+	s := &Server{
+		token:         DefaultToken,
+		login:         "octocat",
+		maxPerPage:    maxPerPage,
+		nextCommentID: 1,
+	}
+	s.Start(s.handle)
+	return s
+}
+
+// SetMaxPerPage lowers the largest page size the server accepts
+func (s *Server) SetMaxPerPage(perPage int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPerPage = perPage
+}
+
+// SetRateLimited makes the server answer every request as if the rate limit were used up
+func (s *Server) SetRateLimited(limited bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimited = limited
+}
+
+// SetRepositories replaces the repositories; the namespace path of each is its owner
+func (s *Server) SetRepositories(repos []models.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos = nil
+	for _, repo := range repos {
+		s.repos = append(s.repos, &repository{repo: repo, comments: make(map[int][]models.Note), protected: make(map[string]bool)})
+	}
+}
+
+// SetPullRequests replaces the pull requests of a repository
+func (s *Server) SetPullRequests(repoID int, pulls []PullRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := fakeapi.ByID(s.repos, repoID)
+	if r == nil {
+		return
+	}
+	r.pulls = nil
+	for i := range pulls {
+		pr := pulls[i]
+		if pr.State == "" {
+			pr.State = "open"
+		}
+		r.pulls = append(r.pulls, &pr)
+	}
+}
+
+// ProtectBranch protects a branch of a repository; every other branch exists unprotected
+func (s *Server) ProtectBranch(repoID int, branch string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := fakeapi.ByID(s.repos, repoID); r != nil {
+		r.protected[branch] = true
+	}
+}
+
+// Comments returns the conversation comments of a pull request
+func (s *Server) Comments(repoID, number int) []models.Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := fakeapi.ByID(s.repos, repoID); r != nil {
+		return append([]models.Note(nil), r.comments[number]...)
+	}
+	return nil
+}
+
+// handle checks the token of a request and dispatches it to the API handlers
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeMessage(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+	if s.rateLimited {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC).Unix(), 10))
+		writeMessage(w, http.StatusForbidden, "API rate limit exceeded")
+		return
+	}
+
+	s.route(w, r, strings.Split(strings.Trim(r.URL.Path, "/"), "/"), body)
+}
+
+// paginate writes the Link header for total items and returns the bounds of the requested page
+func (s *Server) paginate(w http.ResponseWriter, r *http.Request, total int) (int, int) {
+	query := r.URL.Query()
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	perPage := defaultPerPage
+	if pp, err := strconv.Atoi(query.Get("per_page")); err == nil && pp > 0 {
+		perPage = pp
+	}
+	perPage = min(perPage, s.maxPerPage)
+
+	lastPage := max((total+perPage-1)/perPage, 1)
+	pageURL := func(n int) string {
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(n))
+		return fmt.Sprintf("<%s%s?%s>", s.URL(), r.URL.Path, q.Encode())
+	}
+	var links []string
+	if page < lastPage {
+		links = append(links, pageURL(page+1)+`; rel="next"`, pageURL(lastPage)+`; rel="last"`)
+	}
+	if page > 1 {
+		links = append(links, pageURL(page-1)+`; rel="prev"`, pageURL(1)+`; rel="first"`)
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	start := min((page-1)*perPage, total)
+	return start, min(start+perPage, total)
+}
+
+// writeMessage answers with a GitHub style error message
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	fakeapi.WriteJSON(w, status, map[string]string{"message": message})
+}
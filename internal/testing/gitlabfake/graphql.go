@@ -0,0 +1,212 @@
+package gitlabfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+// graphQLVariables are the variables of the projects query sent by the GraphQL backend
+type graphQLVariables struct {
+	First          int      `json:"first"`
+	After          string   `json:"after"`
+	IDs            []string `json:"ids"`
+	State          string   `json:"state"`
+	SourceBranches []string `json:"sourceBranches"`
+	TargetBranches []string `json:"targetBranches"`
+	Labels         []string `json:"labels"`
+	UpdatedAfter   string   `json:"updatedAfter"`
+	MRFirst        int      `json:"mrFirst"`
+	MRAfter        string   `json:"mrAfter"`
+}
+
+// graphQL answers the projects query of the GraphQL backend. The fake does not parse GraphQL: it
+// recognises the query by its operation name and applies its variables, and answers anything
+// else with an error like GitLab does for unknown fields.
+func (s *Server) graphQL(w http.ResponseWriter, body []byte) {
+	var request struct {
+		Query     string           `json:"query"`
+		Variables graphQLVariables `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeMessage(w, http.StatusBadRequest, "400 Bad request")
+		return
+	}
+	if !strings.Contains(request.Query, "query ProjectsWithMergeRequests") {
+		writeGraphQLError(w, "The fake GitLab server only answers the ProjectsWithMergeRequests query")
+		return
+	}
+	vars := request.Variables
+
+	// A simplified complexity model: every project costs one point plus one per merge request asked for
+	if complexity := vars.First * (vars.MRFirst + 1); s.maxComplexity > 0 && complexity > s.maxComplexity {
+		writeGraphQLError(w, fmt.Sprintf("Query has complexity of %d, which exceeds max complexity of %d", complexity, s.maxComplexity))
+		return
+	}
+
+	var updatedAfter time.Time
+	if vars.UpdatedAfter != "" {
+		parsed, err := time.Parse(time.RFC3339, vars.UpdatedAfter)
+		if err != nil {
+			writeGraphQLError(w, "Variable $updatedAfter of type Time was provided invalid value")
+			return
+		}
+		updatedAfter = parsed
+	}
+	filter := mergeRequestFilter{State: vars.State, Labels: vars.Labels, UpdatedAfter: updatedAfter}
+	if len(vars.SourceBranches) > 0 {
+		filter.SourceBranch = vars.SourceBranches[0]
+	}
+	if len(vars.TargetBranches) > 0 {
+		filter.TargetBranch = vars.TargetBranches[0]
+	}
+
+	// Projects are ordered by ID, and the cursor is the ID of the last one returned
+	var projects []*project
+	for _, p := range s.projects {
+		if p.errorStatus != 0 || (len(vars.IDs) > 0 && !slices.Contains(vars.IDs, projectGID(p.repo.ID))) {
+			continue
+		}
+		projects = append(projects, p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].repo.ID < projects[j].repo.ID })
+	if after, err := strconv.Atoi(vars.After); err == nil {
+		for len(projects) > 0 && projects[0].repo.ID <= after {
+			projects = projects[1:]
+		}
+	}
+
+	first := min(max(vars.First, 1), s.maxPerPage)
+	page := projects[:min(first, len(projects))]
+	nodes := make([]map[string]interface{}, 0, len(page))
+	for _, p := range page {
+		nodes = append(nodes, s.graphQLProject(p, filter, vars))
+	}
+
+	var endCursor string
+	if len(page) > 0 {
+		endCursor = strconv.Itoa(page[len(page)-1].repo.ID)
+	}
+	fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"projects": map[string]interface{}{
+				"pageInfo": map[string]interface{}{"hasNextPage": len(projects) > len(page), "endCursor": endCursor},
+				"nodes":    nodes,
+			},
+		},
+	})
+}
+
+// graphQLProject renders a project with a page of its merge requests; the merge request cursor is
+// the number of merge requests returned so far
+func (s *Server) graphQLProject(p *project, filter mergeRequestFilter, vars graphQLVariables) map[string]interface{} {
+	mrs := p.filterMergeRequests(filter)
+	offset, _ := strconv.Atoi(vars.MRAfter)
+	offset = min(offset, len(mrs))
+	end := min(offset+min(max(vars.MRFirst, 1), s.maxPerPage), len(mrs))
+
+	mrNodes := make([]map[string]interface{}, 0, end-offset)
+	for _, mr := range mrs[offset:end] {
+		labels := make([]map[string]string, 0, len(mr.Labels))
+		for _, label := range mr.Labels {
+			labels = append(labels, map[string]string{"title": label})
+		}
+		node := map[string]interface{}{
+			"iid":                 strconv.Itoa(mr.ID),
+			"title":               mr.Title,
+			"webUrl":              mr.WebURL,
+			"sourceBranch":        mr.SourceBranch,
+			"targetBranch":        mr.TargetBranch,
+			"state":               mr.State,
+			"draft":               mr.IsDraft(),
+			"conflicts":           mr.HasConflicts,
+			"createdAt":           mr.CreatedAt,
+			"updatedAt":           mr.UpdatedAt,
+			"mergeStatusEnum":     strings.ToUpper(mr.MergeStatus),
+			"detailedMergeStatus": strings.ToUpper(mr.DetailedMergeStatus),
+			"rebaseInProgress":    mr.RebaseInProgress,
+			"mergeError":          mr.MergeError,
+			"userNotesCount":      len(mr.notes),
+			"diffHeadSha":         mr.SHA,
+			"diffRefs":            nil,
+			"author":              graphQLUser(mr.Author),
+			"assignees":           map[string]interface{}{"nodes": graphQLUsers(mr.Assignees)},
+			"reviewers":           map[string]interface{}{"nodes": graphQLUsers(mr.Reviewers)},
+			"labels":              map[string]interface{}{"nodes": labels},
+			"milestone":           nil,
+			"headPipeline":        nil,
+			"diffStatsSummary":    map[string]int{"fileCount": len(mr.changes)},
+		}
+		if refs := mr.DiffRefs; refs != nil {
+			node["diffRefs"] = map[string]string{"baseSha": refs.BaseSHA, "headSha": refs.HeadSHA, "startSha": refs.StartSHA}
+		}
+		if milestone := mr.Milestone; milestone != nil {
+			node["milestone"] = map[string]string{
+				"id":      fmt.Sprintf("gid://gitlab/Milestone/%d", milestone.ID),
+				"iid":     strconv.Itoa(milestone.IID),
+				"title":   milestone.Title,
+				"dueDate": milestone.DueDate,
+			}
+		}
+		if pipeline := mr.HeadPipeline; pipeline != nil {
+			node["headPipeline"] = map[string]string{
+				"id":     fmt.Sprintf("gid://gitlab/Ci::Pipeline/%d", pipeline.ID),
+				"status": strings.ToUpper(pipeline.Status),
+			}
+		}
+		mrNodes = append(mrNodes, node)
+	}
+
+	repo := p.repo
+	return map[string]interface{}{
+		"id":             projectGID(repo.ID),
+		"name":           repo.Name,
+		"fullPath":       repo.PathWithNamespace,
+		"webUrl":         repo.WebURL,
+		"lastActivityAt": repo.LastActivityAt,
+		"namespace": map[string]interface{}{
+			"id":       fmt.Sprintf("gid://gitlab/Group/%d", repo.Namespace.ID),
+			"name":     repo.Namespace.Name,
+			"path":     repo.Namespace.Path,
+			"fullPath": repo.Namespace.FullPath,
+		},
+		"mergeRequests": map[string]interface{}{
+			"pageInfo": map[string]interface{}{"hasNextPage": end < len(mrs), "endCursor": strconv.Itoa(end)},
+			"nodes":    mrNodes,
+		},
+	}
+}
+
+// graphQLUser renders a user like the GitLab GraphQL API
+func graphQLUser(author models.Author) map[string]string {
+	return map[string]string{"name": author.Name, "username": author.Username, "publicEmail": author.Email}
+}
+
+// graphQLUsers renders a list of users like the nodes of a GitLab GraphQL user connection
+func graphQLUsers(authors []models.Author) []map[string]string {
+	nodes := make([]map[string]string, 0, len(authors))
+	for _, author := range authors {
+		nodes = append(nodes, graphQLUser(author))
+	}
+	return nodes
+}
+
+// writeGraphQLError answers a GraphQL request with an error, which GitLab sends with status 200
+func writeGraphQLError(w http.ResponseWriter, message string) {
+	fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
+
+// projectGID returns the global ID of a project
+func projectGID(projectID int) string {
+	return fmt.Sprintf("gid://gitlab/Project/%d", projectID)
+}
@@ -0,0 +1,543 @@
+package gitlabfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+// route dispatches an authenticated request by its path segments after /api/v4; the caller holds the lock
+func (s *Server) route(w http.ResponseWriter, r *http.Request, segments []string, jobAuth bool, body []byte) {
+	switch {
+	case fakeapi.Match(segments, "job") && r.Method == http.MethodGet:
+		if !jobAuth {
+			writeMessage(w, http.StatusUnauthorized, "401 Unauthorized")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": 1, "status": "running", "user": s.user})
+		return
+	case jobAuth:
+		// Job tokens only reach a handful of endpoints, none of which the checker uses
+		writeMessage(w, http.StatusForbidden, "403 Forbidden")
+		return
+	case fakeapi.Match(segments, "user") && r.Method == http.MethodGet:
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": 1, "username": s.user.Username, "name": s.user.Name})
+		return
+	case fakeapi.Match(segments, "personal_access_tokens", "self") && r.Method == http.MethodGet:
+		if s.tokenInfo == nil {
+			writeMessage(w, http.StatusNotFound, "404 Not Found")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, s.tokenInfo)
+		return
+	case fakeapi.Match(segments, "projects") && r.Method == http.MethodGet:
+		s.listProjects(w, r, func(models.Repository) bool { return true })
+		return
+	case fakeapi.Match(segments, "groups") && r.Method == http.MethodGet:
+		start, end := s.paginate(w, r, len(s.groups))
+		fakeapi.WriteJSON(w, http.StatusOK, pageOf(s.groups, start, end))
+		return
+	case fakeapi.Match(segments, "groups", "*", "projects") && r.Method == http.MethodGet:
+		s.listGroupProjects(w, r, segments[1])
+		return
+	case len(segments) >= 3 && segments[0] == "projects":
+		s.routeProject(w, r, segments[1:], body)
+		return
+	}
+
+	writeMessage(w, http.StatusNotFound, "404 Not Found")
+}
+
+// routeProject dispatches a request below /projects/:id; the caller holds the lock
+func (s *Server) routeProject(w http.ResponseWriter, r *http.Request, segments []string, body []byte) {
+	projectID, err := strconv.Atoi(segments[0])
+	p := fakeapi.ByID(s.projects, projectID)
+	if err != nil || p == nil {
+		writeMessage(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	if p.errorStatus != 0 {
+		writeMessage(w, p.errorStatus, fmt.Sprintf("%d %s", p.errorStatus, http.StatusText(p.errorStatus)))
+		return
+	}
+
+	segments = segments[1:]
+	switch {
+	case fakeapi.Match(segments, "merge_requests") && r.Method == http.MethodGet:
+		s.listMergeRequests(w, r, p)
+		return
+	case fakeapi.Match(segments, "merge_requests") && r.Method == http.MethodPost:
+		s.createMergeRequest(w, p, body)
+		return
+	case fakeapi.Match(segments, "repository", "branches") && r.Method == http.MethodPost:
+		s.createBranch(w, p, body)
+		return
+	case fakeapi.Match(segments, "repository", "branches", "*") && r.Method == http.MethodGet:
+		if _, exists := p.branches[segments[2]]; !exists {
+			writeMessage(w, http.StatusNotFound, "404 Branch Not Found")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, branchJSON(p, segments[2]))
+		return
+	case fakeapi.Match(segments, "repository", "merge_base") && r.Method == http.MethodGet:
+		refs := r.URL.Query()["refs[]"]
+		if len(refs) != 2 {
+			writeMessage(w, http.StatusBadRequest, "400 Bad request - refs is missing")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]string{"id": mergeBaseSHA(refs[0], refs[1])})
+		return
+	case fakeapi.Match(segments, "repository", "compare") && r.Method == http.MethodGet:
+		to := r.URL.Query().Get("to")
+		files, exists := p.branches[to]
+		if !exists {
+			writeMessage(w, http.StatusNotFound, "404 Ref Not Found")
+			return
+		}
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{"diffs": fileDiffs(files)})
+		return
+	case len(segments) >= 2 && segments[0] == "merge_requests":
+		s.routeMergeRequest(w, r, p, segments[1:], body)
+		return
+	}
+
+	writeMessage(w, http.StatusNotFound, "404 Not Found")
+}
+
+// routeMergeRequest dispatches a request below /projects/:id/merge_requests/:iid; the caller holds the lock
+func (s *Server) routeMergeRequest(w http.ResponseWriter, r *http.Request, p *project, segments []string, body []byte) {
+	mrID, err := strconv.Atoi(segments[0])
+	mr := s.mergeRequest(p.repo.ID, mrID)
+	if err != nil || mr == nil {
+		writeMessage(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+
+	segments = segments[1:]
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		fakeapi.WriteJSON(w, http.StatusOK, mr)
+		return
+	case len(segments) == 0 && r.Method == http.MethodPut:
+		s.updateMergeRequest(w, mr, body)
+		return
+	case fakeapi.Match(segments, "rebase") && r.Method == http.MethodPut:
+		// The rebase finishes at once, the next read shows the result
+		mr.RebaseInProgress = false
+		mr.MergeError = ""
+		if mr.DetailedMergeStatus == models.DetailedMergeStatusNeedRebase {
+			mr.DetailedMergeStatus = "mergeable"
+		}
+		fakeapi.WriteJSON(w, http.StatusAccepted, map[string]bool{"rebase_in_progress": true})
+		return
+	case fakeapi.Match(segments, "changes") && r.Method == http.MethodGet:
+		fakeapi.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"iid":           mr.ID,
+			"changes_count": strconv.Itoa(len(mr.changes)),
+			"changes":       fileDiffs(mr.changes),
+		})
+		return
+	case fakeapi.Match(segments, "notes") && r.Method == http.MethodGet:
+		start, end := s.paginate(w, r, len(mr.notes))
+		fakeapi.WriteJSON(w, http.StatusOK, pageOf(mr.notes, start, end))
+		return
+	case fakeapi.Match(segments, "notes") && r.Method == http.MethodPost:
+		s.createNote(w, mr, body)
+		return
+	case fakeapi.Match(segments, "notes", "*"):
+		s.routeNote(w, r, mr, segments[1], body)
+		return
+	}
+
+	writeMessage(w, http.StatusNotFound, "404 Not Found")
+}
+
+// routeNote updates or deletes a single note; the caller holds the lock
+func (s *Server) routeNote(w http.ResponseWriter, r *http.Request, mr *mergeRequest, id string, body []byte) {
+	noteID, _ := strconv.Atoi(id)
+	for i := range mr.notes {
+		if mr.notes[i].ID != noteID {
+			continue
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var request struct {
+				Body string `json:"body"`
+			}
+			if err := json.Unmarshal(body, &request); err != nil || request.Body == "" {
+				writeMessage(w, http.StatusBadRequest, "400 Bad request - body is missing")
+				return
+			}
+			mr.notes[i].Body = request.Body
+			mr.notes[i].UpdatedAt = time.Now().UTC()
+			fakeapi.WriteJSON(w, http.StatusOK, mr.notes[i])
+		case http.MethodDelete:
+			mr.notes = append(mr.notes[:i], mr.notes[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeMessage(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		}
+		return
+	}
+	writeMessage(w, http.StatusNotFound, "404 Note Not Found")
+}
+
+// listProjects answers with a page of the projects accepted by keep
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request, keep func(models.Repository) bool) {
+	activeAfter, ok := timeParam(w, r, "last_activity_after")
+	if !ok {
+		return
+	}
+
+	var repos []models.Repository
+	for _, p := range s.projects {
+		if keep(p.repo) && (activeAfter.IsZero() || p.repo.LastActivityAt.After(activeAfter)) {
+			repos = append(repos, p.repo)
+		}
+	}
+	if r.URL.Query().Get("pagination") == "keyset" {
+		s.listProjectsKeyset(w, r, repos)
+		return
+	}
+	start, end := s.paginate(w, r, len(repos))
+	fakeapi.WriteJSON(w, http.StatusOK, pageOf(repos, start, end))
+}
+
+// listProjectsKeyset answers with a page of projects ordered by ID, linking the next page by
+// id_after or id_before like GitLab's keyset pagination
+func (s *Server) listProjectsKeyset(w http.ResponseWriter, r *http.Request, repos []models.Repository) {
+	query := r.URL.Query()
+	if query.Get("order_by") != "id" {
+		fakeapi.WriteJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Keyset pagination is not yet available for this type of request"})
+		return
+	}
+	descending := query.Get("sort") == "desc"
+
+	sorted := append([]models.Repository(nil), repos...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].ID > sorted[j].ID
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	cursor, cursorParam := query.Get("id_after"), "id_after"
+	if descending {
+		cursor, cursorParam = query.Get("id_before"), "id_before"
+	}
+	if after, err := strconv.Atoi(cursor); err == nil {
+		for len(sorted) > 0 && ((!descending && sorted[0].ID <= after) || (descending && sorted[0].ID >= after)) {
+			sorted = sorted[1:]
+		}
+	}
+
+	perPage := s.pageSize(r)
+	page := pageOf(sorted, 0, min(perPage, len(sorted)))
+	if len(sorted) > perPage {
+		next := r.URL.Query()
+		next.Set(cursorParam, strconv.Itoa(page[len(page)-1].ID))
+		next.Set("per_page", strconv.Itoa(perPage))
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?%s>; rel="next"`, s.URL(), r.URL.Path, next.Encode()))
+	}
+	fakeapi.WriteJSON(w, http.StatusOK, page)
+}
+
+// listGroupProjects answers with the projects of a group, and of its subgroups when include_subgroups is set
+func (s *Server) listGroupProjects(w http.ResponseWriter, r *http.Request, id string) {
+	var group *Group
+	for i := range s.groups {
+		if strconv.Itoa(s.groups[i].ID) == id || s.groups[i].FullPath == id {
+			group = &s.groups[i]
+		}
+	}
+	if group == nil {
+		writeMessage(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+
+	includeSubgroups := r.URL.Query().Get("include_subgroups") == "true"
+	s.listProjects(w, r, func(repo models.Repository) bool {
+		if repo.Namespace.ID == group.ID {
+			return true
+		}
+		return includeSubgroups && s.isSubgroup(repo.Namespace.ID, group.ID)
+	})
+}
+
+// isSubgroup reports whether a group is nested, at any depth, below parentID
+func (s *Server) isSubgroup(groupID, parentID int) bool {
+	for depth := 0; depth < len(s.groups); depth++ {
+		var parent int
+		for _, g := range s.groups {
+			if g.ID == groupID {
+				parent = g.ParentID
+			}
+		}
+		if parent == 0 {
+			return false
+		}
+		if parent == parentID {
+			return true
+		}
+		groupID = parent
+	}
+	return false
+}
+
+// listMergeRequests answers with a page of the merge requests matching the GitLab list filters
+func (s *Server) listMergeRequests(w http.ResponseWriter, r *http.Request, p *project) {
+	query := r.URL.Query()
+	updatedAfter, ok := timeParam(w, r, "updated_after")
+	if !ok {
+		return
+	}
+	var labels []string
+	if query.Get("labels") != "" {
+		labels = strings.Split(query.Get("labels"), ",")
+	}
+
+	mrs := p.filterMergeRequests(mergeRequestFilter{
+		State:        query.Get("state"),
+		SourceBranch: query.Get("source_branch"),
+		TargetBranch: query.Get("target_branch"),
+		Labels:       labels,
+		UpdatedAfter: updatedAfter,
+	})
+
+	start, end := s.paginate(w, r, len(mrs))
+	fakeapi.WriteJSON(w, http.StatusOK, pageOf(mrs, start, end))
+}
+
+// mergeRequestFilter holds the merge request filters shared by the REST and GraphQL APIs; empty
+// fields match everything
+type mergeRequestFilter struct {
+	State        string
+	SourceBranch string
+	TargetBranch string
+	Labels       []string
+	UpdatedAfter time.Time
+}
+
+// filterMergeRequests returns the merge requests of a project that match a filter
+func (p *project) filterMergeRequests(filter mergeRequestFilter) []*mergeRequest {
+	var mrs []*mergeRequest
+	for _, mr := range p.mergeRequests {
+		if filter.State != "" && filter.State != "all" && mr.State != filter.State {
+			continue
+		}
+		if filter.SourceBranch != "" && mr.SourceBranch != filter.SourceBranch {
+			continue
+		}
+		if filter.TargetBranch != "" && mr.TargetBranch != filter.TargetBranch {
+			continue
+		}
+		if !hasLabels(mr.Labels, filter.Labels) {
+			continue
+		}
+		if !filter.UpdatedAfter.IsZero() && !mr.UpdatedAt.After(filter.UpdatedAfter) {
+			continue
+		}
+		mrs = append(mrs, mr)
+	}
+	return mrs
+}
+
+// createMergeRequest opens a merge request, refusing a second open one for the same branches
+func (s *Server) createMergeRequest(w http.ResponseWriter, p *project, body []byte) {
+	var request struct {
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+		Title        string `json:"title"`
+		Labels       string `json:"labels"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || request.SourceBranch == "" || request.TargetBranch == "" || request.Title == "" {
+		writeMessage(w, http.StatusBadRequest, "400 Bad request - source_branch, target_branch and title are required")
+		return
+	}
+
+	nextID := 1
+	for _, mr := range p.mergeRequests {
+		if mr.State == "opened" && mr.SourceBranch == request.SourceBranch && mr.TargetBranch == request.TargetBranch {
+			writeMessage(w, http.StatusConflict, "Another open merge request already exists for this source branch")
+			return
+		}
+		if mr.ID >= nextID {
+			nextID = mr.ID + 1
+		}
+	}
+
+	mr := &mergeRequest{
+		MergeRequest: models.MergeRequest{
+			ID:           nextID,
+			Title:        request.Title,
+			Author:       s.user,
+			WebURL:       fmt.Sprintf("%s/-/merge_requests/%d", p.repo.WebURL, nextID),
+			SourceBranch: request.SourceBranch,
+			TargetBranch: request.TargetBranch,
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+			State:        "opened",
+			MergeStatus:  "checking",
+			Labels:       splitLabels(request.Labels),
+		},
+	}
+	p.mergeRequests = append(p.mergeRequests, mr)
+	fakeapi.WriteJSON(w, http.StatusCreated, mr)
+}
+
+// updateMergeRequest applies the labels, add_labels and remove_labels fields of a merge request update
+func (s *Server) updateMergeRequest(w http.ResponseWriter, mr *mergeRequest, body []byte) {
+	var request struct {
+		Labels       *string `json:"labels"`
+		AddLabels    string  `json:"add_labels"`
+		RemoveLabels string  `json:"remove_labels"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeMessage(w, http.StatusBadRequest, "400 Bad request")
+		return
+	}
+
+	if request.Labels != nil {
+		mr.Labels = splitLabels(*request.Labels)
+	}
+	for _, label := range splitLabels(request.AddLabels) {
+		if !hasLabels(mr.Labels, []string{label}) {
+			mr.Labels = append(mr.Labels, label)
+		}
+	}
+	for _, label := range splitLabels(request.RemoveLabels) {
+		for i, existing := range mr.Labels {
+			if existing == label {
+				mr.Labels = append(mr.Labels[:i], mr.Labels[i+1:]...)
+				break
+			}
+		}
+	}
+	mr.UpdatedAt = time.Now().UTC()
+	fakeapi.WriteJSON(w, http.StatusOK, mr)
+}
+
+// createNote adds a note written by the token's user
+func (s *Server) createNote(w http.ResponseWriter, mr *mergeRequest, body []byte) {
+	var request struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || request.Body == "" {
+		writeMessage(w, http.StatusBadRequest, "400 Bad request - body is missing")
+		return
+	}
+
+	now := time.Now().UTC()
+	note := models.Note{ID: s.nextNoteID, Body: request.Body, Author: s.user, CreatedAt: now, UpdatedAt: now}
+	s.nextNoteID++
+	mr.notes = append(mr.notes, note)
+	fakeapi.WriteJSON(w, http.StatusCreated, note)
+}
+
+// createBranch creates a branch from an existing ref
+func (s *Server) createBranch(w http.ResponseWriter, p *project, body []byte) {
+	var request struct {
+		Branch string `json:"branch"`
+		Ref    string `json:"ref"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || request.Branch == "" || request.Ref == "" {
+		writeMessage(w, http.StatusBadRequest, "400 Bad request - branch and ref are required")
+		return
+	}
+	if _, exists := p.branches[request.Branch]; exists {
+		writeMessage(w, http.StatusBadRequest, "Branch already exists")
+		return
+	}
+	if _, exists := p.branches[request.Ref]; !exists {
+		writeMessage(w, http.StatusBadRequest, "Invalid reference name")
+		return
+	}
+
+	// A new branch has no changes of its own yet
+	p.branches[request.Branch] = nil
+	fakeapi.WriteJSON(w, http.StatusCreated, branchJSON(p, request.Branch))
+}
+
+// timeParam parses an ISO 8601 query parameter, answering 400 when it is malformed; a missing
+// parameter is the zero time
+func timeParam(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		writeMessage(w, http.StatusBadRequest, fmt.Sprintf("400 Bad request - %s is invalid", name))
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// pageOf returns items[start:end], as an empty list rather than null when there are none
+func pageOf[T any](items []T, start, end int) []T {
+	if start >= end {
+		return []T{}
+	}
+	return items[start:end]
+}
+
+// hasLabels reports whether every wanted label is present
+func hasLabels(labels, wanted []string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, label := range labels {
+			if label == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLabels splits a comma-separated label list
+func splitLabels(labels string) []string {
+	var result []string
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			result = append(result, label)
+		}
+	}
+	return result
+}
+
+// fileDiffs renders changed files as GitLab diff entries
+func fileDiffs(files []string) []map[string]interface{} {
+	diffs := []map[string]interface{}{}
+	for _, file := range files {
+		diffs = append(diffs, map[string]interface{}{
+			"old_path": file,
+			"new_path": file,
+			"diff":     "@@ -1 +1 @@\n-old\n+new\n",
+		})
+	}
+	return diffs
+}
+
+// branchJSON renders a branch
+func branchJSON(p *project, name string) models.Branch {
+	return models.Branch{Name: name, WebURL: fmt.Sprintf("%s/-/tree/%s", p.repo.WebURL, name), Protected: p.protected[name]}
+}
+
+// mergeBaseSHA returns a stable fake commit SHA for the merge base of two refs
+func mergeBaseSHA(ref1, ref2 string) string {
+	if ref2 < ref1 {
+		ref1, ref2 = ref2, ref1
+	}
+	return fmt.Sprintf("%040x", []byte(ref1+".."+ref2))[:40]
+}
@@ -0,0 +1,530 @@
+// Package gitlabfake provides a stateful fake GitLab API server for integration tests.
+//
+// The server keeps groups, projects, merge requests, changes, notes and branches in memory,
+// answers with the pagination headers and ETags of the real API, answers the query of the GraphQL
+// backend and can inject latency and errors.
+package gitlabfake
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/fakeapi"
+)
+
+const (
+	// DefaultToken is the personal access token accepted by a new server
+	DefaultToken = "test-token"
+	// defaultPerPage is the page size GitLab uses when per_page is not given
+	defaultPerPage = 20
+	// maxPerPage is the largest page size GitLab accepts
+	maxPerPage = 100
+)
+
+// Group is a GitLab group holding projects
+type Group struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	FullPath string `json:"full_path"`
+	ParentID int    `json:"parent_id,omitempty"`
+}
+
+// Fault describes an injected failure or delay
+type Fault struct {
+	Method     string        // Only requests with this method, any method when empty
+	Path       string        // Only requests whose path starts with this prefix, any path when empty
+	Latency    time.Duration // Delay before the request is answered
+	StatusCode int           // Status answered instead of handling the request, 0 to only add latency
+	RetryAfter time.Duration // Retry-After header sent with the status
+	Times      int           // Number of requests affected, every matching request when 0
+}
+
+// matches reports whether the fault applies to a request
+func (f *Fault) matches(r *http.Request) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// Request is a request received by the server
+type Request = fakeapi.Request
+
+// mergeRequest is a stored merge request with the state only the server tracks
+type mergeRequest struct {
+	models.MergeRequest
+
+	changes []string
+	notes   []models.Note
+}
+
+// project is a stored project with its merge requests and branches
+type project struct {
+	repo          models.Repository
+	mergeRequests []*mergeRequest
+	branches      map[string][]string // Branch name -> files changed on it since the merge base
+	protected     map[string]bool
+	errorStatus   int
+}
+
+// Repository implements fakeapi.Stored
+func (p *project) Repository() models.Repository {
+	return p.repo
+}
+
+// Server is a fake GitLab API server
+type Server struct {
+	fakeapi.Server
+
+	mu             sync.Mutex
+	token          string
+	jobToken       string
+	unauthorized   bool
+	user           models.Author
+	tokenInfo      *models.TokenInfo
+	groups         []Group
+	projects       []*project
+	faults         []*Fault
+	defaultPerPage int
+	maxPerPage     int
+	maxComplexity  int
+	nextNoteID     int
+}
+
+// NewServer starts a fake GitLab server accepting DefaultToken
+func NewServer() *Server {
+	s := &Server{
+		token:          DefaultToken,
+		user:           models.Author{Name: "Test User", Username: "testuser", Email: "test@example.com"},
+		defaultPerPage: defaultPerPage,
+		maxPerPage:     maxPerPage,
+		nextNoteID:     1,
+	}
+	s.Start(s.handle)
+	return s
+}
+
+// SetToken changes the personal access or OAuth token the server accepts
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetJobToken makes the server accept a CI job token
+func (s *Server) SetJobToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobToken = token
+}
+
+// SetUnauthorized makes every request fail with 401 Unauthorized
+func (s *Server) SetUnauthorized(unauthorized bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unauthorized = unauthorized
+}
+
+// SetUser changes the user the token belongs to
+func (s *Server) SetUser(user models.Author) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// SetTokenInfo sets the answer of /personal_access_tokens/self, which is 404 until set
+func (s *Server) SetTokenInfo(info models.TokenInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenInfo = &info
+}
+
+// SetDefaultPerPage sets the page size used when a request does not give per_page
+func (s *Server) SetDefaultPerPage(perPage int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaultPerPage = perPage
+}
+
+// SetMaxPerPage caps the page size, like the max_per_page setting of GitLab
+func (s *Server) SetMaxPerPage(perPage int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPerPage = perPage
+}
+
+// SetMaxComplexity makes GraphQL queries above a complexity fail like on GitLab; 0 disables the limit
+func (s *Server) SetMaxComplexity(complexity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxComplexity = complexity
+}
+
+// AddGroup adds a group
+func (s *Server) AddGroup(group Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if group.FullPath == "" {
+		group.FullPath = group.Path
+	}
+	s.groups = append(s.groups, group)
+}
+
+// SetProjects replaces every project, dropping their merge requests and branches
+func (s *Server) SetProjects(repos []models.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects = nil
+	for _, repo := range repos {
+		s.projects = append(s.projects, &project{repo: repo, branches: make(map[string][]string), protected: make(map[string]bool)})
+	}
+}
+
+// AddProject adds a project
+func (s *Server) AddProject(repo models.Repository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects = append(s.projects, &project{repo: repo, branches: make(map[string][]string), protected: make(map[string]bool)})
+}
+
+// SetProjectError makes every request for a project fail with status, or succeed again when status is 0
+func (s *Server) SetProjectError(projectID, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := fakeapi.ByID(s.projects, projectID); p != nil {
+		p.errorStatus = status
+	}
+}
+
+// SetMergeRequests replaces the merge requests of a project; they are all opened
+func (s *Server) SetMergeRequests(projectID int, mrs []models.MergeRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.ensureProject(projectID)
+	p.mergeRequests = nil
+	for _, mr := range mrs {
+		mr.State = "opened"
+		p.mergeRequests = append(p.mergeRequests, &mergeRequest{MergeRequest: mr})
+	}
+}
+
+// AddMergeRequest adds an opened merge request to a project
+func (s *Server) AddMergeRequest(projectID int, mr models.MergeRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.ensureProject(projectID)
+	mr.State = "opened"
+	p.mergeRequests = append(p.mergeRequests, &mergeRequest{MergeRequest: mr})
+}
+
+// SetMergeRequestState changes the state (opened, closed, merged) of a merge request and bumps its updated_at
+func (s *Server) SetMergeRequestState(projectID, mrID int, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mr := s.mergeRequest(projectID, mrID); mr != nil {
+		mr.State = state
+		mr.UpdatedAt = time.Now().UTC()
+	}
+}
+
+// SetChanges sets the files changed by a merge request
+func (s *Server) SetChanges(projectID, mrID int, files ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mr := s.mergeRequest(projectID, mrID); mr != nil {
+		mr.changes = files
+	}
+}
+
+// AddBranch adds a branch along with the files changed on it since it diverged from the merge base
+func (s *Server) AddBranch(projectID int, branch string, changedFiles ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ensureProject(projectID).branches[branch] = changedFiles
+}
+
+// ProtectBranch protects a branch of a project, adding the branch if needed
+func (s *Server) ProtectBranch(projectID int, branch string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.ensureProject(projectID)
+	if _, exists := p.branches[branch]; !exists {
+		p.branches[branch] = nil
+	}
+	p.protected[branch] = true
+}
+
+// MergeRequest returns the current state of a merge request
+func (s *Server) MergeRequest(projectID, mrID int) (models.MergeRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mr := s.mergeRequest(projectID, mrID); mr != nil {
+		return mr.MergeRequest, true
+	}
+	return models.MergeRequest{}, false
+}
+
+// MergeRequests returns every merge request of a project
+func (s *Server) MergeRequests(projectID int) []models.MergeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var mrs []models.MergeRequest
+	if p := fakeapi.ByID(s.projects, projectID); p != nil {
+		for _, mr := range p.mergeRequests {
+			mrs = append(mrs, mr.MergeRequest)
+		}
+	}
+	return mrs
+}
+
+// Notes returns the notes on a merge request
+func (s *Server) Notes(projectID, mrID int) []models.Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mr := s.mergeRequest(projectID, mrID); mr != nil {
+		return append([]models.Note(nil), mr.notes...)
+	}
+	return nil
+}
+
+// Branches returns the names of the branches of a project
+func (s *Server) Branches(projectID int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	if p := fakeapi.ByID(s.projects, projectID); p != nil {
+		for name := range p.branches {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InjectFault adds a fault; faults are checked in the order they were added
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// ensureProject looks up a project, adding a bare one when it does not exist; the caller holds the lock
+func (s *Server) ensureProject(projectID int) *project {
+	if p := fakeapi.ByID(s.projects, projectID); p != nil {
+		return p
+	}
+	p := &project{repo: models.Repository{ID: projectID}, branches: make(map[string][]string), protected: make(map[string]bool)}
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// mergeRequest looks up a merge request by project ID and IID; the caller holds the lock
+func (s *Server) mergeRequest(projectID, mrID int) *mergeRequest {
+	p := fakeapi.ByID(s.projects, projectID)
+	if p == nil {
+		return nil
+	}
+	for _, mr := range p.mergeRequests {
+		if mr.ID == mrID {
+			return mr
+		}
+	}
+	return nil
+}
+
+// takeFault returns the first fault matching a request and uses up one of its times
+func (s *Server) takeFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, fault := range s.faults {
+		if !fault.matches(r) {
+			continue
+		}
+		applied := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &applied
+	}
+	return nil
+}
+
+// handle applies faults to a request and dispatches it to the API handlers
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, body []byte) {
+	if fault := s.takeFault(r); fault != nil {
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.StatusCode != 0 {
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+			}
+			writeMessage(w, fault.StatusCode, http.StatusText(fault.StatusCode))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobAuth, ok := s.authenticate(r)
+	if !ok {
+		writeMessage(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	recorder := httptest.NewRecorder()
+	if r.URL.Path == "/api/graphql" && r.Method == http.MethodPost {
+		s.graphQL(recorder, body)
+		writeWithETag(w, r, recorder)
+		return
+	}
+
+	segments, ok := splitPath(r)
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	s.route(recorder, r, segments, jobAuth, body)
+	writeWithETag(w, r, recorder)
+}
+
+// writeWithETag copies a recorded response, tagging successful reads with an ETag and answering
+// 304 Not Modified when the request already holds it, as GitLab does
+func writeWithETag(w http.ResponseWriter, r *http.Request, recorder *httptest.ResponseRecorder) {
+	for name, values := range recorder.Header() {
+		w.Header()[name] = values
+	}
+	if r.Method == http.MethodGet && recorder.Code == http.StatusOK {
+		sum := sha256.Sum256(recorder.Body.Bytes())
+		etag := fmt.Sprintf(`W/"%x"`, sum[:16])
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(recorder.Code)
+	w.Write(recorder.Body.Bytes())
+}
+
+// authenticate checks the credentials of a request and reports whether it used the job token
+func (s *Server) authenticate(r *http.Request) (jobAuth, ok bool) {
+	if s.unauthorized {
+		return false, false
+	}
+	if s.jobToken != "" && r.Header.Get("JOB-TOKEN") == s.jobToken {
+		return true, true
+	}
+	if r.Header.Get("Authorization") == "Bearer "+s.token || r.Header.Get("PRIVATE-TOKEN") == s.token {
+		return false, true
+	}
+	return false, false
+}
+
+// splitPath returns the unescaped path segments after /api/v4
+func splitPath(r *http.Request) ([]string, bool) {
+	path, ok := strings.CutPrefix(r.URL.EscapedPath(), "/api/v4/")
+	if !ok {
+		return nil, false
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+		segments[i] = unescaped
+	}
+	return segments, true
+}
+
+// paginate writes the GitLab pagination headers for total items and returns the bounds of the requested page
+func (s *Server) paginate(w http.ResponseWriter, r *http.Request, total int) (int, int) {
+	query := r.URL.Query()
+
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	perPage := s.pageSize(r)
+
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	header := w.Header()
+	header.Set("X-Page", strconv.Itoa(page))
+	header.Set("X-Per-Page", strconv.Itoa(perPage))
+	header.Set("X-Total", strconv.Itoa(total))
+	header.Set("X-Total-Pages", strconv.Itoa(totalPages))
+	header.Set("X-Next-Page", "")
+	header.Set("X-Prev-Page", "")
+
+	pageURL := func(n int) string {
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(n))
+		q.Set("per_page", strconv.Itoa(perPage))
+		return fmt.Sprintf("<%s%s?%s>", s.URL(), r.URL.Path, q.Encode())
+	}
+	var links []string
+	if page > 1 {
+		header.Set("X-Prev-Page", strconv.Itoa(page-1))
+		links = append(links, pageURL(page-1)+`; rel="prev"`)
+	}
+	if page < totalPages {
+		header.Set("X-Next-Page", strconv.Itoa(page+1))
+		links = append(links, pageURL(page+1)+`; rel="next"`)
+	}
+	links = append(links, pageURL(1)+`; rel="first"`, pageURL(totalPages)+`; rel="last"`)
+	header.Set("Link", strings.Join(links, ", "))
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageSize returns the per_page of a request, defaulted and capped like GitLab does
+func (s *Server) pageSize(r *http.Request) int {
+	perPage := s.defaultPerPage
+	if pp, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && pp > 0 {
+		perPage = pp
+	}
+	return min(perPage, s.maxPerPage)
+}
+
+// writeMessage answers with a GitLab style error message
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	fakeapi.WriteJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,370 @@
+package gitlabfake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// newClient creates a GitLab client talking to the fake server
+func newClient(t *testing.T, s *Server, opts ...gitlab.Option) *gitlab.Client {
+	client := gitlab.NewClient(s.URL(), DefaultToken, opts...)
+	t.Cleanup(client.Close)
+	return client
+}
+
+// get performs an authenticated GET against the fake server
+func get(t *testing.T, s *Server, path string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, s.URL()+path, nil)
+	require.NoError(t, err)
+	req.Header.Set("PRIVATE-TOKEN", DefaultToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// repositories creates count projects numbered from 1
+func repositories(count int) []models.Repository {
+	repos := make([]models.Repository, count)
+	for i := range repos {
+		repos[i] = models.Repository{ID: i + 1, Name: "repo", WebURL: "https://gitlab.example.com/group/repo"}
+	}
+	return repos
+}
+
+func TestServer_PaginationHeaders(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetProjects(repositories(45))
+
+	resp := get(t, s, "/api/v4/projects?membership=true&page=2")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Page"))
+	assert.Equal(t, "20", resp.Header.Get("X-Per-Page"))
+	assert.Equal(t, "45", resp.Header.Get("X-Total"))
+	assert.Equal(t, "3", resp.Header.Get("X-Total-Pages"))
+	assert.Equal(t, "3", resp.Header.Get("X-Next-Page"))
+	assert.Equal(t, "1", resp.Header.Get("X-Prev-Page"))
+	assert.Contains(t, resp.Header.Get("Link"), `page=3&per_page=20>; rel="next"`)
+	assert.Contains(t, resp.Header.Get("Link"), `rel="last"`)
+
+	var repos []models.Repository
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&repos))
+	require.Len(t, repos, 20)
+	assert.Equal(t, 21, repos[0].ID)
+
+	// The last page has no next page
+	resp = get(t, s, "/api/v4/projects?page=3")
+	assert.Equal(t, "", resp.Header.Get("X-Next-Page"))
+	assert.NotContains(t, resp.Header.Get("Link"), `rel="next"`)
+
+	// Page sizes above the maximum are capped
+	s.SetMaxPerPage(10)
+	resp = get(t, s, "/api/v4/projects?per_page=100")
+	assert.Equal(t, "10", resp.Header.Get("X-Per-Page"))
+	assert.Equal(t, "5", resp.Header.Get("X-Total-Pages"))
+}
+
+func TestServer_Authentication(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	ctx := context.Background()
+
+	require.NoError(t, newClient(t, s).TestConnection(ctx))
+
+	err := gitlab.NewClient(s.URL(), "wrong-token").TestConnection(ctx)
+	assert.ErrorContains(t, err, "authentication failed")
+
+	s.SetJobToken("job-token-value")
+	jobClient := newClient(t, s, gitlab.WithAuth(gitlab.NewJobTokenAuth("job-token-value")))
+	require.NoError(t, jobClient.TestConnection(ctx))
+	_, err = jobClient.ListRepositories(ctx)
+	assert.Error(t, err, "job tokens cannot list projects")
+
+	s.SetUnauthorized(true)
+	assert.ErrorContains(t, newClient(t, s).TestConnection(ctx), "authentication failed")
+}
+
+func TestServer_TokenInfo(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	_, err := client.GetTokenInfo(ctx)
+	assert.True(t, httpapi.IsNotFound(err))
+
+	s.SetTokenInfo(models.TokenInfo{ID: 3, Name: "scanner", Scopes: []string{"read_api"}, Active: true, ExpiresAt: "2030-01-01"})
+	info, err := client.GetTokenInfo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "scanner", info.Name)
+	assert.Equal(t, []string{"read_api"}, info.Scopes)
+}
+
+func TestServer_GroupProjects(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddGroup(Group{ID: 1, Name: "Platform", Path: "platform"})
+	s.AddGroup(Group{ID: 2, Name: "Backend", Path: "backend", FullPath: "platform/backend", ParentID: 1})
+	s.AddGroup(Group{ID: 3, Name: "Other", Path: "other"})
+	s.AddProject(models.Repository{ID: 10, Namespace: models.Namespace{ID: 1}})
+	s.AddProject(models.Repository{ID: 11, Namespace: models.Namespace{ID: 2}})
+	s.AddProject(models.Repository{ID: 12, Namespace: models.Namespace{ID: 3}})
+
+	projectIDs := func(path string) []int {
+		var repos []models.Repository
+		require.NoError(t, json.NewDecoder(get(t, s, path).Body).Decode(&repos))
+		var ids []int
+		for _, repo := range repos {
+			ids = append(ids, repo.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int{10}, projectIDs("/api/v4/groups/1/projects"))
+	assert.Equal(t, []int{10, 11}, projectIDs("/api/v4/groups/1/projects?include_subgroups=true"))
+	assert.Equal(t, []int{11}, projectIDs("/api/v4/groups/platform%2Fbackend/projects"))
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/api/v4/groups/99/projects").StatusCode)
+
+	var groups []Group
+	require.NoError(t, json.NewDecoder(get(t, s, "/api/v4/groups").Body).Decode(&groups))
+	assert.Len(t, groups, 3)
+}
+
+func TestServer_MergeRequestFilters(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetProjects(repositories(1))
+	s.SetMergeRequests(1, []models.MergeRequest{
+		{ID: 1, SourceBranch: "release", TargetBranch: "master", HasConflicts: true, Labels: []string{"backend"}},
+		{ID: 2, SourceBranch: "feature", TargetBranch: "master"},
+		{ID: 3, SourceBranch: "release", TargetBranch: "develop"},
+	})
+	s.SetMergeRequestState(1, 3, "merged")
+
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	mrs, err := client.ListMergeRequests(ctx, 1, "release", "master")
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 1, mrs[0].ID)
+
+	mrs, err = client.ListMergeRequests(ctx, 1, "", "master")
+	require.NoError(t, err)
+	assert.Len(t, mrs, 2)
+
+	mrs, err = client.ListMergeRequestsWithOptions(ctx, 1, gitlab.MergeRequestListOptions{State: "merged"})
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 3, mrs[0].ID)
+
+	mrs, err = client.ListMergeRequestsWithOptions(ctx, 1, gitlab.MergeRequestListOptions{Labels: []string{"backend"}})
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 1, mrs[0].ID)
+
+	_, err = client.ListMergeRequests(ctx, 2, "release", "master")
+	assert.True(t, httpapi.IsNotFound(err))
+
+	s.SetProjectError(1, http.StatusForbidden)
+	_, err = client.ListMergeRequests(ctx, 1, "release", "master")
+	assert.ErrorContains(t, err, "API error 403")
+}
+
+func TestServer_MergeRequestWrites(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddProject(models.Repository{ID: 1, WebURL: "https://gitlab.example.com/group/repo"})
+	s.AddMergeRequest(1, models.MergeRequest{ID: 4, SourceBranch: "release", TargetBranch: "master",
+		DetailedMergeStatus: models.DetailedMergeStatusNeedRebase})
+	s.SetChanges(1, 4, "go.mod", "main.go")
+	s.AddBranch(1, "release", "main.go")
+	s.AddBranch(1, "master", "main.go", "README.md")
+
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	// Labels
+	mr, err := client.AddMergeRequestLabels(ctx, 1, 4, "conflict", "backend")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"conflict", "backend"}, mr.Labels)
+	mr, err = client.RemoveMergeRequestLabels(ctx, 1, 4, "conflict")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, mr.Labels)
+
+	// Notes
+	note, err := client.CreateMergeRequestNote(ctx, 1, 4, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "testuser", note.Author.Username)
+	_, err = client.UpdateMergeRequestNote(ctx, 1, 4, note.ID, "edited")
+	require.NoError(t, err)
+	notes, err := client.ListMergeRequestNotes(ctx, 1, 4)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "edited", notes[0].Body)
+	require.NoError(t, client.DeleteMergeRequestNote(ctx, 1, 4, note.ID))
+	assert.Empty(t, s.Notes(1, 4))
+
+	// Changes and conflicting files
+	count, err := client.GetMergeRequestChanges(ctx, 1, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	stored, _ := s.MergeRequest(1, 4)
+	files, err := client.ConflictingFiles(ctx, 1, stored)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, files)
+
+	// Branches and merge requests
+	branch, err := client.GetBranch(ctx, 1, "feature/missing")
+	require.NoError(t, err)
+	assert.Nil(t, branch)
+	_, err = client.CreateBranch(ctx, 1, "feature/back-merge", "release")
+	require.NoError(t, err)
+	branch, err = client.GetBranch(ctx, 1, "feature/back-merge")
+	require.NoError(t, err)
+	require.NotNil(t, branch)
+	assert.Equal(t, []string{"feature/back-merge", "master", "release"}, s.Branches(1))
+
+	created, err := client.CreateMergeRequest(ctx, 1, gitlab.CreateMergeRequestOptions{
+		SourceBranch: "feature/back-merge", TargetBranch: "release", Title: "Back-merge", Labels: "auto"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, created.ID)
+	assert.Equal(t, []string{"auto"}, created.Labels)
+	_, err = client.CreateMergeRequest(ctx, 1, gitlab.CreateMergeRequestOptions{
+		SourceBranch: "feature/back-merge", TargetBranch: "release", Title: "Again"})
+	assert.ErrorContains(t, err, "API error 409")
+
+	// Rebase
+	require.NoError(t, client.RebaseMergeRequest(ctx, 1, 4))
+	rebased, err := client.GetMergeRequest(ctx, 1, 4)
+	require.NoError(t, err)
+	assert.False(t, rebased.RebaseInProgress)
+	assert.Equal(t, "mergeable", rebased.DetailedMergeStatus)
+}
+
+func TestServer_ETags(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetProjects(repositories(2))
+
+	resp := get(t, s, "/api/v4/projects")
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// An unchanged response is answered with 304 Not Modified
+	req, err := http.NewRequest(http.MethodGet, s.URL()+"/api/v4/projects", nil)
+	require.NoError(t, err)
+	req.Header.Set("PRIVATE-TOKEN", DefaultToken)
+	req.Header.Set("If-None-Match", etag)
+	notModified, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	notModified.Body.Close()
+	assert.Equal(t, http.StatusNotModified, notModified.StatusCode)
+
+	// Changed data gets a new ETag
+	s.SetProjects(repositories(3))
+	assert.NotEqual(t, etag, get(t, s, "/api/v4/projects").Header.Get("ETag"))
+}
+
+func TestServer_GraphQL(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetProjects(repositories(3))
+	s.SetMergeRequests(2, []models.MergeRequest{
+		{ID: 1, SourceBranch: "release", TargetBranch: "master", HasConflicts: true},
+		{ID: 2, SourceBranch: "feature", TargetBranch: "master"},
+	})
+	ctx := context.Background()
+
+	backend := gitlab.NewGraphQLBackend(newClient(t, s), gitlab.GraphQLOptions{
+		PageSize: 2,
+		Prefetch: gitlab.MergeRequestListOptions{SourceBranch: "release", TargetBranch: "master"},
+	})
+	repos, err := backend.ListRepositories(ctx)
+	require.NoError(t, err)
+	assert.Len(t, repos, 3)
+
+	mrs, err := backend.ListMergeRequests(ctx, 2, "release", "master")
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.True(t, mrs[0].HasConflicts)
+
+	// Two pages of projects, each request answered by the GraphQL endpoint
+	var paths []string
+	for _, request := range s.Requests() {
+		paths = append(paths, request.Method+" "+request.Path)
+	}
+	assert.Equal(t, []string{"POST /api/graphql", "POST /api/graphql"}, paths)
+
+	// Queries over the complexity limit fail like on GitLab
+	s.SetMaxComplexity(1)
+	_, err = gitlab.NewGraphQLBackend(newClient(t, s), gitlab.GraphQLOptions{}).ListRepositories(ctx)
+	assert.ErrorContains(t, err, "exceeds max complexity of 1")
+
+	// Anything but the projects query is refused
+	req, err := http.NewRequest(http.MethodPost, s.URL()+"/api/graphql", strings.NewReader(`{"query":"{ currentUser { id } }"}`))
+	require.NoError(t, err)
+	req.Header.Set("PRIVATE-TOKEN", DefaultToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var body struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Errors, 1)
+	assert.Contains(t, body.Errors[0].Message, "ProjectsWithMergeRequests")
+}
+
+func TestServer_Faults(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetProjects(repositories(1))
+	client := newClient(t, s)
+	ctx := context.Background()
+
+	// Server errors for a limited number of requests
+	s.InjectFault(Fault{Path: "/api/v4/projects", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	_, err := client.ListRepositories(ctx)
+	assert.ErrorContains(t, err, "API error 503")
+	_, err = client.ListRepositories(ctx)
+	assert.NoError(t, err, "the fault is used up")
+
+	// Rate limiting with Retry-After
+	s.InjectFault(Fault{Method: http.MethodGet, Path: "/api/v4/user", StatusCode: http.StatusTooManyRequests, RetryAfter: 30 * time.Second})
+	resp := get(t, s, "/api/v4/user")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+	assert.ErrorContains(t, client.TestConnection(ctx), "rate limit exceeded")
+	s.ClearFaults()
+
+	// Latency beyond the client timeout
+	s.InjectFault(Fault{Latency: time.Second})
+	slow := newClient(t, s, gitlab.WithHTTPClient(&http.Client{Timeout: 100 * time.Millisecond}))
+	assert.ErrorContains(t, slow.TestConnection(ctx), "request failed")
+
+	// Every request is recorded, faulty or not
+	var paths []string
+	for _, request := range s.Requests() {
+		paths = append(paths, request.Method+" "+request.Path)
+	}
+	assert.Contains(t, paths, "GET /api/v4/projects")
+	assert.Contains(t, paths, "GET /api/v4/user")
+	s.ResetRequests()
+	assert.Empty(t, s.Requests())
+}
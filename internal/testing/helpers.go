@@ -1,19 +1,17 @@
 package testing
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/http/httptest"
 	"os"
 	"path/filepath"
-	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
 )
 
 // TestHelper provides common testing utilities and helpers
@@ -85,169 +83,34 @@ func (h *TestHelper) CreateMissingURLConfigFile() string {
 	return h.CreateTempConfigFile(yamlContent)
 }
 
-// MockGitLabServer provides a configurable mock GitLab API server for testing
+// MockGitLabServer provides a configurable mock GitLab API server for testing; it wraps the
+// stateful gitlabfake server, whose full API remains available
 type MockGitLabServer struct {
-	server          *httptest.Server
-	repositories    []models.Repository
-	mergeRequests   map[int][]models.MergeRequest // repo ID -> MRs
-	errorRepos      map[int]bool                  // repo IDs that should return errors
-	unauthorizedReq bool                          // whether to return 401 for all requests
-	perPage         int                           // pagination size
+	*gitlabfake.Server
 }
 
 // NewMockGitLabServer creates a new mock GitLab server
 func NewMockGitLabServer() *MockGitLabServer {
-	mock := &MockGitLabServer{
-		repositories:  []models.Repository{},
-		mergeRequests: make(map[int][]models.MergeRequest),
-		errorRepos:    make(map[int]bool),
-		perPage:       100,
-	}
-
-	mock.server = httptest.NewServer(http.HandlerFunc(mock.handleRequest))
-	return mock
-}
-
-// Close shuts down the mock server
-func (m *MockGitLabServer) Close() {
-	m.server.Close()
-}
-
-// URL returns the mock server's URL
-func (m *MockGitLabServer) URL() string {
-	return m.server.URL
+	return &MockGitLabServer{Server: gitlabfake.NewServer()}
 }
 
 // SetRepositories sets the repositories that the mock server should return
 func (m *MockGitLabServer) SetRepositories(repos []models.Repository) {
-	m.repositories = repos
-}
-
-// SetMergeRequests sets the merge requests for a specific repository
-func (m *MockGitLabServer) SetMergeRequests(repoID int, mrs []models.MergeRequest) {
-	m.mergeRequests[repoID] = mrs
+	m.SetProjects(repos)
 }
 
 // SetRepositoryError marks a repository to return an error when accessed
 func (m *MockGitLabServer) SetRepositoryError(repoID int, hasError bool) {
 	if hasError {
-		m.errorRepos[repoID] = true
+		m.SetProjectError(repoID, http.StatusForbidden)
 	} else {
-		delete(m.errorRepos, repoID)
+		m.SetProjectError(repoID, 0)
 	}
 }
 
-// SetUnauthorized makes all requests return 401 Unauthorized
-func (m *MockGitLabServer) SetUnauthorized(unauthorized bool) {
-	m.unauthorizedReq = unauthorized
-}
-
-// SetPerPage sets the pagination size for repository listing
+// SetPerPage sets the pagination size used when a request does not give per_page
 func (m *MockGitLabServer) SetPerPage(perPage int) {
-	m.perPage = perPage
-}
-
-// handleRequest handles HTTP requests to the mock server
-func (m *MockGitLabServer) handleRequest(w http.ResponseWriter, r *http.Request) {
-	// Check for unauthorized requests
-	if m.unauthorizedReq {
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-
-	// Check authentication
-	auth := r.Header.Get("Authorization")
-	if auth != "Bearer test-token" {
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-
-	// Handle different endpoints
-	switch {
-	case r.URL.Path == "/api/v4/user":
-		// Test connection endpoint
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"id":       1,
-			"username": "testuser",
-		})
-
-	case r.URL.Path == "/api/v4/projects":
-		// List repositories with pagination
-		m.handleRepositoryList(w, r)
-
-	default:
-		// Handle merge request endpoints
-		if len(r.URL.Path) > 20 && r.URL.Path[:20] == "/api/v4/projects/" {
-			m.handleMergeRequestList(w, r)
-		} else {
-			w.WriteHeader(http.StatusNotFound)
-		}
-	}
-}
-
-// handleRepositoryList handles repository listing with pagination
-func (m *MockGitLabServer) handleRepositoryList(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-
-	// Parse pagination parameters
-	page := 1
-	if p := query.Get("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil {
-			page = parsed
-		}
-	}
-
-	pageSize := m.perPage
-	if ps := query.Get("per_page"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil {
-			pageSize = parsed
-		}
-	}
-
-	// Calculate pagination
-	start := (page - 1) * pageSize
-	end := start + pageSize
-	if end > len(m.repositories) {
-		end = len(m.repositories)
-	}
-
-	var pageRepos []models.Repository
-	if start < len(m.repositories) {
-		pageRepos = m.repositories[start:end]
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(pageRepos)
-}
-
-// handleMergeRequestList handles merge request listing for a specific repository
-func (m *MockGitLabServer) handleMergeRequestList(w http.ResponseWriter, r *http.Request) {
-	// Extract project ID from path
-	pathParts := r.URL.Path[20:] // Remove "/api/v4/projects/"
-	var projectID int
-	if _, err := fmt.Sscanf(pathParts, "%d/merge_requests", &projectID); err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-
-	// Check if this repository should return an error
-	if m.errorRepos[projectID] {
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(map[string]string{
-			"message": "403 Forbidden",
-		})
-		return
-	}
-
-	// Return merge requests for this repository
-	mrs, exists := m.mergeRequests[projectID]
-	if !exists {
-		mrs = []models.MergeRequest{} // Empty slice if no MRs defined
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(mrs)
+	m.SetDefaultPerPage(perPage)
 }
 
 // TestDataGenerator provides utilities for generating test data
@@ -14,6 +14,7 @@ import (
 	"mr-conflict-checker/config"
 	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
 	"mr-conflict-checker/reporter"
 	"mr-conflict-checker/scanner"
 )
@@ -40,7 +41,7 @@ func NewIntegrationTest(t *testing.T) *IntegrationTest {
 	client := gitlab.NewClient(cfg.GitLab.URL, cfg.GitLab.Token)
 
 	// Create components
-	repoScanner := scanner.NewRepositoryScanner(client)
+	repoScanner := scanner.NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 	t.Cleanup(func() {
 		client.Close()
@@ -13,6 +13,7 @@ import (
 	"mr-conflict-checker/config"
 	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
 	"mr-conflict-checker/reporter"
 	"mr-conflict-checker/scanner"
 )
@@ -235,7 +236,7 @@ func TestComponentIntegration(t *testing.T) {
 	client := gitlab.NewClient(server.URL(), "test-token")
 	defer client.Close()
 
-	repoScanner := scanner.NewRepositoryScanner(client)
+	repoScanner := scanner.NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 	ctx := context.Background()
 
@@ -373,8 +374,8 @@ func TestPerformance(t *testing.T) {
 	})
 }
 
-// ExampleTestingFrameworkUsage demonstrates how to use the testing framework
-func ExampleTestingFrameworkUsage() {
+// Example_frameworkUsage demonstrates how to use the testing framework
+func Example_frameworkUsage() {
 	// This example shows how to use the testing framework components
 
 	// Create a test helper (normally done in test function with *testing.T)
@@ -406,7 +407,7 @@ func ExampleTestingFrameworkUsage() {
 	isComplete := validator.ValidateRepositoryCompleteness(repos, repos)
 	fmt.Printf("Repository completeness validation: %t\n", isComplete)
 
-	// Output:
+	// Example output (the mock server port varies between runs):
 	// Generated 3 repositories
 	// Generated 2 merge requests
 	// Mock server running at: http://127.0.0.1:xxxxx
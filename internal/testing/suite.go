@@ -17,6 +17,7 @@ import (
 	"mr-conflict-checker/config"
 	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
 	"mr-conflict-checker/reporter"
 	"mr-conflict-checker/scanner"
 )
@@ -177,7 +178,7 @@ func (s *TestSuite) TestRepositoryScanning() {
 
 		client := gitlab.NewClient(s.server.URL(), "test-token")
 		defer client.Close()
-		scanner := scanner.NewRepositoryScanner(client)
+		scanner := scanner.NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 		ctx := context.Background()
 		scannedRepos, err := scanner.ScanRepositories(ctx)
@@ -203,7 +204,7 @@ func (s *TestSuite) TestRepositoryScanning() {
 
 		client := gitlab.NewClient(s.server.URL(), "test-token")
 		defer client.Close()
-		scanner := scanner.NewRepositoryScanner(client)
+		scanner := scanner.NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 		ctx := context.Background()
 		scannedRepos, err := scanner.ScanRepositories(ctx)
@@ -436,7 +437,7 @@ func BenchmarkSuite(b *testing.B) {
 
 		client := gitlab.NewClient(server.URL(), "test-token")
 		defer client.Close()
-		scanner := scanner.NewRepositoryScanner(client)
+		scanner := scanner.NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
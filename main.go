@@ -2,20 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"mr-conflict-checker/actions"
 	"mr-conflict-checker/analyzer"
 	"mr-conflict-checker/config"
 	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/ack"
+	"mr-conflict-checker/internal/httpapi"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
+	"mr-conflict-checker/internal/state"
+	"mr-conflict-checker/notifier"
+	"mr-conflict-checker/provider"
 	"mr-conflict-checker/reporter"
 	"mr-conflict-checker/scanner"
+	"mr-conflict-checker/severity"
 )
 
 // Version information - can be set at build time using ldflags
@@ -32,10 +44,31 @@ func main() {
 	var outputDir string
 	var showVersion bool
 	var showHelp bool
+	var opts runOptions
+
+	// Dispatch subcommands before parsing the global flags
+	if len(os.Args) > 1 {
+		var command func([]string) error
+		switch os.Args[1] {
+		case "diff":
+			command = runDiffCommand
+		case "ack":
+			command = runAckCommand
+		case "unack":
+			command = runUnackCommand
+		}
+		if command != nil {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s failed: %v\n", os.Args[1], err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
 	// Define flags with detailed descriptions
 	flag.StringVar(&configPath, "config", "/Users/panupong.j/Workspace/panupong-project/list-conflict-mr/config.yaml",
-		"Path to YAML configuration file containing GitLab credentials")
+		"Path to YAML configuration file containing GitLab, GitHub, Gitea or Bitbucket credentials")
 	flag.StringVar(&configPath, "c", "/Users/panupong.j/Workspace/panupong-project/list-conflict-mr/config.yaml",
 		"Path to YAML configuration file (shorthand)")
 
@@ -48,6 +81,14 @@ func main() {
 	flag.StringVar(&outputDir, "output", ".", "Directory where the markdown report will be generated")
 	flag.StringVar(&outputDir, "o", ".", "Output directory for reports (shorthand)")
 
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "Refuse every write to GitLab, GitHub, Gitea or Bitbucket and list the actions that would have been taken")
+	flag.StringVar(&opts.RecordPath, "record", "", "Record every GitLab, GitHub, Gitea or Bitbucket response, with tokens scrubbed, to this cassette file")
+	flag.StringVar(&opts.ReplayPath, "replay", "", "Answer GitLab, GitHub, Gitea or Bitbucket requests from this cassette file instead of the network")
+	flag.BoolVar(&opts.NoCache, "no-cache", false, "Do not use the on-disk GitLab response cache")
+	flag.BoolVar(&opts.Refresh, "refresh", false, "Ignore cached GitLab responses and fetch everything again")
+	flag.BoolVar(&opts.Full, "full", false, "Scan every repository even when incremental scans are enabled")
+	flag.StringVar(&opts.FailOn, "fail-on", failOnNone, "Exit with status 2 when unacknowledged conflicts remain: none, any, new (since the previous report), breached (SLA) or a severity level (low, medium, high, critical)")
+
 	flag.BoolVar(&showVersion, "version", false, "Show version information and exit")
 	flag.BoolVar(&showHelp, "help", false, "Show detailed help information and usage examples")
 	flag.BoolVar(&showHelp, "h", false, "Show help information (shorthand)")
@@ -69,6 +110,15 @@ func main() {
 		return
 	}
 
+	if opts.RecordPath != "" && opts.ReplayPath != "" {
+		fmt.Fprintf(os.Stderr, "--record and --replay cannot be used together\n")
+		os.Exit(1)
+	}
+	if err := validateFailOn(opts.FailOn); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
 	// Set up structured logging
 	logLevel := slog.LevelInfo
 	if debug {
@@ -77,9 +127,10 @@ func main() {
 		logLevel = slog.LevelInfo
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// Scrub tokens and credential headers from everything that gets logged
+	logger := slog.New(redact.NewHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logLevel,
-	}))
+	})))
 	slog.SetDefault(logger)
 
 	// Create context with cancellation for graceful shutdown
@@ -97,7 +148,11 @@ func main() {
 	}()
 
 	// Run the main application
-	if err := run(ctx, configPath, outputDir); err != nil {
+	if err := run(ctx, configPath, outputDir, opts); err != nil {
+		if errors.Is(err, errConflictsFound) {
+			slog.Error("Conflicts found", "error", err)
+			os.Exit(2)
+		}
 		slog.Error("Application failed", "error", err)
 		os.Exit(1)
 	}
@@ -105,7 +160,18 @@ func main() {
 	slog.Info("Application completed successfully")
 }
 
-func run(ctx context.Context, configPath, outputDir string) error {
+// runOptions holds the command line switches that change how a scan talks to GitLab, GitHub, Gitea or Bitbucket
+type runOptions struct {
+	DryRun     bool   // Refuse writes and report them instead
+	RecordPath string // Cassette file to record responses to
+	ReplayPath string // Cassette file to answer requests from
+	NoCache    bool   // Bypass the on-disk response cache
+	Refresh    bool   // Refetch every cached response
+	Full       bool   // Run a full scan even when incremental scans are enabled
+	FailOn     string // Which unacknowledged conflicts make the process exit with status 2
+}
+
+func run(ctx context.Context, configPath, outputDir string, opts runOptions) error {
 	slog.Info("MR Conflict Checker starting", "config", configPath, "output", outputDir)
 
 	// 1. Load configuration
@@ -114,7 +180,22 @@ func run(ctx context.Context, configPath, outputDir string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
-	slog.Info("Configuration loaded successfully", "gitlab_url", cfg.GitLab.URL)
+	redact.AddSecret(cfg.GitLab.Token)
+	redact.AddSecret(cfg.GitHub.AccessToken())
+	redact.AddSecret(cfg.Gitea.AccessToken())
+	redact.AddSecret(cfg.Bitbucket.AccessToken())
+	for _, secret := range cfg.Notify.Secrets() {
+		redact.AddSecret(secret)
+	}
+	if names := cfg.ProviderNames(); len(names) == 1 && names[0] == config.ProviderGitLab {
+		slog.Info("Configuration loaded successfully", "gitlab_url", cfg.GitLab.URL)
+	} else {
+		slog.Info("Configuration loaded successfully", "providers", strings.Join(names, ","))
+	}
+
+	if failOnSeverity(opts.FailOn) && !cfg.Severity.Enabled {
+		return fmt.Errorf("--fail-on %s requires severity.enabled", opts.FailOn)
+	}
 
 	// Use config output directory if command line output is default and config has output directory
 	if outputDir == "." && cfg.Output.Directory != "" {
@@ -122,72 +203,232 @@ func run(ctx context.Context, configPath, outputDir string) error {
 		slog.Info("Using output directory from config", "output_dir", outputDir)
 	}
 
-	// 2. Initialize GitLab client
-	slog.Debug("Initializing GitLab client")
-	client := gitlab.NewClient(cfg.GitLab.URL, cfg.GitLab.Token)
-	defer func() {
-		slog.Debug("Cleaning up GitLab client")
-		client.Close()
-	}()
-
-	// Test connection
-	slog.Debug("Testing GitLab connection")
-	if err := client.TestConnection(ctx); err != nil {
-		return fmt.Errorf("failed to connect to GitLab: %w", err)
+	// 2. Connect to the code hosting service
+	if cfg.Actions.DryRun {
+		slog.Warn("actions.dry_run is deprecated, use read_only instead")
+	}
+	dryRun := opts.DryRun || cfg.ReadOnly
+	host, tokenInfo, closeHost, err := connectProvider(ctx, cfg, opts, dryRun)
+	defer closeHost()
+	if err != nil {
+		return err
 	}
-	slog.Info("GitLab connection established successfully")
-
-	// 3. Scan repositories
-	slog.Info("Starting repository scan")
-	repositoryScanner := scanner.NewRepositoryScanner(client, cfg.GitLab.IncludeGroups)
 
-	repositories, err := repositoryScanner.ScanRepositories(ctx)
+	// 3. Scan repositories and analyze merge requests, incrementally when the last scan allows it
+	scanStarted := time.Now().UTC()
+	statePath := stateFilePath(cfg, outputDir)
+	previous, since := incrementalBase(cfg, statePath, opts.Full)
+
+	var analyzedRepos []models.Repository
+	var conflictingMRs map[int][]models.MergeRequest
+	if previous != nil {
+		analyzedRepos, conflictingMRs, err = scanIncremental(ctx, cfg, host, previous, since)
+	} else {
+		analyzedRepos, conflictingMRs, err = scanFull(ctx, cfg, host)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to scan repositories: %w", err)
+		return err
 	}
-	slog.Info("Repository scan completed", "total_repositories", len(repositories))
 
 	// Check for context cancellation
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
-	// 4. Analyze merge requests
-	slog.Info("Starting merge request analysis")
-	analyzedRepos, err := analyzer.AnalyzeMRs(ctx, client, repositories)
-	if err != nil {
-		return fmt.Errorf("failed to analyze merge requests: %w", err)
+	// 4. Generate report
+	slog.Info("Generating report")
+	report := buildReport(analyzedRepos, conflictingMRs)
+	reporter.FilterReport(report, cfg.Output.Filter)
+	if report.FilteredMRs > 0 {
+		slog.Info("Filtered conflicting merge requests out of the report", "filtered_mrs", report.FilteredMRs)
+	}
+	if tokenInfo != nil {
+		report.TokenExpiresAt = tokenInfo.ExpiresAt
+	}
+	if previous != nil {
+		report.IncrementalSince = since.Format(time.RFC3339)
 	}
 
-	// Get conflicting MRs for report generation
-	conflictingMRs, err := analyzer.GetConflictingMRs(ctx, client, analyzedRepos)
-	if err != nil {
-		return fmt.Errorf("failed to get conflicting merge requests: %w", err)
+	// Compare with the previous report before writing the new one
+	report.Changes = compareWithPreviousReport(report, outputDir)
+
+	// Mark the conflicts acknowledged with the ack subcommand
+	ackPath := acknowledgementsFilePath(cfg, outputDir)
+	if acks, err := ack.Load(ackPath); err != nil {
+		slog.Warn("Failed to load acknowledgements, no conflict is acknowledged", "acknowledgements_file", ackPath, "error", err)
+	} else {
+		acks.Mark(report, time.Now().UTC())
+		if report.TotalAcknowledgedMRs > 0 {
+			slog.Info("Conflicting merge requests acknowledged", "acknowledged_mrs", report.TotalAcknowledgedMRs)
+		}
 	}
 
-	// Check for context cancellation
-	if ctx.Err() != nil {
-		return ctx.Err()
+	// Score the conflicts and check them against the SLAs of their namespaces
+	if cfg.Severity.Enabled {
+		severity.NewScorer(host, cfg.Severity).Run(ctx, report, time.Now().UTC())
+		if report.SLABreaches > 0 {
+			slog.Warn("Conflicting merge requests breached their SLA", "sla_breaches", report.SLABreaches)
+		}
 	}
 
-	// 5. Generate report
-	slog.Info("Generating report")
-	report := buildReport(analyzedRepos, conflictingMRs)
+	// 5. Act on conflicting merge requests
+	runActions(ctx, cfg, host, report)
+
+	// List the writes the read-only client refused
+	if dryRun {
+		report.DryRun = true
+		report.SkippedWrites = host.SkippedWrites()
+	}
 
 	reportPath, err := reporter.GenerateReport(report, outputDir)
 	if err != nil {
 		return fmt.Errorf("failed to generate report: %w", err)
 	}
 
+	jsonReportPath := reporter.JSONReportPath(reportPath)
+	if err := reporter.WriteJSONReport(report, jsonReportPath); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	// Remember this scan so that the next one can be incremental
+	if cfg.Incremental.Enabled {
+		scanState := state.State{LastSuccessfulScan: scanStarted, Report: jsonReportPath}
+		if err := scanState.Save(statePath); err != nil {
+			slog.Warn("Failed to save scan state, the next scan will be a full scan", "state_file", statePath, "error", err)
+		}
+	}
+
+	// 6. Send notifications about the conflicts nobody acknowledged
+	unacknowledged := report.Unacknowledged()
+	sendNotifications(ctx, cfg, unacknowledged)
+
 	// Log summary statistics
 	totalRepos, reposWithConflicts, totalConflicts := report.GetSummaryStats()
 	slog.Info("Report generated successfully",
 		"report_path", reportPath,
 		"total_repositories", totalRepos,
 		"repositories_with_conflicts", reposWithConflicts,
-		"total_conflicting_mrs", totalConflicts)
+		"total_conflicting_mrs", totalConflicts,
+		"new_conflicts", len(report.Changes.New),
+		"resolved_conflicts", len(report.Changes.Resolved))
+
+	return checkFailOn(unacknowledged, opts.FailOn)
+}
 
-	return nil
+// scanFull scans every repository and analyzes all of their merge requests
+func scanFull(ctx context.Context, cfg *config.Config, host provider.Provider) ([]models.Repository, map[int][]models.MergeRequest, error) {
+	slog.Info("Starting repository scan", "provider", host.Name())
+	repositoryScanner := scanner.NewRepositoryScanner(host, includeGroups(cfg))
+
+	repositories, err := repositoryScanner.ScanRepositories(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan repositories: %w", err)
+	}
+	slog.Info("Repository scan completed", "total_repositories", len(repositories))
+
+	// Check for context cancellation
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	ignore, err := analyzer.NewIgnoreRules(cfg.Ignore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("Starting merge request analysis")
+	analyzedRepos, err := analyzer.AnalyzeMRs(ctx, host, repositories, ignore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze merge requests: %w", err)
+	}
+
+	// Get conflicting MRs for report generation
+	conflictingMRs, err := analyzer.GetConflictingMRs(ctx, host, analyzedRepos, ignore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get conflicting merge requests: %w", err)
+	}
+
+	return analyzedRepos, conflictingMRs, nil
+}
+
+// scanIncremental rescans the repositories with activity since the last successful scan and merges
+// the merge requests updated since then into its report
+func scanIncremental(ctx context.Context, cfg *config.Config, host provider.Provider, previous *models.Report, since time.Time) ([]models.Repository, map[int][]models.MergeRequest, error) {
+	slog.Info("Starting incremental repository scan", "provider", host.Name(), "since", since.Format(time.RFC3339))
+	repositoryScanner := scanner.NewRepositoryScanner(host, includeGroups(cfg))
+
+	active, err := repositoryScanner.ScanActiveRepositories(ctx, since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan repositories: %w", err)
+	}
+	slog.Info("Repository scan completed", "active_repositories", len(active), "previous_repositories", len(previous.Repositories))
+
+	ignore, err := analyzer.NewIgnoreRules(cfg.Ignore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	analyzedRepos, conflictingMRs, err := analyzer.AnalyzeIncremental(ctx, host, previous, active, since, ignore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze merge requests: %w", err)
+	}
+
+	return analyzedRepos, conflictingMRs, nil
+}
+
+// includeGroups returns the GitLab groups to scan; the other providers are limited by organization
+// or project instead, and their repositories are never filtered by group
+func includeGroups(cfg *config.Config) []int {
+	if !cfg.Scans(config.ProviderGitLab) {
+		return nil
+	}
+	return cfg.GitLab.IncludeGroups
+}
+
+// stateFilePath returns where the state of the last successful scan is kept
+func stateFilePath(cfg *config.Config, outputDir string) string {
+	if cfg.Incremental.StateFile != "" {
+		return cfg.Incremental.StateFile
+	}
+	return filepath.Join(outputDir, state.DefaultFileName)
+}
+
+// acknowledgementsFilePath returns where the acknowledged conflicts are kept
+func acknowledgementsFilePath(cfg *config.Config, outputDir string) string {
+	if cfg.Acknowledgements.File != "" {
+		return cfg.Acknowledgements.File
+	}
+	return filepath.Join(outputDir, ack.DefaultFileName)
+}
+
+// incrementalBase returns the report and start time of the last successful scan when this scan can
+// be incremental, or a nil report when it has to be a full scan
+func incrementalBase(cfg *config.Config, statePath string, full bool) (*models.Report, time.Time) {
+	if !cfg.Incremental.Enabled {
+		return nil, time.Time{}
+	}
+	if full {
+		slog.Info("Full scan requested")
+		return nil, time.Time{}
+	}
+
+	saved, err := state.Load(statePath)
+	if err != nil {
+		slog.Warn("Failed to load scan state, running a full scan", "error", err)
+		return nil, time.Time{}
+	}
+	if saved == nil {
+		slog.Info("No previous scan state, running a full scan", "state_file", statePath)
+		return nil, time.Time{}
+	}
+
+	previous, err := reporter.LoadJSONReport(saved.Report)
+	if err != nil {
+		slog.Warn("Failed to load the report of the last scan, running a full scan", "report", saved.Report, "error", err)
+		return nil, time.Time{}
+	}
+
+	return previous, saved.LastSuccessfulScan
 }
 
 // printVersion displays version information
@@ -201,7 +442,7 @@ func printVersion() {
 // printUsage displays basic usage information
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS]\n\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "MR Conflict Checker - Automatically detect conflicting merge requests across GitLab repositories\n\n")
+	fmt.Fprintf(os.Stderr, "MR Conflict Checker - Automatically detect conflicting merge requests across GitLab, GitHub, Gitea and Bitbucket repositories\n\n")
 	fmt.Fprintf(os.Stderr, "OPTIONS:\n")
 	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, "\nFor detailed help and examples, use: %s --help\n", os.Args[0])
@@ -213,12 +454,15 @@ func printDetailedHelp() {
 	fmt.Printf("=====================================\n\n")
 
 	fmt.Printf("DESCRIPTION:\n")
-	fmt.Printf("  Automatically scans GitLab repositories for conflicting merge requests\n")
+	fmt.Printf("  Automatically scans GitLab, GitHub, Gitea or Bitbucket repositories for conflicting merge requests\n")
 	fmt.Printf("  from 'release' branch to 'master' branch and generates a detailed\n")
 	fmt.Printf("  markdown report for tracking and resolution.\n\n")
 
 	fmt.Printf("USAGE:\n")
-	fmt.Printf("  %s [OPTIONS]\n\n", os.Args[0])
+	fmt.Printf("  %s [OPTIONS]\n", os.Args[0])
+	fmt.Printf("  %s diff [PREVIOUS.json [CURRENT.json]]\n", os.Args[0])
+	fmt.Printf("  %s ack [--config FILE] --project PATH --mr IID --reason TEXT --until DATE\n", os.Args[0])
+	fmt.Printf("  %s unack [--config FILE] --project PATH --mr IID\n\n", os.Args[0])
 
 	fmt.Printf("OPTIONS:\n")
 	flag.PrintDefaults()
@@ -236,6 +480,24 @@ func printDetailedHelp() {
 	fmt.Printf("  # Enable debug logging for troubleshooting\n")
 	fmt.Printf("  %s --debug --config ./config.yaml\n\n", os.Args[0])
 
+	fmt.Printf("  # Preview comments, labels and other actions without changing GitLab\n")
+	fmt.Printf("  %s --dry-run --config ./config.yaml\n\n", os.Args[0])
+
+	fmt.Printf("  # Capture GitLab responses once, then reproduce the scan offline\n")
+	fmt.Printf("  %s --record ./scan-cassette.json\n", os.Args[0])
+	fmt.Printf("  %s --replay ./scan-cassette.json\n\n", os.Args[0])
+
+	fmt.Printf("  # Fetch everything again instead of reusing cached GitLab responses\n")
+	fmt.Printf("  %s --refresh\n\n", os.Args[0])
+
+	fmt.Printf("  # Rescan everything when incremental scans are enabled\n")
+	fmt.Printf("  %s --full\n\n", os.Args[0])
+
+	fmt.Printf("  # Fail a CI job on new conflicts, or on SLA breaches, nobody acknowledged\n")
+	fmt.Printf("  %s --fail-on new\n", os.Args[0])
+	fmt.Printf("  %s --fail-on breached\n", os.Args[0])
+	fmt.Printf("  %s ack --config config.yaml --project group/app --mr 12 --reason \"after the freeze\" --until 2024-04-01\n\n", os.Args[0])
+
 	fmt.Printf("  # Using short flags\n")
 	fmt.Printf("  %s -c ./config.yaml -v -o ./reports\n\n", os.Args[0])
 
@@ -250,15 +512,268 @@ func printDetailedHelp() {
 	fmt.Printf("  containing:\n")
 	fmt.Printf("  - Summary statistics of scanned repositories\n")
 	fmt.Printf("  - List of repositories with conflicting merge requests\n")
-	fmt.Printf("  - Direct links to each conflicting MR for easy access\n\n")
+	fmt.Printf("  - Direct links to each conflicting MR for easy access\n")
+	fmt.Printf("  - Changes since the previous report (new, resolved, still conflicting)\n")
+	fmt.Printf("  A JSON copy of each report is written alongside it for later comparisons.\n\n")
 
 	fmt.Printf("EXIT CODES:\n")
 	fmt.Printf("  0  Success\n")
-	fmt.Printf("  1  Error occurred during execution\n\n")
+	fmt.Printf("  1  Error occurred during execution\n")
+	fmt.Printf("  2  Unacknowledged conflicts matched --fail-on\n\n")
 
 	fmt.Printf("For more information, visit: https://github.com/your-org/mr-conflict-checker\n")
 }
 
+// Values of --fail-on, besides the severity levels failing on conflicts of at least that severity
+const (
+	failOnNone     = "none"     // Never fail because of conflicts
+	failOnAny      = "any"      // Fail while any conflict is left
+	failOnNew      = "new"      // Fail when conflicts appeared since the previous report
+	failOnBreached = "breached" // Fail when conflicts breached their SLA
+)
+
+// errConflictsFound is returned by run when conflicts matched --fail-on
+var errConflictsFound = errors.New("conflicting merge requests found")
+
+// validateFailOn checks the value of --fail-on
+func validateFailOn(failOn string) error {
+	switch {
+	case failOn == failOnNone, failOn == failOnAny, failOn == failOnNew, failOnSeverity(failOn):
+		return nil
+	default:
+		return fmt.Errorf("--fail-on must be %s, %s, %s, %s or one of %s, got %q",
+			failOnNone, failOnAny, failOnNew, failOnBreached, strings.Join(models.SeverityLevels, ", "), failOn)
+	}
+}
+
+// failOnSeverity reports whether --fail-on keys off the severity scores
+func failOnSeverity(failOn string) bool {
+	return failOn == failOnBreached || models.SeverityRank(failOn) >= 0
+}
+
+// checkFailOn returns errConflictsFound when the report, without its acknowledged conflicts, matches --fail-on
+func checkFailOn(report *models.Report, failOn string) error {
+	var count int
+	switch failOn {
+	case failOnAny:
+		count = report.TotalConflictingMRs
+	case failOnNew:
+		if report.Changes != nil {
+			count = len(report.Changes.New)
+		}
+	case failOnBreached:
+		count = report.SLABreaches
+	default:
+		if models.SeverityRank(failOn) >= 0 {
+			count = report.CountAtLeast(failOn)
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d unacknowledged conflicting merge requests match --fail-on %s", errConflictsFound, count, failOn)
+}
+
+// sendNotifications delivers the report to the configured notifiers; failures are logged, not fatal
+func sendNotifications(ctx context.Context, cfg *config.Config, report *models.Report) {
+	notifiers, err := notifier.New(cfg.Notify)
+	if err != nil {
+		slog.Warn("Failed to set up notifiers", "error", err)
+		return
+	}
+
+	for _, n := range notifiers {
+		slog.Info("Sending notification", "notifier", n.Name())
+		if err := n.Notify(ctx, report, report.Changes); err != nil {
+			slog.Warn("Failed to send notification", "notifier", n.Name(), "error", err)
+		}
+	}
+}
+
+// writeActionsEnabled reports whether any action that changes merge requests is enabled
+func writeActionsEnabled(cfg *config.Config) bool {
+	return cfg.Actions.Comment.Enabled || cfg.Actions.Label.Enabled || cfg.Actions.BackMerge.Enabled || cfg.Actions.Rebase.Enabled
+}
+
+// newAuthenticator creates the authentication strategy selected by gitlab.auth.mode
+func newAuthenticator(ctx context.Context, cfg *config.Config, httpClient *http.Client, readOnly bool) (gitlab.Authenticator, error) {
+	switch cfg.GitLab.Auth.Mode {
+	case config.AuthModeJobToken:
+		slog.Info("Authenticating with the CI job token")
+		return gitlab.NewJobTokenAuth(cfg.GitLab.JobToken()), nil
+	case config.AuthModeOAuth:
+		return newOAuthAuthenticator(ctx, cfg, httpClient, readOnly)
+	default:
+		return gitlab.NewTokenAuth(cfg.GitLab.Token), nil
+	}
+}
+
+// newCache creates the response cache enabled by gitlab.cache, or returns nil when the cache is
+// off; cassettes need to see every request, so the cache is also off while recording or replaying
+func newCache(cacheCfg config.CacheConfig, opts runOptions) (*gitlab.Cache, error) {
+	if !cacheCfg.Enabled || opts.NoCache {
+		return nil, nil
+	}
+	if opts.RecordPath != "" || opts.ReplayPath != "" {
+		slog.Info("Response cache disabled while recording or replaying")
+		return nil, nil
+	}
+
+	dir := cacheCfg.Dir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate the cache directory, set gitlab.cache.dir: %w", err)
+		}
+		dir = filepath.Join(cacheDir, "mr-conflict-checker")
+	}
+
+	ttls := gitlab.DefaultCacheTTLs
+	if cacheCfg.TTL.Projects != nil {
+		ttls.Projects = *cacheCfg.TTL.Projects
+	}
+	if cacheCfg.TTL.MergeRequests != nil {
+		ttls.MergeRequests = *cacheCfg.TTL.MergeRequests
+	}
+	if cacheCfg.TTL.Changes != nil {
+		ttls.Changes = *cacheCfg.TTL.Changes
+	}
+	if cacheCfg.TTL.Other != nil {
+		ttls.Other = *cacheCfg.TTL.Other
+	}
+
+	cache, err := gitlab.NewCache(gitlab.CacheOptions{Dir: dir, TTLs: ttls, Refresh: opts.Refresh})
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("Using GitLab response cache", "dir", dir, "refresh", opts.Refresh)
+	return cache, nil
+}
+
+// newOAuthAuthenticator reuses the saved OAuth token, or runs the device flow when there is none
+func newOAuthAuthenticator(ctx context.Context, cfg *config.Config, httpClient *http.Client, readOnly bool) (gitlab.Authenticator, error) {
+	oauthCfg := cfg.GitLab.Auth.OAuth
+
+	tokenFile := oauthCfg.TokenFile
+	if tokenFile == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate the OAuth token file, set gitlab.auth.oauth.token_file: %w", err)
+		}
+		tokenFile = filepath.Join(configDir, "mr-conflict-checker", "oauth-token.json")
+	}
+
+	scopes := oauthCfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{gitlab.ScopeReadAPI}
+		if writeActionsEnabled(cfg) && !readOnly {
+			scopes = []string{gitlab.ScopeAPI}
+		}
+	}
+
+	token, err := gitlab.LoadOAuth2Token(tokenFile)
+	if err != nil {
+		slog.Info("No saved OAuth token, starting device authorization", "token_file", tokenFile)
+		token, err = gitlab.DeviceAuthorization(ctx, httpClient, cfg.GitLab.URL, oauthCfg.ClientID, scopes, func(code gitlab.DeviceCode) {
+			fmt.Fprintf(os.Stderr, "To authorize MR Conflict Checker, open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := gitlab.SaveOAuth2Token(tokenFile, token); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the renewed token for the next run
+	saveToken := func(token *gitlab.OAuth2Token) error {
+		return gitlab.SaveOAuth2Token(tokenFile, token)
+	}
+	return gitlab.NewOAuth2Auth(cfg.GitLab.URL, oauthCfg.ClientID, token, httpClient, saveToken), nil
+}
+
+// verifyToken checks that the token is active and has the scopes the enabled features need, and warns
+// when it expires soon; only GitLab versions before 15.5, which cannot describe the token, skip the checks
+func verifyToken(ctx context.Context, cfg *config.Config, client *gitlab.Client, dryRun bool) (*models.TokenInfo, error) {
+	info, err := client.GetTokenInfo(ctx)
+	if httpapi.IsNotFound(err) {
+		slog.Warn("GitLab does not describe its tokens, skipping the token scope and expiry checks", "error", err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify GitLab token: %w", err)
+	}
+
+	if err := gitlab.CheckTokenActive(info); err != nil {
+		return nil, err
+	}
+
+	required := []gitlab.ScopeRequirement{{Scope: gitlab.ScopeReadAPI, Feature: "scanning repositories"}}
+	if writeActionsEnabled(cfg) && !dryRun {
+		required = append(required, gitlab.ScopeRequirement{Scope: gitlab.ScopeAPI, Feature: "write actions"})
+	}
+	if missing := gitlab.MissingScopes(info, required); len(missing) > 0 {
+		descriptions := make([]string, len(missing))
+		for i, requirement := range missing {
+			descriptions[i] = requirement.String()
+		}
+		return nil, fmt.Errorf("GitLab token %q is missing required scopes: %s (granted: %s)",
+			info.Name, strings.Join(descriptions, ", "), strings.Join(info.Scopes, ", "))
+	}
+
+	window := cfg.GitLab.TokenExpiryWarning
+	if window == 0 {
+		window = config.DefaultTokenExpiryWarning
+	}
+	if info.ExpiresWithin(window, time.Now()) {
+		slog.Warn("GitLab token expires soon, rotate it to keep scans running", "token_name", info.Name, "expires_at", info.ExpiresAt)
+	}
+
+	return info, nil
+}
+
+// runActions performs the opt-in actions on conflicting merge requests; failures are logged, not fatal
+func runActions(ctx context.Context, cfg *config.Config, host provider.Provider, report *models.Report) {
+	if cfg.Actions.Comment.Enabled {
+		slog.Info("Updating conflict comments on merge requests")
+		commenter := actions.NewCommenter(host, cfg.Actions.Comment)
+		if err := commenter.Run(ctx, report, report.Changes); err != nil {
+			slog.Warn("Failed to update conflict comments", "error", err)
+		}
+	}
+
+	// Labels, back-merges and rebases are GitLab only; config validation rejects them elsewhere
+	gitLab, ok := host.(*provider.GitLab)
+	if !ok {
+		return
+	}
+	client := gitLab.Client()
+
+	if cfg.Actions.Label.Enabled {
+		slog.Info("Updating conflict labels on merge requests")
+		labeler := actions.NewLabeler(client, cfg.Actions.Label)
+		if err := labeler.Run(ctx, report); err != nil {
+			slog.Warn("Failed to update conflict labels", "error", err)
+		}
+	}
+
+	if cfg.Actions.BackMerge.Enabled {
+		slog.Info("Opening back-merge MRs for repositories with conflicts")
+		backMerger := actions.NewBackMerger(client, cfg.Actions.BackMerge)
+		if err := backMerger.Run(ctx, report); err != nil {
+			slog.Warn("Failed to open back-merge MRs", "error", err)
+		}
+	}
+
+	if cfg.Actions.Rebase.Enabled {
+		slog.Info("Rebasing merge requests that only need a rebase")
+		rebaser := actions.NewRebaser(client, cfg.Actions.Rebase)
+		if err := rebaser.Run(ctx, report); err != nil {
+			slog.Warn("Failed to rebase merge requests", "error", err)
+		}
+	}
+}
+
 // buildReport constructs a Report from analyzed repositories and conflicting MRs
 func buildReport(repositories []models.Repository, conflictingMRs map[int][]models.MergeRequest) *models.Report {
 	report := &models.Report{
@@ -269,7 +784,7 @@ func buildReport(repositories []models.Repository, conflictingMRs map[int][]mode
 	for _, repo := range repositories {
 		var errorMsg string
 		if repo.Error != nil {
-			errorMsg = repo.Error.Error()
+			errorMsg = redact.String(repo.Error.Error())
 		}
 
 		// Get conflicting MRs for this repository
@@ -0,0 +1,372 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
+)
+
+// defaultEmailSubject is used when no subject is configured
+const defaultEmailSubject = "MR Conflict Report - {{timestamp}}"
+
+// emailHTMLTemplate renders the HTML part of the digest
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("email").Funcs(htmltemplate.FuncMap{
+	"age": formatAge,
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>MR Conflict Report - {{ .Timestamp }}</h2>
+<table cellpadding="4" style="border-collapse: collapse;">
+<tr><td>Repositories Scanned</td><td><strong>{{ .TotalRepositories }}</strong></td></tr>
+<tr><td>Repositories with Conflicts</td><td><strong>{{ .RepositoriesWithConflicts }}</strong></td></tr>
+<tr><td>Conflicting MRs</td><td><strong>{{ .TotalConflictingMRs }}</strong></td></tr>
+{{- if and .Changes .Changes.PreviousTimestamp }}
+<tr><td>New since previous report</td><td><strong>{{ len .Changes.New }}</strong></td></tr>
+<tr><td>Resolved since previous report</td><td><strong>{{ len .Changes.Resolved }}</strong></td></tr>
+{{- end }}
+</table>
+{{- if .Oldest }}
+<h3>Oldest conflicting MRs</h3>
+<ul>
+{{- range .Oldest }}
+<li><a href="{{ .MergeRequest.WebURL }}">{{ .MergeRequest.Title }}</a> in <a href="{{ .Repository.WebURL }}">{{ .Repository.Name }}</a> - {{ .MergeRequest.Author.Name }} - open for {{ age .Age }}</li>
+{{- end }}
+</ul>
+{{- else }}
+<p>No conflicting merge requests found.</p>
+{{- end }}
+{{- if .Namespaces }}
+<h3>Conflicts by namespace</h3>
+<ul>
+{{- range .Namespaces }}
+<li><code>{{ .Namespace }}</code>: {{ .ConflictingMRs }} MRs in {{ .Repositories }} repositories</li>
+{{- end }}
+</ul>
+{{- end }}
+</body>
+</html>
+`))
+
+// EmailNotifier sends a multipart (HTML and plain text) digest over SMTP
+type EmailNotifier struct {
+	cfg       config.EmailConfig
+	tlsConfig *tls.Config
+	now       func() time.Time
+}
+
+// NewEmailNotifier creates an email notifier from its configuration
+func NewEmailNotifier(cfg config.EmailConfig) *EmailNotifier {
+	if cfg.Security == "" {
+		cfg.Security = config.EmailSecurityStartTLS
+	}
+	if cfg.Port == 0 {
+		switch cfg.Security {
+		case config.EmailSecuritySMTPS:
+			cfg.Port = 465
+		case config.EmailSecurityStartTLS:
+			cfg.Port = 587
+		default:
+			cfg.Port = 25
+		}
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = defaultEmailSubject
+	}
+	if cfg.TopN == 0 {
+		cfg.TopN = defaultTopN
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+
+	return &EmailNotifier{
+		cfg:       cfg,
+		tlsConfig: &tls.Config{ServerName: cfg.Host},
+		now:       time.Now,
+	}
+}
+
+// Name identifies the notifier
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends each recipient a digest limited to their namespaces and authors
+func (n *EmailNotifier) Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	var errs []string
+
+	for _, recipient := range n.cfg.Recipients {
+		recipientReport, recipientDiff := report, reportDiff
+		if len(recipient.Namespaces) > 0 {
+			recipientReport, recipientDiff = filterReport(recipientReport, recipientDiff, func(repo models.Repository) bool {
+				return matchesRepository(nil, recipient.Namespaces, repo)
+			})
+		}
+		if len(recipient.Authors) > 0 {
+			recipientReport, recipientDiff = filterByAuthor(recipientReport, recipientDiff, recipient.Authors)
+		}
+
+		if recipientReport.TotalConflictingMRs == 0 && !recipientDiff.HasChanges() && !n.cfg.SendEmpty {
+			slog.Debug("Skipping email digest, no conflicts for recipient", "recipient", recipient.Address)
+			continue
+		}
+
+		message, err := n.buildMessage(recipient.Address, buildSummary(recipientReport, recipientDiff, n.cfg.TopN))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", recipient.Address, err))
+			continue
+		}
+
+		if err := n.send(ctx, recipient.Address, message); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", recipient.Address, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("email notification failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// buildMessage renders the complete MIME message for a recipient
+func (n *EmailNotifier) buildMessage(to string, s summary) ([]byte, error) {
+	var htmlBody bytes.Buffer
+	if err := emailHTMLTemplate.Execute(&htmlBody, s); err != nil {
+		return nil, fmt.Errorf("failed to render HTML body: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", buildEmailText(s)},
+		{"text/html; charset=UTF-8", htmlBody.String()},
+	}
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MIME part: %w", err)
+		}
+		// Scrub before encoding, quoted-printable line breaks could split a token
+		qp := quotedprintable.NewWriter(partWriter)
+		if _, err := qp.Write([]byte(redact.Content(part.content))); err != nil {
+			return nil, fmt.Errorf("failed to encode MIME part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode MIME part: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish MIME message: %w", err)
+	}
+
+	subject := redact.Content(strings.ReplaceAll(n.cfg.Subject, "{{timestamp}}", s.Timestamp))
+	messageID, err := n.messageID()
+	if err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	headers := []struct{ name, value string }{
+		{"From", n.cfg.From},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("UTF-8", subject)},
+		{"Date", n.now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", writer.Boundary())},
+	}
+	for _, header := range headers {
+		message.WriteString(header.name + ": " + header.value + "\r\n")
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// messageID generates a unique Message-ID header value
+func (n *EmailNotifier) messageID() (string, error) {
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate Message-ID: %w", err)
+	}
+
+	domain := "mr-conflict-checker"
+	if address, err := mail.ParseAddress(n.cfg.From); err == nil {
+		if at := strings.LastIndex(address.Address, "@"); at >= 0 {
+			domain = address.Address[at+1:]
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain), nil
+}
+
+// send delivers a message to one recipient, retrying on network errors and transient (4xx) SMTP replies
+func (n *EmailNotifier) send(ctx context.Context, to string, message []byte) error {
+	return retry(ctx, n.cfg.Retries, n.cfg.RetryBackoff, func() (time.Duration, error) {
+		err := n.sendOnce(ctx, to, message)
+		if err != nil && transientSMTPError(err) {
+			return 0, &retryableError{err: err}
+		}
+		return 0, err
+	}, "recipient", to)
+}
+
+// transientSMTPError reports whether a delivery failure is worth another attempt: network errors,
+// dropped connections and 4xx replies are, permanent 5xx replies and TLS failures are not. Nothing
+// is once the server accepted DATA, since the message may already have been delivered
+func transientSMTPError(err error) bool {
+	var sent *sentError
+	if errors.As(err, &sent) {
+		return false
+	}
+	var replyErr *textproto.Error
+	if errors.As(err, &replyErr) {
+		return replyErr.Code >= 400 && replyErr.Code < 500
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.EOF)
+}
+
+// sentError marks failures after the server accepted DATA, which are never retried so that
+// recipients do not get the digest twice
+type sentError struct {
+	err error
+}
+
+func (e *sentError) Error() string { return e.err.Error() }
+func (e *sentError) Unwrap() error { return e.err }
+
+// sendOnce delivers a message to one recipient over SMTP
+func (n *EmailNotifier) sendOnce(ctx context.Context, to string, message []byte) error {
+	address := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	dialer := &net.Dialer{Timeout: n.cfg.Timeout}
+
+	var conn net.Conn
+	var err error
+	if n.cfg.Security == config.EmailSecuritySMTPS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: n.tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(n.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if n.cfg.Security == config.EmailSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", address)
+		}
+		if err := client.StartTLS(n.tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	from := n.cfg.From
+	if address, err := mail.ParseAddress(from); err == nil {
+		from = address.Address
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("RCPT TO rejected: %w", err)
+	}
+
+	data, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := data.Write(message); err != nil {
+		return &sentError{err: fmt.Errorf("failed to write message: %w", err)}
+	}
+	if err := data.Close(); err != nil {
+		return &sentError{err: fmt.Errorf("message rejected: %w", err)}
+	}
+
+	// The message is accepted, a failed QUIT does not make it undelivered
+	if err := client.Quit(); err != nil {
+		slog.Debug("SMTP QUIT failed after delivery", "recipient", to, "error", err)
+	}
+	return nil
+}
+
+// buildEmailText renders the plain text part of the digest
+func buildEmailText(s summary) string {
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("MR Conflict Report - %s\n\n", s.Timestamp))
+	text.WriteString(fmt.Sprintf("Repositories Scanned:        %d\n", s.TotalRepositories))
+	text.WriteString(fmt.Sprintf("Repositories with Conflicts: %d\n", s.RepositoriesWithConflicts))
+	text.WriteString(fmt.Sprintf("Conflicting MRs:             %d\n", s.TotalConflictingMRs))
+	if s.Changes != nil && s.Changes.PreviousTimestamp != "" {
+		text.WriteString(fmt.Sprintf("New since previous report:   %d\n", len(s.Changes.New)))
+		text.WriteString(fmt.Sprintf("Resolved since previous:     %d\n", len(s.Changes.Resolved)))
+	}
+	text.WriteString("\n")
+
+	if len(s.Oldest) > 0 {
+		text.WriteString("Oldest conflicting MRs:\n")
+		for _, entry := range s.Oldest {
+			text.WriteString(fmt.Sprintf("- %s (%s) - %s - open for %s\n  %s\n",
+				entry.MergeRequest.Title, entry.Repository.Name, entry.MergeRequest.Author.Name,
+				formatAge(entry.Age), entry.MergeRequest.WebURL))
+		}
+		text.WriteString("\n")
+	} else {
+		text.WriteString("No conflicting merge requests found.\n\n")
+	}
+
+	if len(s.Namespaces) > 0 {
+		text.WriteString("Conflicts by namespace:\n")
+		for _, stat := range s.Namespaces {
+			text.WriteString(fmt.Sprintf("- %s: %d MRs in %d repositories\n", stat.Namespace, stat.ConflictingMRs, stat.Repositories))
+		}
+	}
+
+	return text.String()
+}
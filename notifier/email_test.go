@@ -0,0 +1,473 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+)
+
+// smtpMessage is a message accepted by the fake SMTP server
+type smtpMessage struct {
+	From string
+	To   []string
+	Auth string
+	TLS  bool
+	Data string
+}
+
+// fakeSMTPServer is a minimal in-process SMTP server for testing
+type fakeSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	rootCAs   *x509.CertPool
+
+	mu          sync.Mutex
+	messages    []smtpMessage
+	unavailable int  // Connections still to be turned away with 421
+	dropData    bool // Hang up after receiving a message instead of accepting it
+	connections int
+}
+
+// newFakeSMTPServer starts an SMTP server on a random local port, advertising STARTTLS if withTLS is set
+func newFakeSMTPServer(t *testing.T, withTLS bool) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	if withTLS {
+		// Borrow the self-signed certificate httptest generates for 127.0.0.1
+		httpServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+		httpServer.StartTLS()
+		t.Cleanup(httpServer.Close)
+
+		server.tlsConfig = &tls.Config{Certificates: httpServer.TLS.Certificates}
+		server.rootCAs = x509.NewCertPool()
+		server.rootCAs.AddCert(httpServer.Certificate())
+	}
+
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+// port returns the port the server listens on
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// received returns the messages accepted so far
+func (s *fakeSMTPServer) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+// turnAway makes the server reject the next n connections with a transient 421 reply
+func (s *fakeSMTPServer) turnAway(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unavailable = n
+}
+
+// dropAfterData makes the server hang up after receiving each message, before replying to it
+func (s *fakeSMTPServer) dropAfterData() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropData = true
+}
+
+// connectionCount returns the number of connections accepted so far
+func (s *fakeSMTPServer) connectionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	s.mu.Lock()
+	s.connections++
+	turnAway := s.unavailable > 0
+	if turnAway {
+		s.unavailable--
+	}
+	s.mu.Unlock()
+	if turnAway {
+		reply("421 fake.smtp Service not available, try again later")
+		return
+	}
+
+	var message smtpMessage
+	reply("220 fake.smtp ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			reply("250-fake.smtp")
+			if s.tlsConfig != nil && !message.TLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			message.TLS = true
+		case "AUTH":
+			fields := strings.Fields(line)
+			if len(fields) == 3 {
+				decoded, _ := base64.StdEncoding.DecodeString(fields[2])
+				message.Auth = string(decoded)
+			}
+			reply("235 Authentication successful")
+		case "MAIL":
+			message.From = strings.Trim(strings.TrimPrefix(line[len("MAIL "):], "FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			message.To = append(message.To, strings.Trim(strings.TrimPrefix(line[len("RCPT "):], "TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			message.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			drop := s.dropData
+			s.mu.Unlock()
+			if drop {
+				return
+			}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// parsedEmail holds the decoded parts of a multipart/alternative message
+type parsedEmail struct {
+	Header mail.Header
+	Text   string
+	HTML   string
+}
+
+// parseEmail decodes a message received by the fake SMTP server
+func parseEmail(t *testing.T, data string) parsedEmail {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parsed := parsedEmail{Header: msg.Header}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		assert.Equal(t, "quoted-printable", part.Header.Get("Content-Transfer-Encoding"))
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+
+		switch {
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain"):
+			parsed.Text = string(content)
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/html"):
+			parsed.HTML = string(content)
+		}
+	}
+	return parsed
+}
+
+func TestEmailNotifier_SendsMultipartDigest(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		Security:   config.EmailSecurityNone,
+		From:       "MR Checker <checker@example.com>",
+		Recipients: []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.NoError(t, err)
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "checker@example.com", messages[0].From)
+	assert.Equal(t, []string{"team@example.com"}, messages[0].To)
+	assert.Empty(t, messages[0].Auth)
+
+	email := parseEmail(t, messages[0].Data)
+	assert.Equal(t, "MR Conflict Report - 2024-01-10T00-00-00", email.Header.Get("Subject"))
+	assert.Equal(t, "team@example.com", email.Header.Get("To"))
+	assert.Contains(t, email.Header.Get("Message-ID"), "@example.com>")
+	assert.Equal(t, "1.0", email.Header.Get("MIME-Version"))
+
+	assert.Contains(t, email.Text, "Conflicting MRs:             3")
+	assert.Contains(t, email.Text, "Release 1.2 (api) - Alice - open for 9d")
+	assert.Contains(t, email.Text, "- backend: 2 MRs in 1 repositories")
+
+	assert.Contains(t, email.HTML, `<a href="https://gitlab.example.com/backend/api/-/merge_requests/1">Release 1.2</a>`)
+	// Titles are HTML-escaped
+	assert.Contains(t, email.HTML, "Release &lt;web&gt;")
+}
+
+func TestEmailNotifier_FiltersPerRecipient(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+
+	report := buildNotifyTestReport()
+	report.Repositories[0].ConflictingMRs[0].Author.Username = "alice"
+	report.Repositories[0].ConflictingMRs[1].Author.Username = "bob"
+	report.Repositories[1].ConflictingMRs[0].Author.Username = "carol"
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Security: config.EmailSecurityNone,
+		From:     "checker@example.com",
+		Subject:  "Conflicts {{timestamp}}",
+		Recipients: []config.EmailRecipient{
+			{Address: "frontend@example.com", Namespaces: []string{"frontend"}},
+			{Address: "bob@example.com", Authors: []string{"@bob"}},
+			// Nobody by this author conflicts, so no email is sent
+			{Address: "dave@example.com", Authors: []string{"dave"}},
+		},
+	})
+
+	err := notifier.Notify(context.Background(), report, nil)
+	require.NoError(t, err)
+
+	messages := server.received()
+	require.Len(t, messages, 2)
+
+	byRecipient := make(map[string]parsedEmail)
+	for _, message := range messages {
+		require.Len(t, message.To, 1)
+		byRecipient[message.To[0]] = parseEmail(t, message.Data)
+	}
+
+	frontend := byRecipient["frontend@example.com"]
+	assert.Equal(t, "Conflicts 2024-01-10T00-00-00", frontend.Header.Get("Subject"))
+	assert.Contains(t, frontend.Text, "Conflicting MRs:             1")
+	assert.Contains(t, frontend.Text, "Release <web>")
+	assert.NotContains(t, frontend.Text, "Release 1.2")
+
+	bob := byRecipient["bob@example.com"]
+	assert.Contains(t, bob.Text, "Conflicting MRs:             1")
+	assert.Contains(t, bob.Text, "Release 1.3")
+	assert.NotContains(t, bob.Text, "Release 1.2")
+	assert.NotContains(t, bob.Text, "Release <web>")
+}
+
+func TestEmailNotifier_SendEmpty(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		Security:   config.EmailSecurityNone,
+		From:       "checker@example.com",
+		SendEmpty:  true,
+		Recipients: []config.EmailRecipient{{Address: "nobody@example.com", Authors: []string{"nobody"}}},
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.NoError(t, err)
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Contains(t, parseEmail(t, messages[0].Data).Text, "No conflicting merge requests found.")
+}
+
+func TestEmailNotifier_StartTLSAndAuth(t *testing.T) {
+	server := newFakeSMTPServer(t, true)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		Username:   "checker",
+		Password:   "secret",
+		From:       "checker@example.com",
+		Recipients: []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+	notifier.tlsConfig.RootCAs = server.rootCAs
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.NoError(t, err)
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].TLS)
+	assert.Equal(t, "\x00checker\x00secret", messages[0].Auth)
+}
+
+func TestEmailNotifier_RequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		From:       "checker@example.com",
+		Recipients: []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support STARTTLS")
+	assert.Empty(t, server.received())
+}
+
+func TestEmailNotifier_RetriesTransientFailures(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	server.turnAway(2)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		NotifierSettings: config.NotifierSettings{Retries: 2, RetryBackoff: time.Millisecond},
+		Host:             "127.0.0.1",
+		Port:             server.port(),
+		Security:         config.EmailSecurityNone,
+		From:             "checker@example.com",
+		Recipients:       []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.NoError(t, err)
+	assert.Len(t, server.received(), 1)
+	assert.Equal(t, 3, server.connectionCount())
+}
+
+func TestEmailNotifier_GivesUpAfterRetries(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	server.turnAway(5)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		NotifierSettings: config.NotifierSettings{Retries: 1, RetryBackoff: time.Millisecond},
+		Host:             "127.0.0.1",
+		Port:             server.port(),
+		Security:         config.EmailSecurityNone,
+		From:             "checker@example.com",
+		Recipients:       []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 2 attempts")
+	assert.Empty(t, server.received())
+	assert.Equal(t, 2, server.connectionCount())
+}
+
+func TestEmailNotifier_DoesNotRetryPermanentFailures(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		NotifierSettings: config.NotifierSettings{Retries: 3, RetryBackoff: time.Millisecond},
+		Host:             "127.0.0.1",
+		Port:             server.port(),
+		From:             "checker@example.com",
+		Recipients:       []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support STARTTLS")
+	assert.Equal(t, 1, server.connectionCount())
+}
+
+func TestEmailNotifier_DoesNotRetryAfterData(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	server.dropAfterData()
+
+	notifier := NewEmailNotifier(config.EmailConfig{
+		NotifierSettings: config.NotifierSettings{Retries: 3, RetryBackoff: time.Millisecond},
+		Host:             "127.0.0.1",
+		Port:             server.port(),
+		Security:         config.EmailSecurityNone,
+		From:             "checker@example.com",
+		Recipients:       []config.EmailRecipient{{Address: "team@example.com"}},
+	})
+
+	// The message may have been delivered, so it is not sent again
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "message rejected")
+	assert.Len(t, server.received(), 1)
+	assert.Equal(t, 1, server.connectionCount())
+}
+
+func TestNewEmailNotifier_DefaultPorts(t *testing.T) {
+	tests := map[string]int{
+		"":                           587,
+		config.EmailSecurityStartTLS: 587,
+		config.EmailSecuritySMTPS:    465,
+		config.EmailSecurityNone:     25,
+	}
+
+	for security, expected := range tests {
+		notifier := NewEmailNotifier(config.EmailConfig{Host: "smtp.example.com", Security: security})
+		assert.Equal(t, expected, notifier.cfg.Port, "security %q", security)
+	}
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// MattermostNotifier posts scan summaries to Mattermost incoming webhooks
+type MattermostNotifier struct {
+	cfg    config.MattermostConfig
+	sender *sender
+}
+
+// NewMattermostNotifier creates a Mattermost notifier from its configuration
+func NewMattermostNotifier(cfg config.MattermostConfig) *MattermostNotifier {
+	if cfg.TopN == 0 {
+		cfg.TopN = defaultTopN
+	}
+	return &MattermostNotifier{
+		cfg:    cfg,
+		sender: newSender(cfg.NotifierSettings),
+	}
+}
+
+// Name identifies the notifier
+func (n *MattermostNotifier) Name() string {
+	return "mattermost"
+}
+
+// mattermostMessage is the payload accepted by Mattermost incoming webhooks
+type mattermostMessage struct {
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+	IconURL  string `json:"icon_url,omitempty"`
+	Text     string `json:"text"`
+}
+
+// Notify posts the report summary as a markdown message
+func (n *MattermostNotifier) Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	message := mattermostMessage{
+		Channel:  n.cfg.Channel,
+		Username: n.cfg.Username,
+		IconURL:  n.cfg.IconURL,
+		Text:     buildMattermostText(buildSummary(report, reportDiff, n.cfg.TopN)),
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode mattermost message: %w", err)
+	}
+
+	if err := n.sender.send(ctx, http.MethodPost, n.cfg.WebhookURL, "application/json", nil, payload); err != nil {
+		return fmt.Errorf("mattermost notification failed: %w", err)
+	}
+	return nil
+}
+
+// buildMattermostText renders a summary as Mattermost markdown
+func buildMattermostText(s summary) string {
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("#### MR Conflict Report - %s\n\n", s.Timestamp))
+	text.WriteString("| Repositories Scanned | Repositories with Conflicts | Conflicting MRs |\n")
+	text.WriteString("|---|---|---|\n")
+	text.WriteString(fmt.Sprintf("| %d | %d | %d |\n\n", s.TotalRepositories, s.RepositoriesWithConflicts, s.TotalConflictingMRs))
+
+	if s.Changes != nil && s.Changes.PreviousTimestamp != "" {
+		text.WriteString(fmt.Sprintf("Since previous report: :new: %d new, :white_check_mark: %d resolved\n\n",
+			len(s.Changes.New), len(s.Changes.Resolved)))
+	}
+
+	// Oldest conflicting merge requests
+	if len(s.Oldest) > 0 {
+		text.WriteString(fmt.Sprintf("**Oldest conflicting MRs (top %d)**\n", len(s.Oldest)))
+		for _, entry := range s.Oldest {
+			text.WriteString(fmt.Sprintf("- [%s](%s) in [%s](%s) - %s - open for %s\n",
+				entry.MergeRequest.Title, entry.MergeRequest.WebURL,
+				entry.Repository.Name, entry.Repository.WebURL,
+				entry.MergeRequest.Author.Name, formatAge(entry.Age)))
+		}
+		text.WriteString("\n")
+	} else {
+		text.WriteString(":white_check_mark: No conflicting merge requests found\n\n")
+	}
+
+	// Per-namespace breakdown
+	if len(s.Namespaces) > 0 {
+		text.WriteString("**Conflicts by namespace**\n")
+		for _, stat := range s.Namespaces {
+			text.WriteString(fmt.Sprintf("- `%s`: %d MRs in %d repositories\n", stat.Namespace, stat.ConflictingMRs, stat.Repositories))
+		}
+	}
+
+	return strings.TrimSuffix(text.String(), "\n")
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestMattermostNotifier_PostsMarkdown(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier := NewMattermostNotifier(config.MattermostConfig{
+		WebhookURL: receiver.server.URL + "/hooks/abc",
+		Channel:    "town-square",
+		Username:   "mr-bot",
+	})
+
+	require.NoError(t, notifier.Notify(context.Background(), buildNotifyTestReport(), nil))
+
+	bodies := receiver.bodies("/hooks/abc")
+	require.Len(t, bodies, 1)
+
+	var message mattermostMessage
+	require.NoError(t, json.Unmarshal(bodies[0], &message))
+
+	assert.Equal(t, "town-square", message.Channel)
+	assert.Equal(t, "mr-bot", message.Username)
+	assert.Contains(t, message.Text, "#### MR Conflict Report - 2024-01-10T00-00-00")
+	assert.Contains(t, message.Text, "| 3 | 2 | 3 |")
+	assert.Contains(t, message.Text, "[Release 1.2](https://gitlab.example.com/backend/api/-/merge_requests/1)")
+	assert.Contains(t, message.Text, "- `frontend`: 1 MRs in 1 repositories")
+	assert.NotContains(t, message.Text, "Since previous report")
+}
+
+func TestMattermostNotifier_NoConflicts(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier := NewMattermostNotifier(config.MattermostConfig{WebhookURL: receiver.server.URL + "/hooks/abc"})
+
+	report := &models.Report{Timestamp: "2024-01-10T00-00-00"}
+	require.NoError(t, notifier.Notify(context.Background(), report, nil))
+
+	var message mattermostMessage
+	require.NoError(t, json.Unmarshal(receiver.bodies("/hooks/abc")[0], &message))
+	assert.Contains(t, message.Text, "No conflicting merge requests found")
+}
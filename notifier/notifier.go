@@ -0,0 +1,228 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
+)
+
+const (
+	// defaultTimeout is the per-request timeout used when none is configured
+	defaultTimeout = 10 * time.Second
+	// defaultRetryBackoff is the initial delay between delivery attempts
+	defaultRetryBackoff = time.Second
+	// defaultTopN is the number of oldest conflicting MRs listed when top_n is not configured
+	defaultTopN = 5
+)
+
+// Notifier delivers a scan report to an external system
+type Notifier interface {
+	// Name identifies the notifier in logs and errors
+	Name() string
+	// Notify sends the report and its diff against the previous report (which may be nil)
+	Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error
+}
+
+// New creates a notifier for every channel enabled in the configuration
+func New(cfg config.NotifyConfig) ([]Notifier, error) {
+	var notifiers []Notifier
+
+	if cfg.Slack.Enabled() {
+		notifiers = append(notifiers, withSettings(NewSlackNotifier(cfg.Slack), cfg.Slack.NotifierSettings))
+	}
+	if cfg.Teams.Enabled() {
+		notifiers = append(notifiers, withSettings(NewTeamsNotifier(cfg.Teams), cfg.Teams.NotifierSettings))
+	}
+	if cfg.Mattermost.Enabled() {
+		notifiers = append(notifiers, withSettings(NewMattermostNotifier(cfg.Mattermost), cfg.Mattermost.NotifierSettings))
+	}
+	if cfg.Email.Enabled() {
+		notifiers = append(notifiers, withSettings(NewEmailNotifier(cfg.Email), cfg.Email.NotifierSettings))
+	}
+	for _, webhookCfg := range cfg.Webhooks {
+		webhook, err := NewWebhookNotifier(webhookCfg)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, withSettings(webhook, webhookCfg.NotifierSettings))
+	}
+
+	return notifiers, nil
+}
+
+// filteredNotifier applies the shared filter and only_on_changes settings before delegating
+type filteredNotifier struct {
+	Notifier
+	settings config.NotifierSettings
+}
+
+// withSettings wraps a notifier with the shared filtering settings
+func withSettings(n Notifier, settings config.NotifierSettings) Notifier {
+	return &filteredNotifier{Notifier: n, settings: settings}
+}
+
+// Notify filters the report and skips delivery when the settings say there is nothing to send
+func (f *filteredNotifier) Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	filter := f.settings.Filter
+	if len(filter.Projects) > 0 || len(filter.Namespaces) > 0 {
+		report, reportDiff = filterReport(report, reportDiff, func(repo models.Repository) bool {
+			return matchesRepository(filter.Projects, filter.Namespaces, repo)
+		})
+	}
+
+	if f.settings.OnlyOnChanges && reportDiff != nil && !reportDiff.HasChanges() {
+		slog.Info("Skipping notification, no changes since previous report", "notifier", f.Name())
+		return nil
+	}
+	if report.TotalConflictingMRs < filter.MinConflicts {
+		slog.Info("Skipping notification, below minimum conflict count",
+			"notifier", f.Name(), "conflicts", report.TotalConflictingMRs, "min_conflicts", filter.MinConflicts)
+		return nil
+	}
+	if filter.MinSeverity != "" && report.CountAtLeast(filter.MinSeverity) == 0 {
+		slog.Info("Skipping notification, no conflict reaches the minimum severity",
+			"notifier", f.Name(), "min_severity", filter.MinSeverity)
+		return nil
+	}
+
+	return f.Notifier.Notify(ctx, report, reportDiff)
+}
+
+// sender performs HTTP deliveries with a timeout and retries
+type sender struct {
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+// newSender creates a sender from the shared notifier settings
+func newSender(settings config.NotifierSettings) *sender {
+	timeout := settings.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	backoff := settings.RetryBackoff
+	if backoff == 0 {
+		backoff = defaultRetryBackoff
+	}
+	return &sender{
+		httpClient: &http.Client{Timeout: timeout},
+		retries:    settings.Retries,
+		backoff:    backoff,
+	}
+}
+
+// send delivers a request body, retrying on network errors, 429 and 5xx responses
+func (s *sender) send(ctx context.Context, method, url, contentType string, headers map[string]string, body []byte) error {
+	body = []byte(redact.Content(string(body)))
+	return retry(ctx, s.retries, s.backoff, func() (time.Duration, error) {
+		return s.sendOnce(ctx, method, url, contentType, headers, body)
+	})
+}
+
+// retry makes up to retries+1 delivery attempts, doubling the delay between them, until one
+// succeeds or fails with an error that is not a retryableError. An attempt can ask for a longer
+// delay, such as a Retry-After; logArgs are added to the log of failed attempts
+func retry(ctx context.Context, retries int, backoff time.Duration, attempt func() (time.Duration, error), logArgs ...interface{}) error {
+	delay := backoff
+	var lastErr error
+
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+
+		retryAfter, err := attempt()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) {
+			return err
+		}
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		slog.Debug("Notification delivery failed, retrying", append([]interface{}{"attempt", i + 1, "error", err}, logArgs...)...)
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", retries+1, lastErr)
+}
+
+// sendOnce performs a single delivery attempt and reports any Retry-After delay
+func (s *sender) sendOnce(ctx context.Context, method, url, contentType string, headers map[string]string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, &retryableError{err: fmt.Errorf("request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 400 {
+		return 0, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	err = fmt.Errorf("webhook error %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		var retryAfter time.Duration
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryAfter, &retryableError{err: err}
+	}
+
+	return 0, err
+}
+
+// retryableError marks delivery failures that are worth another attempt
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// matchesRepository reports whether a repository is selected by project paths or namespaces
+func matchesRepository(projects, namespaces []string, repo models.Repository) bool {
+	for _, project := range projects {
+		if strings.EqualFold(project, repo.PathWithNamespace) {
+			return true
+		}
+	}
+	for _, namespace := range namespaces {
+		namespace = strings.Trim(namespace, "/")
+		if strings.EqualFold(namespace, namespaceOf(repo)) ||
+			strings.HasPrefix(strings.ToLower(repo.PathWithNamespace), strings.ToLower(namespace)+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// SlackNotifier posts scan summaries to Slack incoming webhooks using Block Kit
+type SlackNotifier struct {
+	cfg    config.SlackConfig
+	sender *sender
+}
+
+// NewSlackNotifier creates a Slack notifier from its configuration
+func NewSlackNotifier(cfg config.SlackConfig) *SlackNotifier {
+	if cfg.TopN == 0 {
+		cfg.TopN = defaultTopN
+	}
+	return &SlackNotifier{
+		cfg:    cfg,
+		sender: newSender(cfg.NotifierSettings),
+	}
+}
+
+// Name identifies the notifier
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// slackMessage is the payload accepted by Slack incoming webhooks
+type slackMessage struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"`
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+// slackBlock is a Block Kit layout block
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Notify posts the report summary to the default webhook and to every matching route
+func (n *SlackNotifier) Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	var errs []string
+
+	// Full summary to the default channel
+	message := buildSlackMessage(buildSummary(report, reportDiff, n.cfg.TopN), n.cfg.Channel)
+	if err := n.post(ctx, n.cfg.WebhookURL, message); err != nil {
+		errs = append(errs, fmt.Sprintf("default channel: %v", err))
+	}
+
+	// Per-project summaries to routed channels
+	for i, route := range n.cfg.Routes {
+		routed, routedDiff := filterReport(report, reportDiff, func(repo models.Repository) bool {
+			return matchesRepository(route.Projects, route.Namespaces, repo)
+		})
+		if routed.TotalConflictingMRs == 0 && !routedDiff.HasChanges() {
+			continue
+		}
+		if n.cfg.OnlyOnChanges && routedDiff != nil && !routedDiff.HasChanges() {
+			continue
+		}
+
+		webhookURL := route.WebhookURL
+		if webhookURL == "" {
+			webhookURL = n.cfg.WebhookURL
+		}
+		message := buildSlackMessage(buildSummary(routed, routedDiff, n.cfg.TopN), route.Channel)
+		if err := n.post(ctx, webhookURL, message); err != nil {
+			errs = append(errs, fmt.Sprintf("route %d (%s): %v", i, route.Channel, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("slack notification failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// post sends a message to a Slack incoming webhook
+func (n *SlackNotifier) post(ctx context.Context, webhookURL string, message slackMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	return n.sender.send(ctx, http.MethodPost, webhookURL, "application/json", nil, payload)
+}
+
+// buildSlackMessage renders a summary as Block Kit blocks
+func buildSlackMessage(s summary, channel string) slackMessage {
+	text := fmt.Sprintf("MR Conflict Report - %d conflicting MRs in %d repositories",
+		s.TotalConflictingMRs, s.RepositoriesWithConflicts)
+
+	blocks := []slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: fmt.Sprintf("MR Conflict Report - %s", s.Timestamp)},
+		},
+		{
+			Type: "section",
+			Fields: []slackText{
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Repositories Scanned*\n%d", s.TotalRepositories)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Repositories with Conflicts*\n%d", s.RepositoriesWithConflicts)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Conflicting MRs*\n%d", s.TotalConflictingMRs)},
+			},
+		},
+	}
+
+	// Changes since the previous report
+	if s.Changes != nil && s.Changes.PreviousTimestamp != "" {
+		blocks[1].Fields = append(blocks[1].Fields,
+			slackText{Type: "mrkdwn", Text: fmt.Sprintf("*Changes*\n🆕 %d new · ✅ %d resolved", len(s.Changes.New), len(s.Changes.Resolved))})
+	}
+
+	// Oldest conflicting merge requests
+	if len(s.Oldest) > 0 {
+		var lines strings.Builder
+		lines.WriteString(fmt.Sprintf("*Oldest conflicting MRs (top %d)*\n", len(s.Oldest)))
+		for _, entry := range s.Oldest {
+			lines.WriteString(fmt.Sprintf("• <%s|%s> in <%s|%s> - %s - open for %s\n",
+				entry.MergeRequest.WebURL,
+				escapeSlack(entry.MergeRequest.Title),
+				entry.Repository.WebURL,
+				escapeSlack(entry.Repository.Name),
+				escapeSlack(entry.MergeRequest.Author.Name),
+				formatAge(entry.Age)))
+		}
+		blocks = append(blocks, slackBlock{Type: "divider"}, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: strings.TrimSuffix(lines.String(), "\n")},
+		})
+	}
+
+	// Per-namespace breakdown
+	if len(s.Namespaces) > 0 {
+		var lines strings.Builder
+		lines.WriteString("*Conflicts by namespace*\n")
+		for _, stat := range s.Namespaces {
+			lines.WriteString(fmt.Sprintf("• `%s`: %d MRs in %d repositories\n",
+				escapeSlack(stat.Namespace), stat.ConflictingMRs, stat.Repositories))
+		}
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: strings.TrimSuffix(lines.String(), "\n")},
+		})
+	}
+
+	if s.TotalConflictingMRs == 0 {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: "✅ No conflicting merge requests found"},
+		})
+	}
+
+	blocks = append(blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: "Generated by MR Conflict Checker"}},
+	})
+
+	return slackMessage{Channel: channel, Text: text, Blocks: blocks}
+}
+
+// escapeSlack escapes the control characters of Slack mrkdwn
+func escapeSlack(s string) string {
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(s)
+}
+
+// formatAge renders an MR age in whole days, or hours for younger MRs
+func formatAge(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// buildNotifyTestReport creates a report with conflicts in two namespaces
+func buildNotifyTestReport() *models.Report {
+	report := &models.Report{Timestamp: "2024-01-10T00-00-00"}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report.AddRepository(models.Repository{
+		ID: 1, Name: "api", PathWithNamespace: "backend/api", WebURL: "https://gitlab.example.com/backend/api",
+		Namespace: models.Namespace{Path: "backend", FullPath: "backend"},
+	}, []models.MergeRequest{
+		{ID: 1, Title: "Release 1.2", WebURL: "https://gitlab.example.com/backend/api/-/merge_requests/1", CreatedAt: base, Author: models.Author{Name: "Alice"}},
+		{ID: 2, Title: "Release 1.3", WebURL: "https://gitlab.example.com/backend/api/-/merge_requests/2", CreatedAt: base.Add(48 * time.Hour), Author: models.Author{Name: "Bob"}},
+	}, models.StatusConflicts, "")
+
+	report.AddRepository(models.Repository{
+		ID: 2, Name: "web", PathWithNamespace: "frontend/web", WebURL: "https://gitlab.example.com/frontend/web",
+		Namespace: models.Namespace{Path: "frontend", FullPath: "frontend"},
+	}, []models.MergeRequest{
+		{ID: 7, Title: "Release <web>", WebURL: "https://gitlab.example.com/frontend/web/-/merge_requests/7", CreatedAt: base.Add(24 * time.Hour), Author: models.Author{Name: "Carol"}},
+	}, models.StatusConflicts, "")
+
+	report.AddRepository(models.Repository{ID: 3, Name: "docs", PathWithNamespace: "frontend/docs"}, nil, models.StatusNoMRs, "")
+
+	return report
+}
+
+// receivedSlackMessages decodes the Slack messages posted to a path
+func receivedSlackMessages(t *testing.T, receiver *webhookReceiver, path string) []slackMessage {
+	var messages []slackMessage
+	for _, body := range receiver.bodies(path) {
+		var message slackMessage
+		require.NoError(t, json.Unmarshal(body, &message))
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func TestSlackNotifier_PostsBlockKitSummary(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier := NewSlackNotifier(config.SlackConfig{
+		WebhookURL: receiver.server.URL + "/default",
+		Channel:    "#releases",
+		TopN:       2,
+	})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.NoError(t, err)
+
+	messages := receivedSlackMessages(t, receiver, "/default")
+	require.Len(t, messages, 1)
+	message := messages[0]
+
+	assert.Equal(t, "#releases", message.Channel)
+	assert.Contains(t, message.Text, "3 conflicting MRs in 2 repositories")
+	assert.Equal(t, "header", message.Blocks[0].Type)
+	assert.Equal(t, "MR Conflict Report - 2024-01-10T00-00-00", message.Blocks[0].Text.Text)
+	assert.Contains(t, message.Blocks[1].Fields[0].Text, "3")
+
+	content := slackMessageText(message)
+
+	// Top 2 oldest: MR 1 (Jan 1) then MR 7 (Jan 2), MR 2 is cut off
+	assert.Contains(t, content, "Oldest conflicting MRs (top 2)")
+	assert.Contains(t, content, "<https://gitlab.example.com/backend/api/-/merge_requests/1|Release 1.2>")
+	assert.Contains(t, content, "Release &lt;web&gt;")
+	assert.NotContains(t, content, "Release 1.3")
+	assert.Less(t, strings.Index(content, "Release 1.2"), strings.Index(content, "Release &lt;web&gt;"))
+
+	// Namespace breakdown
+	assert.Contains(t, content, "`backend`: 2 MRs in 1 repositories")
+	assert.Contains(t, content, "`frontend`: 1 MRs in 1 repositories")
+}
+
+// slackMessageText concatenates the text of all blocks in a message
+func slackMessageText(message slackMessage) string {
+	var content strings.Builder
+	for _, block := range message.Blocks {
+		if block.Text != nil {
+			content.WriteString(block.Text.Text + "\n")
+		}
+		for _, field := range block.Fields {
+			content.WriteString(field.Text + "\n")
+		}
+	}
+	return content.String()
+}
+
+func TestSlackNotifier_RoutesPerProject(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier := NewSlackNotifier(config.SlackConfig{
+		WebhookURL: receiver.server.URL + "/default",
+		Routes: []config.SlackRoute{
+			{Namespaces: []string{"frontend"}, Channel: "#frontend"},
+			{Projects: []string{"backend/api"}, WebhookURL: receiver.server.URL + "/backend"},
+			{Projects: []string{"other/project"}, Channel: "#unused"},
+		},
+	})
+
+	require.NoError(t, notifier.Notify(context.Background(), buildNotifyTestReport(), nil))
+
+	defaultMessages := receivedSlackMessages(t, receiver, "/default")
+	require.Len(t, defaultMessages, 2) // full summary + frontend route
+	assert.Equal(t, "#frontend", defaultMessages[1].Channel)
+	assert.Contains(t, defaultMessages[1].Text, "1 conflicting MRs in 1 repositories")
+
+	backendMessages := receivedSlackMessages(t, receiver, "/backend")
+	require.Len(t, backendMessages, 1)
+	assert.Contains(t, backendMessages[0].Text, "2 conflicting MRs in 1 repositories")
+}
+
+func TestSlackNotifier_OnlyOnChanges(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	cfg := config.SlackConfig{WebhookURL: receiver.server.URL + "/default"}
+	cfg.OnlyOnChanges = true
+	notifier := withSettings(NewSlackNotifier(cfg), cfg.NotifierSettings)
+
+	report := buildNotifyTestReport()
+	reportDiff := &models.ReportDiff{PreviousTimestamp: "2024-01-09T00-00-00"}
+	require.NoError(t, notifier.Notify(context.Background(), report, reportDiff))
+	assert.Empty(t, receivedSlackMessages(t, receiver, "/default"))
+
+	reportDiff.New = []models.ConflictChange{{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 2}}}
+	require.NoError(t, notifier.Notify(context.Background(), report, reportDiff))
+	require.Len(t, receivedSlackMessages(t, receiver, "/default"), 1)
+}
+
+func TestSlackNotifier_WebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(config.SlackConfig{WebhookURL: server.URL})
+
+	err := notifier.Notify(context.Background(), buildNotifyTestReport(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook error 404: no_service")
+}
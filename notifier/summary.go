@@ -0,0 +1,217 @@
+package notifier
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// conflictEntry is a conflicting merge request together with its repository
+type conflictEntry struct {
+	Repository   models.Repository
+	MergeRequest models.MergeRequest
+	Age          time.Duration
+}
+
+// namespaceStat counts the conflicts found in a single namespace
+type namespaceStat struct {
+	Namespace      string
+	Repositories   int
+	ConflictingMRs int
+}
+
+// summary is the notifier-independent digest of a report
+type summary struct {
+	Timestamp                 string
+	TotalRepositories         int
+	RepositoriesWithConflicts int
+	TotalConflictingMRs       int
+	Oldest                    []conflictEntry
+	Namespaces                []namespaceStat
+	Changes                   *models.ReportDiff
+}
+
+// buildSummary condenses a report into totals, the topN oldest conflicts and per-namespace counts
+func buildSummary(report *models.Report, reportDiff *models.ReportDiff, topN int) summary {
+	totalRepos, reposWithConflicts, totalConflicts := report.GetSummaryStats()
+	s := summary{
+		Timestamp:                 report.Timestamp,
+		TotalRepositories:         totalRepos,
+		RepositoriesWithConflicts: reposWithConflicts,
+		TotalConflictingMRs:       totalConflicts,
+		Changes:                   reportDiff,
+	}
+
+	now := reportTime(report)
+	stats := make(map[string]*namespaceStat)
+	var entries []conflictEntry
+
+	for _, repoReport := range report.Repositories {
+		if len(repoReport.ConflictingMRs) == 0 {
+			continue
+		}
+
+		namespace := namespaceOf(repoReport.Repository)
+		stat, exists := stats[namespace]
+		if !exists {
+			stat = &namespaceStat{Namespace: namespace}
+			stats[namespace] = stat
+		}
+		stat.Repositories++
+		stat.ConflictingMRs += len(repoReport.ConflictingMRs)
+
+		for _, mr := range repoReport.ConflictingMRs {
+			entries = append(entries, conflictEntry{
+				Repository:   repoReport.Repository,
+				MergeRequest: mr,
+				Age:          now.Sub(mr.CreatedAt),
+			})
+		}
+	}
+
+	// Oldest merge requests first
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].MergeRequest.CreatedAt.Before(entries[j].MergeRequest.CreatedAt)
+	})
+	if topN > 0 && len(entries) > topN {
+		entries = entries[:topN]
+	}
+	s.Oldest = entries
+
+	// Namespaces with the most conflicts first
+	for _, stat := range stats {
+		s.Namespaces = append(s.Namespaces, *stat)
+	}
+	sort.Slice(s.Namespaces, func(i, j int) bool {
+		if s.Namespaces[i].ConflictingMRs == s.Namespaces[j].ConflictingMRs {
+			return s.Namespaces[i].Namespace < s.Namespaces[j].Namespace
+		}
+		return s.Namespaces[i].ConflictingMRs > s.Namespaces[j].ConflictingMRs
+	})
+
+	return s
+}
+
+// filterReport returns copies of the report and diff containing only the repositories accepted by keep
+func filterReport(report *models.Report, reportDiff *models.ReportDiff, keep func(models.Repository) bool) (*models.Report, *models.ReportDiff) {
+	filtered := &models.Report{Timestamp: report.Timestamp}
+
+	for _, repoReport := range report.Repositories {
+		if !keep(repoReport.Repository) {
+			continue
+		}
+		filtered.AddRepository(repoReport.Repository, repoReport.ConflictingMRs, repoReport.Status, repoReport.ErrorMessage)
+		filtered.Repositories[len(filtered.Repositories)-1].Severities = repoReport.Severities
+	}
+
+	if reportDiff == nil {
+		return filtered, nil
+	}
+
+	filteredDiff := &models.ReportDiff{
+		PreviousTimestamp: reportDiff.PreviousTimestamp,
+		CurrentTimestamp:  reportDiff.CurrentTimestamp,
+		New:               filterChanges(reportDiff.New, keep),
+		Resolved:          filterChanges(reportDiff.Resolved, keep),
+		Persisting:        filterChanges(reportDiff.Persisting, keep),
+	}
+	filtered.Changes = filteredDiff
+
+	return filtered, filteredDiff
+}
+
+// filterChanges keeps the diff entries whose repository is accepted by keep
+func filterChanges(changes []models.ConflictChange, keep func(models.Repository) bool) []models.ConflictChange {
+	var filtered []models.ConflictChange
+	for _, change := range changes {
+		if keep(changeRepository(change)) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+// filterByAuthor returns copies of the report and diff containing only merge requests by the given usernames
+func filterByAuthor(report *models.Report, reportDiff *models.ReportDiff, usernames []string) (*models.Report, *models.ReportDiff) {
+	authors := make(map[string]bool)
+	for _, username := range usernames {
+		authors[strings.ToLower(strings.TrimPrefix(username, "@"))] = true
+	}
+	keep := func(mr models.MergeRequest) bool {
+		return authors[strings.ToLower(mr.Author.Username)]
+	}
+
+	filtered := &models.Report{Timestamp: report.Timestamp}
+	for _, repoReport := range report.Repositories {
+		var mrs []models.MergeRequest
+		for _, mr := range repoReport.ConflictingMRs {
+			if keep(mr) {
+				mrs = append(mrs, mr)
+			}
+		}
+		status := repoReport.Status
+		if status == models.StatusConflicts && len(mrs) == 0 {
+			// None of the conflicts belong to these authors
+			status = models.StatusAccessible
+		}
+		filtered.AddRepository(repoReport.Repository, mrs, status, repoReport.ErrorMessage)
+	}
+
+	if reportDiff == nil {
+		return filtered, nil
+	}
+
+	keepChange := func(changes []models.ConflictChange) []models.ConflictChange {
+		var result []models.ConflictChange
+		for _, change := range changes {
+			if keep(change.MergeRequest) {
+				result = append(result, change)
+			}
+		}
+		return result
+	}
+	filteredDiff := &models.ReportDiff{
+		PreviousTimestamp: reportDiff.PreviousTimestamp,
+		CurrentTimestamp:  reportDiff.CurrentTimestamp,
+		New:               keepChange(reportDiff.New),
+		Resolved:          keepChange(reportDiff.Resolved),
+		Persisting:        keepChange(reportDiff.Persisting),
+	}
+	filtered.Changes = filteredDiff
+
+	return filtered, filteredDiff
+}
+
+// changeRepository reconstructs the repository a diff entry belongs to
+func changeRepository(change models.ConflictChange) models.Repository {
+	return models.Repository{
+		ID:                change.RepositoryID,
+		Name:              change.RepositoryName,
+		PathWithNamespace: change.RepositoryPath,
+		WebURL:            change.RepositoryURL,
+	}
+}
+
+// namespaceOf returns the namespace path used to group a repository
+func namespaceOf(repo models.Repository) string {
+	switch {
+	case repo.Namespace.FullPath != "":
+		return repo.Namespace.FullPath
+	case repo.Namespace.Path != "":
+		return repo.Namespace.Path
+	case repo.Namespace.Name != "":
+		return repo.Namespace.Name
+	default:
+		return "(no namespace)"
+	}
+}
+
+// reportTime returns the time the report was generated, falling back to now
+func reportTime(report *models.Report) time.Time {
+	if t, err := models.ParseReportTimestamp(report.Timestamp); err == nil {
+		return t
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// TeamsNotifier posts scan summaries to Microsoft Teams as Adaptive Cards
+type TeamsNotifier struct {
+	cfg    config.TeamsConfig
+	sender *sender
+}
+
+// NewTeamsNotifier creates a Teams notifier from its configuration
+func NewTeamsNotifier(cfg config.TeamsConfig) *TeamsNotifier {
+	if cfg.TopN == 0 {
+		cfg.TopN = defaultTopN
+	}
+	return &TeamsNotifier{
+		cfg:    cfg,
+		sender: newSender(cfg.NotifierSettings),
+	}
+}
+
+// Name identifies the notifier
+func (n *TeamsNotifier) Name() string {
+	return "teams"
+}
+
+// teamsMessage is the incoming webhook envelope carrying an Adaptive Card
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// teamsAttachment wraps the Adaptive Card content
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+// adaptiveCard is the root Adaptive Card object
+type adaptiveCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+	Actions []map[string]any `json:"actions,omitempty"`
+}
+
+// Notify posts the report summary card
+func (n *TeamsNotifier) Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	message := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     buildAdaptiveCard(buildSummary(report, reportDiff, n.cfg.TopN)),
+		}},
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode teams message: %w", err)
+	}
+
+	if err := n.sender.send(ctx, http.MethodPost, n.cfg.WebhookURL, "application/json", nil, payload); err != nil {
+		return fmt.Errorf("teams notification failed: %w", err)
+	}
+	return nil
+}
+
+// buildAdaptiveCard renders a summary as an Adaptive Card
+func buildAdaptiveCard(s summary) adaptiveCard {
+	facts := []map[string]any{
+		{"title": "Repositories Scanned", "value": fmt.Sprint(s.TotalRepositories)},
+		{"title": "Repositories with Conflicts", "value": fmt.Sprint(s.RepositoriesWithConflicts)},
+		{"title": "Conflicting MRs", "value": fmt.Sprint(s.TotalConflictingMRs)},
+	}
+	if s.Changes != nil && s.Changes.PreviousTimestamp != "" {
+		facts = append(facts,
+			map[string]any{"title": "New since previous report", "value": fmt.Sprint(len(s.Changes.New))},
+			map[string]any{"title": "Resolved since previous report", "value": fmt.Sprint(len(s.Changes.Resolved))})
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "size": "Large", "weight": "Bolder", "text": fmt.Sprintf("MR Conflict Report - %s", s.Timestamp), "wrap": true},
+		{"type": "FactSet", "facts": facts},
+	}
+
+	// Oldest conflicting merge requests
+	if len(s.Oldest) > 0 {
+		body = append(body, map[string]any{
+			"type": "TextBlock", "weight": "Bolder", "separator": true,
+			"text": fmt.Sprintf("Oldest conflicting MRs (top %d)", len(s.Oldest)),
+		})
+		for _, entry := range s.Oldest {
+			body = append(body, map[string]any{
+				"type": "TextBlock", "wrap": true, "spacing": "Small",
+				"text": fmt.Sprintf("- [%s](%s) in [%s](%s) - %s - open for %s",
+					entry.MergeRequest.Title, entry.MergeRequest.WebURL,
+					entry.Repository.Name, entry.Repository.WebURL,
+					entry.MergeRequest.Author.Name, formatAge(entry.Age)),
+			})
+		}
+	} else {
+		body = append(body, map[string]any{"type": "TextBlock", "text": "✅ No conflicting merge requests found", "wrap": true})
+	}
+
+	// Per-namespace breakdown
+	if len(s.Namespaces) > 0 {
+		var namespaceFacts []map[string]any
+		for _, stat := range s.Namespaces {
+			namespaceFacts = append(namespaceFacts, map[string]any{
+				"title": stat.Namespace,
+				"value": fmt.Sprintf("%d MRs in %d repositories", stat.ConflictingMRs, stat.Repositories),
+			})
+		}
+		body = append(body,
+			map[string]any{"type": "TextBlock", "weight": "Bolder", "separator": true, "text": "Conflicts by namespace"},
+			map[string]any{"type": "FactSet", "facts": namespaceFacts})
+	}
+
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+	}
+	if len(s.Oldest) > 0 {
+		card.Actions = []map[string]any{{
+			"type": "Action.OpenUrl", "title": "Open oldest conflicting MR", "url": s.Oldest[0].MergeRequest.WebURL,
+		}}
+	}
+
+	return card
+}
@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestTeamsNotifier_PostsAdaptiveCard(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier := NewTeamsNotifier(config.TeamsConfig{WebhookURL: receiver.server.URL + "/teams", TopN: 1})
+
+	reportDiff := &models.ReportDiff{
+		PreviousTimestamp: "2024-01-09T00-00-00",
+		New:               []models.ConflictChange{{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 2}}},
+	}
+	require.NoError(t, notifier.Notify(context.Background(), buildNotifyTestReport(), reportDiff))
+
+	bodies := receiver.bodies("/teams")
+	require.Len(t, bodies, 1)
+
+	var message teamsMessage
+	require.NoError(t, json.Unmarshal(bodies[0], &message))
+
+	assert.Equal(t, "message", message.Type)
+	require.Len(t, message.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", message.Attachments[0].ContentType)
+
+	card := message.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, "MR Conflict Report - 2024-01-10T00-00-00", card.Body[0]["text"])
+
+	content := string(bodies[0])
+	assert.Contains(t, content, `"title":"Conflicting MRs","value":"3"`)
+	assert.Contains(t, content, `"title":"New since previous report","value":"1"`)
+	assert.Contains(t, content, "Oldest conflicting MRs (top 1)")
+	assert.Contains(t, content, "[Release 1.2](https://gitlab.example.com/backend/api/-/merge_requests/1)")
+	assert.Contains(t, content, `"title":"backend","value":"2 MRs in 1 repositories"`)
+	require.Len(t, card.Actions, 1)
+	assert.Equal(t, "https://gitlab.example.com/backend/api/-/merge_requests/1", card.Actions[0]["url"])
+}
@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// defaultWebhookTemplate is used when a generic webhook has no template configured
+const defaultWebhookTemplate = `{
+  "timestamp": {{ json .Report.Timestamp }},
+  "total_repositories": {{ .Report.TotalRepositories }},
+  "repositories_with_conflicts": {{ .Report.RepositoriesWithConflicts }},
+  "total_conflicting_mrs": {{ .Report.TotalConflictingMRs }},
+  "new_conflicts": {{ if .Diff }}{{ len .Diff.New }}{{ else }}0{{ end }},
+  "resolved_conflicts": {{ if .Diff }}{{ len .Diff.Resolved }}{{ else }}0{{ end }}
+}`
+
+// WebhookNotifier sends a body rendered from a user-supplied Go template to any HTTP endpoint
+type WebhookNotifier struct {
+	cfg      config.WebhookConfig
+	template *template.Template
+	sender   *sender
+}
+
+// WebhookData is the data available to generic webhook templates
+type WebhookData struct {
+	Report *models.Report
+	Diff   *models.ReportDiff
+}
+
+// NewWebhookNotifier creates a generic webhook notifier, parsing its body template
+func NewWebhookNotifier(cfg config.WebhookConfig) (*WebhookNotifier, error) {
+	source := cfg.Template
+	if cfg.TemplateFile != "" {
+		data, err := os.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template for webhook %s: %w", cfg.Name, err)
+		}
+		source = string(data)
+	}
+	if source == "" {
+		source = defaultWebhookTemplate
+	}
+
+	tmpl, err := template.New(cfg.Name).Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"join": strings.Join,
+	}).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template for webhook %s: %w", cfg.Name, err)
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = "application/json"
+	}
+
+	return &WebhookNotifier{
+		cfg:      cfg,
+		template: tmpl,
+		sender:   newSender(cfg.NotifierSettings),
+	}, nil
+}
+
+// Name identifies the notifier
+func (n *WebhookNotifier) Name() string {
+	return "webhook:" + n.cfg.Name
+}
+
+// Notify renders the template and sends it to the configured URL
+func (n *WebhookNotifier) Notify(ctx context.Context, report *models.Report, reportDiff *models.ReportDiff) error {
+	var body bytes.Buffer
+	if err := n.template.Execute(&body, WebhookData{Report: report, Diff: reportDiff}); err != nil {
+		return fmt.Errorf("failed to render template for webhook %s: %w", n.cfg.Name, err)
+	}
+
+	method := strings.ToUpper(n.cfg.Method)
+	if err := n.sender.send(ctx, method, n.cfg.URL, n.cfg.ContentType, n.cfg.Headers, body.Bytes()); err != nil {
+		return fmt.Errorf("webhook %s notification failed: %w", n.cfg.Name, err)
+	}
+	return nil
+}
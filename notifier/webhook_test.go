@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestWebhookNotifier_DefaultTemplate(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier, err := NewWebhookNotifier(config.WebhookConfig{Name: "ops", URL: receiver.server.URL + "/ops"})
+	require.NoError(t, err)
+
+	reportDiff := &models.ReportDiff{Resolved: []models.ConflictChange{{RepositoryID: 1}, {RepositoryID: 2}}}
+	require.NoError(t, notifier.Notify(context.Background(), buildNotifyTestReport(), reportDiff))
+
+	bodies := receiver.bodies("/ops")
+	require.Len(t, bodies, 1)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(bodies[0], &payload))
+	assert.Equal(t, "2024-01-10T00-00-00", payload["timestamp"])
+	assert.Equal(t, float64(3), payload["total_conflicting_mrs"])
+	assert.Equal(t, float64(0), payload["new_conflicts"])
+	assert.Equal(t, float64(2), payload["resolved_conflicts"])
+	assert.Equal(t, "application/json", receiver.headers["/ops"][0].Get("Content-Type"))
+	assert.Equal(t, "POST", receiver.methods["/ops"][0])
+}
+
+func TestWebhookNotifier_CustomTemplateAndHeaders(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	notifier, err := NewWebhookNotifier(config.WebhookConfig{
+		Name:        "pager",
+		URL:         receiver.server.URL + "/pager",
+		Method:      "put",
+		ContentType: "text/plain",
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		Template:    `{{ range .Report.Repositories }}{{ range .ConflictingMRs }}{{ .Title }};{{ end }}{{ end }}`,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(context.Background(), buildNotifyTestReport(), nil))
+
+	bodies := receiver.bodies("/pager")
+	require.Len(t, bodies, 1)
+	assert.Equal(t, "Release 1.2;Release 1.3;Release <web>;", string(bodies[0]))
+	assert.Equal(t, "secret", receiver.headers["/pager"][0].Get("X-Api-Key"))
+	assert.Equal(t, "text/plain", receiver.headers["/pager"][0].Get("Content-Type"))
+	assert.Equal(t, "PUT", receiver.methods["/pager"][0])
+}
+
+func TestWebhookNotifier_TemplateFile(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+
+	templatePath := filepath.Join(t.TempDir(), "body.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte(`{"conflicts": {{ .Report.TotalConflictingMRs }}}`), 0644))
+
+	notifier, err := NewWebhookNotifier(config.WebhookConfig{Name: "file", URL: receiver.server.URL + "/file", TemplateFile: templatePath})
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(context.Background(), buildNotifyTestReport(), nil))
+	assert.JSONEq(t, `{"conflicts": 3}`, string(receiver.bodies("/file")[0]))
+}
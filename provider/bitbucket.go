@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+
+	"mr-conflict-checker/bitbucket"
+	"mr-conflict-checker/internal/models"
+)
+
+// Bitbucket reads repositories and pull requests through the Bitbucket Server and Data Center REST API
+type Bitbucket struct {
+	client   *bitbucket.Client
+	projects []string
+}
+
+// NewBitbucket creates a Bitbucket provider scanning the repositories of project keys, or every
+// repository the token can access when there are none
+func NewBitbucket(client *bitbucket.Client, projects []string) *Bitbucket {
+	return &Bitbucket{client: client, projects: projects}
+}
+
+// Name implements Provider
+func (b *Bitbucket) Name() string {
+	return NameBitbucket
+}
+
+// ListRepositories implements Provider
+func (b *Bitbucket) ListRepositories(ctx context.Context) ([]models.Repository, error) {
+	return b.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{})
+}
+
+// ListRepositoriesWithOptions implements Provider
+func (b *Bitbucket) ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	return b.client.ListRepositories(ctx, bitbucket.ListRepositoriesOptions{
+		Projects:          b.projects,
+		LastActivityAfter: opts.LastActivityAfter,
+	})
+}
+
+// ListMergeRequests implements Provider
+func (b *Bitbucket) ListMergeRequests(ctx context.Context, repoID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error) {
+	return b.ListMergeRequestsWithOptions(ctx, repoID, MergeRequestListOptions{SourceBranch: sourceBranch, TargetBranch: targetBranch})
+}
+
+// ListMergeRequestsWithOptions implements Provider
+func (b *Bitbucket) ListMergeRequestsWithOptions(ctx context.Context, repoID int, opts MergeRequestListOptions) ([]models.MergeRequest, error) {
+	return b.client.ListPullRequests(ctx, repoID, bitbucket.PullRequestListOptions{
+		State:        opts.State,
+		SourceBranch: opts.SourceBranch,
+		TargetBranch: opts.TargetBranch,
+		Labels:       opts.Labels,
+		UpdatedAfter: opts.UpdatedAfter,
+	})
+}
+
+// GetMergeRequestChanges implements Provider
+func (b *Bitbucket) GetMergeRequestChanges(ctx context.Context, repoID, mrID int) (int, error) {
+	return b.client.GetPullRequestChanges(ctx, repoID, mrID)
+}
+
+// ListComments implements Provider
+func (b *Bitbucket) ListComments(ctx context.Context, repoID, mrID int) ([]models.Note, error) {
+	return b.client.ListPullRequestComments(ctx, repoID, mrID)
+}
+
+// CreateComment implements Provider
+func (b *Bitbucket) CreateComment(ctx context.Context, repoID, mrID int, body string) (*models.Note, error) {
+	return b.client.CreatePullRequestComment(ctx, repoID, mrID, body)
+}
+
+// UpdateComment implements Provider
+func (b *Bitbucket) UpdateComment(ctx context.Context, repoID, mrID, commentID int, body string) (*models.Note, error) {
+	return b.client.UpdatePullRequestComment(ctx, repoID, mrID, commentID, body)
+}
+
+// DeleteComment implements Provider
+func (b *Bitbucket) DeleteComment(ctx context.Context, repoID, mrID, commentID int) error {
+	return b.client.DeletePullRequestComment(ctx, repoID, mrID, commentID)
+}
+
+// SkippedWrites implements Provider
+func (b *Bitbucket) SkippedWrites() []models.SkippedWrite {
+	return b.client.SkippedWrites()
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+
+	"mr-conflict-checker/gitea"
+	"mr-conflict-checker/internal/models"
+)
+
+// Gitea reads repositories and pull requests through the Gitea or Forgejo REST API
+type Gitea struct {
+	client        *gitea.Client
+	organizations []string
+}
+
+// NewGitea creates a Gitea provider scanning the repositories of organizations, or every
+// repository the token can access when there are none
+func NewGitea(client *gitea.Client, organizations []string) *Gitea {
+	return &Gitea{client: client, organizations: organizations}
+}
+
+// Name implements Provider
+func (g *Gitea) Name() string {
+	return NameGitea
+}
+
+// ListRepositories implements Provider
+func (g *Gitea) ListRepositories(ctx context.Context) ([]models.Repository, error) {
+	return g.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{})
+}
+
+// ListRepositoriesWithOptions implements Provider
+func (g *Gitea) ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	return g.client.ListRepositories(ctx, gitea.ListRepositoriesOptions{
+		Organizations:     g.organizations,
+		LastActivityAfter: opts.LastActivityAfter,
+	})
+}
+
+// ListMergeRequests implements Provider
+func (g *Gitea) ListMergeRequests(ctx context.Context, repoID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error) {
+	return g.ListMergeRequestsWithOptions(ctx, repoID, MergeRequestListOptions{SourceBranch: sourceBranch, TargetBranch: targetBranch})
+}
+
+// ListMergeRequestsWithOptions implements Provider
+func (g *Gitea) ListMergeRequestsWithOptions(ctx context.Context, repoID int, opts MergeRequestListOptions) ([]models.MergeRequest, error) {
+	return g.client.ListPullRequests(ctx, repoID, gitea.PullRequestListOptions{
+		State:        opts.State,
+		SourceBranch: opts.SourceBranch,
+		TargetBranch: opts.TargetBranch,
+		Labels:       opts.Labels,
+		UpdatedAfter: opts.UpdatedAfter,
+	})
+}
+
+// GetMergeRequestChanges implements Provider
+func (g *Gitea) GetMergeRequestChanges(ctx context.Context, repoID, mrID int) (int, error) {
+	return g.client.GetPullRequestChanges(ctx, repoID, mrID)
+}
+
+// ListComments implements Provider
+func (g *Gitea) ListComments(ctx context.Context, repoID, mrID int) ([]models.Note, error) {
+	return g.client.ListPullRequestComments(ctx, repoID, mrID)
+}
+
+// CreateComment implements Provider
+func (g *Gitea) CreateComment(ctx context.Context, repoID, mrID int, body string) (*models.Note, error) {
+	return g.client.CreatePullRequestComment(ctx, repoID, mrID, body)
+}
+
+// UpdateComment implements Provider
+func (g *Gitea) UpdateComment(ctx context.Context, repoID, mrID, commentID int, body string) (*models.Note, error) {
+	return g.client.UpdatePullRequestComment(ctx, repoID, mrID, commentID, body)
+}
+
+// DeleteComment implements Provider
+func (g *Gitea) DeleteComment(ctx context.Context, repoID, mrID, commentID int) error {
+	return g.client.DeletePullRequestComment(ctx, repoID, mrID, commentID)
+}
+
+// SkippedWrites implements Provider
+func (g *Gitea) SkippedWrites() []models.SkippedWrite {
+	return g.client.SkippedWrites()
+}
+
+// BranchProtected reports whether a branch of a repository is protected
+func (g *Gitea) BranchProtected(ctx context.Context, repoID int, branch string) (bool, error) {
+	return g.client.BranchProtected(ctx, repoID, branch)
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+)
+
+// GitLab reads projects and merge requests through a gitlab.Backend and writes through the REST client
+type GitLab struct {
+	client  *gitlab.Client
+	backend gitlab.Backend
+}
+
+// NewGitLab creates a GitLab provider; backend is the REST client itself or a GraphQL backend on it
+func NewGitLab(client *gitlab.Client, backend gitlab.Backend) *GitLab {
+	return &GitLab{client: client, backend: backend}
+}
+
+// Client returns the REST client, for the actions only GitLab supports
+func (g *GitLab) Client() *gitlab.Client {
+	return g.client
+}
+
+// Name implements Provider
+func (g *GitLab) Name() string {
+	return NameGitLab
+}
+
+// ListRepositories implements Provider
+func (g *GitLab) ListRepositories(ctx context.Context) ([]models.Repository, error) {
+	return g.backend.ListRepositories(ctx)
+}
+
+// ListRepositoriesWithOptions implements Provider
+func (g *GitLab) ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	return g.backend.ListRepositoriesWithOptions(ctx, gitlab.ListRepositoriesOptions{LastActivityAfter: opts.LastActivityAfter})
+}
+
+// ListMergeRequests implements Provider
+func (g *GitLab) ListMergeRequests(ctx context.Context, repoID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error) {
+	return g.backend.ListMergeRequests(ctx, repoID, sourceBranch, targetBranch)
+}
+
+// ListMergeRequestsWithOptions implements Provider
+func (g *GitLab) ListMergeRequestsWithOptions(ctx context.Context, repoID int, opts MergeRequestListOptions) ([]models.MergeRequest, error) {
+	return g.backend.ListMergeRequestsWithOptions(ctx, repoID, gitlab.MergeRequestListOptions{
+		State:        opts.State,
+		SourceBranch: opts.SourceBranch,
+		TargetBranch: opts.TargetBranch,
+		Labels:       opts.Labels,
+		UpdatedAfter: opts.UpdatedAfter,
+	})
+}
+
+// GetMergeRequestChanges implements Provider
+func (g *GitLab) GetMergeRequestChanges(ctx context.Context, repoID, mrID int) (int, error) {
+	return g.backend.GetMergeRequestChanges(ctx, repoID, mrID)
+}
+
+// ListComments implements Provider
+func (g *GitLab) ListComments(ctx context.Context, repoID, mrID int) ([]models.Note, error) {
+	return g.client.ListMergeRequestNotes(ctx, repoID, mrID)
+}
+
+// CreateComment implements Provider
+func (g *GitLab) CreateComment(ctx context.Context, repoID, mrID int, body string) (*models.Note, error) {
+	return g.client.CreateMergeRequestNote(ctx, repoID, mrID, body)
+}
+
+// UpdateComment implements Provider
+func (g *GitLab) UpdateComment(ctx context.Context, repoID, mrID, commentID int, body string) (*models.Note, error) {
+	return g.client.UpdateMergeRequestNote(ctx, repoID, mrID, commentID, body)
+}
+
+// DeleteComment implements Provider
+func (g *GitLab) DeleteComment(ctx context.Context, repoID, mrID, commentID int) error {
+	return g.client.DeleteMergeRequestNote(ctx, repoID, mrID, commentID)
+}
+
+// SkippedWrites implements Provider
+func (g *GitLab) SkippedWrites() []models.SkippedWrite {
+	return g.client.SkippedWrites()
+}
+
+// ConflictingFiles returns the files a merge request conflicts on, which only GitLab can tell
+func (g *GitLab) ConflictingFiles(ctx context.Context, repoID int, mr models.MergeRequest) ([]string, error) {
+	return g.client.ConflictingFiles(ctx, repoID, mr)
+}
+
+// BranchProtected reports whether a branch of a repository is protected
+func (g *GitLab) BranchProtected(ctx context.Context, repoID int, branch string) (bool, error) {
+	return g.client.BranchProtected(ctx, repoID, branch)
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+)
+
+func TestGitLab_ReadsThroughBackend(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	server.SetProjects([]models.Repository{{ID: 1, Name: "api", LastActivityAt: since.Add(time.Hour)}})
+	server.SetMergeRequests(1, []models.MergeRequest{
+		{ID: 5, SourceBranch: "release", TargetBranch: "master", HasConflicts: true, State: "opened", Labels: []string{"release"}, UpdatedAt: since.Add(time.Hour)},
+		{ID: 6, SourceBranch: "feature", TargetBranch: "master", State: "opened", UpdatedAt: since.Add(time.Hour)},
+	})
+	server.SetChanges(1, 5, "a.go", "b.go")
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+	host := NewGitLab(client, client)
+	ctx := context.Background()
+
+	assert.Equal(t, NameGitLab, host.Name())
+	assert.Same(t, client, host.Client())
+
+	repos, err := host.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{LastActivityAfter: since})
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+
+	mrs, err := host.ListMergeRequests(ctx, 1, "release", "master")
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.True(t, mrs[0].HasConflicts)
+
+	mrs, err = host.ListMergeRequestsWithOptions(ctx, 1, MergeRequestListOptions{Labels: []string{"release"}, UpdatedAfter: since})
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 5, mrs[0].ID)
+
+	changes, err := host.GetMergeRequestChanges(ctx, 1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changes)
+}
+
+func TestGitLab_CommentsUseNotes(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects([]models.Repository{{ID: 1, Name: "api"}})
+	server.SetMergeRequests(1, []models.MergeRequest{{ID: 5, SourceBranch: "release", TargetBranch: "master", State: "opened"}})
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+	host := NewGitLab(client, client)
+	ctx := context.Background()
+
+	created, err := host.CreateComment(ctx, 1, 5, "first")
+	require.NoError(t, err)
+	_, err = host.UpdateComment(ctx, 1, 5, created.ID, "second")
+	require.NoError(t, err)
+
+	notes, err := host.ListComments(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "second", notes[0].Body)
+	assert.Equal(t, server.Notes(1, 5), notes)
+
+	require.NoError(t, host.DeleteComment(ctx, 1, 5, created.ID))
+	assert.Empty(t, server.Notes(1, 5))
+}
+
+func TestGitLab_ReadOnly(t *testing.T) {
+	server := gitlabfake.NewServer()
+	defer server.Close()
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken, gitlab.WithReadOnly())
+	defer client.Close()
+	host := NewGitLab(client, client)
+
+	_, err := host.CreateComment(context.Background(), 1, 5, "body")
+
+	assert.True(t, IsReadOnly(err))
+	assert.Len(t, host.SkippedWrites(), 1)
+}
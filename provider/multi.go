@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"mr-conflict-checker/internal/models"
+)
+
+// repositoryIDShift is how far the range of a provider is shifted into the repository IDs of a Multi
+const repositoryIDShift = 40
+
+// repositoryIDRanges gives every provider its own range of repository IDs in a Multi, since the
+// IDs of different services can collide. Ranges go by provider name, so that IDs stay the same
+// when providers are reordered, added or removed; GitLab IDs are left as they are.
+var repositoryIDRanges = map[string]int64{
+	NameGitLab:    0,
+	NameGitHub:    1,
+	NameGitea:     2,
+	NameBitbucket: 3,
+}
+
+// conflictingFilesFinder is implemented by the providers that can tell which files conflict
+type conflictingFilesFinder interface {
+	ConflictingFiles(ctx context.Context, repoID int, mr models.MergeRequest) ([]string, error)
+}
+
+// branchProtectionChecker is implemented by the providers that can tell whether a branch is protected
+type branchProtectionChecker interface {
+	BranchProtected(ctx context.Context, repoID int, branch string) (bool, error)
+}
+
+// Multi scans several providers as one. Repositories are tagged with the name of their provider
+// and their IDs are moved to the range of their provider.
+type Multi struct {
+	providers []Provider
+}
+
+// NewMulti creates a provider combining the repositories and merge requests of providers
+func NewMulti(providers ...Provider) *Multi {
+	return &Multi{providers: providers}
+}
+
+// Name implements Provider
+func (m *Multi) Name() string {
+	names := make([]string, len(m.providers))
+	for i, p := range m.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// ListRepositories implements Provider
+func (m *Multi) ListRepositories(ctx context.Context) ([]models.Repository, error) {
+	return m.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{})
+}
+
+// ListRepositoriesWithOptions implements Provider
+func (m *Multi) ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error) {
+	var all []models.Repository
+	for _, p := range m.providers {
+		repos, err := p.ListRepositoriesWithOptions(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s repositories: %w", p.Name(), err)
+		}
+		for _, repo := range repos {
+			repo.ID, err = repositoryID(p.Name(), repo.ID)
+			if err != nil {
+				return nil, err
+			}
+			repo.Provider = p.Name()
+			all = append(all, repo)
+		}
+	}
+	return all, nil
+}
+
+// ListMergeRequests implements Provider
+func (m *Multi) ListMergeRequests(ctx context.Context, repoID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ListMergeRequests(ctx, id, sourceBranch, targetBranch)
+}
+
+// ListMergeRequestsWithOptions implements Provider
+func (m *Multi) ListMergeRequestsWithOptions(ctx context.Context, repoID int, opts MergeRequestListOptions) ([]models.MergeRequest, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ListMergeRequestsWithOptions(ctx, id, opts)
+}
+
+// GetMergeRequestChanges implements Provider
+func (m *Multi) GetMergeRequestChanges(ctx context.Context, repoID, mrID int) (int, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return 0, err
+	}
+	return p.GetMergeRequestChanges(ctx, id, mrID)
+}
+
+// ListComments implements Provider
+func (m *Multi) ListComments(ctx context.Context, repoID, mrID int) ([]models.Note, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ListComments(ctx, id, mrID)
+}
+
+// CreateComment implements Provider
+func (m *Multi) CreateComment(ctx context.Context, repoID, mrID int, body string) (*models.Note, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return nil, err
+	}
+	return p.CreateComment(ctx, id, mrID, body)
+}
+
+// UpdateComment implements Provider
+func (m *Multi) UpdateComment(ctx context.Context, repoID, mrID, commentID int, body string) (*models.Note, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return nil, err
+	}
+	return p.UpdateComment(ctx, id, mrID, commentID, body)
+}
+
+// DeleteComment implements Provider
+func (m *Multi) DeleteComment(ctx context.Context, repoID, mrID, commentID int) error {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return err
+	}
+	return p.DeleteComment(ctx, id, mrID, commentID)
+}
+
+// SkippedWrites implements Provider
+func (m *Multi) SkippedWrites() []models.SkippedWrite {
+	var skipped []models.SkippedWrite
+	for _, p := range m.providers {
+		skipped = append(skipped, p.SkippedWrites()...)
+	}
+	return skipped
+}
+
+// ConflictingFiles returns the files a merge request conflicts on, when its provider can tell
+func (m *Multi) ConflictingFiles(ctx context.Context, repoID int, mr models.MergeRequest) ([]string, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return nil, err
+	}
+	finder, ok := p.(conflictingFilesFinder)
+	if !ok {
+		return nil, fmt.Errorf("%s cannot list conflicting files", p.Name())
+	}
+	return finder.ConflictingFiles(ctx, id, mr)
+}
+
+// BranchProtected reports whether a branch of a repository is protected, when its provider can tell
+func (m *Multi) BranchProtected(ctx context.Context, repoID int, branch string) (bool, error) {
+	p, id, err := m.provider(repoID)
+	if err != nil {
+		return false, err
+	}
+	checker, ok := p.(branchProtectionChecker)
+	if !ok {
+		return false, fmt.Errorf("%s cannot tell protected branches", p.Name())
+	}
+	return checker.BranchProtected(ctx, id, branch)
+}
+
+// provider returns the provider of a repository and the ID the provider knows it by
+func (m *Multi) provider(repoID int) (Provider, int, error) {
+	idRange := int64(repoID) >> repositoryIDShift
+	for _, p := range m.providers {
+		if r, ok := repositoryIDRanges[p.Name()]; ok && r == idRange {
+			return p, int(int64(repoID) & (1<<repositoryIDShift - 1)), nil
+		}
+	}
+	return nil, 0, fmt.Errorf("repository %d belongs to none of the configured providers", repoID)
+}
+
+// repositoryID returns the ID a Multi knows a repository of the named provider by; the IDs of
+// providers other than GitLab only fit in a 64-bit int
+func repositoryID(name string, id int) (int, error) {
+	idRange, ok := repositoryIDRanges[name]
+	if !ok {
+		return 0, fmt.Errorf("provider %s has no repository ID range", name)
+	}
+	if id < 0 || int64(id) >= 1<<repositoryIDShift {
+		return 0, fmt.Errorf("repository %d of %s is outside the range of combined repository IDs", id, name)
+	}
+	multiID := idRange<<repositoryIDShift | int64(id)
+	if int64(int(multiID)) != multiID {
+		return 0, fmt.Errorf("repository %d of %s does not fit in a combined repository ID on this platform", id, name)
+	}
+	return int(multiID), nil
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/bitbucket"
+	"mr-conflict-checker/gitea"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/bitbucketfake"
+	"mr-conflict-checker/internal/testing/giteafake"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+)
+
+// newMulti combines a fake GitLab holding project 1, the fake Gitea of newGitea and the fake
+// Bitbucket of newBitbucket; every service has a repository with ID 1 and a conflicting release
+// merge request on it
+func newMulti(t *testing.T, readOnly bool) (*Multi, *giteafake.Server, *bitbucketfake.Server) {
+	t.Helper()
+	if strconv.IntSize < 64 {
+		t.Skip("combined repository IDs need a 64-bit int")
+	}
+	gitLabServer := gitlabfake.NewServer()
+	t.Cleanup(gitLabServer.Close)
+	gitLabServer.SetProjects([]models.Repository{{ID: 1, Name: "api", Namespace: models.Namespace{ID: 10, Path: "backend"}}})
+	gitLabServer.SetMergeRequests(1, []models.MergeRequest{{ID: 5, SourceBranch: "release", TargetBranch: "master", HasConflicts: true, State: "opened"}})
+	gitLabServer.SetChanges(1, 5, "a.go")
+
+	var gitLabOpts []gitlab.Option
+	giteaOpts := []gitea.Option{gitea.WithConflictCheckRetry(2, 0)}
+	var bitbucketOpts []bitbucket.Option
+	if readOnly {
+		gitLabOpts = append(gitLabOpts, gitlab.WithReadOnly())
+		giteaOpts = append(giteaOpts, gitea.WithReadOnly())
+		bitbucketOpts = append(bitbucketOpts, bitbucket.WithReadOnly())
+	}
+	client := gitlab.NewClient(gitLabServer.URL(), gitlabfake.DefaultToken, gitLabOpts...)
+	t.Cleanup(client.Close)
+
+	giteaServer, giteaHost := newGitea(t, nil, giteaOpts...)
+	giteaServer.SetPullRequests(1, []giteafake.PullRequest{{Number: 6, Head: "release", Base: "master", ChangedFiles: 2}})
+	bitbucketServer, bitbucketHost := newBitbucket(t, nil, bitbucketOpts...)
+	bitbucketServer.SetPullRequests(1, []bitbucketfake.PullRequest{{ID: 7, Source: "release", Target: "master", Conflicted: true}})
+
+	return NewMulti(NewGitLab(client, client), giteaHost, bitbucketHost), giteaServer, bitbucketServer
+}
+
+// multiID returns the ID a Multi knows a repository of the named provider by
+func multiID(t *testing.T, name string, id int) int {
+	t.Helper()
+	multiID, err := repositoryID(name, id)
+	require.NoError(t, err)
+	return multiID
+}
+
+func TestMulti_ListRepositories(t *testing.T) {
+	host, _, _ := newMulti(t, false)
+
+	assert.Equal(t, "gitlab+gitea+bitbucket", host.Name())
+
+	repos, err := host.ListRepositories(context.Background())
+	require.NoError(t, err)
+	require.Len(t, repos, 5)
+
+	// GitLab keeps its IDs, the others get the range of their provider
+	assert.Equal(t, 1, repos[0].ID)
+	assert.Equal(t, NameGitLab, repos[0].Provider)
+	assert.Equal(t, multiID(t, NameGitea, 1), repos[1].ID)
+	assert.Equal(t, NameGitea, repos[1].Provider)
+	assert.Equal(t, "acme/app", repos[1].PathWithNamespace)
+	assert.Equal(t, multiID(t, NameBitbucket, 1), repos[3].ID)
+	assert.Equal(t, NameBitbucket, repos[3].Provider)
+	assert.Equal(t, "PAY/app", repos[3].PathWithNamespace)
+}
+
+func TestMulti_ListMergeRequests(t *testing.T) {
+	host, _, _ := newMulti(t, false)
+	ctx := context.Background()
+
+	tests := []struct {
+		repoID   int
+		mrID     int
+		conflict bool
+		changes  int
+	}{
+		{repoID: 1, mrID: 5, conflict: true, changes: 1},
+		{repoID: multiID(t, NameGitea, 1), mrID: 6, conflict: true, changes: 2},
+		{repoID: multiID(t, NameBitbucket, 1), mrID: 7, conflict: true, changes: 0},
+	}
+	for _, tt := range tests {
+		mrs, err := host.ListMergeRequests(ctx, tt.repoID, "release", "master")
+		require.NoError(t, err)
+		require.Len(t, mrs, 1)
+		assert.Equal(t, tt.mrID, mrs[0].ID)
+		assert.Equal(t, tt.conflict, mrs[0].HasConflicts)
+
+		mrs, err = host.ListMergeRequestsWithOptions(ctx, tt.repoID, MergeRequestListOptions{SourceBranch: "release", TargetBranch: "master"})
+		require.NoError(t, err)
+		assert.Len(t, mrs, 1)
+
+		changes, err := host.GetMergeRequestChanges(ctx, tt.repoID, tt.mrID)
+		require.NoError(t, err)
+		assert.Equal(t, tt.changes, changes)
+	}
+}
+
+func TestMulti_ReorderedProviders(t *testing.T) {
+	host, _, _ := newMulti(t, false)
+	repos, err := host.ListRepositories(context.Background())
+	require.NoError(t, err)
+
+	// The same providers in another order, and without GitLab, keep the repository IDs
+	reordered := NewMulti(host.providers[2], host.providers[1])
+	reorderedRepos, err := reordered.ListRepositories(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reorderedRepos, 4)
+	assert.ElementsMatch(t, repos[1:], reorderedRepos)
+
+	mrs, err := reordered.ListMergeRequests(context.Background(), multiID(t, NameGitea, 1), "release", "master")
+	require.NoError(t, err)
+	require.Len(t, mrs, 1)
+	assert.Equal(t, 6, mrs[0].ID)
+}
+
+func TestMulti_UnknownRepository(t *testing.T) {
+	host, _, _ := newMulti(t, false)
+
+	_, err := host.ListMergeRequests(context.Background(), multiID(t, NameGitHub, 1), "release", "master")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "belongs to none of the configured providers")
+}
+
+func TestRepositoryID_OutOfRange(t *testing.T) {
+	_, err := repositoryID(NameGitLab, -1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside the range of combined repository IDs")
+
+	// Only a 64-bit int holds IDs past the range
+	if strconv.IntSize < 64 {
+		return
+	}
+	limit := int64(1) << repositoryIDShift
+	_, err = repositoryID(NameGitLab, int(limit))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside the range of combined repository IDs")
+
+	id, err := repositoryID(NameGitLab, int(limit-1))
+	require.NoError(t, err)
+	assert.Equal(t, int(limit-1), id)
+}
+
+func TestMulti_ListRepositoriesError(t *testing.T) {
+	_, giteaHost := newGitea(t, []string{"missing"})
+	_, bitbucketHost := newBitbucket(t, nil)
+
+	_, err := NewMulti(bitbucketHost, giteaHost).ListRepositories(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list gitea repositories")
+}
+
+func TestMulti_Comments(t *testing.T) {
+	host, giteaServer, bitbucketServer := newMulti(t, false)
+	ctx := context.Background()
+	giteaRepo := multiID(t, NameGitea, 1)
+	bitbucketRepo := multiID(t, NameBitbucket, 1)
+
+	giteaNote, err := host.CreateComment(ctx, giteaRepo, 6, "gitea")
+	require.NoError(t, err)
+	bitbucketNote, err := host.CreateComment(ctx, bitbucketRepo, 7, "bitbucket")
+	require.NoError(t, err)
+
+	_, err = host.UpdateComment(ctx, bitbucketRepo, 7, bitbucketNote.ID, "edited")
+	require.NoError(t, err)
+	notes, err := host.ListComments(ctx, bitbucketRepo, 7)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "edited", notes[0].Body)
+
+	require.NoError(t, host.DeleteComment(ctx, giteaRepo, 6, giteaNote.ID))
+	assert.Empty(t, giteaServer.Comments(1, 6))
+	assert.Len(t, bitbucketServer.Comments(1, 7), 1)
+}
+
+func TestMulti_SkippedWrites(t *testing.T) {
+	host, _, _ := newMulti(t, true)
+	ctx := context.Background()
+
+	_, err := host.CreateComment(ctx, 1, 5, "gitlab")
+	assert.True(t, IsReadOnly(err))
+	_, err = host.CreateComment(ctx, multiID(t, NameBitbucket, 1), 7, "bitbucket")
+	assert.True(t, IsReadOnly(err))
+
+	skipped := host.SkippedWrites()
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "Comment on PR #7 in PAY/app", skipped[1].Description)
+}
+
+func TestMulti_ConflictingFiles(t *testing.T) {
+	host, _, _ := newMulti(t, false)
+
+	_, err := host.ConflictingFiles(context.Background(), multiID(t, NameGitea, 1), models.MergeRequest{ID: 6})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gitea cannot list conflicting files")
+}
+
+func TestMulti_BranchProtected(t *testing.T) {
+	host, giteaServer, _ := newMulti(t, false)
+	giteaServer.ProtectBranch(1, "master")
+
+	protected, err := host.BranchProtected(context.Background(), multiID(t, NameGitea, 1), "master")
+	require.NoError(t, err)
+	assert.True(t, protected)
+
+	_, err = host.BranchProtected(context.Background(), multiID(t, NameBitbucket, 1), "master")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bitbucket cannot tell protected branches")
+}
@@ -0,0 +1,69 @@
+// Package provider puts the code hosting services the checker scans behind one interface, so that
+// the scanner, analyzer and comment action work the same on GitLab merge requests and on GitHub,
+// Gitea and Bitbucket Server pull requests. Pull requests are mapped onto models.MergeRequest with
+// GitLab's state and status names, and Multi scans several services into one report.
+package provider
+
+import (
+	"context"
+	"time"
+
+	"mr-conflict-checker/internal/httpapi"
+	"mr-conflict-checker/internal/models"
+)
+
+// Provider names, as used by the provider setting
+const (
+	NameGitLab    = "gitlab"
+	NameGitHub    = "github"
+	NameGitea     = "gitea"
+	NameBitbucket = "bitbucket"
+)
+
+// Provider is a code hosting service holding repositories and their merge requests
+type Provider interface {
+	// Name returns the provider name, one of the Name constants; a Multi joins the names of its providers with +
+	Name() string
+
+	ListRepositories(ctx context.Context) ([]models.Repository, error)
+	ListRepositoriesWithOptions(ctx context.Context, opts ListRepositoriesOptions) ([]models.Repository, error)
+	ListMergeRequests(ctx context.Context, repoID int, sourceBranch, targetBranch string) ([]models.MergeRequest, error)
+	ListMergeRequestsWithOptions(ctx context.Context, repoID int, opts MergeRequestListOptions) ([]models.MergeRequest, error)
+	// GetMergeRequestChanges returns the number of files a merge request changes
+	GetMergeRequestChanges(ctx context.Context, repoID, mrID int) (int, error)
+
+	ListComments(ctx context.Context, repoID, mrID int) ([]models.Note, error)
+	CreateComment(ctx context.Context, repoID, mrID int, body string) (*models.Note, error)
+	UpdateComment(ctx context.Context, repoID, mrID, commentID int, body string) (*models.Note, error)
+	DeleteComment(ctx context.Context, repoID, mrID, commentID int) error
+
+	// SkippedWrites returns the write requests refused so far in read-only mode
+	SkippedWrites() []models.SkippedWrite
+}
+
+// ListRepositoriesOptions filters the repositories returned by ListRepositoriesWithOptions
+type ListRepositoriesOptions struct {
+	LastActivityAfter time.Time // Only repositories with activity after this time, when set
+}
+
+// MergeRequestListOptions filters the merge requests returned by ListMergeRequestsWithOptions
+type MergeRequestListOptions struct {
+	State        string // "opened" (default), "closed", "merged" or "all"
+	SourceBranch string
+	TargetBranch string
+	Labels       []string  // Only merge requests carrying all of these labels
+	UpdatedAfter time.Time // Only merge requests updated after this time, when set
+}
+
+// IsReadOnly reports whether err comes from a write refused by a read-only provider
+func IsReadOnly(err error) bool {
+	return httpapi.IsReadOnly(err)
+}
+
+var (
+	_ Provider = (*GitLab)(nil)
+	_ Provider = (*GitHub)(nil)
+	_ Provider = (*Gitea)(nil)
+	_ Provider = (*Bitbucket)(nil)
+	_ Provider = (*Multi)(nil)
+)
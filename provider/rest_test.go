@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/bitbucket"
+	"mr-conflict-checker/gitea"
+	"mr-conflict-checker/github"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/bitbucketfake"
+	"mr-conflict-checker/internal/testing/giteafake"
+	"mr-conflict-checker/internal/testing/githubfake"
+)
+
+// newGitHub starts a fake GitHub server holding acme/app and octocat/dotfiles and returns a provider for it
+func newGitHub(t *testing.T, organizations []string, opts ...github.Option) (*githubfake.Server, *GitHub) {
+	t.Helper()
+	server := githubfake.NewServer()
+	t.Cleanup(server.Close)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}, LastActivityAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "dotfiles", Namespace: models.Namespace{Path: "octocat"}, LastActivityAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	})
+
+	opts = append([]github.Option{github.WithMergeabilityRetry(3, 0)}, opts...)
+	client := github.NewClient(server.URL(), githubfake.DefaultToken, opts...)
+	return server, NewGitHub(client, organizations)
+}
+
+// newGitea starts a fake Gitea server holding acme/app and alice/dotfiles and returns a provider for it
+func newGitea(t *testing.T, organizations []string, opts ...gitea.Option) (*giteafake.Server, *Gitea) {
+	t.Helper()
+	server := giteafake.NewServer()
+	t.Cleanup(server.Close)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{Path: "acme"}, LastActivityAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "dotfiles", Namespace: models.Namespace{Path: "alice"}, LastActivityAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	})
+
+	opts = append([]gitea.Option{gitea.WithConflictCheckRetry(2, 0)}, opts...)
+	client := gitea.NewClient(server.URL(), giteafake.DefaultToken, opts...)
+	return server, NewGitea(client, organizations)
+}
+
+// newBitbucket starts a fake Bitbucket server holding PAY/app and OPS/infra and returns a provider for it
+func newBitbucket(t *testing.T, projects []string, opts ...bitbucket.Option) (*bitbucketfake.Server, *Bitbucket) {
+	t.Helper()
+	server := bitbucketfake.NewServer()
+	t.Cleanup(server.Close)
+	server.SetRepositories([]models.Repository{
+		{ID: 1, Name: "app", Namespace: models.Namespace{ID: 1, Path: "PAY"}},
+		{ID: 2, Name: "infra", Namespace: models.Namespace{ID: 2, Path: "OPS"}},
+	})
+
+	client := bitbucket.NewClient(server.URL(), bitbucketfake.DefaultToken, opts...)
+	return server, NewBitbucket(client, projects)
+}
+
+// restService is one of the services behind a REST client, started on its fake server. Repository
+// 1 is active since 2024 and repository 2 before, where the service reports activity.
+type restService struct {
+	host Provider
+	// setPullRequests stores on repository 1 pull request 1 from release into master, conflicting,
+	// changing 3 files, labelled release where the service has labels and updated after since, and
+	// pull request 2 from feature into master,
+	// mergeable and updated before since
+	setPullRequests func(since time.Time)
+	comments        func() []models.Note // Comments on pull request 1 of repository 1
+	firstPath       func() string        // Path of the first request the server received
+}
+
+// restServices are the services behind the REST clients, with what sets them apart
+var restServices = []struct {
+	name       string
+	start      func(t *testing.T, scope []string, readOnly bool) restService
+	scope      string // Organization or project holding only repository 1
+	scopePath  string // Request listing the repositories of scope
+	repository string // Path of repository 1
+	activity   bool   // Whether the service reports the last activity of repositories
+	labels     bool   // Whether pull requests have labels
+}{
+	{
+		name: NameGitHub,
+		start: func(t *testing.T, scope []string, readOnly bool) restService {
+			var opts []github.Option
+			if readOnly {
+				opts = append(opts, github.WithReadOnly())
+			}
+			server, host := newGitHub(t, scope, opts...)
+			return restService{
+				host: host,
+				setPullRequests: func(since time.Time) {
+					server.SetPullRequests(1, []githubfake.PullRequest{
+						{Number: 1, Head: "release", Base: "master", MergeableState: github.MergeableStateDirty, ChangedFiles: 3, UpdatedAt: since.Add(time.Hour), Labels: []string{"release"}},
+						{Number: 2, Head: "feature", Base: "master", UpdatedAt: since.Add(-time.Hour)},
+					})
+				},
+				comments:  func() []models.Note { return server.Comments(1, 1) },
+				firstPath: func() string { return server.Requests()[0].Path },
+			}
+		},
+		scope:      "acme",
+		scopePath:  "/orgs/acme/repos",
+		repository: "acme/app",
+		activity:   true,
+		labels:     true,
+	},
+	{
+		name: NameGitea,
+		start: func(t *testing.T, scope []string, readOnly bool) restService {
+			var opts []gitea.Option
+			if readOnly {
+				opts = append(opts, gitea.WithReadOnly())
+			}
+			server, host := newGitea(t, scope, opts...)
+			return restService{
+				host: host,
+				setPullRequests: func(since time.Time) {
+					server.SetPullRequests(1, []giteafake.PullRequest{
+						{Number: 1, Head: "release", Base: "master", ChangedFiles: 3, UpdatedAt: since.Add(time.Hour), Labels: []string{"release"}},
+						{Number: 2, Head: "feature", Base: "master", Mergeable: true, UpdatedAt: since.Add(-time.Hour)},
+					})
+				},
+				comments:  func() []models.Note { return server.Comments(1, 1) },
+				firstPath: func() string { return server.Requests()[0].Path },
+			}
+		},
+		scope:      "acme",
+		scopePath:  "/api/v1/orgs/acme/repos",
+		repository: "acme/app",
+		activity:   true,
+		labels:     true,
+	},
+	{
+		name: NameBitbucket,
+		start: func(t *testing.T, scope []string, readOnly bool) restService {
+			var opts []bitbucket.Option
+			if readOnly {
+				opts = append(opts, bitbucket.WithReadOnly())
+			}
+			server, host := newBitbucket(t, scope, opts...)
+			return restService{
+				host: host,
+				setPullRequests: func(since time.Time) {
+					server.SetPullRequests(1, []bitbucketfake.PullRequest{
+						{ID: 1, Source: "release", Target: "master", Conflicted: true, ChangedFiles: 3, UpdatedAt: since.Add(time.Hour)},
+						{ID: 2, Source: "feature", Target: "master", UpdatedAt: since.Add(-time.Hour)},
+					})
+				},
+				comments:  func() []models.Note { return server.Comments(1, 1) },
+				firstPath: func() string { return server.Requests()[0].Path },
+			}
+		},
+		scope:      "PAY",
+		scopePath:  "/rest/api/1.0/projects/PAY/repos",
+		repository: "PAY/app",
+	},
+}
+
+func TestRESTProviders_ListRepositories(t *testing.T) {
+	for _, tt := range restServices {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.start(t, nil, false)
+			ctx := context.Background()
+
+			assert.Equal(t, tt.name, service.host.Name())
+
+			repos, err := service.host.ListRepositories(ctx)
+			require.NoError(t, err)
+			assert.Len(t, repos, 2)
+
+			repos, err = service.host.ListRepositoriesWithOptions(ctx, ListRepositoriesOptions{LastActivityAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+			require.NoError(t, err)
+			if !tt.activity {
+				// Without activity every repository counts as active
+				assert.Len(t, repos, 2)
+				return
+			}
+			require.Len(t, repos, 1)
+			assert.Equal(t, tt.repository, repos[0].PathWithNamespace)
+		})
+	}
+}
+
+func TestRESTProviders_ListRepositories_Scope(t *testing.T) {
+	for _, tt := range restServices {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.start(t, []string{tt.scope}, false)
+
+			repos, err := service.host.ListRepositories(context.Background())
+
+			require.NoError(t, err)
+			require.Len(t, repos, 1)
+			assert.Equal(t, tt.repository, repos[0].PathWithNamespace)
+			assert.Equal(t, tt.scopePath, service.firstPath())
+		})
+	}
+}
+
+func TestRESTProviders_ListMergeRequests(t *testing.T) {
+	since := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range restServices {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.start(t, nil, false)
+			service.setPullRequests(since)
+			ctx := context.Background()
+
+			mrs, err := service.host.ListMergeRequests(ctx, 1, "release", "master")
+			require.NoError(t, err)
+			require.Len(t, mrs, 1)
+			assert.True(t, mrs[0].HasConflicts)
+
+			opts := MergeRequestListOptions{UpdatedAfter: since}
+			if tt.labels {
+				opts.Labels = []string{"release"}
+			}
+			mrs, err = service.host.ListMergeRequestsWithOptions(ctx, 1, opts)
+			require.NoError(t, err)
+			require.Len(t, mrs, 1)
+			assert.Equal(t, 1, mrs[0].ID)
+
+			changes, err := service.host.GetMergeRequestChanges(ctx, 1, 1)
+			require.NoError(t, err)
+			assert.Equal(t, 3, changes)
+		})
+	}
+}
+
+func TestRESTProviders_Comments(t *testing.T) {
+	for _, tt := range restServices {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.start(t, nil, false)
+			service.setPullRequests(time.Now())
+			ctx := context.Background()
+
+			created, err := service.host.CreateComment(ctx, 1, 1, "first")
+			require.NoError(t, err)
+			_, err = service.host.UpdateComment(ctx, 1, 1, created.ID, "second")
+			require.NoError(t, err)
+
+			notes, err := service.host.ListComments(ctx, 1, 1)
+			require.NoError(t, err)
+			require.Len(t, notes, 1)
+			assert.Equal(t, "second", notes[0].Body)
+
+			require.NoError(t, service.host.DeleteComment(ctx, 1, 1, created.ID))
+			assert.Empty(t, service.comments())
+		})
+	}
+}
+
+func TestRESTProviders_ReadOnly(t *testing.T) {
+	for _, tt := range restServices {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.start(t, nil, true)
+			service.setPullRequests(time.Now())
+
+			_, err := service.host.CreateComment(context.Background(), 1, 1, "body")
+
+			assert.True(t, IsReadOnly(err))
+			require.Len(t, service.host.SkippedWrites(), 1)
+			assert.Equal(t, "Comment on PR #1 in "+tt.repository, service.host.SkippedWrites()[0].Description)
+			assert.Empty(t, service.comments())
+		})
+	}
+}
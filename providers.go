@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"mr-conflict-checker/bitbucket"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/gitea"
+	"mr-conflict-checker/github"
+	"mr-conflict-checker/gitlab"
+	"mr-conflict-checker/internal/cassette"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+// connectProvider connects to the configured code hosting services and checks their credentials;
+// several services are combined into one provider, in the configured order. The returned close
+// function releases the clients and saves a recorded cassette; it is never nil.
+func connectProvider(ctx context.Context, cfg *config.Config, opts runOptions, dryRun bool) (provider.Provider, *models.TokenInfo, func(), error) {
+	var cleanups []func()
+	closeAll := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	names := cfg.ProviderNames()
+	if len(names) > 1 && (opts.RecordPath != "" || opts.ReplayPath != "") {
+		return nil, nil, closeAll, fmt.Errorf("--record and --replay support a single provider, got %s", strings.Join(names, ", "))
+	}
+
+	var hosts []provider.Provider
+	var tokenInfo *models.TokenInfo
+	for _, name := range names {
+		var host provider.Provider
+		var err error
+		switch name {
+		case config.ProviderGitHub:
+			host, err = connectGitHub(ctx, cfg, opts, dryRun, &cleanups)
+		case config.ProviderGitea:
+			host, err = connectGitea(ctx, cfg, opts, dryRun, &cleanups)
+		case config.ProviderBitbucket:
+			host, err = connectBitbucket(ctx, cfg, opts, dryRun, &cleanups)
+		default:
+			host, tokenInfo, err = connectGitLab(ctx, cfg, opts, dryRun, &cleanups)
+		}
+		if err != nil {
+			return nil, nil, closeAll, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	if len(hosts) == 1 {
+		return hosts[0], tokenInfo, closeAll, nil
+	}
+	return provider.NewMulti(hosts...), tokenInfo, closeAll, nil
+}
+
+// connectGitLab creates the GitLab client, authenticates and verifies the token
+func connectGitLab(ctx context.Context, cfg *config.Config, opts runOptions, dryRun bool, cleanups *[]func()) (provider.Provider, *models.TokenInfo, error) {
+	slog.Debug("Initializing GitLab client")
+	httpClient, err := gitlab.NewHTTPClient(gitlab.TransportOptions{
+		CAFile:             cfg.GitLab.CAFile,
+		CertFile:           cfg.GitLab.CertFile,
+		KeyFile:            cfg.GitLab.KeyFile,
+		InsecureSkipVerify: cfg.GitLab.InsecureSkipVerify,
+		ProxyURL:           cfg.GitLab.ProxyURL,
+		NoProxy:            cfg.GitLab.NoProxy,
+		Timeout:            cfg.GitLab.Timeout,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure GitLab HTTP client: %w", err)
+	}
+	if cfg.GitLab.InsecureSkipVerify {
+		slog.Warn("!!! TLS CERTIFICATE VERIFICATION IS DISABLED (gitlab.insecure_skip_verify) - the GitLab token can be intercepted; use gitlab.ca_file instead !!!")
+	}
+
+	if err := useCassette(httpClient, opts, cleanups); err != nil {
+		return nil, nil, err
+	}
+
+	auth, err := newAuthenticator(ctx, cfg, httpClient, dryRun)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to authenticate to GitLab: %w", err)
+	}
+
+	clientOpts := []gitlab.Option{gitlab.WithHTTPClient(httpClient), gitlab.WithAuth(auth)}
+	if dryRun {
+		slog.Info("Dry-run mode enabled, write requests to GitLab will be skipped")
+		clientOpts = append(clientOpts, gitlab.WithReadOnly())
+	}
+
+	// Reuse unchanged GitLab responses between runs
+	cache, err := newCache(cfg.GitLab.Cache, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cache != nil {
+		clientOpts = append(clientOpts, gitlab.WithCache(cache))
+		*cleanups = append(*cleanups, func() {
+			stats := cache.Stats()
+			slog.Info("GitLab response cache", "hits", stats.Hits, "revalidated", stats.Revalidated, "misses", stats.Misses)
+		})
+	}
+
+	client := gitlab.NewClient(cfg.GitLab.URL, cfg.GitLab.Token, clientOpts...)
+	*cleanups = append(*cleanups, func() {
+		slog.Debug("Cleaning up GitLab client")
+		client.Close()
+	})
+
+	// Test connection
+	slog.Debug("Testing GitLab connection")
+	if err := client.TestConnection(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to GitLab: %w", err)
+	}
+	slog.Info("GitLab connection established successfully")
+
+	// Only access tokens can describe their own scopes and expiry
+	var tokenInfo *models.TokenInfo
+	if mode := cfg.GitLab.Auth.Mode; mode == "" || mode == config.AuthModeToken {
+		if tokenInfo, err = verifyToken(ctx, cfg, client, dryRun); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Scans read GitLab through REST or GraphQL; writes always use REST
+	var backend gitlab.Backend = client
+	if cfg.GitLab.API == config.APIGraphQL {
+		slog.Info("Reading projects and merge requests through the GraphQL API")
+		backend = gitlab.NewGraphQLBackend(client, gitlab.GraphQLOptions{
+			Prefetch: gitlab.MergeRequestListOptions{SourceBranch: models.CheckedSourceBranch, TargetBranch: models.CheckedTargetBranch},
+		})
+	}
+
+	return provider.NewGitLab(client, backend), tokenInfo, nil
+}
+
+// connectGitHub creates the GitHub client and checks that the token is accepted
+func connectGitHub(ctx context.Context, cfg *config.Config, opts runOptions, dryRun bool, cleanups *[]func()) (provider.Provider, error) {
+	slog.Debug("Initializing GitHub client")
+	timeout := cfg.GitHub.Timeout
+	if timeout == 0 {
+		timeout = github.DefaultTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if err := useCassette(httpClient, opts, cleanups); err != nil {
+		return nil, err
+	}
+
+	clientOpts := []github.Option{github.WithHTTPClient(httpClient)}
+	if dryRun {
+		slog.Info("Dry-run mode enabled, write requests to GitHub will be skipped")
+		clientOpts = append(clientOpts, github.WithReadOnly())
+	}
+	client := github.NewClient(cfg.GitHub.URL, cfg.GitHub.AccessToken(), clientOpts...)
+
+	slog.Debug("Testing GitHub connection")
+	if err := client.TestConnection(ctx); err != nil {
+		return nil, err
+	}
+	slog.Info("GitHub connection established successfully")
+
+	return provider.NewGitHub(client, cfg.GitHub.Organizations), nil
+}
+
+// connectGitea creates the Gitea client and checks that the token is accepted
+func connectGitea(ctx context.Context, cfg *config.Config, opts runOptions, dryRun bool, cleanups *[]func()) (provider.Provider, error) {
+	slog.Debug("Initializing Gitea client")
+	timeout := cfg.Gitea.Timeout
+	if timeout == 0 {
+		timeout = gitea.DefaultTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if err := useCassette(httpClient, opts, cleanups); err != nil {
+		return nil, err
+	}
+
+	clientOpts := []gitea.Option{gitea.WithHTTPClient(httpClient)}
+	if dryRun {
+		slog.Info("Dry-run mode enabled, write requests to Gitea will be skipped")
+		clientOpts = append(clientOpts, gitea.WithReadOnly())
+	}
+	client := gitea.NewClient(cfg.Gitea.URL, cfg.Gitea.AccessToken(), clientOpts...)
+
+	slog.Debug("Testing Gitea connection")
+	if err := client.TestConnection(ctx); err != nil {
+		return nil, err
+	}
+	slog.Info("Gitea connection established successfully")
+
+	return provider.NewGitea(client, cfg.Gitea.Organizations), nil
+}
+
+// connectBitbucket creates the Bitbucket Server client and checks that the token is accepted
+func connectBitbucket(ctx context.Context, cfg *config.Config, opts runOptions, dryRun bool, cleanups *[]func()) (provider.Provider, error) {
+	slog.Debug("Initializing Bitbucket client")
+	timeout := cfg.Bitbucket.Timeout
+	if timeout == 0 {
+		timeout = bitbucket.DefaultTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+	if err := useCassette(httpClient, opts, cleanups); err != nil {
+		return nil, err
+	}
+
+	clientOpts := []bitbucket.Option{bitbucket.WithHTTPClient(httpClient)}
+	if dryRun {
+		slog.Info("Dry-run mode enabled, write requests to Bitbucket will be skipped")
+		clientOpts = append(clientOpts, bitbucket.WithReadOnly())
+	}
+	client := bitbucket.NewClient(cfg.Bitbucket.URL, cfg.Bitbucket.AccessToken(), clientOpts...)
+
+	slog.Debug("Testing Bitbucket connection")
+	if err := client.TestConnection(ctx); err != nil {
+		return nil, err
+	}
+	slog.Info("Bitbucket connection established successfully")
+
+	return provider.NewBitbucket(client, cfg.Bitbucket.Projects), nil
+}
+
+// useCassette routes the traffic of httpClient through a cassette when recording or replaying; a
+// recording is saved by the cleanup it adds
+func useCassette(httpClient *http.Client, opts runOptions, cleanups *[]func()) error {
+	switch {
+	case opts.ReplayPath != "":
+		recorded, err := cassette.Load(opts.ReplayPath)
+		if err != nil {
+			return err
+		}
+		slog.Info("Replaying responses from cassette", "cassette", opts.ReplayPath, "interactions", len(recorded.Interactions))
+		httpClient.Transport = cassette.NewReplayer(recorded)
+	case opts.RecordPath != "":
+		recorder := cassette.NewRecorder(httpClient.Transport)
+		httpClient.Transport = recorder
+		*cleanups = append(*cleanups, func() {
+			if err := recorder.Cassette().Save(opts.RecordPath); err != nil {
+				slog.Warn("Failed to save cassette", "cassette", opts.RecordPath, "error", err)
+				return
+			}
+			slog.Info("Recorded responses to cassette", "cassette", opts.RecordPath)
+		})
+	}
+	return nil
+}
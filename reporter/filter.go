@@ -0,0 +1,91 @@
+package reporter
+
+import (
+	"strings"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+// FilterReport moves the conflicting merge requests the filter does not keep to FilteredOutMRs and
+// recomputes the summary; repositories left without conflicts become accessible, like those whose
+// MRs do not conflict
+func FilterReport(report *models.Report, filter config.FilterConfig) {
+	if !filter.Enabled() {
+		return
+	}
+
+	report.RepositoriesWithConflicts = 0
+	report.TotalConflictingMRs = 0
+	for i := range report.Repositories {
+		repoReport := &report.Repositories[i]
+		if repoReport.Status != models.StatusConflicts {
+			continue
+		}
+
+		kept := make([]models.MergeRequest, 0, len(repoReport.ConflictingMRs))
+		for _, mr := range repoReport.ConflictingMRs {
+			if Matches(mr, filter) {
+				kept = append(kept, mr)
+			} else {
+				repoReport.FilteredOutMRs = append(repoReport.FilteredOutMRs, mr)
+				report.FilteredMRs++
+			}
+		}
+		repoReport.ConflictingMRs = kept
+
+		if len(kept) == 0 {
+			repoReport.Status = models.StatusAccessible
+			continue
+		}
+		report.RepositoriesWithConflicts++
+		report.TotalConflictingMRs += len(kept)
+	}
+}
+
+// Matches returns true if the filter keeps the merge request
+func Matches(mr models.MergeRequest, filter config.FilterConfig) bool {
+	if filter.ExcludeDrafts && mr.IsDraft() {
+		return false
+	}
+	for _, label := range filter.Labels {
+		if !mr.HasLabel(label) {
+			return false
+		}
+	}
+	for _, label := range filter.ExcludeLabels {
+		if mr.HasLabel(label) {
+			return false
+		}
+	}
+	if len(filter.Assignees) > 0 && !assignedToAny(mr, filter.Assignees) {
+		return false
+	}
+	if len(filter.Milestones) > 0 && !plannedForAny(mr, filter.Milestones) {
+		return false
+	}
+	return true
+}
+
+// assignedToAny reports whether one of the usernames is an assignee of the merge request
+func assignedToAny(mr models.MergeRequest, usernames []string) bool {
+	for _, username := range usernames {
+		if mr.IsAssignedTo(username) {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedForAny reports whether the merge request is planned for one of the milestone titles
+func plannedForAny(mr models.MergeRequest, titles []string) bool {
+	if mr.Milestone == nil {
+		return false
+	}
+	for _, title := range titles {
+		if strings.EqualFold(mr.Milestone.Title, title) {
+			return true
+		}
+	}
+	return false
+}
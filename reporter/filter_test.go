@@ -0,0 +1,84 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+)
+
+func TestMatches(t *testing.T) {
+	mr := models.MergeRequest{
+		Labels:    []string{"release", "backend"},
+		Assignees: []models.Author{{Username: "alice"}},
+		Milestone: &models.Milestone{Title: "1.2"},
+	}
+
+	tests := []struct {
+		name     string
+		mr       models.MergeRequest
+		filter   config.FilterConfig
+		expected bool
+	}{
+		{name: "no filter", mr: mr, expected: true},
+		{name: "draft kept", mr: models.MergeRequest{Draft: true}, expected: true},
+		{name: "draft excluded", mr: models.MergeRequest{Draft: true}, filter: config.FilterConfig{ExcludeDrafts: true}, expected: false},
+		{name: "work in progress excluded", mr: models.MergeRequest{WorkInProgress: true}, filter: config.FilterConfig{ExcludeDrafts: true}, expected: false},
+		{name: "every label", mr: mr, filter: config.FilterConfig{Labels: []string{"Release", "backend"}}, expected: true},
+		{name: "missing label", mr: mr, filter: config.FilterConfig{Labels: []string{"release", "frontend"}}, expected: false},
+		{name: "excluded label", mr: mr, filter: config.FilterConfig{ExcludeLabels: []string{"wontfix", "backend"}}, expected: false},
+		{name: "assignee", mr: mr, filter: config.FilterConfig{Assignees: []string{"bob", "alice"}}, expected: true},
+		{name: "other assignee", mr: mr, filter: config.FilterConfig{Assignees: []string{"bob"}}, expected: false},
+		{name: "milestone", mr: mr, filter: config.FilterConfig{Milestones: []string{"1.2"}}, expected: true},
+		{name: "no milestone", mr: models.MergeRequest{}, filter: config.FilterConfig{Milestones: []string{"1.2"}}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Matches(tt.mr, tt.filter))
+		})
+	}
+}
+
+func TestFilterReport(t *testing.T) {
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1, Name: "app"}, []models.MergeRequest{
+		{ID: 1, Title: "Release"},
+		{ID: 2, Title: "Draft: Release", Draft: true},
+	}, models.StatusConflicts, "")
+	report.AddRepository(models.Repository{ID: 2, Name: "api"}, []models.MergeRequest{
+		{ID: 3, Title: "Draft: Release", Draft: true},
+	}, models.StatusConflicts, "")
+	report.AddRepository(models.Repository{ID: 3, Name: "web"}, []models.MergeRequest{}, models.StatusError, "boom")
+
+	FilterReport(report, config.FilterConfig{ExcludeDrafts: true})
+
+	assert.Equal(t, 3, report.TotalRepositories)
+	assert.Equal(t, 1, report.RepositoriesWithConflicts)
+	assert.Equal(t, 1, report.TotalConflictingMRs)
+	assert.Equal(t, 2, report.FilteredMRs)
+	require.Len(t, report.Repositories[0].ConflictingMRs, 1)
+	assert.Equal(t, 1, report.Repositories[0].ConflictingMRs[0].ID)
+	assert.Equal(t, models.StatusAccessible, report.Repositories[1].Status)
+	assert.Empty(t, report.Repositories[1].ConflictingMRs)
+
+	// Filtered out merge requests are kept aside, so they do not count as resolved
+	require.Len(t, report.Repositories[0].FilteredOutMRs, 1)
+	assert.Equal(t, 2, report.Repositories[0].FilteredOutMRs[0].ID)
+	require.Len(t, report.HeldEntries(), 2)
+	assert.Equal(t, 3, report.HeldEntries()[1].MergeRequest.ID)
+	assert.Equal(t, models.StatusError, report.Repositories[2].Status)
+}
+
+func TestFilterReport_Disabled(t *testing.T) {
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1, Name: "app"}, []models.MergeRequest{{ID: 1, Draft: true}}, models.StatusConflicts, "")
+
+	FilterReport(report, config.FilterConfig{})
+
+	assert.Equal(t, 1, report.TotalConflictingMRs)
+	assert.Zero(t, report.FilteredMRs)
+}
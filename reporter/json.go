@@ -0,0 +1,75 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
+)
+
+// JSONReportPath returns the JSON report path that accompanies a markdown report
+func JSONReportPath(markdownPath string) string {
+	return strings.TrimSuffix(markdownPath, filepath.Ext(markdownPath)) + ".json"
+}
+
+// WriteJSONReport writes the report as JSON so later runs can compare against it
+func WriteJSONReport(report *models.Report, path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(redact.Content(string(data))), 0644); err != nil {
+		return fmt.Errorf("failed to write JSON report file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadJSONReport reads a report previously written by WriteJSONReport
+func LoadJSONReport(path string) (*models.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON report: %w", err)
+	}
+
+	var report models.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON report %s: %w", path, err)
+	}
+
+	return &report, nil
+}
+
+// ListJSONReports returns the JSON reports in a directory, oldest first
+func ListJSONReports(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "MR-conflict-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JSON reports: %w", err)
+	}
+
+	// Timestamps in file names sort lexicographically in chronological order
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// FindLatestJSONReport returns the most recent JSON report in a directory, or "" if there is none
+func FindLatestJSONReport(dir string) (string, error) {
+	paths, err := ListJSONReports(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+	return paths[len(paths)-1], nil
+}
@@ -0,0 +1,60 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/internal/models"
+)
+
+func TestJSONReportPath(t *testing.T) {
+	assert.Equal(t, "/tmp/MR-conflict-2024-01-01T00-00-00.json", JSONReportPath("/tmp/MR-conflict-2024-01-01T00-00-00.md"))
+	assert.Equal(t, "/tmp/MR-conflict-2024-01-01T00-00-00_1.json", JSONReportPath("/tmp/MR-conflict-2024-01-01T00-00-00_1.md"))
+}
+
+func TestWriteAndLoadJSONReport(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "MR-conflict-2024-01-01T00-00-00.json")
+
+	report := &models.Report{Timestamp: "2024-01-01T00-00-00"}
+	report.AddRepository(
+		models.Repository{ID: 1, Name: "repo", WebURL: "https://gitlab.example.com/repo"},
+		[]models.MergeRequest{{ID: 5, Title: "Release", HasConflicts: true, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		models.StatusConflicts, "")
+
+	require.NoError(t, WriteJSONReport(report, path))
+
+	loaded, err := LoadJSONReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report.Timestamp, loaded.Timestamp)
+	assert.Equal(t, 1, loaded.TotalConflictingMRs)
+	require.Len(t, loaded.Repositories, 1)
+	assert.Equal(t, models.StatusConflicts, loaded.Repositories[0].Status)
+	assert.Equal(t, 5, loaded.Repositories[0].ConflictingMRs[0].ID)
+}
+
+func TestFindLatestJSONReport(t *testing.T) {
+	tempDir := t.TempDir()
+
+	latest, err := FindLatestJSONReport(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+
+	for _, name := range []string{
+		"MR-conflict-2024-01-02T00-00-00.json",
+		"MR-conflict-2024-01-01T00-00-00.json",
+		"MR-conflict-2024-01-02T00-00-00_1.json",
+		"MR-conflict-2024-01-03T00-00-00.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, name), []byte("{}"), 0644))
+	}
+
+	latest, err = FindLatestJSONReport(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, "MR-conflict-2024-01-02T00-00-00_1.json"), latest)
+}
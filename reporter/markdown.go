@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
 )
 
 // GenerateReport creates a markdown report file with the given report data
@@ -58,20 +59,54 @@ func generateMarkdownContent(report *models.Report) string {
 
 	// Header with timestamp
 	content.WriteString(fmt.Sprintf("# MR Conflict Report - %s\n\n", report.Timestamp))
+	if report.TokenExpiresAt != "" {
+		content.WriteString(fmt.Sprintf("**GitLab token expires**: %s\n\n", report.TokenExpiresAt))
+	}
+	if report.IncrementalSince != "" {
+		content.WriteString(fmt.Sprintf("**Incremental scan**: only activity since %s was rescanned\n\n", report.IncrementalSince))
+	}
 
 	// Summary statistics
 	content.WriteString("## Summary\n")
 	content.WriteString(fmt.Sprintf("- Total Repositories Scanned: %d\n", report.TotalRepositories))
 	content.WriteString(fmt.Sprintf("- Repositories with Conflicts: %d\n", report.RepositoriesWithConflicts))
-	content.WriteString(fmt.Sprintf("- Total Conflicting MRs: %d\n\n", report.TotalConflictingMRs))
+	content.WriteString(fmt.Sprintf("- Total Conflicting MRs: %d\n", report.TotalConflictingMRs))
+	if report.FilteredMRs > 0 {
+		content.WriteString(fmt.Sprintf("- Conflicting MRs Filtered Out: %d\n", report.FilteredMRs))
+	}
+	if report.TotalSuppressedMRs > 0 {
+		content.WriteString(fmt.Sprintf("- Suppressed MRs: %d\n", report.TotalSuppressedMRs))
+	}
+	if report.TotalAcknowledgedMRs > 0 {
+		content.WriteString(fmt.Sprintf("- Acknowledged MRs: %d\n", report.TotalAcknowledgedMRs))
+	}
+	if report.SLABreaches > 0 {
+		content.WriteString(fmt.Sprintf("- SLA Breaches: %d\n", report.SLABreaches))
+	}
+	content.WriteString("\n")
+
+	// Changes since the previous report, when one was available
+	if report.Changes != nil {
+		content.WriteString(generateChangesSection(report.Changes, "##"))
+	}
+
+	// Writes refused by the read-only client
+	if report.DryRun {
+		content.WriteString(generateSkippedWritesSection(report.SkippedWrites))
+	}
 
 	// Repository details
 	content.WriteString("## Repository Details\n\n")
 
-	// Sort repositories by name for consistent output
+	// Sort repositories by their most severe conflict when scored, then by name for consistent output
 	sortedRepos := make([]models.RepositoryReport, len(report.Repositories))
 	copy(sortedRepos, report.Repositories)
 	sort.Slice(sortedRepos, func(i, j int) bool {
+		si, _ := sortedRepos[i].HighestSeverity()
+		sj, _ := sortedRepos[j].HighestSeverity()
+		if si.Score != sj.Score {
+			return si.Score > sj.Score
+		}
 		return sortedRepos[i].Repository.Name < sortedRepos[j].Repository.Name
 	})
 
@@ -79,7 +114,11 @@ func generateMarkdownContent(report *models.Report) string {
 		content.WriteString(generateRepositorySection(repoReport))
 	}
 
-	return content.String()
+	// Conflicts matched by an ignore rule, collapsed below the details
+	content.WriteString(generateSuppressedSection(sortedRepos))
+
+	// Error messages and skipped writes may quote API responses
+	return redact.Content(content.String())
 }
 
 // generateRepositorySection creates the markdown section for a single repository
@@ -99,26 +138,77 @@ func generateRepositorySection(repoReport models.RepositoryReport) string {
 
 	// Add error message if present
 	if repoReport.ErrorMessage != "" {
-		section.WriteString(fmt.Sprintf("**Error**: %s\n", repoReport.ErrorMessage))
+		section.WriteString(fmt.Sprintf("**Error**: %s\n", redact.String(repoReport.ErrorMessage)))
+	}
+
+	// Link the back-merge MR opened for the conflicts
+	if repoReport.BackMergeMR != nil {
+		section.WriteString(fmt.Sprintf("**Back-merge MR**: [%s](%s)\n", repoReport.BackMergeMR.Title, repoReport.BackMergeMR.WebURL))
 	}
 
 	// Add conflicting MRs if any
 	if len(repoReport.ConflictingMRs) > 0 {
 		section.WriteString("\n#### Conflicting Merge Requests\n")
 
-		// Sort MRs by creation date (newest first)
+		// Sort MRs by severity when scored, then by creation date (newest first)
 		sortedMRs := make([]models.MergeRequest, len(repoReport.ConflictingMRs))
 		copy(sortedMRs, repoReport.ConflictingMRs)
 		sort.Slice(sortedMRs, func(i, j int) bool {
+			si, _ := repoReport.Severity(sortedMRs[i].ID)
+			sj, _ := repoReport.Severity(sortedMRs[j].ID)
+			if si.Score != sj.Score {
+				return si.Score > sj.Score
+			}
 			return sortedMRs[i].CreatedAt.After(sortedMRs[j].CreatedAt)
 		})
 
 		for _, mr := range sortedMRs {
-			section.WriteString(fmt.Sprintf("- ❌ [%s](%s) - Author: %s - Created: %s\n",
+			acknowledgement, acknowledged := repoReport.Acknowledgement(mr.ID)
+			icon := "❌"
+			if acknowledged {
+				icon = "💤"
+			}
+			section.WriteString(fmt.Sprintf("- %s [%s](%s) - Author: %s - Created: %s",
+				icon,
 				mr.Title,
 				mr.WebURL,
 				mr.Author.Name,
 				mr.CreatedAt.Format("2006-01-02 15:04:05")))
+			if mr.UpdatedAt.After(mr.CreatedAt) {
+				section.WriteString(fmt.Sprintf(" - Updated: %s", mr.UpdatedAt.Format("2006-01-02 15:04:05")))
+			}
+			section.WriteString("\n")
+			if severity, ok := repoReport.Severity(mr.ID); ok {
+				section.WriteString(fmt.Sprintf("  - %s\n", severityDetails(severity)))
+			}
+			if details := mergeRequestDetails(mr); details != "" {
+				section.WriteString(fmt.Sprintf("  - %s\n", details))
+			}
+			if acknowledged {
+				section.WriteString(fmt.Sprintf("  - Acknowledged by @%s until %s: %s\n",
+					acknowledgement.By,
+					acknowledgement.ExpiresAt.Format("2006-01-02 15:04:05"),
+					acknowledgement.Reason))
+			}
+			if downgrade, ok := repoReport.Downgrade(mr.ID); ok {
+				section.WriteString(fmt.Sprintf("  - Downgraded: %s\n", downgrade.Reason))
+			}
+		}
+	}
+
+	// Add server-side rebases triggered for this repository
+	if len(repoReport.Rebases) > 0 {
+		section.WriteString("\n#### Rebased Merge Requests\n")
+		for _, rebase := range repoReport.Rebases {
+			mr := rebase.MergeRequest
+			switch rebase.Status {
+			case models.RebaseSucceeded:
+				section.WriteString(fmt.Sprintf("- 🔄 [%s](%s) - Rebased onto %s\n", mr.Title, mr.WebURL, mr.TargetBranch))
+			case models.RebaseTimedOut:
+				section.WriteString(fmt.Sprintf("- ⏳ [%s](%s) - Rebase still in progress: %s\n", mr.Title, mr.WebURL, redact.String(rebase.Error)))
+			default:
+				section.WriteString(fmt.Sprintf("- ⚠️ [%s](%s) - Rebase failed: %s\n", mr.Title, mr.WebURL, redact.String(rebase.Error)))
+			}
 		}
 	}
 
@@ -126,6 +216,208 @@ func generateRepositorySection(repoReport models.RepositoryReport) string {
 	return section.String()
 }
 
+// generateSuppressedSection lists the suppressed merge requests with their reasons in a collapsed
+// block, or returns an empty string when there are none
+func generateSuppressedSection(repoReports []models.RepositoryReport) string {
+	var lines []string
+	for _, repoReport := range repoReports {
+		suppressed := make([]models.SuppressedMR, len(repoReport.SuppressedMRs))
+		copy(suppressed, repoReport.SuppressedMRs)
+		sort.SliceStable(suppressed, func(i, j int) bool {
+			return suppressed[i].MergeRequest.CreatedAt.After(suppressed[j].MergeRequest.CreatedAt)
+		})
+		for _, s := range suppressed {
+			mr := s.MergeRequest
+			lines = append(lines, fmt.Sprintf("- 🔕 [%s](%s) in %s - Author: %s - Created: %s - Reason: %s\n",
+				mr.Title,
+				mr.WebURL,
+				repoReport.Repository.Name,
+				mr.Author.Name,
+				mr.CreatedAt.Format("2006-01-02 15:04:05"),
+				s.Reason))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var section strings.Builder
+	section.WriteString("## Suppressed\n\n")
+	section.WriteString(fmt.Sprintf("<details>\n<summary>%d conflicting MRs matched an ignore rule</summary>\n\n", len(lines)))
+	for _, line := range lines {
+		section.WriteString(line)
+	}
+	section.WriteString("\n</details>\n\n")
+	return section.String()
+}
+
+// severityIcons marks each severity level
+var severityIcons = map[string]string{
+	models.SeverityLow:      "⚪",
+	models.SeverityMedium:   "🟡",
+	models.SeverityHigh:     "🟠",
+	models.SeverityCritical: "🔴",
+}
+
+// severityDetails renders the severity of a merge request, since when it conflicts and its SLA on one line
+func severityDetails(severity models.Severity) string {
+	details := fmt.Sprintf("%s Severity: %s (%d) - In conflict since: %s - Conflicting files: %d",
+		severityIcons[severity.Level],
+		severity.Level,
+		severity.Score,
+		severity.InConflictSince.Format("2006-01-02 15:04:05"),
+		severity.ConflictingFiles)
+	switch {
+	case severity.Breached:
+		details += fmt.Sprintf(" - ⏰ SLA breached on %s", severity.SLADeadline.Format("2006-01-02 15:04:05"))
+	case !severity.SLADeadline.IsZero():
+		details += fmt.Sprintf(" - SLA: %s", severity.SLADeadline.Format("2006-01-02 15:04:05"))
+	}
+	return details
+}
+
+// mergeRequestDetails renders the draft state, people, labels, milestone and pipeline of a merge
+// request on one line, or an empty string when it has none of them
+func mergeRequestDetails(mr models.MergeRequest) string {
+	var details []string
+	if mr.IsDraft() {
+		details = append(details, "📝 Draft")
+	}
+	if len(mr.Assignees) > 0 {
+		details = append(details, "Assignees: "+mentions(mr.Assignees))
+	}
+	if len(mr.Reviewers) > 0 {
+		details = append(details, "Reviewers: "+mentions(mr.Reviewers))
+	}
+	if len(mr.Labels) > 0 {
+		labels := make([]string, len(mr.Labels))
+		for i, label := range mr.Labels {
+			labels[i] = "`" + label + "`"
+		}
+		details = append(details, "Labels: "+strings.Join(labels, ", "))
+	}
+	if mr.Milestone != nil && mr.Milestone.Title != "" {
+		milestone := mr.Milestone.Title
+		if mr.Milestone.DueDate != "" {
+			milestone += fmt.Sprintf(" (due %s)", mr.Milestone.DueDate)
+		}
+		details = append(details, "Milestone: "+milestone)
+	}
+	if mr.HeadPipeline != nil && mr.HeadPipeline.Status != "" {
+		details = append(details, "Pipeline: "+mr.HeadPipeline.Status)
+	}
+	return strings.Join(details, " - ")
+}
+
+// mentions renders users as @username, falling back to the name
+func mentions(users []models.Author) string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		if user.Username != "" {
+			names[i] = "@" + user.Username
+		} else {
+			names[i] = user.Name
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// GenerateChangesMarkdown renders a standalone markdown document for a report diff
+func GenerateChangesMarkdown(reportDiff *models.ReportDiff) string {
+	var content strings.Builder
+
+	content.WriteString("# MR Conflict Changes\n\n")
+	content.WriteString(generateChangesSection(reportDiff, "##"))
+
+	return redact.Content(content.String())
+}
+
+// generateSkippedWritesSection creates the "Actions that would have been taken" section of a dry run
+func generateSkippedWritesSection(writes []models.SkippedWrite) string {
+	var section strings.Builder
+
+	section.WriteString("## Actions that would have been taken\n")
+	section.WriteString("This was a dry run; no changes were made in GitLab.\n")
+	if len(writes) == 0 {
+		section.WriteString("No write actions were needed.\n\n")
+		return section.String()
+	}
+
+	for _, write := range writes {
+		section.WriteString(fmt.Sprintf("- %s (`%s %s`)\n", write.Description, write.Method, write.Path))
+	}
+	section.WriteString("\n")
+	return section.String()
+}
+
+// generateChangesSection creates the "Changes since previous report" section
+func generateChangesSection(reportDiff *models.ReportDiff, heading string) string {
+	var section strings.Builder
+
+	section.WriteString(fmt.Sprintf("%s Changes since previous report\n", heading))
+	if reportDiff.PreviousTimestamp == "" {
+		section.WriteString("No previous report found; all conflicts are reported as new.\n")
+	} else {
+		section.WriteString(fmt.Sprintf("Compared with report from %s\n", reportDiff.PreviousTimestamp))
+	}
+	section.WriteString(fmt.Sprintf("- New Conflicts: %d\n", len(reportDiff.New)))
+	section.WriteString(fmt.Sprintf("- Resolved Conflicts: %d\n", len(reportDiff.Resolved)))
+	section.WriteString(fmt.Sprintf("- Still Conflicting: %d\n\n", len(reportDiff.Persisting)))
+
+	groups := []struct {
+		title   string
+		icon    string
+		verb    string
+		changes []models.ConflictChange
+	}{
+		{"New Conflicts", "🆕", "Conflicting for", reportDiff.New},
+		{"Resolved Conflicts", "✅", "Was conflicting for", reportDiff.Resolved},
+		{"Still Conflicting", "⏳", "Conflicting for", reportDiff.Persisting},
+	}
+
+	for _, group := range groups {
+		if len(group.changes) == 0 {
+			continue
+		}
+
+		section.WriteString(fmt.Sprintf("%s# %s\n", heading, group.title))
+		for _, change := range group.changes {
+			section.WriteString(fmt.Sprintf("- %s [%s](%s) in [%s](%s) - Author: %s - %s: %s\n",
+				group.icon,
+				change.MergeRequest.Title,
+				change.MergeRequest.WebURL,
+				change.RepositoryName,
+				change.RepositoryURL,
+				change.MergeRequest.Author.Name,
+				group.verb,
+				formatDuration(change.Persisted())))
+		}
+		section.WriteString("\n")
+	}
+
+	return section.String()
+}
+
+// formatDuration renders a duration in days, hours and minutes for report readers
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "less than a minute"
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
 // handleFileConflict generates a unique filename when a file already exists
 func handleFileConflict(originalPath string) string {
 	dir := filepath.Dir(originalPath)
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/redact"
 )
 
 // **Feature: mr-conflict-checker, Property 6: File Naming Consistency**
@@ -472,6 +473,172 @@ func TestGenerateRepositorySection_WithConflicts(t *testing.T) {
 	assert.Contains(t, section, "Created: 2024-01-01 12:00:00")
 }
 
+func TestGenerateRepositorySection_MergeRequestDetails(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repoReport := models.RepositoryReport{
+		Repository: models.Repository{ID: 1, Name: "test-repo", WebURL: "https://gitlab.example.com/test-repo"},
+		ConflictingMRs: []models.MergeRequest{
+			{
+				ID:           1,
+				Title:        "Draft: Release",
+				Author:       models.Author{Name: "Test Author"},
+				WebURL:       "https://gitlab.example.com/test-repo/-/merge_requests/1",
+				CreatedAt:    created,
+				UpdatedAt:    created.Add(26 * time.Hour),
+				Draft:        true,
+				Assignees:    []models.Author{{Name: "Carol", Username: "carol"}, {Name: "Erin"}},
+				Reviewers:    []models.Author{{Name: "Dave", Username: "dave"}},
+				Labels:       []string{"release", "backend"},
+				Milestone:    &models.Milestone{Title: "1.2", DueDate: "2024-02-01"},
+				HeadPipeline: &models.Pipeline{Status: "failed"},
+			},
+			{ID: 2, Title: "Plain", WebURL: "https://gitlab.example.com/test-repo/-/merge_requests/2", CreatedAt: created.Add(-time.Hour), UpdatedAt: created.Add(-time.Hour)},
+		},
+		Status: models.StatusConflicts,
+	}
+
+	section := generateRepositorySection(repoReport)
+
+	assert.Contains(t, section, "Created: 2024-01-01 12:00:00 - Updated: 2024-01-02 14:00:00\n")
+	assert.Contains(t, section, "  - 📝 Draft - Assignees: @carol, Erin - Reviewers: @dave - Labels: `release`, `backend` - Milestone: 1.2 (due 2024-02-01) - Pipeline: failed\n")
+	assert.Contains(t, section, "[Plain](https://gitlab.example.com/test-repo/-/merge_requests/2) - Author:  - Created: 2024-01-01 11:00:00\n\n")
+}
+
+func TestGenerateMarkdownContent_FilteredMRs(t *testing.T) {
+	content := generateMarkdownContent(&models.Report{Timestamp: "2024-01-01T00-00-00", FilteredMRs: 3})
+	assert.Contains(t, content, "- Conflicting MRs Filtered Out: 3\n")
+
+	content = generateMarkdownContent(&models.Report{Timestamp: "2024-01-01T00-00-00"})
+	assert.NotContains(t, content, "Filtered Out")
+}
+
+func TestGenerateMarkdownContent_Suppressed(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := &models.Report{Timestamp: "2024-01-01T00-00-00"}
+	report.AddRepository(models.Repository{ID: 1, Name: "web", SuppressedMRs: []models.SuppressedMR{
+		{MergeRequest: models.MergeRequest{Title: "Older", WebURL: "https://gitlab.example.com/web/-/merge_requests/1", Author: models.Author{Name: "Alice"}, CreatedAt: created}, Reason: `label "on-hold"`},
+		{MergeRequest: models.MergeRequest{Title: "Newer", WebURL: "https://gitlab.example.com/web/-/merge_requests/2", Author: models.Author{Name: "Bob"}, CreatedAt: created.Add(time.Hour)}, Reason: "draft"},
+	}}, []models.MergeRequest{}, models.StatusAccessible, "")
+
+	content := generateMarkdownContent(report)
+
+	assert.Contains(t, content, "- Suppressed MRs: 2\n")
+	assert.Contains(t, content, "## Suppressed\n\n<details>\n<summary>2 conflicting MRs matched an ignore rule</summary>\n\n"+
+		"- 🔕 [Newer](https://gitlab.example.com/web/-/merge_requests/2) in web - Author: Bob - Created: 2024-01-01 13:00:00 - Reason: draft\n"+
+		"- 🔕 [Older](https://gitlab.example.com/web/-/merge_requests/1) in web - Author: Alice - Created: 2024-01-01 12:00:00 - Reason: label \"on-hold\"\n"+
+		"\n</details>\n")
+	assert.Less(t, strings.Index(content, "## Repository Details"), strings.Index(content, "## Suppressed"))
+
+	content = generateMarkdownContent(&models.Report{Timestamp: "2024-01-01T00-00-00"})
+	assert.NotContains(t, content, "Suppressed")
+}
+
+func TestGenerateRepositorySection_Acknowledged(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repoReport := models.RepositoryReport{
+		Repository: models.Repository{ID: 1, Name: "web", WebURL: "https://gitlab.example.com/web"},
+		ConflictingMRs: []models.MergeRequest{
+			{ID: 1, Title: "Known", WebURL: "https://gitlab.example.com/web/-/merge_requests/1", Author: models.Author{Name: "Alice"}, CreatedAt: created},
+			{ID: 2, Title: "Unknown", WebURL: "https://gitlab.example.com/web/-/merge_requests/2", Author: models.Author{Name: "Bob"}, CreatedAt: created},
+		},
+		Status: models.StatusConflicts,
+		Acknowledgements: []models.Acknowledgement{
+			{MergeRequestID: 1, By: "lead", Reason: "fix after the freeze", ExpiresAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	section := generateRepositorySection(repoReport)
+
+	assert.Contains(t, section, "- 💤 [Known](https://gitlab.example.com/web/-/merge_requests/1) - Author: Alice - Created: 2024-01-01 12:00:00\n"+
+		"  - Acknowledged by @lead until 2024-02-01 00:00:00: fix after the freeze\n")
+	assert.Contains(t, section, "- ❌ [Unknown](https://gitlab.example.com/web/-/merge_requests/2) - Author: Bob - Created: 2024-01-01 12:00:00\n")
+	assert.Equal(t, 1, strings.Count(section, "Acknowledged by"))
+}
+
+func TestGenerateRepositorySection_Downgraded(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repoReport := models.RepositoryReport{
+		Repository: models.Repository{Name: "web"},
+		ConflictingMRs: []models.MergeRequest{
+			{ID: 1, Title: "Bot release", WebURL: "https://gitlab.example.com/web/-/merge_requests/1", Author: models.Author{Name: "Bot"}, CreatedAt: created},
+		},
+		Status:     models.StatusConflicts,
+		Downgrades: []models.Downgrade{{MergeRequestID: 1, Reason: `author "bot"`}},
+	}
+
+	section := generateRepositorySection(repoReport)
+
+	assert.Contains(t, section, "- ❌ [Bot release](https://gitlab.example.com/web/-/merge_requests/1) - Author: Bot - Created: 2024-01-01 12:00:00\n"+
+		"  - Downgraded: author \"bot\"\n")
+}
+
+func TestGenerateMarkdownContent_Severity(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := &models.Report{Timestamp: "2024-01-05T00-00-00"}
+	report.AddRepository(models.Repository{ID: 1, Name: "api"}, []models.MergeRequest{
+		{ID: 1, Title: "Mild", Author: models.Author{Name: "Alice"}, CreatedAt: created.Add(time.Hour)},
+	}, models.StatusConflicts, "")
+	report.AddRepository(models.Repository{ID: 2, Name: "web"}, []models.MergeRequest{
+		{ID: 1, Title: "Newer", Author: models.Author{Name: "Bob"}, CreatedAt: created.Add(time.Hour)},
+		{ID: 2, Title: "Urgent", Author: models.Author{Name: "Carol"}, CreatedAt: created},
+	}, models.StatusConflicts, "")
+	report.Repositories[0].Severities = []models.Severity{
+		{MergeRequestID: 1, Score: 10, Level: models.SeverityLow, InConflictSince: created},
+	}
+	report.Repositories[1].Severities = []models.Severity{
+		{MergeRequestID: 1, Score: 25, Level: models.SeverityMedium, InConflictSince: created, ConflictingFiles: 1,
+			SLADeadline: time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)},
+		{MergeRequestID: 2, Score: 80, Level: models.SeverityCritical, InConflictSince: created, ConflictingFiles: 4,
+			SLADeadline: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), Breached: true},
+	}
+	report.SLABreaches = 1
+
+	content := generateMarkdownContent(report)
+
+	assert.Contains(t, content, "- SLA Breaches: 1\n")
+	assert.Contains(t, content, "- ❌ [Urgent]() - Author: Carol - Created: 2024-01-01 12:00:00\n"+
+		"  - 🔴 Severity: critical (80) - In conflict since: 2024-01-01 12:00:00 - Conflicting files: 4 - ⏰ SLA breached on 2024-01-03 12:00:00\n")
+	assert.Contains(t, content, "  - 🟡 Severity: medium (25) - In conflict since: 2024-01-01 12:00:00 - Conflicting files: 1 - SLA: 2024-01-08 12:00:00\n")
+	assert.Contains(t, content, "  - ⚪ Severity: low (10) - In conflict since: 2024-01-01 12:00:00 - Conflicting files: 0\n")
+	// The most severe conflicts come first, across and within repositories
+	assert.Less(t, strings.Index(content, "### [web]"), strings.Index(content, "### [api]"))
+	assert.Less(t, strings.Index(content, "[Urgent]"), strings.Index(content, "[Newer]"))
+}
+
+func TestGenerateRepositorySection_WithBackMerge(t *testing.T) {
+	repoReport := models.RepositoryReport{
+		Repository:     models.Repository{ID: 1, Name: "test-repo", WebURL: "https://gitlab.example.com/test-repo"},
+		ConflictingMRs: []models.MergeRequest{{ID: 1, Title: "Test MR", WebURL: "https://gitlab.example.com/test-repo/-/merge_requests/1"}},
+		Status:         models.StatusConflicts,
+		BackMergeMR: &models.MergeRequest{
+			ID:     2,
+			Title:  "Back-merge master into release",
+			WebURL: "https://gitlab.example.com/test-repo/-/merge_requests/2",
+		},
+	}
+
+	section := generateRepositorySection(repoReport)
+
+	assert.Contains(t, section, "**Back-merge MR**: [Back-merge master into release](https://gitlab.example.com/test-repo/-/merge_requests/2)")
+}
+
+func TestGenerateRepositorySection_WithRebases(t *testing.T) {
+	repoReport := models.RepositoryReport{
+		Repository: models.Repository{ID: 1, Name: "test-repo", WebURL: "https://gitlab.example.com/test-repo"},
+		Status:     models.StatusAccessible,
+		Rebases: []models.RebaseResult{
+			{MergeRequest: models.MergeRequest{Title: "Release 1.2", WebURL: "https://gitlab.example.com/mr/1", TargetBranch: "master"}, Status: models.RebaseSucceeded},
+			{MergeRequest: models.MergeRequest{Title: "Release 1.3", WebURL: "https://gitlab.example.com/mr/2"}, Status: models.RebaseFailed, Error: "rebase failed"},
+		},
+	}
+
+	section := generateRepositorySection(repoReport)
+
+	assert.Contains(t, section, "#### Rebased Merge Requests")
+	assert.Contains(t, section, "- 🔄 [Release 1.2](https://gitlab.example.com/mr/1) - Rebased onto master")
+	assert.Contains(t, section, "- ⚠️ [Release 1.3](https://gitlab.example.com/mr/2) - Rebase failed: rebase failed")
+}
+
 func TestHandleFileConflict(t *testing.T) {
 	// Create a temporary directory
 	tempDir := t.TempDir()
@@ -515,3 +682,142 @@ func TestGenerateReport_DirectoryCreation(t *testing.T) {
 	// Clean up
 	os.RemoveAll(tempDir)
 }
+
+func TestGenerateMarkdownContent_WithChanges(t *testing.T) {
+	report := &models.Report{
+		Timestamp: "2024-01-02T00-00-00",
+		Changes: &models.ReportDiff{
+			PreviousTimestamp: "2024-01-01T00-00-00",
+			New: []models.ConflictChange{{
+				RepositoryName: "repo",
+				RepositoryURL:  "https://gitlab.example.com/repo",
+				MergeRequest:   models.MergeRequest{ID: 2, Title: "New MR", WebURL: "https://gitlab.example.com/repo/-/merge_requests/2"},
+			}},
+			Persisting: []models.ConflictChange{{
+				RepositoryName: "repo",
+				RepositoryURL:  "https://gitlab.example.com/repo",
+				MergeRequest:   models.MergeRequest{ID: 1, Title: "Old MR", WebURL: "https://gitlab.example.com/repo/-/merge_requests/1"},
+				FirstSeen:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				LastSeen:       time.Date(2024, 1, 3, 5, 0, 0, 0, time.UTC),
+			}},
+		},
+	}
+
+	content := generateMarkdownContent(report)
+
+	assert.Contains(t, content, "## Changes since previous report")
+	assert.Contains(t, content, "Compared with report from 2024-01-01T00-00-00")
+	assert.Contains(t, content, "- New Conflicts: 1")
+	assert.Contains(t, content, "- Resolved Conflicts: 0")
+	assert.Contains(t, content, "### New Conflicts")
+	assert.Contains(t, content, "[New MR](https://gitlab.example.com/repo/-/merge_requests/2)")
+	assert.Contains(t, content, "### Still Conflicting")
+	assert.Contains(t, content, "Conflicting for: 2d 5h")
+	assert.NotContains(t, content, "### Resolved Conflicts")
+}
+
+func TestGenerateMarkdownContent_DryRun(t *testing.T) {
+	report := &models.Report{
+		Timestamp: "2024-01-15T10-30-00",
+		DryRun:    true,
+		SkippedWrites: []models.SkippedWrite{
+			{Method: "POST", Path: "/api/v4/projects/1/merge_requests/5/notes", Description: "Comment on MR !5 in project 1"},
+		},
+	}
+
+	content := generateMarkdownContent(report)
+
+	assert.Contains(t, content, "## Actions that would have been taken")
+	assert.Contains(t, content, "- Comment on MR !5 in project 1 (`POST /api/v4/projects/1/merge_requests/5/notes`)")
+
+	report.SkippedWrites = nil
+	assert.Contains(t, generateMarkdownContent(report), "No write actions were needed.")
+
+	report.DryRun = false
+	assert.NotContains(t, generateMarkdownContent(report), "Actions that would have been taken")
+}
+
+// **Feature: mr-conflict-checker, Property 12: Report Redaction**
+func TestProperty_NoTokenReachesReports(t *testing.T) {
+	t.Cleanup(redact.SaveSecrets())
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("secrets and GitLab tokens never appear in the written markdown or JSON reports", prop.ForAll(
+		func(secret, tokenBody, context string) bool {
+			redact.AddSecret(secret)
+			token := "glpat-" + tokenBody
+
+			report := &models.Report{Timestamp: "2024-01-15T10-30-00", DryRun: true}
+			report.AddRepository(
+				models.Repository{ID: 1, Name: "repo-" + secret, WebURL: "https://gitlab.example.com/" + secret},
+				[]models.MergeRequest{{ID: 5, Title: context + secret, HasConflicts: true}},
+				models.StatusConflicts, "")
+			report.AddRepository(models.Repository{ID: 2, Name: "broken"}, nil, models.StatusError, "API error 401: "+context+" "+token)
+			report.SkippedWrites = []models.SkippedWrite{{Method: "POST", Path: "/api/v4/projects/1/hooks", Description: "POST with " + secret}}
+
+			markdownPath, err := GenerateReport(report, t.TempDir())
+			if err != nil {
+				return false
+			}
+			jsonPath := JSONReportPath(markdownPath)
+			if err := WriteJSONReport(report, jsonPath); err != nil {
+				return false
+			}
+
+			for _, path := range []string{markdownPath, jsonPath} {
+				data, err := os.ReadFile(path)
+				if err != nil || strings.Contains(string(data), secret) || strings.Contains(string(data), token) {
+					return false
+				}
+			}
+			return true
+		},
+		gen.AlphaString().SuchThat(func(s string) bool { return len(s) >= 12 }),
+		gen.Identifier().SuchThat(func(s string) bool { return len(s) >= 20 }),
+		gen.AlphaString(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
+func TestGenerateReport_KeepsHeaderWordsInTitles(t *testing.T) {
+	titles := []string{"Fix authorization: add roles for admins", "Refactor job-token=handling in CI", "Authorization: Bearer scopes for releases"}
+	mrs := make([]models.MergeRequest, len(titles))
+	for i, title := range titles {
+		mrs[i] = models.MergeRequest{ID: i + 1, Title: title, HasConflicts: true}
+	}
+	report := &models.Report{Timestamp: "2024-01-15T10-30-00"}
+	report.AddRepository(models.Repository{ID: 1, Name: "repo"}, mrs, models.StatusConflicts, "")
+
+	markdownPath, err := GenerateReport(report, t.TempDir())
+	require.NoError(t, err)
+	jsonPath := JSONReportPath(markdownPath)
+	require.NoError(t, WriteJSONReport(report, jsonPath))
+
+	markdown, err := os.ReadFile(markdownPath)
+	require.NoError(t, err)
+	loaded, err := LoadJSONReport(jsonPath)
+	require.NoError(t, err)
+	for i, title := range titles {
+		assert.Contains(t, string(markdown), title)
+		assert.Equal(t, title, loaded.Repositories[0].ConflictingMRs[i].Title)
+	}
+}
+
+func TestGenerateMarkdownContent_TokenExpiry(t *testing.T) {
+	report := &models.Report{Timestamp: "2024-01-15T10-30-00", TokenExpiresAt: "2024-01-20"}
+
+	assert.Contains(t, generateMarkdownContent(report), "# MR Conflict Report - 2024-01-15T10-30-00\n\n**GitLab token expires**: 2024-01-20\n")
+
+	report.TokenExpiresAt = ""
+	assert.NotContains(t, generateMarkdownContent(report), "GitLab token expires")
+}
+
+func TestGenerateMarkdownContent_Incremental(t *testing.T) {
+	report := &models.Report{Timestamp: "2024-01-15T10-30-00", IncrementalSince: "2024-01-15T09:00:00Z"}
+
+	assert.Contains(t, generateMarkdownContent(report), "**Incremental scan**: only activity since 2024-01-15T09:00:00Z was rescanned\n")
+
+	report.IncrementalSince = ""
+	assert.NotContains(t, generateMarkdownContent(report), "Incremental scan")
+}
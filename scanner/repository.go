@@ -4,19 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
-	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
 )
 
 // RepositoryScanner handles scanning repositories for merge request conflicts
 type RepositoryScanner struct {
-	client        *gitlab.Client
+	client        provider.Provider
 	includeGroups []int
 }
 
-// NewRepositoryScanner creates a new repository scanner with the provided GitLab client
-func NewRepositoryScanner(client *gitlab.Client, includeGroups []int) *RepositoryScanner {
+// NewRepositoryScanner creates a new repository scanner reading repositories from the provider
+func NewRepositoryScanner(client provider.Provider, includeGroups []int) *RepositoryScanner {
 	return &RepositoryScanner{
 		client:        client,
 		includeGroups: includeGroups,
@@ -44,6 +45,28 @@ func (rs *RepositoryScanner) ScanRepositories(ctx context.Context) ([]models.Rep
 	return processedRepos, nil
 }
 
+// activityInterval is how often GitLab refreshes a project's last_activity_at at most; active
+// repositories are looked up this much further back so that none are missed
+const activityInterval = time.Hour
+
+// ScanActiveRepositories retrieves the repositories with activity since the given time, without
+// looking at their merge requests; repositories without activity are left out
+func (rs *RepositoryScanner) ScanActiveRepositories(ctx context.Context, since time.Time) ([]models.Repository, error) {
+	repos, err := rs.client.ListRepositoriesWithOptions(ctx, provider.ListRepositoriesOptions{
+		LastActivityAfter: since.Add(-activityInterval),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve active repositories: %w", err)
+	}
+
+	filteredRepos := rs.filterRepositories(repos)
+	for i := range filteredRepos {
+		filteredRepos[i].Status = models.StatusAccessible
+	}
+
+	return filteredRepos, nil
+}
+
 // processRepository determines the status of a single repository
 func (rs *RepositoryScanner) processRepository(ctx context.Context, repo models.Repository) models.Repository {
 	// Initialize repository with accessible status
@@ -51,7 +74,7 @@ func (rs *RepositoryScanner) processRepository(ctx context.Context, repo models.
 	repo.Error = nil
 
 	// Try to get merge requests for this repository
-	mrs, err := rs.client.ListMergeRequests(ctx, repo.ID, "release", "master")
+	mrs, err := rs.client.ListMergeRequests(ctx, repo.ID, models.CheckedSourceBranch, models.CheckedTargetBranch)
 	if err != nil {
 		// Log the error but continue processing
 		log.Printf("Error accessing repository %s (ID: %d): %v", repo.Name, repo.ID, err)
@@ -84,7 +107,8 @@ func (rs *RepositoryScanner) processRepository(ctx context.Context, repo models.
 	return repo
 }
 
-// filterRepositories keeps only repositories from included groups (whitelist)
+// filterRepositories keeps only repositories from included groups (whitelist); groups are GitLab's,
+// so repositories of the other providers of a combined scan are always kept
 func (rs *RepositoryScanner) filterRepositories(repos []models.Repository) []models.Repository {
 	// If no include groups specified, include all repositories
 	if len(rs.includeGroups) == 0 {
@@ -100,7 +124,7 @@ func (rs *RepositoryScanner) filterRepositories(repos []models.Repository) []mod
 	// Filter repositories - only keep those in included groups
 	var filtered []models.Repository
 	for _, repo := range repos {
-		if includeMap[repo.Namespace.ID] {
+		if includeMap[repo.Namespace.ID] || (repo.Provider != "" && repo.Provider != provider.NameGitLab) {
 			filtered = append(filtered, repo)
 		} else {
 			log.Printf("Excluding repository %s from group %s (ID: %d) - not in whitelist", repo.Name, repo.Namespace.Name, repo.Namespace.ID)
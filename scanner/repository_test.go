@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,8 +17,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"mr-conflict-checker/gitea"
 	"mr-conflict-checker/gitlab"
 	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/internal/testing/giteafake"
+	"mr-conflict-checker/internal/testing/gitlabfake"
+	"mr-conflict-checker/provider"
 )
 
 // **Feature: mr-conflict-checker, Property 5: Report Inclusion Completeness**
@@ -37,7 +42,7 @@ func TestProperty_ReportInclusionCompleteness(t *testing.T) {
 			// Create client and scanner
 			client := gitlab.NewClient(server.URL, "test-token")
 			defer client.Close()
-			scanner := NewRepositoryScanner(client)
+			scanner := NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 			// Scan repositories
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -177,9 +182,9 @@ func createMockServerWithVariousStates(repos []models.Repository, errorIndices,
 
 		default:
 			// Handle merge request endpoints
-			if len(r.URL.Path) > 20 && r.URL.Path[:20] == "/api/v4/projects/" {
+			if strings.HasPrefix(r.URL.Path, "/api/v4/projects/") {
 				// Extract project ID from path
-				pathParts := r.URL.Path[20:] // Remove "/api/v4/projects/"
+				pathParts := strings.TrimPrefix(r.URL.Path, "/api/v4/projects/")
 				var projectID int
 				if _, err := fmt.Sscanf(pathParts, "%d/merge_requests", &projectID); err == nil {
 					// Check if this repository should return an error
@@ -246,9 +251,10 @@ func TestNewRepositoryScanner(t *testing.T) {
 	client := gitlab.NewClient("https://gitlab.example.com", "test-token")
 	defer client.Close()
 
-	scanner := NewRepositoryScanner(client)
+	host := provider.NewGitLab(client, client)
+	scanner := NewRepositoryScanner(host, nil)
 	assert.NotNil(t, scanner)
-	assert.Equal(t, client, scanner.client)
+	assert.Equal(t, host, scanner.client)
 }
 
 func TestRepositoryScanner_ScanRepositories_Success(t *testing.T) {
@@ -263,7 +269,7 @@ func TestRepositoryScanner_ScanRepositories_Success(t *testing.T) {
 
 	client := gitlab.NewClient(server.URL, "test-token")
 	defer client.Close()
-	scanner := NewRepositoryScanner(client)
+	scanner := NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 	ctx := context.Background()
 	scannedRepos, err := scanner.ScanRepositories(ctx)
@@ -292,7 +298,7 @@ func TestRepositoryScanner_ScanRepositories_WithErrors(t *testing.T) {
 
 	client := gitlab.NewClient(server.URL, "test-token")
 	defer client.Close()
-	scanner := NewRepositoryScanner(client)
+	scanner := NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 	ctx := context.Background()
 	scannedRepos, err := scanner.ScanRepositories(ctx)
@@ -317,7 +323,7 @@ func TestRepositoryScanner_GetRepositoryCount(t *testing.T) {
 
 	client := gitlab.NewClient(server.URL, "test-token")
 	defer client.Close()
-	scanner := NewRepositoryScanner(client)
+	scanner := NewRepositoryScanner(provider.NewGitLab(client, client), nil)
 
 	ctx := context.Background()
 	count, err := scanner.GetRepositoryCount(ctx)
@@ -325,3 +331,61 @@ func TestRepositoryScanner_GetRepositoryCount(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 5, count)
 }
+
+func TestRepositoryScanner_ScanActiveRepositories(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	server := gitlabfake.NewServer()
+	defer server.Close()
+	server.SetProjects([]models.Repository{
+		{ID: 1, Name: "quiet", Namespace: models.Namespace{ID: 10}, LastActivityAt: since.Add(-2 * time.Hour)},
+		{ID: 2, Name: "throttled", Namespace: models.Namespace{ID: 10}, LastActivityAt: since.Add(-30 * time.Minute)},
+		{ID: 3, Name: "busy", Namespace: models.Namespace{ID: 10}, LastActivityAt: since.Add(time.Hour)},
+		{ID: 4, Name: "other-group", Namespace: models.Namespace{ID: 20}, LastActivityAt: since.Add(time.Hour)},
+	})
+
+	client := gitlab.NewClient(server.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+	scanner := NewRepositoryScanner(provider.NewGitLab(client, client), []int{10})
+
+	repos, err := scanner.ScanActiveRepositories(context.Background(), since)
+	require.NoError(t, err)
+
+	// Activity up to an hour before is included, as GitLab only refreshes it hourly
+	require.Len(t, repos, 2)
+	assert.Equal(t, "throttled", repos[0].Name)
+	assert.Equal(t, "busy", repos[1].Name)
+	assert.Equal(t, models.StatusAccessible, repos[0].Status)
+
+	// Merge requests are left to the analyzer
+	assert.Len(t, server.Requests(), 1)
+}
+
+func TestRepositoryScanner_IncludeGroupsOnlyFilterGitLab(t *testing.T) {
+	gitLabServer := gitlabfake.NewServer()
+	defer gitLabServer.Close()
+	gitLabServer.SetProjects([]models.Repository{
+		{ID: 1, Name: "included", Namespace: models.Namespace{ID: 10}},
+		{ID: 2, Name: "other-group", Namespace: models.Namespace{ID: 20}},
+	})
+	client := gitlab.NewClient(gitLabServer.URL(), gitlabfake.DefaultToken)
+	defer client.Close()
+
+	giteaServer := giteafake.NewServer()
+	defer giteaServer.Close()
+	// The owner ID matches no included group, which must not matter for Gitea repositories
+	giteaServer.SetRepositories([]models.Repository{{ID: 1, Name: "app", Namespace: models.Namespace{ID: 20, Path: "acme"}}})
+
+	host := provider.NewMulti(provider.NewGitLab(client, client), provider.NewGitea(gitea.NewClient(giteaServer.URL(), giteafake.DefaultToken), nil))
+	scanner := NewRepositoryScanner(host, []int{10})
+
+	repos, err := scanner.ScanRepositories(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, repos, 2)
+	assert.Equal(t, "included", repos[0].Name)
+	assert.Equal(t, "app", repos[1].Name)
+	assert.Equal(t, provider.NameGitea, repos[1].Provider)
+	// The merge requests are read from Gitea, which has none
+	assert.Equal(t, models.StatusNoMRs, repos[1].Status)
+}
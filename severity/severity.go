@@ -0,0 +1,195 @@
+// Package severity scores conflicting merge requests and flags those in conflict for longer than
+// the SLA of their namespace.
+package severity
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+// Points of each factor; a score adds them up to at most 100
+const (
+	pointsPerDayInConflict  = 5
+	maxInConflictPoints     = 35
+	daysPerAgePoint         = 2
+	maxAgePoints            = 15
+	pointsPerConflictedFile = 4
+	maxConflictedFilePoints = 20
+	protectedTargetPoints   = 15
+	failedPipelinePoints    = 15
+	pendingPipelinePoints   = 5
+)
+
+// Lowest score of each level above low
+const (
+	mediumScore   = 20
+	highScore     = 45
+	criticalScore = 70
+)
+
+// Factors are what the severity of a conflicting merge request depends on
+type Factors struct {
+	InConflict       time.Duration // Since the conflict was first seen
+	Age              time.Duration // Since the merge request was opened
+	ConflictingFiles int
+	ProtectedTarget  bool   // Targets a branch the provider protects
+	PipelineStatus   string // Status of the head pipeline, empty when there is none
+}
+
+// Score combines the factors into a score from 0 to 100
+func Score(f Factors) int {
+	const day = 24 * time.Hour
+
+	score := min(int(f.InConflict/day)*pointsPerDayInConflict, maxInConflictPoints)
+	score += min(int(f.Age/day)/daysPerAgePoint, maxAgePoints)
+	score += min(f.ConflictingFiles*pointsPerConflictedFile, maxConflictedFilePoints)
+	if f.ProtectedTarget {
+		score += protectedTargetPoints
+	}
+	switch f.PipelineStatus {
+	case "", "success", "skipped":
+	case "failed":
+		score += failedPipelinePoints
+	default:
+		// Running, pending, canceled or waiting for a manual job
+		score += pendingPipelinePoints
+	}
+	return score
+}
+
+// Level returns the severity level of a score
+func Level(score int) string {
+	switch {
+	case score >= criticalScore:
+		return models.SeverityCritical
+	case score >= highScore:
+		return models.SeverityHigh
+	case score >= mediumScore:
+		return models.SeverityMedium
+	default:
+		return models.SeverityLow
+	}
+}
+
+// conflictingFilesFinder is implemented by the providers that can tell which files conflict
+type conflictingFilesFinder interface {
+	ConflictingFiles(ctx context.Context, repoID int, mr models.MergeRequest) ([]string, error)
+}
+
+// branchProtectionChecker is implemented by the providers that can tell whether a branch is protected
+type branchProtectionChecker interface {
+	BranchProtected(ctx context.Context, repoID int, branch string) (bool, error)
+}
+
+// targetBranch identifies a branch of a repository
+type targetBranch struct {
+	repoID int
+	branch string
+}
+
+// Scorer scores the conflicting merge requests of a report
+type Scorer struct {
+	client    provider.Provider
+	cfg       config.SeverityConfig
+	protected map[targetBranch]bool // Protection of the target branches looked up so far
+}
+
+// NewScorer creates a scorer from its configuration
+func NewScorer(client provider.Provider, cfg config.SeverityConfig) *Scorer {
+	return &Scorer{client: client, cfg: cfg, protected: make(map[targetBranch]bool)}
+}
+
+// Run scores every conflicting merge request of the report at now and counts the SLA breaches;
+// the report changes tell since when each merge request conflicts
+func (s *Scorer) Run(ctx context.Context, report *models.Report, now time.Time) {
+	report.SLABreaches = 0
+	for i := range report.Repositories {
+		repoReport := &report.Repositories[i]
+		repoReport.Severities = nil
+		sla := s.cfg.SLAFor(repoReport.Repository.PathWithNamespace)
+
+		for _, mr := range repoReport.ConflictingMRs {
+			severity := s.score(ctx, report.Changes, repoReport.Repository, mr, now)
+			if _, ok := repoReport.Downgrade(mr.ID); ok {
+				// Downgraded by an ignore rule
+				severity.Score = min(severity.Score, mediumScore-1)
+				severity.Level = models.SeverityLow
+			}
+			if sla > 0 {
+				severity.SLADeadline = severity.InConflictSince.Add(sla)
+				severity.Breached = now.After(severity.SLADeadline)
+			}
+			if severity.Breached {
+				report.SLABreaches++
+			}
+			repoReport.Severities = append(repoReport.Severities, severity)
+		}
+	}
+}
+
+// score computes the severity of a single conflicting merge request
+func (s *Scorer) score(ctx context.Context, changes *models.ReportDiff, repo models.Repository, mr models.MergeRequest, now time.Time) models.Severity {
+	since, ok := changes.FirstSeen(models.ConflictKey(repo.ID, mr.ID))
+	if !ok || since.IsZero() || since.After(now) {
+		since = now
+	}
+
+	factors := Factors{
+		InConflict:       now.Sub(since),
+		ConflictingFiles: s.conflictingFiles(ctx, repo, mr),
+		ProtectedTarget:  s.protectedTarget(ctx, repo, mr.TargetBranch),
+	}
+	if !mr.CreatedAt.IsZero() && mr.CreatedAt.Before(now) {
+		factors.Age = now.Sub(mr.CreatedAt)
+	}
+	if mr.HeadPipeline != nil {
+		factors.PipelineStatus = mr.HeadPipeline.Status
+	}
+
+	score := Score(factors)
+	return models.Severity{
+		MergeRequestID:   mr.ID,
+		Score:            score,
+		Level:            Level(score),
+		InConflictSince:  since,
+		ConflictingFiles: factors.ConflictingFiles,
+	}
+}
+
+// conflictingFiles counts the files a merge request conflicts on, falling back to the files it
+// changes when the provider cannot tell
+func (s *Scorer) conflictingFiles(ctx context.Context, repo models.Repository, mr models.MergeRequest) int {
+	if finder, ok := s.client.(conflictingFilesFinder); ok {
+		files, err := finder.ConflictingFiles(ctx, repo.ID, mr)
+		if err == nil {
+			return len(files)
+		}
+		slog.Debug("Could not determine conflicting files, counting changed files", "repository", repo.Name, "mr", mr.ID, "error", err)
+	}
+	return int(mr.ChangesCount)
+}
+
+// protectedTarget reports whether the provider protects a target branch, looking each branch up once;
+// branches the provider cannot tell about are scored as unprotected
+func (s *Scorer) protectedTarget(ctx context.Context, repo models.Repository, branch string) bool {
+	key := targetBranch{repoID: repo.ID, branch: branch}
+	if protected, ok := s.protected[key]; ok {
+		return protected
+	}
+
+	protected := false
+	if checker, ok := s.client.(branchProtectionChecker); ok {
+		var err error
+		protected, err = checker.BranchProtected(ctx, repo.ID, branch)
+		if err != nil {
+			slog.Debug("Could not determine whether the target branch is protected", "repository", repo.Name, "branch", branch, "error", err)
+		}
+	}
+	s.protected[key] = protected
+	return protected
+}
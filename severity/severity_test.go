@@ -0,0 +1,178 @@
+package severity
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mr-conflict-checker/config"
+	"mr-conflict-checker/internal/models"
+	"mr-conflict-checker/provider"
+)
+
+const day = 24 * time.Hour
+
+// fakeFinder is a provider that lists conflicting files, failing for merge requests it does not know,
+// and tells the protected branches of each repository
+type fakeFinder struct {
+	provider.Provider
+	files     map[int][]string
+	protected map[int][]string // Repository ID -> protected branches
+	lookups   map[int]int      // Repository ID -> branch protection lookups
+}
+
+func (f fakeFinder) ConflictingFiles(_ context.Context, _ int, mr models.MergeRequest) ([]string, error) {
+	files, ok := f.files[mr.ID]
+	if !ok {
+		return nil, errors.New("unknown merge request")
+	}
+	return files, nil
+}
+
+func (f fakeFinder) BranchProtected(_ context.Context, repoID int, branch string) (bool, error) {
+	f.lookups[repoID]++
+	return slices.Contains(f.protected[repoID], branch), nil
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		factors Factors
+		score   int
+	}{
+		{name: "fresh", factors: Factors{}, score: 0},
+		{name: "in conflict for two days", factors: Factors{InConflict: 2*day + time.Hour}, score: 10},
+		{name: "in conflict for a month", factors: Factors{InConflict: 30 * day}, score: 35},
+		{name: "opened ten days ago", factors: Factors{Age: 10 * day}, score: 5},
+		{name: "opened a year ago", factors: Factors{Age: 365 * day}, score: 15},
+		{name: "two conflicting files", factors: Factors{ConflictingFiles: 2}, score: 8},
+		{name: "many conflicting files", factors: Factors{ConflictingFiles: 40}, score: 20},
+		{name: "protected target", factors: Factors{ProtectedTarget: true}, score: 15},
+		{name: "failed pipeline", factors: Factors{PipelineStatus: "failed"}, score: 15},
+		{name: "running pipeline", factors: Factors{PipelineStatus: "running"}, score: 5},
+		{name: "passed pipeline", factors: Factors{PipelineStatus: "success"}, score: 0},
+		{
+			name:    "everything",
+			factors: Factors{InConflict: 30 * day, Age: 365 * day, ConflictingFiles: 40, ProtectedTarget: true, PipelineStatus: "failed"},
+			score:   100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.score, Score(tt.factors))
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, models.SeverityLow, Level(0))
+	assert.Equal(t, models.SeverityLow, Level(19))
+	assert.Equal(t, models.SeverityMedium, Level(20))
+	assert.Equal(t, models.SeverityHigh, Level(45))
+	assert.Equal(t, models.SeverityCritical, Level(70))
+	assert.Equal(t, models.SeverityCritical, Level(100))
+}
+
+func TestScorer_Run(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1, Name: "ledger", PathWithNamespace: "payments/ledger"}, []models.MergeRequest{
+		{ID: 1, TargetBranch: "master", CreatedAt: now.Add(-10 * day), HeadPipeline: &models.Pipeline{Status: "failed"}},
+		{ID: 2, TargetBranch: "master", CreatedAt: now.Add(-time.Hour), ChangesCount: 3},
+	}, models.StatusConflicts, "")
+	report.AddRepository(models.Repository{ID: 2, Name: "app", PathWithNamespace: "web/app"}, []models.MergeRequest{
+		{ID: 7, TargetBranch: "master", CreatedAt: now.Add(-4 * day)},
+	}, models.StatusConflicts, "")
+	report.Changes = &models.ReportDiff{
+		Persisting: []models.ConflictChange{
+			{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 1}, FirstSeen: now.Add(-3 * day)},
+			{RepositoryID: 2, MergeRequest: models.MergeRequest{ID: 7}, FirstSeen: now.Add(-4 * day)},
+		},
+		New: []models.ConflictChange{{RepositoryID: 1, MergeRequest: models.MergeRequest{ID: 2}, FirstSeen: now}},
+	}
+
+	// Only the ledger protects master
+	client := fakeFinder{
+		files:     map[int][]string{1: {"go.mod", "main.go"}},
+		protected: map[int][]string{1: {"master"}},
+		lookups:   make(map[int]int),
+	}
+	scorer := NewScorer(client, config.SeverityConfig{
+		SLA:        7 * day,
+		Namespaces: []config.NamespaceSLA{{Namespace: "payments", SLA: 2 * day}},
+	})
+	scorer.Run(context.Background(), report, now)
+
+	assert.Equal(t, 1, report.SLABreaches)
+	// Each target branch is looked up once
+	assert.Equal(t, map[int]int{1: 1, 2: 1}, client.lookups)
+
+	ledger := report.Repositories[0]
+	require.Len(t, ledger.Severities, 2)
+	// 3 days in conflict, 10 days old, 2 files, protected master and a failed pipeline
+	assert.Equal(t, models.Severity{
+		MergeRequestID:   1,
+		Score:            15 + 5 + 8 + 15 + 15,
+		Level:            models.SeverityHigh,
+		InConflictSince:  now.Add(-3 * day),
+		ConflictingFiles: 2,
+		SLADeadline:      now.Add(-day),
+		Breached:         true,
+	}, ledger.Severities[0])
+	// The provider cannot tell, so the changed files are counted
+	fresh, ok := ledger.Severity(2)
+	require.True(t, ok)
+	assert.Equal(t, 3, fresh.ConflictingFiles)
+	assert.Equal(t, 12+15, fresh.Score)
+	assert.Equal(t, models.SeverityMedium, fresh.Level)
+	assert.False(t, fresh.Breached)
+
+	app, ok := report.Repositories[1].Severity(7)
+	require.True(t, ok)
+	assert.Equal(t, 20+2, app.Score)
+	assert.Equal(t, now.Add(3*day), app.SLADeadline)
+	assert.False(t, app.Breached)
+}
+
+func TestScorer_Run_NoSLA(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	report := &models.Report{}
+	report.AddRepository(models.Repository{ID: 1}, []models.MergeRequest{{ID: 1, TargetBranch: "master"}}, models.StatusConflicts, "")
+
+	NewScorer(nil, config.SeverityConfig{}).Run(context.Background(), report, now)
+
+	severity, ok := report.Repositories[0].Severity(1)
+	require.True(t, ok)
+	assert.Equal(t, now, severity.InConflictSince)
+	// Without a provider telling otherwise, master is not protected
+	assert.Zero(t, severity.Score)
+	assert.True(t, severity.SLADeadline.IsZero())
+	assert.Zero(t, report.SLABreaches)
+}
+
+func TestScorer_Run_CapsDowngradedAtLow(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := models.Repository{ID: 1, Downgrades: []models.Downgrade{{MergeRequestID: 1, Reason: "parked"}}}
+	report := &models.Report{}
+	report.AddRepository(repo, []models.MergeRequest{
+		{ID: 1, TargetBranch: "master", CreatedAt: now.Add(-30 * day), HeadPipeline: &models.Pipeline{Status: "failed"}},
+		{ID: 2, TargetBranch: "master", CreatedAt: now.Add(-30 * day), HeadPipeline: &models.Pipeline{Status: "failed"}},
+	}, models.StatusConflicts, "")
+
+	NewScorer(nil, config.SeverityConfig{}).Run(context.Background(), report, now)
+
+	downgraded, ok := report.Repositories[0].Severity(1)
+	require.True(t, ok)
+	assert.Equal(t, models.SeverityLow, downgraded.Level)
+	assert.Equal(t, mediumScore-1, downgraded.Score)
+
+	other, ok := report.Repositories[0].Severity(2)
+	require.True(t, ok)
+	assert.Equal(t, models.SeverityMedium, other.Level)
+}